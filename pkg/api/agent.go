@@ -12,6 +12,7 @@ import (
 	hooks "github.com/stellarlinkco/agentsdk-go/pkg/hooks"
 	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
 	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+	toolbuiltin "github.com/stellarlinkco/agentsdk-go/pkg/tool/builtin"
 )
 
 var newTracer = NewTracer
@@ -114,18 +115,36 @@ func New(ctx context.Context, opts Options) (*Runtime, error) {
 		log.Printf("subagent loader warning: %v", err)
 	}
 	opts.subMgr = subMgr
+	opts.todos = toolbuiltin.NewTodoStore()
+	opts.shells = toolbuiltin.NewShellManager()
+	opts.files = toolbuiltin.NewFileStateTracker()
+	opts.lsp = toolbuiltin.NewLSPManager(opts.ProjectRoot, lspServersFromSettings(settings))
+	opts.sql = toolbuiltin.NewSQLManager(sqlConnectionsFromSettings(settings))
+	opts.tasks = newSubagentTaskRunner(subMgr)
+	if opts.CodeIndex {
+		opts.codeIndex = toolbuiltin.NewCodeIndex(opts.ProjectRoot)
+	}
 
+	mcpServers := collectMCPServers(settings, opts.MCPServers)
+	if err := configureMCPOAuth(opts, mcpServers); err != nil {
+		return nil, err
+	}
 	registry := tool.NewRegistry()
+	registry.OnMCPResourceChange(opts.MCPResourceChanged)
+	registry.SetRepairHook(opts.ParamRepaired)
+	registry.SetMCPClientHandlers(mcpClientHandlers(opts, settings, mcpServers, sbRoot))
 	if err := registerTools(registry, opts, settings, opts.skReg); err != nil {
 		return nil, err
 	}
-	mcpServers := collectMCPServers(settings, opts.MCPServers)
 	if err := registerMCPServers(ctx, registry, sbox, mcpServers); err != nil {
 		return nil, err
 	}
+	registerMCPPromptSkills(ctx, registry, opts.skReg)
 	executor := tool.NewExecutor(registry, sbox).
 		WithOutputPersister(tool.NewOutputPersister()).
-		WithMaxOutputSize(opts.MaxToolOutputSize)
+		WithMaxOutputSize(opts.MaxToolOutputSize).
+		WithPolicies(toolPolicyOverrides(settings)).
+		WithParamRepair(true)
 
 	hooks := newHookExecutor(opts, settings)
 	compactor := newCompactor(opts.AutoCompact, opts.TokenLimit)
@@ -150,6 +169,28 @@ func New(ctx context.Context, opts Options) (*Runtime, error) {
 	opts.SystemPromptBuilder = builder
 	opts.SystemPrompt = builder.Build()
 
+	if opts.ToolResultCache || opts.codeIndex != nil {
+		// One watcher serves both the result cache and the code index.
+		tree, err := tool.NewTreeWatcher(opts.ProjectRoot)
+		if err != nil {
+			log.Printf("file watcher warning: %v", err)
+		}
+		opts.tree = tree
+		opts.codeIndex.SetTreeWatcher(tree)
+	}
+	if opts.ToolResultCache {
+		if opts.tree == nil {
+			// Results could not be invalidated on outside edits.
+			log.Printf("tool result cache disabled: no file watcher")
+		} else {
+			opts.resultCache = tool.NewResultCache(0)
+			if err := opts.resultCache.WatchTree(opts.tree); err != nil {
+				log.Printf("tool result cache watcher warning: %v", err)
+			}
+			executor = executor.WithResultCache(opts.resultCache)
+		}
+	}
+
 	histories := newHistoryStore(opts.MaxSessions)
 
 	rt := &Runtime{
@@ -162,7 +203,10 @@ func New(ctx context.Context, opts Options) (*Runtime, error) {
 		compactor: compactor,
 		deferred:  newDeferredToolState(registry),
 	}
+	histories.onEvict = rt.handleSessionEvict
+	opts.tasks.rt = rt
 	rt.bindSubagentCallbacks()
+	opts.codeIndex.Start()
 	return rt, nil
 }
 
@@ -304,7 +348,14 @@ func (rt *Runtime) Close() error {
 		rt.closed = true
 		rt.runMu.Unlock()
 
+		rt.opts.tasks.close()
 		rt.runWG.Wait()
+		rt.opts.shells.Close()
+		rt.opts.lsp.Close()
+		rt.opts.sql.Close()
+		_ = rt.opts.resultCache.Close() //nolint:errcheck // best-effort watcher shutdown
+		_ = rt.opts.codeIndex.Close()   //nolint:errcheck // best-effort watcher shutdown
+		_ = rt.opts.tree.Close()        //nolint:errcheck // best-effort watcher shutdown
 
 		var err error
 		if rt.histories != nil {
@@ -330,6 +381,24 @@ func (rt *Runtime) Close() error {
 	return rt.closeErr
 }
 
+// DeleteSession drops the session's history and releases its per-session
+// state, including todos and background shells. It reports whether the
+// session was known to the runtime.
+func (rt *Runtime) DeleteSession(sessionID string) bool {
+	if rt == nil || rt.histories == nil {
+		return false
+	}
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" {
+		return false
+	}
+	deleted := rt.histories.Delete(sessionID)
+	if err := cleanupBashOutputSessionDir(sessionID); err != nil {
+		log.Printf("api: session %q temp cleanup failed: %v", sessionID, err)
+	}
+	return deleted
+}
+
 // Config returns the last loaded project config.
 func (rt *Runtime) Config() *config.Settings {
 	if rt == nil {
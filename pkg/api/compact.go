@@ -23,6 +23,10 @@ const (
 	defaultCompactPreserve    = 5
 	defaultClaudeContextLimit = 200000
 	defaultMicroTriggerBuffer = 4
+
+	// compactSummaryPrefix heads the synthetic system message that replaces
+	// compacted history.
+	compactSummaryPrefix = "## Summary\n\n"
 )
 
 func (c CompactConfig) withDefaults() CompactConfig {
@@ -117,7 +121,7 @@ func (c *compactor) maybeCompact(ctx context.Context, hist *message.History, mdl
 	out := make([]message.Message, 0, 1+len(snapshot[cut:]))
 	out = append(out, message.Message{
 		Role:    "system",
-		Content: "## Summary\n\n" + summary,
+		Content: compactSummaryPrefix + summary,
 	})
 	out = append(out, snapshot[cut:]...)
 	hist.Replace(out)
@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/stellarlinkco/agentsdk-go/pkg/config"
 	hooks "github.com/stellarlinkco/agentsdk-go/pkg/hooks"
@@ -14,6 +15,7 @@ import (
 	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/skills"
 	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
 	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+	toolbuiltin "github.com/stellarlinkco/agentsdk-go/pkg/tool/builtin"
 )
 
 type namedTool struct{ name string }
@@ -194,7 +196,7 @@ func TestRegisterToolsUsesDefaultImplementations(t *testing.T) {
 		t.Fatalf("register tools: %v", err)
 	}
 	tools := registry.List()
-	expected := []string{"bash", "read", "write", "edit", "glob", "grep", "skill"}
+	expected := []string{"bash", "bash_output", "kill_shell", "read", "write", "edit", "multi_edit", "apply_patch", "notebook_edit", "glob", "grep", "skill", "todo_write", "todo_read"}
 	if len(tools) != len(expected) {
 		t.Fatalf("expected %d default tools, got %d", len(expected), len(tools))
 	}
@@ -264,6 +266,55 @@ func TestRegisterToolsSkipsDuplicateNames(t *testing.T) {
 	}
 }
 
+func TestRegisterToolsLoadsCommandTools(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, ".agents", "tools")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "run_tests.yaml"), []byte("command: make test PKG={{.package}}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bash.yaml"), []byte("command: echo shadowed\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	opts := Options{ProjectRoot: root, EnabledBuiltinTools: []string{"bash"}}
+	registry := tool.NewRegistry()
+	if err := registerTools(registry, opts, nil, nil); err != nil {
+		t.Fatalf("register tools: %v", err)
+	}
+
+	embedRoot := t.TempDir()
+	embedded := fstest.MapFS{".agents/tools/lint.yaml": {Data: []byte("command: make lint\n")}}
+	embedOpts := Options{ProjectRoot: embedRoot, EnabledBuiltinTools: []string{}, fsLayer: config.NewFS(embedRoot, embedded)}
+	if err := registerTools(registry, embedOpts, nil, nil); err != nil {
+		t.Fatalf("register embedded tools: %v", err)
+	}
+
+	for _, name := range []string{"run_tests", "lint"} {
+		impl, err := registry.Get(name)
+		if err != nil {
+			t.Fatalf("command tool %s not registered: %v", name, err)
+		}
+		if _, ok := impl.(*toolbuiltin.CommandTool); !ok {
+			t.Fatalf("%s should be a command tool, got %T", name, impl)
+		}
+	}
+	if impl, _ := registry.Get("bash"); impl == nil {
+		t.Fatal("bash builtin missing")
+	} else if _, ok := impl.(*toolbuiltin.BashTool); !ok {
+		t.Fatalf("command tool must not shadow the bash builtin: %+v", impl)
+	}
+
+	registry = tool.NewRegistry()
+	if err := registerTools(registry, Options{ProjectRoot: root, Tools: []tool.Tool{&namedTool{name: "only"}}}, nil, nil); err != nil {
+		t.Fatalf("register tools: %v", err)
+	}
+	if len(registry.List()) != 1 {
+		t.Fatalf("explicit Tools should replace command tools, got %d tools", len(registry.List()))
+	}
+}
+
 func TestRegisterToolsWhitelistCaseInsensitive(t *testing.T) {
 	registry := tool.NewRegistry()
 	opts := Options{ProjectRoot: t.TempDir(), EnabledBuiltinTools: []string{"BASH", "GrEp", "READ"}}
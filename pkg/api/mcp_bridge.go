@@ -1,10 +1,16 @@
 package api
 
 import (
+	"context"
 	"fmt"
+	"log"
 	"strings"
 
 	"github.com/stellarlinkco/agentsdk-go/pkg/config"
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp/oauth"
+	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/skills"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
 )
 
 type mcpServer struct {
@@ -17,6 +23,9 @@ type mcpServer struct {
 	EnabledTools       []string
 	DisabledTools      []string
 	ToolTimeoutSeconds int
+	Sampling           *config.MCPSamplingConfig
+	OAuth              *config.MCPOAuthConfig
+	authorizer         *oauth.Authorizer
 }
 
 // collectMCPServers merges explicit API inputs, settings.json entries, and
@@ -72,6 +81,8 @@ func collectMCPServers(settings *config.Settings, explicit []string) []mcpServer
 				EnabledTools:       cfg.EnabledTools,
 				DisabledTools:      cfg.DisabledTools,
 				ToolTimeoutSeconds: cfg.ToolTimeoutSeconds,
+				Sampling:           cfg.Sampling,
+				OAuth:              cfg.OAuth,
 			})
 		}
 	}
@@ -117,3 +128,133 @@ func matchesRule(name, target string, rules []config.MCPServerRule) bool {
 	}
 	return false
 }
+
+// registerMCPPromptSkills exposes every prompt of the connected MCP servers as
+// a manually invoked skill named mcp-<server>-<prompt>. Prompt arguments are
+// read from skills.ArgumentsMetadataKey, which the skill tool fills from its
+// "args" parameter and requests fill from Request.Metadata.
+func registerMCPPromptSkills(ctx context.Context, registry *tool.Registry, skReg *skills.Registry) {
+	if registry == nil || skReg == nil {
+		return
+	}
+	prompts, err := registry.ListMCPPrompts(ctx)
+	if err != nil {
+		log.Printf("mcp prompt loader warning: %v", err)
+	}
+	for _, prompt := range prompts {
+		def := skills.Definition{
+			Name:                  mcpPromptSkillName(prompt.Server, prompt.Name),
+			Description:           mcpPromptDescription(prompt),
+			DisableAutoActivation: true,
+			Metadata:              map[string]string{"source": "mcp:" + prompt.Server},
+		}
+		if err := skReg.Register(def, mcpPromptHandler(registry, prompt)); err != nil {
+			log.Printf("mcp prompt %s/%s skipped: %v", prompt.Server, prompt.Name, err)
+		}
+	}
+}
+
+// mcpPromptSkillName folds server and prompt names into the skill name
+// alphabet: lowercase alphanumerics separated by single hyphens.
+func mcpPromptSkillName(server, prompt string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower("mcp-" + server + "-" + prompt) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(r)
+			continue
+		}
+		hyphen = true
+	}
+	name := b.String()
+	if len(name) > 64 {
+		name = strings.TrimRight(name[:64], "-")
+	}
+	return name
+}
+
+func mcpPromptDescription(prompt tool.MCPPrompt) string {
+	desc := strings.TrimSpace(prompt.Description)
+	if desc == "" {
+		desc = fmt.Sprintf("MCP prompt %s from %s.", prompt.Name, prompt.Server)
+	}
+	var args []string
+	for _, arg := range prompt.Arguments {
+		if arg == nil || strings.TrimSpace(arg.Name) == "" {
+			continue
+		}
+		entry := arg.Name
+		if arg.Required {
+			entry += " (required)"
+		}
+		if text := strings.TrimSpace(arg.Description); text != "" {
+			entry += ": " + text
+		}
+		args = append(args, entry)
+	}
+	if len(args) == 0 {
+		return desc
+	}
+	return desc + " Arguments: " + strings.Join(args, "; ")
+}
+
+func mcpPromptHandler(registry *tool.Registry, prompt tool.MCPPrompt) skills.Handler {
+	return skills.HandlerFunc(func(ctx context.Context, ac skills.ActivationContext) (skills.Result, error) {
+		args := mcpPromptArguments(ac.Metadata[skills.ArgumentsMetadataKey])
+		for _, arg := range prompt.Arguments {
+			if arg != nil && arg.Required && strings.TrimSpace(args[arg.Name]) == "" {
+				return skills.Result{}, fmt.Errorf("mcp prompt %s: missing required argument %q", prompt.Name, arg.Name)
+			}
+		}
+		res, err := registry.GetMCPPrompt(ctx, prompt.Server, prompt.Name, args)
+		if err != nil {
+			return skills.Result{}, err
+		}
+		return skills.Result{Output: renderMCPPrompt(res)}, nil
+	})
+}
+
+func mcpPromptArguments(raw any) map[string]string {
+	out := map[string]string{}
+	switch v := raw.(type) {
+	case map[string]string:
+		for key, value := range v {
+			out[key] = value
+		}
+	case map[string]any:
+		for key, value := range v {
+			if value != nil {
+				out[key] = fmt.Sprint(value)
+			}
+		}
+	}
+	return out
+}
+
+// renderMCPPrompt flattens the prompt messages into text. Assistant turns are
+// labelled so a multi-turn template still reads correctly as one block.
+func renderMCPPrompt(res *mcp.GetPromptResult) string {
+	if res == nil {
+		return ""
+	}
+	var parts []string
+	for _, msg := range res.Messages {
+		if msg == nil {
+			continue
+		}
+		text, ok := msg.Content.(*mcp.TextContent)
+		if !ok || strings.TrimSpace(text.Text) == "" {
+			continue
+		}
+		if msg.Role == "assistant" {
+			parts = append(parts, "[assistant] "+text.Text)
+			continue
+		}
+		parts = append(parts, text.Text)
+	}
+	return strings.Join(parts, "\n\n")
+}
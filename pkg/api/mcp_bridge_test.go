@@ -1,9 +1,12 @@
 package api
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stellarlinkco/agentsdk-go/pkg/config"
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
 )
 
 func TestAllowedByManagedPoliciesPrefersDeny(t *testing.T) {
@@ -109,3 +112,40 @@ func TestAllowedByManagedPoliciesAllowList(t *testing.T) {
 		t.Fatalf("expected allowlist to deny non-matching target")
 	}
 }
+
+func TestMCPPromptSkillName(t *testing.T) {
+	if got := mcpPromptSkillName("Docs Server", "summarize_topic"); got != "mcp-docs-server-summarize-topic" {
+		t.Fatalf("unexpected name %q", got)
+	}
+	if got := mcpPromptSkillName("a", strings.Repeat("x", 80)); len(got) != 64 || strings.HasSuffix(got, "-") {
+		t.Fatalf("name should be capped at 64 chars: %q", got)
+	}
+}
+
+func TestMCPPromptDescriptionListsArguments(t *testing.T) {
+	desc := mcpPromptDescription(tool.MCPPrompt{
+		Server: "docs",
+		Name:   "summarize",
+		Arguments: []*mcp.PromptArgument{
+			{Name: "topic", Required: true, Description: "What to summarize"},
+			{Name: "tone"},
+		},
+	})
+	if desc != "MCP prompt summarize from docs. Arguments: topic (required): What to summarize; tone" {
+		t.Fatalf("unexpected description %q", desc)
+	}
+}
+
+func TestRenderMCPPrompt(t *testing.T) {
+	res := &mcp.GetPromptResult{Messages: []*mcp.PromptMessage{
+		{Role: "user", Content: &mcp.TextContent{Text: "Summarize MCP"}},
+		{Role: "assistant", Content: &mcp.TextContent{Text: "Sure."}},
+		{Role: "user", Content: &mcp.ImageContent{MIMEType: "image/png"}},
+	}}
+	if got := renderMCPPrompt(res); got != "Summarize MCP\n\n[assistant] Sure." {
+		t.Fatalf("unexpected rendering %q", got)
+	}
+	if args := mcpPromptArguments(map[string]any{"n": 3, "skip": nil}); args["n"] != "3" || len(args) != 1 {
+		t.Fatalf("unexpected arguments %v", args)
+	}
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/config"
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+// defaultMCPSamplingMaxTokens caps sampling requests when no policy sets
+// MaxTokens.
+const defaultMCPSamplingMaxTokens = 1024
+
+// MCPSamplingPolicy decides whether and how the runtime model answers
+// sampling/createMessage requests from MCP servers.
+type MCPSamplingPolicy struct {
+	Enabled   bool
+	ModelTier ModelTier // Model pool tier to sample with; empty or missing tiers use Options.Model.
+	MaxTokens int       // Cap on tokens per request; 0 uses a default of 1024.
+}
+
+// mcpClientHandlers builds the handlers the tool registry installs on every
+// MCP client: sampling through the runtime models, elicitation through
+// Options.MCPElicitation or the stream, and the sandbox roots.
+func mcpClientHandlers(opts Options, settings *config.Settings, servers []mcpServer, sbRoot string) tool.MCPClientHandlers {
+	handlers := tool.MCPClientHandlers{
+		Elicit: mcpElicitationHandler(opts.MCPElicitation),
+		Roots:  mcpRoots(append(append([]string{sbRoot}, additionalSandboxPaths(settings)...), opts.Sandbox.AllowedPaths...)),
+	}
+	sampler := newMCPSampler(opts, settings, servers)
+	if sampler.enabledAnywhere() {
+		handlers.CreateMessage = sampler.createMessage
+	}
+	return handlers
+}
+
+type mcpSampler struct {
+	defaults  MCPSamplingPolicy
+	servers   map[string]*config.MCPSamplingConfig
+	model     model.Model
+	pool      map[ModelTier]model.Model
+	modelName string
+}
+
+func newMCPSampler(opts Options, settings *config.Settings, servers []mcpServer) *mcpSampler {
+	s := &mcpSampler{
+		servers: map[string]*config.MCPSamplingConfig{},
+		model:   opts.Model,
+		pool:    opts.ModelPool,
+	}
+	if opts.MCPSampling != nil {
+		s.defaults = *opts.MCPSampling
+	}
+	for _, server := range servers {
+		if server.Sampling != nil {
+			s.servers[mcpServerLabel(server)] = server.Sampling
+		}
+	}
+	if settings != nil {
+		s.modelName = strings.TrimSpace(settings.Model)
+	}
+	return s
+}
+
+func mcpServerLabel(server mcpServer) string {
+	if server.Name != "" {
+		return server.Name
+	}
+	return server.Spec
+}
+
+// policy applies the per-server settings on top of the runtime default.
+func (s *mcpSampler) policy(server string) MCPSamplingPolicy {
+	policy := s.defaults
+	cfg := s.servers[server]
+	if cfg == nil {
+		return policy
+	}
+	if cfg.Enabled != nil {
+		policy.Enabled = *cfg.Enabled
+	}
+	if tier := strings.TrimSpace(cfg.ModelTier); tier != "" {
+		policy.ModelTier = ModelTier(tier)
+	}
+	if cfg.MaxTokens > 0 {
+		policy.MaxTokens = cfg.MaxTokens
+	}
+	return policy
+}
+
+func (s *mcpSampler) enabledAnywhere() bool {
+	if s.defaults.Enabled {
+		return true
+	}
+	for server := range s.servers {
+		if s.policy(server).Enabled {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *mcpSampler) createMessage(ctx context.Context, server string, params *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error) {
+	policy := s.policy(server)
+	if !policy.Enabled {
+		return nil, fmt.Errorf("sampling is disabled for MCP server %s", server)
+	}
+	mdl, name := s.model, s.modelName
+	if m, ok := s.pool[policy.ModelTier]; ok && m != nil {
+		mdl, name = m, string(policy.ModelTier)
+	}
+	if mdl == nil {
+		return nil, errors.New("sampling: no model configured")
+	}
+	if name == "" {
+		name = "default"
+	}
+
+	maxTokens := policy.MaxTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMCPSamplingMaxTokens
+	}
+	if params.MaxTokens > 0 && int(params.MaxTokens) < maxTokens {
+		maxTokens = int(params.MaxTokens)
+	}
+	req := model.Request{
+		System:    params.SystemPrompt,
+		MaxTokens: maxTokens,
+	}
+	if params.Temperature != 0 {
+		temperature := params.Temperature
+		req.Temperature = &temperature
+	}
+	for _, msg := range params.Messages {
+		converted, err := samplingMessageToModel(msg)
+		if err != nil {
+			return nil, err
+		}
+		req.Messages = append(req.Messages, converted)
+	}
+	if len(req.Messages) == 0 {
+		return nil, errors.New("sampling: request has no messages")
+	}
+
+	resp, err := mdl.Complete(ctx, req)
+	if err != nil {
+		return nil, fmt.Errorf("sampling for MCP server %s: %w", server, err)
+	}
+	return &mcp.CreateMessageResult{
+		Role:       "assistant",
+		Model:      name,
+		Content:    &mcp.TextContent{Text: resp.Message.Content},
+		StopReason: samplingStopReason(resp.StopReason),
+	}, nil
+}
+
+func samplingMessageToModel(msg *mcp.SamplingMessage) (model.Message, error) {
+	if msg == nil {
+		return model.Message{}, errors.New("sampling: message is nil")
+	}
+	role := string(msg.Role)
+	if role != "assistant" {
+		role = "user"
+	}
+	switch content := msg.Content.(type) {
+	case *mcp.TextContent:
+		return model.Message{Role: role, Content: content.Text}, nil
+	case *mcp.ImageContent:
+		return model.Message{Role: role, ContentBlocks: []model.ContentBlock{{
+			Type:      model.ContentBlockImage,
+			MediaType: content.MIMEType,
+			Data:      base64.StdEncoding.EncodeToString(content.Data),
+		}}}, nil
+	default:
+		return model.Message{}, fmt.Errorf("sampling: unsupported content %T", msg.Content)
+	}
+}
+
+// samplingStopReason maps provider stop reasons to the MCP spellings.
+func samplingStopReason(reason string) string {
+	switch reason {
+	case "end_turn", "stop":
+		return "endTurn"
+	case "max_tokens", "length":
+		return "maxTokens"
+	case "stop_sequence":
+		return "stopSequence"
+	default:
+		return reason
+	}
+}
+
+func mcpElicitationHandler(fn func(context.Context, string, *mcp.ElicitParams) (*mcp.ElicitResult, error)) func(context.Context, string, *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+	if fn != nil {
+		return fn
+	}
+	return func(ctx context.Context, server string, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+		// Streams are one-way, so the request is surfaced and then cancelled.
+		if emit := streamEmitFromContext(ctx); emit != nil {
+			emit(ctx, StreamEvent{
+				Type: EventMCPElicitation,
+				Name: server,
+				Output: map[string]any{
+					"message":          params.Message,
+					"requested_schema": params.RequestedSchema,
+				},
+			})
+		}
+		return &mcp.ElicitResult{Action: "cancel"}, nil
+	}
+}
+
+// mcpRoots advertises the sandbox directories as file:// roots.
+func mcpRoots(paths []string) []*mcp.Root {
+	seen := map[string]struct{}{}
+	var roots []*mcp.Root
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			continue
+		}
+		slashed := filepath.ToSlash(abs)
+		if !strings.HasPrefix(slashed, "/") {
+			slashed = "/" + slashed
+		}
+		uri := (&url.URL{Scheme: "file", Path: slashed}).String()
+		if _, ok := seen[uri]; ok {
+			continue
+		}
+		seen[uri] = struct{}{}
+		roots = append(roots, &mcp.Root{URI: uri, Name: filepath.Base(abs)})
+	}
+	return roots
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/config"
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+)
+
+func TestMCPSamplerPolicyAndModel(t *testing.T) {
+	main, low := &recordingModel{}, &recordingModel{}
+	disabled, enabled := false, true
+	settings := &config.Settings{Model: "claude-test", MCP: &config.MCPConfig{Servers: map[string]config.MCPServerConfig{
+		"cheap":  {Type: "http", URL: "http://cheap.example", Sampling: &config.MCPSamplingConfig{Enabled: &enabled, ModelTier: "low", MaxTokens: 50}},
+		"denied": {Type: "http", URL: "http://denied.example", Sampling: &config.MCPSamplingConfig{Enabled: &disabled}},
+	}}}
+	opts := Options{
+		Model:       main,
+		ModelPool:   map[ModelTier]model.Model{ModelTierLow: low},
+		MCPSampling: &MCPSamplingPolicy{Enabled: true},
+	}
+	handlers := mcpClientHandlers(opts, settings, collectMCPServers(settings, []string{"http://plain.example"}), t.TempDir())
+	if handlers.CreateMessage == nil {
+		t.Fatal("sampling should be advertised when a policy enables it")
+	}
+	params := &mcp.CreateMessageParams{
+		MaxTokens:    5000,
+		SystemPrompt: "be brief",
+		Messages:     []*mcp.SamplingMessage{{Role: "user", Content: &mcp.TextContent{Text: "hi"}}},
+	}
+
+	res, err := handlers.CreateMessage(context.Background(), "http://plain.example", params)
+	if err != nil {
+		t.Fatalf("default policy: %v", err)
+	}
+	if res.Model != "claude-test" || res.StopReason != "endTurn" || res.Content.(*mcp.TextContent).Text != "ok" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if req := main.requests[0]; req.MaxTokens != defaultMCPSamplingMaxTokens || req.System != "be brief" || req.Messages[0].Content != "hi" {
+		t.Fatalf("unexpected model request %+v", req)
+	}
+
+	res, err = handlers.CreateMessage(context.Background(), "cheap", params)
+	if err != nil || res.Model != "low" || len(low.requests) != 1 || low.requests[0].MaxTokens != 50 {
+		t.Fatalf("per-server tier and cap not applied: %+v %v %+v", res, err, low.requests)
+	}
+
+	if _, err := handlers.CreateMessage(context.Background(), "denied", params); err == nil || !strings.Contains(err.Error(), "disabled") {
+		t.Fatalf("disabled server should be refused, got %v", err)
+	}
+}
+
+func TestMCPClientHandlersWithoutSampling(t *testing.T) {
+	handlers := mcpClientHandlers(Options{Model: &recordingModel{}}, nil, nil, t.TempDir())
+	if handlers.CreateMessage != nil {
+		t.Fatal("sampling must stay unadvertised without a policy")
+	}
+	if handlers.Elicit == nil || len(handlers.Roots) != 1 {
+		t.Fatalf("elicitation and roots should always be wired: %+v", handlers)
+	}
+}
+
+func TestMCPElicitationFallsBackToStream(t *testing.T) {
+	var events []StreamEvent
+	ctx := withStreamEmit(context.Background(), func(_ context.Context, evt StreamEvent) { events = append(events, evt) })
+	elicit := mcpElicitationHandler(nil)
+	res, err := elicit(ctx, "docs", &mcp.ElicitParams{Message: "name?"})
+	if err != nil || res.Action != "cancel" {
+		t.Fatalf("unexpected result %+v %v", res, err)
+	}
+	if len(events) != 1 || events[0].Type != EventMCPElicitation || events[0].Name != "docs" {
+		t.Fatalf("unexpected events %+v", events)
+	}
+
+	custom := mcpElicitationHandler(func(context.Context, string, *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+		return &mcp.ElicitResult{Action: "accept", Content: map[string]any{"name": "ada"}}, nil
+	})
+	if res, _ := custom(ctx, "docs", &mcp.ElicitParams{}); res.Action != "accept" || len(events) != 1 {
+		t.Fatalf("callback should take precedence over the stream: %+v", res)
+	}
+}
+
+func TestMCPRoots(t *testing.T) {
+	dir := t.TempDir()
+	roots := mcpRoots([]string{dir, dir, " ", filepath.Join(dir, "sub")})
+	if len(roots) != 2 {
+		t.Fatalf("expected deduplicated roots, got %+v", roots)
+	}
+	if !strings.HasPrefix(roots[0].URI, "file:///") || roots[0].Name != filepath.Base(dir) {
+		t.Fatalf("unexpected root %+v", roots[0])
+	}
+}
@@ -7,7 +7,9 @@ import (
 	"time"
 	_ "unsafe"
 
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/skills"
 	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
 )
 
@@ -99,3 +101,48 @@ func TestRegisterMCPServersWithToolOptionsUsesOptionsDialer(t *testing.T) {
 		t.Fatalf("expected disabled tools propagated, got %+v", optCounter.lastOps.DisabledTools)
 	}
 }
+
+func TestRegisterMCPPromptSkills(t *testing.T) {
+	server := mcpsdk.NewServer(&mcpsdk.Implementation{Name: "docs", Version: "1"}, nil)
+	server.AddTool(&mcpsdk.Tool{Name: "noop", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcpsdk.CallToolRequest) (*mcpsdk.CallToolResult, error) {
+		return &mcpsdk.CallToolResult{}, nil
+	})
+	server.AddPrompt(&mcpsdk.Prompt{
+		Name:      "summarize",
+		Arguments: []*mcpsdk.PromptArgument{{Name: "topic", Required: true}},
+	}, func(_ context.Context, req *mcpsdk.GetPromptRequest) (*mcpsdk.GetPromptResult, error) {
+		return &mcpsdk.GetPromptResult{Messages: []*mcpsdk.PromptMessage{{Role: "user", Content: &mcpsdk.TextContent{Text: "Summarize " + req.Params.Arguments["topic"]}}}}, nil
+	})
+
+	orig := patchedNewMCPClient
+	patchedNewMCPClient = func(ctx context.Context, _ string, _ func(context.Context, *mcp.ClientSession)) (*mcp.ClientSession, error) {
+		serverTransport, clientTransport := mcpsdk.NewInMemoryTransports()
+		if _, err := server.Connect(ctx, serverTransport, nil); err != nil {
+			return nil, err
+		}
+		return mcpsdk.NewClient(&mcpsdk.Implementation{Name: "test", Version: "1"}, nil).Connect(ctx, clientTransport, nil)
+	}
+	defer func() { patchedNewMCPClient = orig }()
+
+	reg := tool.NewRegistry()
+	defer reg.Close()
+	if err := registerMCPServers(context.Background(), reg, nil, []mcpServer{{Name: "docs", Spec: "stdio://docs"}}); err != nil {
+		t.Fatalf("register MCP server: %v", err)
+	}
+	skReg := skills.NewRegistry()
+	registerMCPPromptSkills(context.Background(), reg, skReg)
+
+	skill, ok := skReg.Get("mcp-docs-summarize")
+	if !ok || !skill.Definition().DisableAutoActivation || skill.Definition().Metadata["source"] != "mcp:docs" {
+		t.Fatalf("prompt skill not registered as manual skill: %+v", skReg.List())
+	}
+	if _, err := skReg.Execute(context.Background(), "mcp-docs-summarize", skills.ActivationContext{}); err == nil {
+		t.Fatal("missing required argument should fail")
+	}
+	res, err := skReg.Execute(context.Background(), "mcp-docs-summarize", skills.ActivationContext{
+		Metadata: map[string]any{skills.ArgumentsMetadataKey: map[string]string{"topic": "MCP"}},
+	})
+	if err != nil || res.Output != "Summarize MCP" {
+		t.Fatalf("execute prompt skill: %+v %v", res, err)
+	}
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp/oauth"
+)
+
+// mcpOAuthTimeout bounds the interactive login of one MCP server. It replaces
+// the much shorter connect timeout, which a user in a browser cannot meet.
+const mcpOAuthTimeout = 5 * time.Minute
+
+// configureMCPOAuth attaches an authorizer to every server with an oauth
+// block. All of them share one credentials store.
+func configureMCPOAuth(opts Options, servers []mcpServer) error {
+	var store oauth.Store
+	for i := range servers {
+		server := &servers[i]
+		if server.OAuth == nil {
+			continue
+		}
+		if store == nil {
+			store = mcpTokenStore(opts)
+		}
+		serverURL := server.URL
+		if serverURL == "" {
+			serverURL = server.Spec
+		}
+		cfg := oauth.Config{
+			ServerURL:    mcpOAuthResource(serverURL),
+			ClientID:     server.OAuth.ClientID,
+			ClientSecret: server.OAuth.ClientSecret,
+			Scopes:       append([]string(nil), server.OAuth.Scopes...),
+			RedirectPort: server.OAuth.RedirectPort,
+			Store:        store,
+		}
+		if opts.MCPAuthorize != nil {
+			label := mcpServerLabel(*server)
+			cfg.Authorize = func(ctx context.Context, authURL string) error {
+				return opts.MCPAuthorize(ctx, label, authURL)
+			}
+		}
+		authorizer, err := oauth.NewAuthorizer(cfg)
+		if err != nil {
+			return fmt.Errorf("api: MCP %s oauth: %w", mcpServerLabel(*server), err)
+		}
+		server.authorizer = authorizer
+	}
+	return nil
+}
+
+// mcpOAuthResource strips transport hints such as "https+stream://" or
+// "sse://" so the OAuth resource is the plain endpoint URL.
+func mcpOAuthResource(spec string) string {
+	scheme, rest, ok := strings.Cut(strings.TrimSpace(spec), "://")
+	if !ok {
+		return spec
+	}
+	scheme = strings.ToLower(scheme)
+	if scheme == "sse" {
+		return "https://" + rest
+	}
+	base, _, _ := strings.Cut(scheme, "+")
+	return base + "://" + rest
+}
+
+func mcpTokenStore(opts Options) oauth.Store {
+	if opts.MCPTokenStore != nil {
+		return opts.MCPTokenStore
+	}
+	store, err := oauth.DefaultStore()
+	if err != nil {
+		// Tokens then live for this process only.
+		log.Printf("mcp oauth store warning: %v", err)
+		return oauth.NewMemoryStore()
+	}
+	return store
+}
+
+// preauthorizeMCP obtains a token before connecting so a pending login is not
+// cut short by the MCP connect timeout.
+func preauthorizeMCP(ctx context.Context, authorizer *oauth.Authorizer) error {
+	ctx, cancel := context.WithTimeout(ctx, mcpOAuthTimeout)
+	defer cancel()
+	_, err := authorizer.Token(ctx)
+	return err
+}
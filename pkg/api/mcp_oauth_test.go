@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/config"
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp/oauth"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// newOAuthMCPServer serves a streamable MCP server at /mcp that only accepts
+// tokens from the authorization server mounted next to it.
+func newOAuthMCPServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := mcpsdk.NewServer(&mcpsdk.Implementation{Name: "secure", Version: "1"}, nil)
+	server.AddTool(&mcpsdk.Tool{Name: "whoami", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcpsdk.CallToolRequest) (*mcpsdk.CallToolResult, error) {
+		return &mcpsdk.CallToolResult{Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: "user"}}}, nil
+	})
+	mcpHandler := mcpsdk.NewStreamableHTTPHandler(func(*http.Request) *mcpsdk.Server { return server }, nil)
+
+	var (
+		mu        sync.Mutex
+		challenge string
+	)
+	var srv *httptest.Server
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-protected-resource/mcp", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{"resource": srv.URL + "/mcp", "authorization_servers": []string{srv.URL}})
+	})
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, _ *http.Request) {
+		writeJSON(w, map[string]any{
+			"issuer":                           srv.URL,
+			"authorization_endpoint":           srv.URL + "/authorize",
+			"token_endpoint":                   srv.URL + "/token",
+			"registration_endpoint":            srv.URL + "/register",
+			"code_challenge_methods_supported": []string{"S256"},
+		})
+	})
+	mux.HandleFunc("/register", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, map[string]any{"client_id": "dynamic"})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		mu.Lock()
+		challenge = q.Get("code_challenge")
+		mu.Unlock()
+		http.Redirect(w, r, q.Get("redirect_uri")+"?code=ok&state="+url.QueryEscape(q.Get("state")), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		sum := sha256.Sum256([]byte(r.Form.Get("code_verifier")))
+		mu.Lock()
+		ok := r.Form.Get("code") == "ok" && base64.RawURLEncoding.EncodeToString(sum[:]) == challenge
+		mu.Unlock()
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			writeJSON(w, map[string]any{"error": "invalid_grant"})
+			return
+		}
+		writeJSON(w, map[string]any{"access_token": "secret", "token_type": "Bearer", "expires_in": 3600})
+	})
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.Header().Set("WWW-Authenticate", `Bearer resource_metadata="`+srv.URL+`/.well-known/oauth-protected-resource/mcp"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mcpHandler.ServeHTTP(w, r)
+	})
+	srv = httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestRegisterMCPServersAuthorizesWithOAuth(t *testing.T) {
+	srv := newOAuthMCPServer(t)
+	var authorized []string
+	opts := Options{
+		MCPTokenStore: oauth.NewMemoryStore(),
+		MCPAuthorize: func(ctx context.Context, server, authURL string) error {
+			authorized = append(authorized, server)
+			// Follow the redirect to the loopback listener like a browser.
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, authURL, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				return err
+			}
+			return resp.Body.Close()
+		},
+	}
+	settings := &config.Settings{MCP: &config.MCPConfig{Servers: map[string]config.MCPServerConfig{
+		"secure": {Type: "http", URL: strings.Replace(srv.URL, "http://", "http+stream://", 1) + "/mcp", OAuth: &config.MCPOAuthConfig{}},
+	}}}
+	servers := collectMCPServers(settings, nil)
+	if err := configureMCPOAuth(opts, servers); err != nil {
+		t.Fatalf("configure oauth: %v", err)
+	}
+
+	reg := tool.NewRegistry()
+	defer reg.Close()
+	if err := registerMCPServers(context.Background(), reg, nil, servers); err != nil {
+		t.Fatalf("register MCP server: %v", err)
+	}
+	if _, err := reg.Get("secure__whoami"); err != nil {
+		t.Fatalf("remote tool not registered: %v", err)
+	}
+	if len(authorized) != 1 || authorized[0] != "secure" {
+		t.Fatalf("expected one authorization for secure, got %v", authorized)
+	}
+}
+
+func TestConfigureMCPOAuth(t *testing.T) {
+	servers := []mcpServer{
+		{Name: "plain", Spec: "https://plain.example/mcp"},
+		{Name: "secure", Spec: "https://secure.example/mcp", OAuth: &config.MCPOAuthConfig{ClientID: "c"}},
+	}
+	if err := configureMCPOAuth(Options{MCPTokenStore: oauth.NewMemoryStore()}, servers); err != nil {
+		t.Fatalf("configure oauth: %v", err)
+	}
+	if servers[0].authorizer != nil || servers[1].authorizer == nil {
+		t.Fatalf("only servers with an oauth block get an authorizer: %+v", servers)
+	}
+
+	for spec, want := range map[string]string{
+		"https+stream://x.example/mcp": "https://x.example/mcp",
+		"sse://x.example/events":       "https://x.example/events",
+		"http://x.example/mcp":         "http://x.example/mcp",
+	} {
+		if got := mcpOAuthResource(spec); got != want {
+			t.Fatalf("mcpOAuthResource(%q) = %q, want %q", spec, got, want)
+		}
+	}
+
+	bad := []mcpServer{{Name: "local", Spec: "stdio://server", OAuth: &config.MCPOAuthConfig{}}}
+	if err := configureMCPOAuth(Options{MCPTokenStore: oauth.NewMemoryStore()}, bad); err == nil {
+		t.Fatal("expected error for non-http server")
+	}
+}
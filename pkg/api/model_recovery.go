@@ -38,9 +38,13 @@ func (rt *Runtime) completeWithRecovery(ctx context.Context, mdl model.Model, re
 			if !reactiveRetried && isPromptTooLongError(err) {
 				reactiveRetried = true
 				if comp := rt.reactiveCompactor(); comp != nil && hist != nil {
-					if _, compactErr := comp.reactiveCompact(ctx, hist, mdl); compactErr != nil {
+					compacted, compactErr := comp.reactiveCompact(ctx, hist, mdl)
+					if compactErr != nil {
 						return nil, compactErr
 					}
+					if compacted {
+						rt.reinjectTodos(hist, normalized.SessionID)
+					}
 					req.Messages = convertMessages(hist.All())
 				}
 				continue
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/config"
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const (
+	openAPISpecFetchTimeout = 30 * time.Second
+	openAPISpecMaxBytes     = 16 << 20
+)
+
+// openAPITools builds the tools for settings.openapi. Each service's tools
+// are named "<service>__<operation>" and deferred behind tool_search. A
+// service that fails to load is logged and skipped.
+func openAPITools(opts Options, settings *config.Settings) []tool.Tool {
+	if settings == nil || settings.OpenAPI == nil || len(settings.OpenAPI.Services) == 0 {
+		return nil
+	}
+	network := sandboxNetworkPolicy(opts, settings)
+	names := make([]string, 0, len(settings.OpenAPI.Services))
+	for name := range settings.OpenAPI.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var tools []tool.Tool
+	for _, name := range names {
+		cfg := settings.OpenAPI.Services[name]
+		spec, err := readOpenAPISpec(opts, network, cfg.Spec)
+		if err != nil {
+			log.Printf("openapi service %s skipped: %v", name, err)
+			continue
+		}
+		headers := make(map[string]string, len(cfg.Headers))
+		for key, value := range cfg.Headers {
+			headers[key] = expandSettingsVars(settings, value)
+		}
+		generated, err := tool.FromOpenAPI(spec, tool.OpenAPIOptions{
+			Prefix:     name + "__",
+			BaseURL:    strings.TrimSpace(cfg.BaseURL),
+			Headers:    headers,
+			Network:    network,
+			Operations: cfg.Operations,
+			Timeout:    time.Duration(cfg.TimeoutSeconds) * time.Second,
+		})
+		if err != nil {
+			log.Printf("openapi service %s skipped: %v", name, err)
+			continue
+		}
+		tools = append(tools, generated...)
+	}
+	return tools
+}
+
+// readOpenAPISpec loads a document from an http(s) URL allowed by network, or
+// from a path relative to the project root through the runtime filesystem so
+// embedded specs work too.
+func readOpenAPISpec(opts Options, network sandbox.NetworkPolicy, location string) ([]byte, error) {
+	location = strings.TrimSpace(location)
+	if u, err := url.Parse(location); err == nil && (u.Scheme == "http" || u.Scheme == "https") {
+		if network != nil {
+			if err := network.Validate(u.Hostname()); err != nil {
+				return nil, err
+			}
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), openAPISpecFetchTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, location, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("fetch spec: %w", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetch spec: %s", resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, openAPISpecMaxBytes))
+	}
+
+	path := location
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(opts.ProjectRoot, path)
+	}
+	fsLayer := opts.fsLayer
+	if fsLayer == nil {
+		fsLayer = config.NewFS(opts.ProjectRoot, nil)
+	}
+	return fsLayer.ReadFile(path)
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/config"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+	toolbuiltin "github.com/stellarlinkco/agentsdk-go/pkg/tool/builtin"
+)
+
+const openAPITestSpec = `{"openapi": "3.0.0", "paths": {"/status": {"get": {"operationId": "status"}}, "/jobs": {"post": {"operationId": "createJob"}}}}`
+
+func TestRegisterToolsAddsOpenAPIServices(t *testing.T) {
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/spec.json" {
+			_, _ = w.Write([]byte(openAPITestSpec))
+			return
+		}
+		auth = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	root := t.TempDir()
+	if err := os.WriteFile(filepath.Join(root, "ops.json"), []byte(openAPITestSpec), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("OPS_TOKEN", "from-process")
+	settings := &config.Settings{
+		Env: map[string]string{"OPS_USER": "bot"},
+		OpenAPI: &config.OpenAPIConfig{Services: map[string]config.OpenAPIServiceConfig{
+			"ops":     {Spec: "ops.json", BaseURL: srv.URL, Headers: map[string]string{"Authorization": "Bearer ${OPS_USER}:${OPS_TOKEN}"}, Operations: []string{"status"}},
+			"remote":  {Spec: srv.URL + "/spec.json", BaseURL: srv.URL},
+			"missing": {Spec: "missing.yaml", BaseURL: srv.URL},
+		}},
+	}
+	opts := Options{ProjectRoot: root, EnabledBuiltinTools: []string{}, Sandbox: SandboxOptions{NetworkAllow: []string{"127.0.0.1"}}}
+
+	registry := tool.NewRegistry()
+	if err := registerTools(registry, opts, settings, nil); err != nil {
+		t.Fatalf("register tools: %v", err)
+	}
+	for _, name := range []string{"ops__status", "remote__status", "remote__createJob", toolbuiltin.ToolSearchName} {
+		if _, err := registry.Get(name); err != nil {
+			t.Fatalf("expected %s: %v", name, err)
+		}
+	}
+	if _, err := registry.Get("ops__createJob"); err == nil {
+		t.Fatal("operations allowlist should be applied")
+	}
+
+	impl, _ := registry.Get("ops__status")
+	res, err := impl.Execute(context.Background(), map[string]interface{}{})
+	if err != nil || !res.Success {
+		t.Fatalf("execute: %+v %v", res, err)
+	}
+	if auth != "Bearer bot:from-process" {
+		t.Fatalf("headers should expand ${VAR}, got %q", auth)
+	}
+
+	// Hosts outside the sandbox allowlist are never fetched or called.
+	opts.Sandbox.NetworkAllow = []string{"api.example.com"}
+	registry = tool.NewRegistry()
+	if err := registerTools(registry, opts, settings, nil); err != nil {
+		t.Fatalf("register tools: %v", err)
+	}
+	if _, err := registry.Get("remote__status"); err == nil {
+		t.Fatal("spec URL outside the allowlist should not be fetched")
+	}
+	impl, err = registry.Get("ops__status")
+	if err != nil {
+		t.Fatalf("local spec should still load: %v", err)
+	}
+	if _, err := impl.Execute(context.Background(), map[string]interface{}{}); err == nil {
+		t.Fatal("calls outside the allowlist should be denied")
+	}
+}
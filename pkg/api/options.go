@@ -15,12 +15,15 @@ import (
 
 	"github.com/stellarlinkco/agentsdk-go/pkg/config"
 	hooks "github.com/stellarlinkco/agentsdk-go/pkg/hooks"
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp/oauth"
 	"github.com/stellarlinkco/agentsdk-go/pkg/middleware"
 	"github.com/stellarlinkco/agentsdk-go/pkg/model"
 	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/skills"
 	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/subagents"
 	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
 	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+	toolbuiltin "github.com/stellarlinkco/agentsdk-go/pkg/tool/builtin"
 )
 
 var (
@@ -167,6 +170,27 @@ type Options struct {
 	DisallowedTools        []string
 	CustomTools            []tool.Tool
 	MCPServers             []string
+	// MCPResourceChanged is called when a connected MCP server reports a
+	// changed resource list or an update to a resource read through
+	// read_mcp_resource.
+	MCPResourceChanged func(tool.MCPResourceEvent)
+	// ParamRepaired observes each tool argument repaired against the tool's
+	// schema before the call runs, e.g. for debug logging.
+	ParamRepaired func(tool string, repair tool.ParamRepair)
+	// MCPSampling is the default policy for sampling/createMessage requests
+	// from MCP servers; nil refuses them. The sampling block of an
+	// mcp.servers entry in settings overrides it for that server.
+	MCPSampling *MCPSamplingPolicy
+	// MCPElicitation answers elicitation requests from MCP servers. When nil,
+	// requests made during RunStream are emitted as EventMCPElicitation and
+	// every request is cancelled.
+	MCPElicitation func(ctx context.Context, server string, params *mcp.ElicitParams) (*mcp.ElicitResult, error)
+	// MCPAuthorize sends the user to authURL to authorize an MCP server
+	// configured with an oauth block. Defaults to opening a browser.
+	MCPAuthorize func(ctx context.Context, server, authURL string) error
+	// MCPTokenStore persists MCP OAuth credentials. Defaults to an encrypted
+	// store under the user config directory.
+	MCPTokenStore oauth.Store
 
 	TypedHooks             []hooks.ShellHook
 	HookMiddleware         []hooks.Middleware
@@ -174,6 +198,21 @@ type Options struct {
 	DisableSafetyHook      bool
 	DisableSubagentSummary bool
 
+	// PersistentShell runs foreground bash commands in one long-lived shell
+	// per session so cd, export and sourced environments persist.
+	PersistentShell bool
+	// CodeIndex builds an in-memory trigram index of ProjectRoot at startup
+	// and keeps it current, so grep and glob skip files that cannot match.
+	CodeIndex bool
+	// ToolResultCache serves repeated calls to read-only tools (read, glob,
+	// grep, ...) from memory until a non-read-only tool runs or a file under
+	// ProjectRoot changes. Caching turns itself off when part of the tree
+	// cannot be watched.
+	ToolResultCache bool
+	// SecretProvider resolves {{secret:NAME}} placeholders for http_request
+	// ahead of Settings.Env. Resolved values never reach the model.
+	SecretProvider toolbuiltin.SecretProvider
+
 	Skills           []SkillRegistration
 	Subagents        []SubagentRegistration
 	Sandbox          SandboxOptions
@@ -183,6 +222,15 @@ type Options struct {
 	settingsSnapshot *config.Settings
 	skReg            *skills.Registry
 	subMgr           *subagents.Manager
+	todos            *toolbuiltin.TodoStore
+	shells           *toolbuiltin.ShellManager
+	files            *toolbuiltin.FileStateTracker
+	lsp              *toolbuiltin.LSPManager
+	codeIndex        *toolbuiltin.CodeIndex
+	sql              *toolbuiltin.SQLManager
+	resultCache      *tool.ResultCache
+	tree             *tool.TreeWatcher
+	tasks            *subagentTaskRunner
 	tracer           Tracer
 }
 
@@ -222,6 +270,7 @@ type Response struct {
 	Settings        *config.Settings
 	SandboxSnapshot SandboxReport
 	Tags            map[string]string
+	Todos           []toolbuiltin.TodoItem // Session task list maintained by todo_write.
 }
 
 type Result struct {
@@ -336,6 +385,10 @@ func (o Options) frozen() Options {
 	if len(o.MCPServers) > 0 {
 		o.MCPServers = append([]string(nil), o.MCPServers...)
 	}
+	if o.MCPSampling != nil {
+		sampling := *o.MCPSampling
+		o.MCPSampling = &sampling
+	}
 	if len(o.TypedHooks) > 0 {
 		hooks := make([]hooks.ShellHook, len(o.TypedHooks))
 		for i, hook := range o.TypedHooks {
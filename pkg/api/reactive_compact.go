@@ -59,7 +59,7 @@ func (c *compactor) reactiveCompact(ctx context.Context, hist *message.History,
 	}
 
 	out := make([]message.Message, 0, 1+len(snapshot[cut:]))
-	out = append(out, message.Message{Role: "system", Content: "## Summary\n\n" + summary})
+	out = append(out, message.Message{Role: "system", Content: compactSummaryPrefix + summary})
 	out = append(out, snapshot[cut:]...)
 	hist.Replace(out)
 	return true, nil
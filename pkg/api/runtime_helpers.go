@@ -60,20 +60,7 @@ func availableToolsFromList(tools []tool.Tool, whitelist map[string]struct{}, de
 }
 
 func schemaToMap(schema *tool.JSONSchema) map[string]any {
-	if schema == nil {
-		return nil
-	}
-	payload := map[string]any{}
-	if schema.Type != "" {
-		payload["type"] = schema.Type
-	}
-	if len(schema.Properties) > 0 {
-		payload["properties"] = schema.Properties
-	}
-	if len(schema.Required) > 0 {
-		payload["required"] = append([]string(nil), schema.Required...)
-	}
-	return payload
+	return schema.Map()
 }
 
 func convertMessages(msgs []message.Message) []model.Message {
@@ -401,6 +388,28 @@ func (s *historyStore) evictOldest() string {
 	return oldestKey
 }
 
+// Delete drops a session and reports whether it was present. The eviction
+// callback runs so per-session state is released as on LRU eviction.
+func (s *historyStore) Delete(id string) bool {
+	if s == nil {
+		return false
+	}
+	s.mu.Lock()
+	_, ok := s.data[id]
+	delete(s.data, id)
+	delete(s.lastUsed, id)
+	onEvict := s.onEvict
+	s.mu.Unlock()
+	if !ok {
+		return false
+	}
+	cleanupToolOutputSessionDir(id) //nolint:errcheck
+	if onEvict != nil {
+		onEvict(id)
+	}
+	return true
+}
+
 func (s *historyStore) SessionIDs() []string {
 	if s == nil {
 		return nil
@@ -1,6 +1,14 @@
 package api
 
-import "github.com/stellarlinkco/agentsdk-go/pkg/model"
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/stellarlinkco/agentsdk-go/pkg/middleware"
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
 
 // EnabledBuiltinToolKeys returns the built-in registration keys selected by
 // Options.EnabledBuiltinTools for the effective entrypoint.
@@ -26,6 +34,47 @@ func (rt *Runtime) AvailableToolsForWhitelist(toolWhitelist []string) []model.To
 	return availableToolsForSession(rt.registry, toLowerSet(toolWhitelist), rt.deferred, "")
 }
 
+// Tools returns every tool registered with the runtime, including deferred
+// ones, in registration order.
+func (rt *Runtime) Tools() []tool.Tool {
+	if rt == nil || rt.registry == nil {
+		return nil
+	}
+	return rt.registry.List()
+}
+
+// ExecuteTool runs a registered tool outside of an agent loop. The call goes
+// through the same hooks, sandbox, policies and argument repair as calls
+// issued by the model; an empty sessionID selects the entrypoint default.
+func (rt *Runtime) ExecuteTool(ctx context.Context, sessionID, name string, params map[string]any) (*tool.CallResult, error) {
+	if rt == nil {
+		return nil, ErrRuntimeClosed
+	}
+	if err := rt.beginRun(); err != nil {
+		return nil, err
+	}
+	defer rt.endRun()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	sessionID = strings.TrimSpace(sessionID)
+	if sessionID == "" {
+		sessionID = defaultSessionID(rt.opts.modeContext().EntryPoint)
+	}
+	// Tools key shells and read-before-write state by the session in the
+	// middleware state, as they do on the Run path.
+	ctx = context.WithValue(ctx, model.MiddlewareStateKey, &middleware.State{Values: map[string]any{"session_id": sessionID}})
+	exec := &runtimeToolExecutor{
+		executor:  rt.executor,
+		hooks:     &runtimeHookAdapter{executor: rt.hooks, disableSafetyHook: rt.opts.DisableSafetyHook},
+		root:      rt.sbRoot,
+		host:      "localhost",
+		sessionID: sessionID,
+		deferred:  rt.deferred,
+	}
+	return exec.execute(ctx, model.ToolCall{ID: uuid.New().String(), Name: name, Arguments: params}, false)
+}
+
 func (rt *Runtime) systemPromptForSession(sessionID string, whitelist map[string]struct{}) string {
 	if rt == nil {
 		return ""
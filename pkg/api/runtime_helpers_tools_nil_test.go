@@ -10,4 +10,10 @@ func TestRuntimeAvailableToolsNilRuntime(t *testing.T) {
 	if got := rt.AvailableToolsForWhitelist([]string{"bash"}); got != nil {
 		t.Fatalf("expected nil tools for nil runtime")
 	}
+	if got := rt.Tools(); got != nil {
+		t.Fatalf("expected nil tools for nil runtime")
+	}
+	if got := rt.Skills(); got != nil {
+		t.Fatalf("expected nil skills for nil runtime")
+	}
 }
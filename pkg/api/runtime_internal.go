@@ -218,10 +218,14 @@ func (rt *Runtime) runLoop(prep preparedRun, mdl model.Model, hookAdapter *runti
 		state.Iteration = iteration
 
 		if rt.compactor != nil {
-			if _, err := rt.compactor.maybeCompact(ctx, prep.history, mdl); err != nil {
+			compacted, err := rt.compactor.maybeCompact(ctx, prep.history, mdl)
+			if err != nil {
 				runErr = err
 				return last, err
 			}
+			if compacted {
+				rt.reinjectTodos(prep.history, prep.normalized.SessionID)
+			}
 		}
 
 		snapshot := prep.history.All()
@@ -339,6 +343,7 @@ func (rt *Runtime) buildResponse(prep preparedRun, result runResult) *Response {
 		Settings:        rt.Settings(),
 		SandboxSnapshot: rt.sandboxReport(),
 		Tags:            maps.Clone(prep.normalized.Tags),
+		Todos:           rt.Todos(prep.normalized.SessionID),
 	}
 	return resp
 }
@@ -79,12 +79,13 @@ func (t *runtimeToolExecutor) execute(ctx context.Context, call model.ToolCall,
 		return nil, fmt.Errorf("tool %s is not whitelisted", call.Name)
 	}
 
-	appendToolResult := func(content string) {
+	appendToolResult := func(content string, blocks ...message.ContentBlock) {
 		if !appendHistory || t.history == nil {
 			return
 		}
 		t.history.Append(message.Message{
-			Role: "tool",
+			Role:          "tool",
+			ContentBlocks: blocks,
 			ToolCalls: []message.ToolCall{{
 				ID:     call.ID,
 				Name:   call.Name,
@@ -164,13 +165,14 @@ func (t *runtimeToolExecutor) execute(ctx context.Context, call model.ToolCall,
 
 	if t.hooks != nil {
 		if hookErr := t.hooks.PostToolUse(ctx, coreToolResultPayload(call, result, err)); hookErr != nil && err == nil {
-			appendToolResult(content)
+			appendToolResult(content, toolCallContentBlocks(result, err)...)
 			return result, hookErr
 		}
 	}
 
-	appendToolResult(content)
+	appendToolResult(content, toolCallContentBlocks(result, err)...)
 	t.activateDeferredTools(call, result)
+	t.publishTodoUpdate(ctx, call, result)
 	return result, err
 }
 
@@ -179,7 +181,8 @@ func (t *runtimeToolExecutor) appendCallResult(call model.ToolCall, result *tool
 		return
 	}
 	t.history.Append(message.Message{
-		Role: "tool",
+		Role:          "tool",
+		ContentBlocks: toolCallContentBlocks(result, err),
 		ToolCalls: []message.ToolCall{{
 			ID:     call.ID,
 			Name:   call.Name,
@@ -198,6 +201,15 @@ func toolCallResultContent(result *tool.CallResult, err error) string {
 	return ""
 }
 
+// toolCallContentBlocks returns the multimodal blocks (images, documents) a
+// successful tool attached to its result.
+func toolCallContentBlocks(result *tool.CallResult, err error) []message.ContentBlock {
+	if err != nil || result == nil || result.Result == nil {
+		return nil
+	}
+	return convertAPIContentBlocks(result.Result.ContentBlocks)
+}
+
 func coreToolUsePayload(call model.ToolCall) hooks.ToolUsePayload {
 	return hooks.ToolUsePayload{Name: call.Name, Params: call.Arguments}
 }
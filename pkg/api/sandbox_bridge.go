@@ -67,13 +67,21 @@ func buildSandboxManager(opts Options, settings *config.Settings) (*sandbox.Mana
 		}
 	}
 
+	nw := sandboxNetworkPolicy(opts, settings)
+	return sandbox.NewManager(fs, nw, sandbox.NewResourceLimiter(opts.Sandbox.ResourceLimit)), root
+}
+
+// sandboxNetworkPolicy builds the outbound host allowlist, or nil when the
+// sandbox is disabled in settings.
+func sandboxNetworkPolicy(opts Options, settings *config.Settings) sandbox.NetworkPolicy {
+	if settings != nil && settings.Sandbox != nil && settings.Sandbox.Enabled != nil && !*settings.Sandbox.Enabled {
+		return nil
+	}
 	netAllow := opts.Sandbox.NetworkAllow
 	if len(netAllow) == 0 {
 		netAllow = defaultNetworkAllowList(opts.EntryPoint)
 	}
-
-	nw := sandbox.NewDomainAllowList(netAllow...)
-	return sandbox.NewManager(fs, nw, sandbox.NewResourceLimiter(opts.Sandbox.ResourceLimit)), root
+	return sandbox.NewDomainAllowList(netAllow...)
 }
 
 func additionalSandboxPaths(settings *config.Settings) []string {
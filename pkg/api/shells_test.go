@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"runtime"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	toolbuiltin "github.com/stellarlinkco/agentsdk-go/pkg/tool/builtin"
+)
+
+func TestRuntimeBackgroundShellsCleanedOnDeleteAndClose(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires POSIX shell")
+	}
+	t.Parallel()
+
+	root := newClaudeProject(t)
+	bg := func(id string) *model.Response {
+		return &model.Response{Message: model.Message{Role: "assistant", ToolCalls: []model.ToolCall{{
+			ID:        id,
+			Name:      "bash",
+			Arguments: map[string]any{"command": "sleep 30", "run_in_background": true},
+		}}}}
+	}
+	mdl := &stubModel{responses: []*model.Response{
+		bg("tool_1"),
+		{Message: model.Message{Role: "assistant", Content: "started"}},
+		bg("tool_2"),
+		{Message: model.Message{Role: "assistant", Content: "started"}},
+	}}
+	rt, err := New(context.Background(), Options{ProjectRoot: root, Model: mdl})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+	for _, name := range []string{toolbuiltin.BashOutputName, toolbuiltin.KillShellName} {
+		if _, err := rt.registry.Get(name); err != nil {
+			t.Fatalf("%s not registered: %v", name, err)
+		}
+	}
+
+	shells := rt.opts.shells
+	for _, sessionID := range []string{"a", "b"} {
+		if _, err := rt.Run(context.Background(), Request{Prompt: "start server", SessionID: sessionID}); err != nil {
+			t.Fatalf("run %s: %v", sessionID, err)
+		}
+		if got := shells.List(sessionID); len(got) != 1 || got[0].State != toolbuiltin.ShellRunning {
+			t.Fatalf("expected running shell for %s, got %+v", sessionID, got)
+		}
+	}
+
+	if !rt.DeleteSession("a") {
+		t.Fatal("expected session a to be deleted")
+	}
+	if rt.DeleteSession("a") {
+		t.Fatal("second delete should report missing session")
+	}
+	if got := shells.List("a"); len(got) != 0 {
+		t.Fatalf("delete should kill session shells, got %+v", got)
+	}
+	if got := shells.List("b"); len(got) != 1 {
+		t.Fatalf("other sessions must survive delete, got %+v", got)
+	}
+
+	if err := rt.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if got := shells.List("b"); len(got) != 0 {
+		t.Fatalf("close should kill all shells, got %+v", got)
+	}
+}
@@ -0,0 +1,26 @@
+package api
+
+import (
+	"context"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/skills"
+)
+
+// Skills lists the definitions of every skill loaded by the runtime.
+func (rt *Runtime) Skills() []skills.Definition {
+	if rt == nil || rt.opts.skReg == nil {
+		return nil
+	}
+	return rt.opts.skReg.List()
+}
+
+// ExecuteSkill runs the named skill with the supplied activation context.
+func (rt *Runtime) ExecuteSkill(ctx context.Context, name string, activation skills.ActivationContext) (skills.Result, error) {
+	if rt == nil || rt.opts.skReg == nil {
+		return skills.Result{}, skills.ErrUnknownSkill
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return rt.opts.skReg.Execute(ctx, name, activation)
+}
@@ -27,6 +27,8 @@ const (
 	EventToolExecutionStart  = "tool_execution_start"
 	EventToolExecutionOutput = "tool_execution_output"
 	EventToolExecutionResult = "tool_execution_result"
+	EventTodoUpdate          = "todo_update"
+	EventMCPElicitation      = "mcp_elicitation"
 	EventError               = "error"
 )
 
@@ -12,11 +12,19 @@ import (
 const subagentOutputLimit = 2000
 
 func (rt *Runtime) bindSubagentCallbacks() {
-	if rt == nil || rt.opts.subMgr == nil {
+	if rt == nil {
 		return
 	}
-	rt.opts.subMgr.SetMaxConcurrentBackground(rt.opts.MaxConcurrentSubagents)
-	rt.opts.subMgr.SetCompletionHandler(rt.handleSubagentCompletion)
+	if rt.opts.subMgr != nil {
+		rt.opts.subMgr.SetMaxConcurrentBackground(rt.opts.MaxConcurrentSubagents)
+		rt.opts.subMgr.SetCompletionHandler(rt.handleSubagentCompletion)
+	}
+	// Without registered subagents the task runner keeps a private manager
+	// that still needs the runtime's concurrency limit and completion hook.
+	if tasks := rt.opts.tasks; tasks != nil && tasks.mgr != rt.opts.subMgr {
+		tasks.mgr.SetMaxConcurrentBackground(rt.opts.MaxConcurrentSubagents)
+		tasks.mgr.SetCompletionHandler(rt.handleSubagentCompletion)
+	}
 }
 
 func (rt *Runtime) handleSubagentCompletion(status subagents.Status) {
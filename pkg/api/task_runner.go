@@ -0,0 +1,242 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/google/uuid"
+	"github.com/stellarlinkco/agentsdk-go/pkg/message"
+	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/subagents"
+	toolbuiltin "github.com/stellarlinkco/agentsdk-go/pkg/tool/builtin"
+)
+
+// taskToolNames lists the delegation tools hidden from subagents so a task
+// cannot recursively spawn further tasks.
+var taskToolNames = map[string]struct{}{
+	toolbuiltin.TaskName:       {},
+	toolbuiltin.TaskStatusName: {},
+	toolbuiltin.TaskOutputName: {},
+}
+
+// subagentTaskRunner backs the task tools by running subagents as nested agent
+// loops on the owning runtime. Background runs are tracked by a
+// subagents.Manager so TaskStatus and completion callbacks behave exactly like
+// DispatchAsync.
+type subagentTaskRunner struct {
+	rt     *Runtime
+	mgr    *subagents.Manager
+	ctx    context.Context
+	cancel context.CancelFunc
+	seq    atomic.Uint64
+}
+
+func newSubagentTaskRunner(mgr *subagents.Manager) *subagentTaskRunner {
+	if mgr == nil {
+		mgr = subagents.NewManager()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &subagentTaskRunner{mgr: mgr, ctx: ctx, cancel: cancel}
+}
+
+// Definitions returns registered subagents followed by builtin types that were
+// not overridden.
+func (r *subagentTaskRunner) Definitions() []subagents.Definition {
+	if r == nil {
+		return nil
+	}
+	var defs []subagents.Definition
+	seen := map[string]struct{}{}
+	if r.rt != nil && r.rt.opts.subMgr != nil {
+		for _, def := range r.rt.opts.subMgr.List() {
+			seen[canonicalToolName(def.Name)] = struct{}{}
+			defs = append(defs, def)
+		}
+	}
+	for _, def := range subagents.BuiltinDefinitions() {
+		if _, ok := seen[canonicalToolName(def.Name)]; ok {
+			continue
+		}
+		defs = append(defs, def)
+	}
+	return defs
+}
+
+func (r *subagentTaskRunner) Run(ctx context.Context, req toolbuiltin.TaskRequest) (subagents.Result, error) {
+	if r == nil || r.rt == nil {
+		return subagents.Result{}, errors.New("api: task runner is not bound to a runtime")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	name := canonicalToolName(req.SubagentType)
+	def, registered, ok := r.definition(name)
+	if !ok {
+		return subagents.Result{}, fmt.Errorf("%w: %s", subagents.ErrUnknownSubagent, name)
+	}
+	rt := r.rt
+	childSession := r.childSessionID(req.SessionID, name)
+
+	prompt := strings.TrimSpace(req.Prompt)
+	if registered {
+		res, err := rt.opts.subMgr.Dispatch(subagents.WithContext(ctx, subagents.Context{SessionID: childSession}), subagents.Request{
+			Target:      name,
+			Instruction: prompt,
+			Metadata: map[string]any{
+				"session_id":        childSession,
+				"parent_session_id": req.SessionID,
+				"task.description":  req.Description,
+			},
+		})
+		if err != nil {
+			return res, err
+		}
+		prompt = composeTaskPrompt(prompt, res)
+	}
+
+	whitelist := rt.taskToolWhitelist(def.BaseContext.ToolWhitelist)
+	mode := rt.opts.modeContext()
+	normalized := Request{
+		Prompt:         prompt,
+		SessionID:      childSession,
+		TargetSubagent: name,
+		ToolWhitelist:  whitelist,
+	}.normalized(mode, childSession)
+	normalized.RequestID = uuid.New().String()
+
+	// Nested runs stay silent on the parent stream and never share history
+	// with the caller; the final answer travels back as the tool result.
+	childCtx := context.WithValue(ctx, streamEmitCtxKey, streamEmitFunc(nil))
+	childCtx = subagents.WithContext(childCtx, subagents.Context{
+		SessionID:     childSession,
+		ToolWhitelist: whitelist,
+		Model:         def.BaseContext.Model,
+	})
+	prep := preparedRun{
+		ctx:           childCtx,
+		prompt:        prompt,
+		history:       message.NewHistory(),
+		normalized:    normalized,
+		recorder:      defaultHookRecorder(),
+		mode:          normalized.Mode,
+		toolWhitelist: combineToolWhitelists(whitelist, nil),
+	}
+	defer rt.releaseTaskSession(childSession)
+
+	result, err := rt.runAgent(prep)
+	output := ""
+	if result.response != nil {
+		output = strings.TrimSpace(result.response.Message.Content)
+	}
+	res := subagents.Result{
+		Subagent: name,
+		Output:   output,
+		Metadata: map[string]any{"session_id": childSession},
+	}
+	if err != nil {
+		res.Error = err.Error()
+		return res, err
+	}
+	return res, nil
+}
+
+func (r *subagentTaskRunner) Start(_ context.Context, req toolbuiltin.TaskRequest) (string, error) {
+	if r == nil || r.rt == nil {
+		return "", errors.New("api: task runner is not bound to a runtime")
+	}
+	if err := r.ctx.Err(); err != nil {
+		return "", ErrRuntimeClosed
+	}
+	name := canonicalToolName(req.SubagentType)
+	if _, _, ok := r.definition(name); !ok {
+		return "", fmt.Errorf("%w: %s", subagents.ErrUnknownSubagent, name)
+	}
+	// Background tasks outlive the tool call, so they run on the runner's own
+	// context and are cancelled by Runtime.Close instead.
+	return r.mgr.DispatchAsyncWith(r.ctx, subagents.Request{
+		Target:      name,
+		Instruction: req.Prompt,
+		Metadata:    map[string]any{"session_id": req.SessionID},
+	}, func(ctx context.Context, _ subagents.Request) (subagents.Result, error) {
+		if err := r.rt.beginRun(); err != nil {
+			return subagents.Result{}, err
+		}
+		defer r.rt.endRun()
+		return r.Run(ctx, req)
+	})
+}
+
+func (r *subagentTaskRunner) Status(taskID string) (subagents.Status, error) {
+	if r == nil {
+		return subagents.Status{}, subagents.ErrUnknownTask
+	}
+	return r.mgr.TaskStatus(taskID)
+}
+
+func (r *subagentTaskRunner) close() {
+	if r != nil && r.cancel != nil {
+		r.cancel()
+	}
+}
+
+func (r *subagentTaskRunner) definition(name string) (subagents.Definition, bool, bool) {
+	if r.rt != nil && r.rt.opts.subMgr != nil {
+		for _, def := range r.rt.opts.subMgr.List() {
+			if canonicalToolName(def.Name) == name {
+				return def, true, true
+			}
+		}
+	}
+	if def, ok := subagents.BuiltinDefinition(name); ok {
+		return def, false, true
+	}
+	return subagents.Definition{}, false, false
+}
+
+func (r *subagentTaskRunner) childSessionID(parent, name string) string {
+	parent = strings.TrimSpace(parent)
+	if parent == "" {
+		parent = defaultSessionID(r.rt.opts.modeContext().EntryPoint)
+	}
+	return fmt.Sprintf("%s/%s-%d", parent, name, r.seq.Add(1))
+}
+
+// taskToolWhitelist resolves the tools a subagent may use: its own whitelist
+// or every registered tool, minus the delegation tools.
+func (rt *Runtime) taskToolWhitelist(base []string) []string {
+	if len(base) == 0 && rt.registry != nil {
+		for _, impl := range rt.registry.List() {
+			base = append(base, impl.Name())
+		}
+	}
+	out := make([]string, 0, len(base))
+	for _, name := range base {
+		canon := canonicalToolName(name)
+		if _, blocked := taskToolNames[canon]; blocked || canon == "" {
+			continue
+		}
+		out = append(out, canon)
+	}
+	return out
+}
+
+// releaseTaskSession drops per-session state created by a nested task run.
+func (rt *Runtime) releaseTaskSession(sessionID string) {
+	rt.handleSessionEvict(sessionID)
+	cleanupBashOutputSessionDir(sessionID) //nolint:errcheck
+	cleanupToolOutputSessionDir(sessionID) //nolint:errcheck
+}
+
+// composeTaskPrompt places the subagent handler output (typically its system
+// instructions) ahead of the delegated task.
+func composeTaskPrompt(task string, res subagents.Result) string {
+	prompt := task
+	if res.Output != nil {
+		if instructions := strings.TrimSpace(fmt.Sprint(res.Output)); instructions != "" && instructions != task {
+			prompt = instructions + "\n\n## Task\n\n" + task
+		}
+	}
+	return applyPromptMetadata(prompt, res.Metadata)
+}
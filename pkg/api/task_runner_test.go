@@ -0,0 +1,173 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/skills"
+	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/subagents"
+	toolbuiltin "github.com/stellarlinkco/agentsdk-go/pkg/tool/builtin"
+)
+
+func TestRuntimeTaskToolRunsBuiltinSubagent(t *testing.T) {
+	t.Parallel()
+
+	root := newClaudeProject(t)
+	mdl := &stubModel{responses: []*model.Response{
+		{Message: model.Message{Role: "assistant", ToolCalls: []model.ToolCall{{
+			ID:   "tool_1",
+			Name: toolbuiltin.TaskName,
+			Arguments: map[string]any{
+				"subagent_type": "explore",
+				"prompt":        "locate the config loader",
+			},
+		}}}},
+		{Message: model.Message{Role: "assistant", Content: "loader lives in pkg/config"}},
+		{Message: model.Message{Role: "assistant", Content: "done"}},
+	}}
+	rt, err := New(context.Background(), Options{ProjectRoot: root, Model: mdl})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	resp, err := rt.Run(context.Background(), Request{Prompt: "where is config loaded?", SessionID: "parent"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if resp.Result.Output != "done" {
+		t.Fatalf("unexpected output %q", resp.Result.Output)
+	}
+	if len(mdl.requests) != 3 {
+		t.Fatalf("expected 3 model requests, got %d", len(mdl.requests))
+	}
+
+	if !hasToolDef(mdl.requests[0], toolbuiltin.TaskName) {
+		t.Fatalf("task tool missing from parent request")
+	}
+	child := mdl.requests[1]
+	for _, def := range child.Tools {
+		switch canonicalToolName(def.Name) {
+		case "glob", "grep", "read":
+		default:
+			t.Fatalf("explore subagent exposed unexpected tool %q", def.Name)
+		}
+	}
+	if len(child.Messages) != 1 || child.Messages[0].Content != "locate the config loader" {
+		t.Fatalf("child should start from a fresh history: %+v", child.Messages)
+	}
+
+	final := mdl.requests[2]
+	last := final.Messages[len(final.Messages)-1]
+	if len(last.ToolCalls) == 0 || !strings.Contains(last.ToolCalls[0].Result, "loader lives in pkg/config") {
+		t.Fatalf("subagent answer not returned to parent: %+v", last)
+	}
+	if ids := rt.histories.SessionIDs(); len(ids) != 1 || ids[0] != "parent" {
+		t.Fatalf("nested run should not persist history, sessions=%v", ids)
+	}
+}
+
+func TestSubagentTaskRunnerUsesRegisteredHandler(t *testing.T) {
+	t.Parallel()
+
+	root := newClaudeProject(t)
+	mdl := &stubModel{responses: []*model.Response{{Message: model.Message{Role: "assistant", Content: "looks good"}}}}
+	never := skills.MatcherFunc(func(skills.ActivationContext) skills.MatchResult { return skills.MatchResult{} })
+	rt, err := New(context.Background(), Options{
+		ProjectRoot: root,
+		Model:       mdl,
+		Subagents: []SubagentRegistration{{
+			Definition: subagents.Definition{
+				Name:        "reviewer",
+				Description: "Reviews diffs",
+				Matchers:    []skills.Matcher{never},
+				BaseContext: subagents.Context{ToolWhitelist: []string{"read", "task"}},
+			},
+			Handler: subagents.HandlerFunc(func(context.Context, subagents.Context, subagents.Request) (subagents.Result, error) {
+				return subagents.Result{Output: "You are a strict reviewer."}, nil
+			}),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	runner := rt.opts.tasks
+	names := map[string]bool{}
+	for _, def := range runner.Definitions() {
+		names[def.Name] = true
+	}
+	for _, want := range []string{"reviewer", subagents.TypeExplore, subagents.TypeGeneralPurpose, subagents.TypePlan} {
+		if !names[want] {
+			t.Fatalf("definitions missing %q: %v", want, names)
+		}
+	}
+
+	res, err := runner.Run(context.Background(), toolbuiltin.TaskRequest{SubagentType: "reviewer", Prompt: "review the patch", SessionID: "parent"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if res.Output != "looks good" || res.Subagent != "reviewer" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	req := mdl.requests[0]
+	if got := req.Messages[0].Content; got != "You are a strict reviewer.\n\n## Task\n\nreview the patch" {
+		t.Fatalf("unexpected child prompt %q", got)
+	}
+	if len(req.Tools) != 1 || req.Tools[0].Name != "read" {
+		t.Fatalf("task tool should be stripped from subagent tools: %+v", req.Tools)
+	}
+
+	if _, err := runner.Run(context.Background(), toolbuiltin.TaskRequest{SubagentType: "ghost", Prompt: "x"}); err == nil {
+		t.Fatal("expected unknown subagent error")
+	}
+}
+
+func TestSubagentTaskRunnerBackground(t *testing.T) {
+	t.Parallel()
+
+	rt := newTestRuntime(t, staticModel{content: "background answer"}, CompactConfig{})
+	parent := rt.histories.Get("parent")
+
+	runner := rt.opts.tasks
+	taskID, err := runner.Start(context.Background(), toolbuiltin.TaskRequest{SubagentType: "plan", Prompt: "draft a plan", SessionID: "parent"})
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	var status subagents.Status
+	for time.Now().Before(deadline) {
+		status, err = runner.Status(taskID)
+		if err != nil {
+			t.Fatalf("status: %v", err)
+		}
+		if status.State == subagents.StatusSuccess || status.State == subagents.StatusError {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if status.State != subagents.StatusSuccess || status.Output != "background answer" || status.SessionID != "parent" {
+		t.Fatalf("unexpected status %+v", status)
+	}
+
+	deadline = time.Now().Add(time.Second)
+	for parent.Len() == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	msgs := parent.All()
+	if len(msgs) != 1 || !strings.Contains(msgs[0].Content, "background answer") {
+		t.Fatalf("expected completion summary in parent history, got %+v", msgs)
+	}
+
+	if err := rt.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if _, err := runner.Start(context.Background(), toolbuiltin.TaskRequest{SubagentType: "plan", Prompt: "x"}); err == nil {
+		t.Fatal("expected start to fail after close")
+	}
+}
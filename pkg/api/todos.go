@@ -0,0 +1,107 @@
+package api
+
+import (
+	"context"
+	"strings"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/message"
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+	toolbuiltin "github.com/stellarlinkco/agentsdk-go/pkg/tool/builtin"
+)
+
+const todoReminderType = "todo_reminder"
+
+// Todos returns the current task list recorded by todo_write for sessionID.
+func (rt *Runtime) Todos(sessionID string) []toolbuiltin.TodoItem {
+	if rt == nil || rt.opts.todos == nil {
+		return nil
+	}
+	return rt.opts.todos.Get(sessionID)
+}
+
+// reinjectTodos restores the session task list right after a compaction
+// summary so the model keeps its plan once the original todo_write calls have
+// been summarised away.
+func (rt *Runtime) reinjectTodos(hist *message.History, sessionID string) {
+	if rt == nil || hist == nil || rt.opts.todos == nil {
+		return
+	}
+	items := rt.opts.todos.Get(sessionID)
+	if len(items) == 0 {
+		return
+	}
+	snapshot := hist.All()
+	if len(snapshot) == 0 || !isCompactSummary(snapshot[0]) {
+		return
+	}
+	if len(snapshot) > 1 && isTodoReminder(snapshot[1]) {
+		return
+	}
+	reminder := message.Message{
+		Role:    "system",
+		Content: "## Current Todos\n\n" + toolbuiltin.FormatTodos(items),
+		Metadata: map[string]any{
+			"api.synthetic":      true,
+			"api.synthetic_type": todoReminderType,
+		},
+	}
+	out := make([]message.Message, 0, len(snapshot)+1)
+	out = append(out, snapshot[0], reminder)
+	out = append(out, snapshot[1:]...)
+	hist.Replace(out)
+}
+
+func isCompactSummary(msg message.Message) bool {
+	return msg.Role == "system" && strings.HasPrefix(msg.Content, compactSummaryPrefix)
+}
+
+func isTodoReminder(msg message.Message) bool {
+	if msg.Metadata == nil {
+		return false
+	}
+	kind, _ := msg.Metadata["api.synthetic_type"].(string)
+	return kind == todoReminderType
+}
+
+// publishTodoUpdate emits EventTodoUpdate after a successful todo_write call.
+func (t *runtimeToolExecutor) publishTodoUpdate(ctx context.Context, call model.ToolCall, result *tool.CallResult) {
+	if t == nil || canonicalToolName(call.Name) != canonicalToolName(toolbuiltin.TodoWriteName) {
+		return
+	}
+	if result == nil || result.Result == nil || !result.Result.Success {
+		return
+	}
+	data, ok := result.Result.Data.(map[string]interface{})
+	if !ok {
+		return
+	}
+	todos, ok := data["todos"].([]toolbuiltin.TodoItem)
+	if !ok {
+		todos = []toolbuiltin.TodoItem{}
+	}
+	emit := streamEmitFromContext(ctx)
+	if emit == nil {
+		return
+	}
+	emit(ctx, StreamEvent{
+		Type:      EventTodoUpdate,
+		ToolUseID: call.ID,
+		Name:      call.Name,
+		Output:    todos,
+		SessionID: t.sessionID,
+	})
+}
+
+// handleSessionEvict releases per-session runtime state once the history
+// store drops a session.
+func (rt *Runtime) handleSessionEvict(sessionID string) {
+	if rt == nil {
+		return
+	}
+	if rt.opts.todos != nil {
+		rt.opts.todos.Delete(sessionID)
+	}
+	rt.opts.shells.KillSession(sessionID)
+	rt.opts.files.Delete(sessionID)
+}
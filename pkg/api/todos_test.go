@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/message"
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	toolbuiltin "github.com/stellarlinkco/agentsdk-go/pkg/tool/builtin"
+)
+
+func todoWriteCall(id string) model.ToolCall {
+	return model.ToolCall{
+		ID:   id,
+		Name: toolbuiltin.TodoWriteName,
+		Arguments: map[string]any{"todos": []any{
+			map[string]any{"content": "Write tests", "status": "completed", "activeForm": "Writing tests"},
+			map[string]any{"content": "Fix bug", "status": "in_progress", "activeForm": "Fixing bug"},
+		}},
+	}
+}
+
+func TestRuntimeTodoWriteSurfacesTodosInResponse(t *testing.T) {
+	t.Parallel()
+
+	root := newClaudeProject(t)
+	mdl := &stubModel{responses: []*model.Response{
+		{Message: model.Message{Role: "assistant", ToolCalls: []model.ToolCall{todoWriteCall("tool_1")}}},
+		{Message: model.Message{Role: "assistant", Content: "done"}},
+	}}
+	rt, err := New(context.Background(), Options{ProjectRoot: root, Model: mdl})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	resp, err := rt.Run(context.Background(), Request{Prompt: "plan", SessionID: "sess"})
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(resp.Todos) != 2 || resp.Todos[1].Status != toolbuiltin.TodoInProgress {
+		t.Fatalf("unexpected response todos: %+v", resp.Todos)
+	}
+	if got := rt.Todos("other"); len(got) != 0 {
+		t.Fatalf("todos leaked across sessions: %+v", got)
+	}
+
+	rt.handleSessionEvict("sess")
+	if got := rt.Todos("sess"); len(got) != 0 {
+		t.Fatalf("expected todos cleared on eviction, got %+v", got)
+	}
+}
+
+func TestRuntimeTodoWriteEmitsStreamEvent(t *testing.T) {
+	t.Parallel()
+
+	root := newClaudeProject(t)
+	mdl := &stubModel{responses: []*model.Response{
+		{Message: model.Message{Role: "assistant", ToolCalls: []model.ToolCall{todoWriteCall("tool_1")}}},
+		{Message: model.Message{Role: "assistant", Content: "done"}},
+	}}
+	rt, err := New(context.Background(), Options{ProjectRoot: root, Model: mdl})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+
+	events, err := rt.RunStream(context.Background(), Request{Prompt: "plan", SessionID: "sess"})
+	if err != nil {
+		t.Fatalf("run stream: %v", err)
+	}
+	var update *StreamEvent
+	for evt := range events {
+		if evt.Type == EventTodoUpdate {
+			evt := evt
+			update = &evt
+		}
+	}
+	if update == nil {
+		t.Fatal("expected todo_update event")
+	}
+	if update.SessionID != "sess" || update.ToolUseID != "tool_1" {
+		t.Fatalf("unexpected event metadata: %+v", update)
+	}
+	todos, ok := update.Output.([]toolbuiltin.TodoItem)
+	if !ok || len(todos) != 2 || todos[0].Content != "Write tests" {
+		t.Fatalf("unexpected event payload: %#v", update.Output)
+	}
+}
+
+func TestReinjectTodosAfterCompaction(t *testing.T) {
+	t.Parallel()
+
+	rt := &Runtime{}
+	rt.opts.todos = toolbuiltin.NewTodoStore()
+	rt.opts.todos.Set("sess", []toolbuiltin.TodoItem{{Content: "Fix bug", Status: toolbuiltin.TodoInProgress, ActiveForm: "Fixing bug"}})
+
+	hist := message.NewHistory()
+	hist.Append(message.Message{Role: "user", Content: "no summary yet"})
+	rt.reinjectTodos(hist, "sess")
+	if hist.Len() != 1 {
+		t.Fatalf("expected no reminder without summary, got %d messages", hist.Len())
+	}
+
+	hist.Replace([]message.Message{
+		{Role: "system", Content: compactSummaryPrefix + "earlier work"},
+		{Role: "user", Content: "continue"},
+	})
+	rt.reinjectTodos(hist, "sess")
+	rt.reinjectTodos(hist, "sess")
+
+	msgs := hist.All()
+	if len(msgs) != 3 {
+		t.Fatalf("expected single reminder, got %d messages", len(msgs))
+	}
+	if !isTodoReminder(msgs[1]) || msgs[1].Role != "system" {
+		t.Fatalf("reminder not placed after summary: %+v", msgs[1])
+	}
+	if !strings.Contains(msgs[1].Content, "Fixing bug") {
+		t.Fatalf("reminder missing todo list: %q", msgs[1].Content)
+	}
+
+	rt.reinjectTodos(hist, "empty")
+	if hist.Len() != 3 {
+		t.Fatalf("empty session should not change history")
+	}
+}
+
+func TestRuntimeReinjectsTodosAfterAutoCompact(t *testing.T) {
+	t.Parallel()
+
+	mdl := &stubModel{responses: []*model.Response{
+		{Message: model.Message{Role: "assistant", Content: "summary"}},
+		{Message: model.Message{Role: "assistant", Content: "done"}},
+	}}
+	rt := newTestRuntime(t, mdl, CompactConfig{Enabled: true, Threshold: 0.1, PreserveCount: 1})
+	rt.opts.todos.Set("sess", []toolbuiltin.TodoItem{{Content: "Ship it", Status: toolbuiltin.TodoPending, ActiveForm: "Shipping it"}})
+
+	hist := rt.histories.Get("sess")
+	for i := 0; i < 4; i++ {
+		hist.Append(message.Message{Role: "user", Content: strings.Repeat("context ", 20)})
+	}
+
+	if _, err := rt.Run(context.Background(), Request{Prompt: "go", SessionID: "sess"}); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	reminders := 0
+	for _, msg := range hist.All() {
+		if isTodoReminder(msg) && strings.Contains(msg.Content, "Ship it") {
+			reminders++
+		}
+	}
+	if reminders != 1 {
+		t.Fatalf("expected todo list re-injected once after compaction, got %d", reminders)
+	}
+}
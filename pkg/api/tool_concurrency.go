@@ -86,10 +86,12 @@ func (rt *Runtime) executeSingleToolCall(ctx context.Context, call model.ToolCal
 	res, err := tools.Execute(ctx, call)
 	if tracer != nil {
 		tracer.EndSpan(toolSpan, map[string]any{
-			"session_id":  strings.TrimSpace(sessionID),
-			"request_id":  strings.TrimSpace(requestID),
-			"tool_use_id": strings.TrimSpace(call.ID),
-			"tool_name":   strings.TrimSpace(call.Name),
+			"session_id":    strings.TrimSpace(sessionID),
+			"request_id":    strings.TrimSpace(requestID),
+			"tool_use_id":   strings.TrimSpace(call.ID),
+			"tool_name":     strings.TrimSpace(call.Name),
+			"tool_cached":   res != nil && res.Cached,
+			"tool_attempts": toolAttempts(res),
 		}, err)
 	}
 	exec.result = res
@@ -175,3 +177,10 @@ func (rt *Runtime) executeToolCalls(ctx context.Context, calls []model.ToolCall,
 
 	return firstMiddlewareErr
 }
+
+func toolAttempts(res *tool.CallResult) int {
+	if res == nil {
+		return 0
+	}
+	return res.Attempts
+}
@@ -4,7 +4,11 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"maps"
 	"net/url"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -27,6 +31,9 @@ func registerTools(registry *tool.Registry, opts Options, settings *config.Setti
 		}
 
 		factories := builtinToolFactories(opts.ProjectRoot, sandboxDisabled, entry, settings, skReg)
+		addRuntimeToolFactories(factories, opts)
+		factories["http_request"] = httpRequestFactory(opts, settings)
+		addMCPResourceToolFactories(factories, registry, opts, settings)
 		names := builtinOrder(entry)
 		selectedNames := filterBuiltinNames(opts.EnabledBuiltinTools, names)
 		for _, name := range selectedNames {
@@ -44,7 +51,9 @@ func registerTools(registry *tool.Registry, opts Options, settings *config.Setti
 		if len(opts.CustomTools) > 0 {
 			tools = append(tools, opts.CustomTools...)
 		}
+		tools = append(tools, commandTools(opts, sandboxDisabled)...)
 	}
+	tools = append(tools, openAPITools(opts, settings)...)
 	tools = withToolSearch(tools)
 
 	disallowed := toLowerSet(opts.DisallowedTools)
@@ -91,6 +100,47 @@ func registerTools(registry *tool.Registry, opts Options, settings *config.Setti
 	return nil
 }
 
+// addMCPResourceToolFactories adds the MCP resource tools when at least one
+// MCP server is configured. They read through registry at call time because
+// servers connect after the builtins are registered.
+func addMCPResourceToolFactories(factories map[string]func() tool.Tool, registry *tool.Registry, opts Options, settings *config.Settings) {
+	if len(collectMCPServers(settings, opts.MCPServers)) == 0 {
+		return
+	}
+	factories["list_mcp_resources"] = func() tool.Tool { return toolbuiltin.NewListMCPResourcesTool(registry) }
+	factories["read_mcp_resource"] = func() tool.Tool { return toolbuiltin.NewReadMCPResourceTool(registry) }
+}
+
+// commandTools builds the declarative tools under .agents/tools. They come
+// after builtins and custom tools, so a file cannot shadow either of them.
+func commandTools(opts Options, sandboxDisabled bool) []tool.Tool {
+	specs, errs := toolbuiltin.LoadCommandTools(toolbuiltin.CommandToolLoaderOptions{
+		ProjectRoot: opts.ProjectRoot,
+		FS:          opts.fsLayer,
+	})
+	for _, err := range errs {
+		log.Printf("command tool loader warning: %v", err)
+	}
+	tools := make([]tool.Tool, 0, len(specs))
+	for _, spec := range specs {
+		var (
+			impl *toolbuiltin.CommandTool
+			err  error
+		)
+		if sandboxDisabled {
+			impl, err = toolbuiltin.NewCommandToolWithSandbox(spec, opts.ProjectRoot, nil)
+		} else {
+			impl, err = toolbuiltin.NewCommandToolWithRoot(spec, opts.ProjectRoot)
+		}
+		if err != nil {
+			log.Printf("command tool loader warning: %s: %v", spec.Path, err)
+			continue
+		}
+		tools = append(tools, impl)
+	}
+	return tools
+}
+
 func withToolSearch(tools []tool.Tool) []tool.Tool {
 	hasDeferred := false
 	for _, impl := range tools {
@@ -141,6 +191,24 @@ func builtinToolFactories(root string, sandboxDisabled bool, entry EntryPoint, s
 		}
 		return toolbuiltin.NewEditToolWithRoot(root)
 	}
+	multiEditCtor := func() tool.Tool {
+		if sandboxDisabled {
+			return toolbuiltin.NewMultiEditToolWithSandbox(root, nil)
+		}
+		return toolbuiltin.NewMultiEditToolWithRoot(root)
+	}
+	applyPatchCtor := func() tool.Tool {
+		if sandboxDisabled {
+			return toolbuiltin.NewApplyPatchToolWithSandbox(root, nil)
+		}
+		return toolbuiltin.NewApplyPatchToolWithRoot(root)
+	}
+	notebookEditCtor := func() tool.Tool {
+		if sandboxDisabled {
+			return toolbuiltin.NewNotebookEditToolWithSandbox(root, nil)
+		}
+		return toolbuiltin.NewNotebookEditToolWithRoot(root)
+	}
 
 	respectGitignore := true
 	if settings != nil && settings.RespectGitignore != nil {
@@ -170,21 +238,175 @@ func builtinToolFactories(root string, sandboxDisabled bool, entry EntryPoint, s
 	factories["read"] = readCtor
 	factories["write"] = writeCtor
 	factories["edit"] = editCtor
+	factories["multi_edit"] = multiEditCtor
+	factories["apply_patch"] = applyPatchCtor
+	factories["notebook_edit"] = notebookEditCtor
 	factories["grep"] = grepCtor
 	factories["glob"] = globCtor
+	gitPolicy := gitPolicyFromSettings(settings)
+	factories["git"] = func() tool.Tool {
+		var git *toolbuiltin.GitTool
+		if sandboxDisabled {
+			git = toolbuiltin.NewGitToolWithSandbox(root, nil)
+		} else {
+			git = toolbuiltin.NewGitToolWithRoot(root)
+		}
+		git.SetPolicy(gitPolicy)
+		return git
+	}
+	factories["go_symbols"] = func() tool.Tool {
+		if sandboxDisabled {
+			return toolbuiltin.NewGoSymbolsToolWithSandbox(root, nil)
+		}
+		return toolbuiltin.NewGoSymbolsToolWithRoot(root)
+	}
+	factories["lsp"] = func() tool.Tool {
+		if sandboxDisabled {
+			return toolbuiltin.NewLSPToolWithSandbox(root, nil)
+		}
+		return toolbuiltin.NewLSPToolWithRoot(root)
+	}
 	factories["skill"] = func() tool.Tool { return toolbuiltin.NewSkillTool(skReg, nil) }
 
 	return factories
 }
 
+// addRuntimeToolFactories registers builtins that depend on runtime-scoped
+// state held in Options rather than on the project root.
+func addRuntimeToolFactories(factories map[string]func() tool.Tool, opts Options) {
+	todos := opts.todos
+	if todos == nil {
+		todos = toolbuiltin.NewTodoStore()
+	}
+	factories["todo_write"] = func() tool.Tool { return toolbuiltin.NewTodoWriteTool(todos) }
+	factories["todo_read"] = func() tool.Tool { return toolbuiltin.NewTodoReadTool(todos) }
+
+	shells := opts.shells
+	if shells == nil {
+		shells = toolbuiltin.NewShellManager()
+	}
+	if bashCtor := factories["bash"]; bashCtor != nil {
+		factories["bash"] = func() tool.Tool {
+			impl := bashCtor()
+			if bash, ok := impl.(*toolbuiltin.BashTool); ok {
+				bash.SetShellManager(shells)
+				bash.SetPersistentShell(opts.PersistentShell)
+			}
+			return impl
+		}
+	}
+	factories["bash_output"] = func() tool.Tool { return toolbuiltin.NewBashOutputTool(shells) }
+	factories["kill_shell"] = func() tool.Tool { return toolbuiltin.NewKillShellTool(shells) }
+
+	// File tools share one tracker so edits require a prior read in the
+	// same session and stale files are detected.
+	files := opts.files
+	if files == nil {
+		files = toolbuiltin.NewFileStateTracker()
+	}
+	for _, name := range []string{"read", "write", "edit", "multi_edit", "apply_patch", "notebook_edit", "grep"} {
+		ctor := factories[name]
+		if ctor == nil {
+			continue
+		}
+		factories[name] = func() tool.Tool {
+			impl := ctor()
+			if aware, ok := impl.(interface {
+				SetFileState(*toolbuiltin.FileStateTracker)
+			}); ok {
+				aware.SetFileState(files)
+			}
+			return impl
+		}
+	}
+
+	if index := opts.codeIndex; index != nil {
+		for _, name := range []string{"grep", "glob"} {
+			ctor := factories[name]
+			if ctor == nil {
+				continue
+			}
+			factories[name] = func() tool.Tool {
+				impl := ctor()
+				if aware, ok := impl.(interface {
+					SetCodeIndex(*toolbuiltin.CodeIndex)
+				}); ok {
+					aware.SetCodeIndex(index)
+				}
+				return impl
+			}
+		}
+	}
+
+	// Language servers are expensive to start, so every lsp tool instance
+	// shares the runtime's manager.
+	if lsp := opts.lsp; lsp != nil {
+		if lspCtor := factories["lsp"]; lspCtor != nil {
+			factories["lsp"] = func() tool.Tool {
+				impl := lspCtor()
+				if t, ok := impl.(*toolbuiltin.LSPTool); ok {
+					t.SetLSPManager(lsp)
+				}
+				return impl
+			}
+		}
+	}
+
+	// sql_query only exists when settings declare connections; the manager
+	// keeps one pool per connection for the runtime's lifetime.
+	if db := opts.sql; len(db.Names()) > 0 {
+		factories["sql_query"] = func() tool.Tool { return toolbuiltin.NewSQLQueryTool(db) }
+	}
+
+	// The task tools drive nested agent runs and therefore only exist when a
+	// runtime owns the registry.
+	if tasks := opts.tasks; tasks != nil {
+		factories["task"] = func() tool.Tool { return toolbuiltin.NewTaskTool(tasks) }
+		factories["task_status"] = func() tool.Tool { return toolbuiltin.NewTaskStatusTool(tasks) }
+		factories["task_output"] = func() tool.Tool { return toolbuiltin.NewTaskOutputTool(tasks) }
+	}
+}
+
+// httpRequestFactory builds http_request with the sandbox's host allowlist.
+// {{secret:NAME}} placeholders resolve from Options.SecretProvider first and
+// then from the settings env.
+func httpRequestFactory(opts Options, settings *config.Settings) func() tool.Tool {
+	var env toolbuiltin.SecretProvider
+	if settings != nil && len(settings.Env) > 0 {
+		env = toolbuiltin.SecretMap(maps.Clone(settings.Env))
+	}
+	secrets := toolbuiltin.MultiSecretProvider(opts.SecretProvider, env)
+	return func() tool.Tool {
+		h := toolbuiltin.NewHTTPRequestTool(sandboxNetworkPolicy(opts, settings))
+		h.SetSecretProvider(secrets)
+		return h
+	}
+}
+
 func builtinOrder(entry EntryPoint) []string {
 	_ = entry
-	return []string{"bash", "read", "write", "edit", "glob", "grep", "skill"}
+	return []string{"bash", "bash_output", "kill_shell", "read", "write", "edit", "multi_edit", "apply_patch", "notebook_edit", "glob", "grep", "lsp", "go_symbols", "git", "http_request", "sql_query", "skill", "todo_write", "todo_read", "task", "task_status", "task_output", "list_mcp_resources", "read_mcp_resource"}
+}
+
+// optInBuiltins reach a language server, version control, the network or a
+// database. They are only registered when EnabledBuiltinTools names them.
+var optInBuiltins = map[string]struct{}{
+	"lsp":          {},
+	"go_symbols":   {},
+	"git":          {},
+	"http_request": {},
+	"sql_query":    {},
 }
 
 func filterBuiltinNames(enabled []string, order []string) []string {
 	if enabled == nil {
-		return append([]string(nil), order...)
+		var defaults []string
+		for _, name := range order {
+			if _, ok := optInBuiltins[name]; !ok {
+				defaults = append(defaults, name)
+			}
+		}
+		return defaults
 	}
 	if len(enabled) == 0 {
 		return nil
@@ -236,6 +458,12 @@ func registerMCPServers(ctx context.Context, registry *tool.Registry, manager *s
 		if server.ToolTimeoutSeconds > 0 {
 			opts.ToolTimeout = time.Duration(server.ToolTimeoutSeconds) * time.Second
 		}
+		if server.authorizer != nil {
+			if err := preauthorizeMCP(ctx, server.authorizer); err != nil {
+				return fmt.Errorf("api: authorize MCP %s: %w", spec, err)
+			}
+			opts.WrapTransport = server.authorizer.Transport
+		}
 
 		var err error
 		if !hasMCPServerOptions(opts) {
@@ -256,7 +484,8 @@ func hasMCPServerOptions(opts tool.MCPServerOptions) bool {
 		opts.Timeout > 0 ||
 		len(opts.EnabledTools) > 0 ||
 		len(opts.DisabledTools) > 0 ||
-		opts.ToolTimeout > 0
+		opts.ToolTimeout > 0 ||
+		opts.WrapTransport != nil
 }
 
 func enforceSandboxHost(manager *sandbox.Manager, server string) error {
@@ -299,3 +528,140 @@ func defaultSessionID(entry EntryPoint) string {
 	}
 	return fmt.Sprintf("%s-%d", prefix, time.Now().UnixNano())
 }
+
+// lspServersFromSettings overlays the configured language servers on the
+// defaults: entries with a default's name replace it and disabled entries
+// remove it.
+func lspServersFromSettings(settings *config.Settings) []toolbuiltin.LSPServerConfig {
+	servers := toolbuiltin.DefaultLSPServers()
+	if settings == nil || settings.LSP == nil || len(settings.LSP.Servers) == 0 {
+		return servers
+	}
+	names := make([]string, 0, len(settings.LSP.Servers))
+	for name := range settings.LSP.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cfg := settings.LSP.Servers[name]
+		kept := servers[:0]
+		for _, server := range servers {
+			if server.Name != name {
+				kept = append(kept, server)
+			}
+		}
+		servers = kept
+		if cfg.Disabled {
+			continue
+		}
+		// Configured servers take precedence over defaults for shared extensions.
+		servers = append([]toolbuiltin.LSPServerConfig{{
+			Name:       name,
+			Command:    cfg.Command,
+			Args:       append([]string(nil), cfg.Args...),
+			Extensions: append([]string(nil), cfg.Extensions...),
+			LanguageID: cfg.LanguageID,
+			Env:        cfg.Env,
+		}}, servers...)
+	}
+	return servers
+}
+
+// gitPolicyFromSettings applies includeCoAuthoredBy and the git section of
+// the settings on top of the git tool's defaults.
+func gitPolicyFromSettings(settings *config.Settings) toolbuiltin.GitPolicy {
+	policy := toolbuiltin.DefaultGitPolicy()
+	if settings == nil {
+		return policy
+	}
+	if settings.IncludeCoAuthoredBy != nil {
+		policy.CoAuthoredBy = *settings.IncludeCoAuthoredBy
+	}
+	if cfg := settings.Git; cfg != nil {
+		if len(cfg.ProtectedBranches) > 0 {
+			policy.ProtectedBranches = append([]string(nil), cfg.ProtectedBranches...)
+		}
+		if cfg.AllowHistoryRewrite != nil {
+			policy.AllowHistoryRewrite = *cfg.AllowHistoryRewrite
+		}
+		if strings.TrimSpace(cfg.CoAuthor) != "" {
+			policy.CoAuthor = strings.TrimSpace(cfg.CoAuthor)
+		}
+	}
+	return policy
+}
+
+// settingsVarPattern matches ${VAR}; a bare $ is left alone since passwords
+// in DSNs and header values often contain one.
+var settingsVarPattern = regexp.MustCompile(`\$\{[A-Za-z_][A-Za-z0-9_]*\}`)
+
+// expandSettingsVars replaces ${VAR} in text from the settings env and then
+// the process environment so credentials can stay out of settings files.
+func expandSettingsVars(settings *config.Settings, text string) string {
+	return settingsVarPattern.ReplaceAllStringFunc(text, func(match string) string {
+		key := match[2 : len(match)-1]
+		if settings != nil {
+			if v, ok := settings.Env[key]; ok {
+				return v
+			}
+		}
+		return os.Getenv(key)
+	})
+}
+
+// sqlConnectionsFromSettings converts settings.sql into tool connections,
+// expanding ${VAR} in each DSN.
+func sqlConnectionsFromSettings(settings *config.Settings) []toolbuiltin.SQLConnectionConfig {
+	if settings == nil || settings.SQL == nil || len(settings.SQL.Connections) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(settings.SQL.Connections))
+	for name := range settings.SQL.Connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	conns := make([]toolbuiltin.SQLConnectionConfig, 0, len(names))
+	for _, name := range names {
+		cfg := settings.SQL.Connections[name]
+		conns = append(conns, toolbuiltin.SQLConnectionConfig{
+			Name:        name,
+			Driver:      cfg.Driver,
+			DSN:         expandSettingsVars(settings, cfg.DSN),
+			AllowWrites: cfg.AllowWrites != nil && *cfg.AllowWrites,
+			MaxRows:     cfg.MaxRows,
+			MaxBytes:    cfg.MaxBytes,
+			Timeout:     time.Duration(cfg.TimeoutSeconds) * time.Second,
+		})
+	}
+	return conns
+}
+
+// toolPolicyOverrides converts settings.toolPolicies into executor overrides
+// keyed by tool name.
+func toolPolicyOverrides(settings *config.Settings) map[string]tool.PolicyOverride {
+	if settings == nil || len(settings.ToolPolicies) == 0 {
+		return nil
+	}
+	overrides := make(map[string]tool.PolicyOverride, len(settings.ToolPolicies))
+	for name, cfg := range settings.ToolPolicies {
+		var override tool.PolicyOverride
+		if cfg.TimeoutSeconds != nil {
+			timeout := time.Duration(*cfg.TimeoutSeconds) * time.Second
+			override.Timeout = &timeout
+		}
+		if cfg.MaxRetries != nil {
+			retries := *cfg.MaxRetries
+			override.MaxRetries = &retries
+		}
+		if cfg.BackoffMs != nil {
+			backoff := time.Duration(*cfg.BackoffMs) * time.Millisecond
+			override.Backoff = &backoff
+		}
+		if cfg.Idempotent != nil {
+			idempotent := *cfg.Idempotent
+			override.Idempotent = &idempotent
+		}
+		overrides[strings.TrimSpace(name)] = override
+	}
+	return overrides
+}
@@ -71,3 +71,44 @@ func TestValidateSettingsMCPHeaderAndTimeoutErrors(t *testing.T) {
 	require.Contains(t, err.Error(), "enabledTools")
 	require.Contains(t, err.Error(), "disabledTools")
 }
+
+func TestValidateSettingsMCPSampling(t *testing.T) {
+	settings := GetDefaultSettings()
+	settings.Model = "dummy"
+	enabled := true
+	settings.MCP = &MCPConfig{Servers: map[string]MCPServerConfig{
+		"ok":  {Type: "http", URL: "https://ok.example", Sampling: &MCPSamplingConfig{Enabled: &enabled, ModelTier: "low", MaxTokens: 512}},
+		"bad": {Type: "http", URL: "https://bad.example", Sampling: &MCPSamplingConfig{ModelTier: "huge", MaxTokens: -1}},
+	}}
+
+	err := settings.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sampling.maxTokens")
+	require.Contains(t, err.Error(), "sampling.modelTier")
+	require.NotContains(t, err.Error(), "mcp.servers[ok]")
+
+	clone := cloneMCPServerConfig(settings.MCP.Servers["ok"])
+	*clone.Sampling.Enabled = false
+	require.True(t, *settings.MCP.Servers["ok"].Sampling.Enabled, "clone must not share the sampling policy")
+}
+
+func TestValidateSettingsMCPOAuth(t *testing.T) {
+	settings := GetDefaultSettings()
+	settings.Model = "dummy"
+	settings.MCP = &MCPConfig{Servers: map[string]MCPServerConfig{
+		"ok":    {Type: "http", URL: "https://ok.example", OAuth: &MCPOAuthConfig{Scopes: []string{"files"}}},
+		"stdio": {Type: "stdio", Command: "srv", OAuth: &MCPOAuthConfig{}},
+		"bad":   {Type: "sse", URL: "https://bad.example", OAuth: &MCPOAuthConfig{ClientSecret: "s", RedirectPort: 70000}},
+	}}
+
+	err := settings.Validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mcp.servers[stdio].oauth requires type http or sse")
+	require.Contains(t, err.Error(), "oauth.redirectPort")
+	require.Contains(t, err.Error(), "oauth.clientSecret requires clientId")
+	require.NotContains(t, err.Error(), "mcp.servers[ok]")
+
+	clone := cloneMCPServerConfig(settings.MCP.Servers["ok"])
+	clone.OAuth.Scopes[0] = "other"
+	require.Equal(t, "files", settings.MCP.Servers["ok"].OAuth.Scopes[0])
+}
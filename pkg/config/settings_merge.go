@@ -57,6 +57,11 @@ func MergeSettings(lower, higher *Settings) *Settings {
 	result.Sandbox = mergeSandbox(lower.Sandbox, higher.Sandbox)
 	result.BashOutput = mergeBashOutput(lower.BashOutput, higher.BashOutput)
 	result.ToolOutput = mergeToolOutput(lower.ToolOutput, higher.ToolOutput)
+	result.LSP = mergeLSPConfig(lower.LSP, higher.LSP)
+	result.Git = mergeGitConfig(lower.Git, higher.Git)
+	result.SQL = mergeSQLConfig(lower.SQL, higher.SQL)
+	result.ToolPolicies = mergeToolPolicies(lower.ToolPolicies, higher.ToolPolicies)
+	result.OpenAPI = mergeOpenAPIConfig(lower.OpenAPI, higher.OpenAPI)
 	result.AllowedMcpServers = mergeMCPServerRules(lower.AllowedMcpServers, higher.AllowedMcpServers)
 	result.DeniedMcpServers = mergeMCPServerRules(lower.DeniedMcpServers, higher.DeniedMcpServers)
 	if higher.AWSAuthRefresh != "" {
@@ -343,6 +348,124 @@ func mergeMCPConfig(lower, higher *MCPConfig) *MCPConfig {
 	return out
 }
 
+func mergeLSPConfig(lower, higher *LSPConfig) *LSPConfig {
+	if lower == nil && higher == nil {
+		return nil
+	}
+	if lower == nil {
+		return cloneLSPConfig(higher)
+	}
+	if higher == nil {
+		return cloneLSPConfig(lower)
+	}
+	out := cloneLSPConfig(lower)
+	if len(higher.Servers) > 0 {
+		if out.Servers == nil {
+			out.Servers = make(map[string]LSPServerConfig, len(higher.Servers))
+		}
+		for name, cfg := range higher.Servers {
+			out.Servers[name] = cloneLSPServerConfig(cfg)
+		}
+	}
+	return out
+}
+
+func mergeGitConfig(lower, higher *GitConfig) *GitConfig {
+	if lower == nil && higher == nil {
+		return nil
+	}
+	if lower == nil {
+		return cloneGitConfig(higher)
+	}
+	if higher == nil {
+		return cloneGitConfig(lower)
+	}
+	out := cloneGitConfig(lower)
+	if len(higher.ProtectedBranches) > 0 {
+		out.ProtectedBranches = append([]string(nil), higher.ProtectedBranches...)
+	}
+	if higher.AllowHistoryRewrite != nil {
+		out.AllowHistoryRewrite = boolPtr(*higher.AllowHistoryRewrite)
+	}
+	if higher.CoAuthor != "" {
+		out.CoAuthor = higher.CoAuthor
+	}
+	return out
+}
+
+func mergeSQLConfig(lower, higher *SQLConfig) *SQLConfig {
+	if lower == nil && higher == nil {
+		return nil
+	}
+	if lower == nil {
+		return cloneSQLConfig(higher)
+	}
+	if higher == nil {
+		return cloneSQLConfig(lower)
+	}
+	out := cloneSQLConfig(lower)
+	if len(higher.Connections) > 0 {
+		if out.Connections == nil {
+			out.Connections = make(map[string]SQLConnectionConfig, len(higher.Connections))
+		}
+		for name, cfg := range higher.Connections {
+			out.Connections[name] = cloneSQLConnectionConfig(cfg)
+		}
+	}
+	return out
+}
+
+func mergeOpenAPIConfig(lower, higher *OpenAPIConfig) *OpenAPIConfig {
+	if lower == nil && higher == nil {
+		return nil
+	}
+	if lower == nil {
+		return cloneOpenAPIConfig(higher)
+	}
+	if higher == nil {
+		return cloneOpenAPIConfig(lower)
+	}
+	out := cloneOpenAPIConfig(lower)
+	if len(higher.Services) > 0 {
+		if out.Services == nil {
+			out.Services = make(map[string]OpenAPIServiceConfig, len(higher.Services))
+		}
+		for name, cfg := range higher.Services {
+			out.Services[name] = cloneOpenAPIServiceConfig(cfg)
+		}
+	}
+	return out
+}
+
+// mergeToolPolicies merges field by field so a project can, for example, raise
+// a timeout without dropping retries configured at user level.
+func mergeToolPolicies(lower, higher map[string]ToolPolicyConfig) map[string]ToolPolicyConfig {
+	if len(lower) == 0 && len(higher) == 0 {
+		return nil
+	}
+	out := cloneToolPolicies(lower)
+	if out == nil {
+		out = make(map[string]ToolPolicyConfig, len(higher))
+	}
+	for name, cfg := range higher {
+		merged := out[name]
+		if cfg.TimeoutSeconds != nil {
+			merged.TimeoutSeconds = intPtr(*cfg.TimeoutSeconds)
+		}
+		if cfg.MaxRetries != nil {
+			merged.MaxRetries = intPtr(*cfg.MaxRetries)
+		}
+		if cfg.BackoffMs != nil {
+			merged.BackoffMs = intPtr(*cfg.BackoffMs)
+		}
+		if cfg.Idempotent != nil {
+			merged.Idempotent = boolPtr(*cfg.Idempotent)
+		}
+		out[name] = merged
+	}
+	return out
+}
+
 func mergeMCPServerRules(lower, higher []MCPServerRule) []MCPServerRule {
 	if len(higher) > 0 {
 		return append([]MCPServerRule(nil), higher...)
@@ -374,6 +497,11 @@ func cloneSettings(src *Settings) *Settings {
 	out.AllowedMcpServers = mergeMCPServerRules(nil, src.AllowedMcpServers)
 	out.DeniedMcpServers = mergeMCPServerRules(nil, src.DeniedMcpServers)
 	out.MCP = cloneMCPConfig(src.MCP)
+	out.LSP = cloneLSPConfig(src.LSP)
+	out.Git = cloneGitConfig(src.Git)
+	out.SQL = cloneSQLConfig(src.SQL)
+	out.ToolPolicies = cloneToolPolicies(src.ToolPolicies)
+	out.OpenAPI = cloneOpenAPIConfig(src.OpenAPI)
 	out.LegacyMCPServers = mergeStringSlices(nil, src.LegacyMCPServers)
 	return &out
 }
@@ -487,6 +615,41 @@ func cloneMCPServerConfig(src MCPServerConfig) MCPServerConfig {
 	out.Headers = mergeMaps(nil, src.Headers)
 	out.EnabledTools = mergeStringSlices(nil, src.EnabledTools)
 	out.DisabledTools = mergeStringSlices(nil, src.DisabledTools)
+	if src.Sampling != nil {
+		sampling := *src.Sampling
+		if src.Sampling.Enabled != nil {
+			enabled := *src.Sampling.Enabled
+			sampling.Enabled = &enabled
+		}
+		out.Sampling = &sampling
+	}
+	if src.OAuth != nil {
+		oauth := *src.OAuth
+		oauth.Scopes = mergeStringSlices(nil, src.OAuth.Scopes)
+		out.OAuth = &oauth
+	}
+	return out
+}
+
+func cloneLSPConfig(src *LSPConfig) *LSPConfig {
+	if src == nil {
+		return nil
+	}
+	out := &LSPConfig{}
+	if len(src.Servers) > 0 {
+		out.Servers = make(map[string]LSPServerConfig, len(src.Servers))
+		for name, cfg := range src.Servers {
+			out.Servers[name] = cloneLSPServerConfig(cfg)
+		}
+	}
+	return out
+}
+
+func cloneLSPServerConfig(src LSPServerConfig) LSPServerConfig {
+	out := src
+	out.Args = mergeStringSlices(nil, src.Args)
+	out.Extensions = mergeStringSlices(nil, src.Extensions)
+	out.Env = mergeMaps(nil, src.Env)
 	return out
 }
 
@@ -504,3 +667,77 @@ func cloneBoolPtr(v *bool) *bool {
 	}
 	return boolPtr(*v)
 }
+
+func cloneGitConfig(src *GitConfig) *GitConfig {
+	if src == nil {
+		return nil
+	}
+	out := *src
+	out.ProtectedBranches = mergeStringSlices(nil, src.ProtectedBranches)
+	out.AllowHistoryRewrite = cloneBoolPtr(src.AllowHistoryRewrite)
+	return &out
+}
+
+func cloneSQLConfig(src *SQLConfig) *SQLConfig {
+	if src == nil {
+		return nil
+	}
+	out := &SQLConfig{}
+	if len(src.Connections) > 0 {
+		out.Connections = make(map[string]SQLConnectionConfig, len(src.Connections))
+		for name, cfg := range src.Connections {
+			out.Connections[name] = cloneSQLConnectionConfig(cfg)
+		}
+	}
+	return out
+}
+
+func cloneSQLConnectionConfig(src SQLConnectionConfig) SQLConnectionConfig {
+	out := src
+	out.AllowWrites = cloneBoolPtr(src.AllowWrites)
+	return out
+}
+
+func cloneOpenAPIConfig(src *OpenAPIConfig) *OpenAPIConfig {
+	if src == nil {
+		return nil
+	}
+	out := &OpenAPIConfig{}
+	if len(src.Services) > 0 {
+		out.Services = make(map[string]OpenAPIServiceConfig, len(src.Services))
+		for name, cfg := range src.Services {
+			out.Services[name] = cloneOpenAPIServiceConfig(cfg)
+		}
+	}
+	return out
+}
+
+func cloneOpenAPIServiceConfig(src OpenAPIServiceConfig) OpenAPIServiceConfig {
+	out := src
+	out.Headers = mergeMaps(nil, src.Headers)
+	out.Operations = mergeStringSlices(nil, src.Operations)
+	return out
+}
+
+func cloneToolPolicies(src map[string]ToolPolicyConfig) map[string]ToolPolicyConfig {
+	if len(src) == 0 {
+		return nil
+	}
+	out := make(map[string]ToolPolicyConfig, len(src))
+	for name, cfg := range src {
+		out[name] = ToolPolicyConfig{
+			TimeoutSeconds: cloneIntPtr(cfg.TimeoutSeconds),
+			MaxRetries:     cloneIntPtr(cfg.MaxRetries),
+			BackoffMs:      cloneIntPtr(cfg.BackoffMs),
+			Idempotent:     cloneBoolPtr(cfg.Idempotent),
+		}
+	}
+	return out
+}
+
+func cloneIntPtr(v *int) *int {
+	if v == nil {
+		return nil
+	}
+	return intPtr(*v)
+}
@@ -48,3 +48,25 @@ func TestMergeSettingsNilInputs(t *testing.T) {
 		t.Fatalf("expected lower preserved")
 	}
 }
+
+func TestMergeSettingsLSPServers(t *testing.T) {
+	lower := &Settings{LSP: &LSPConfig{Servers: map[string]LSPServerConfig{
+		"gopls":   {Command: "gopls", Extensions: []string{".go"}},
+		"pyright": {Command: "pyright-langserver", Args: []string{"--stdio"}, Extensions: []string{".py"}},
+	}}}
+	higher := &Settings{LSP: &LSPConfig{Servers: map[string]LSPServerConfig{
+		"gopls": {Command: "/opt/gopls", Extensions: []string{".go"}},
+	}}}
+
+	merged := MergeSettings(lower, higher)
+	if got := merged.LSP.Servers["gopls"].Command; got != "/opt/gopls" {
+		t.Fatalf("expected higher gopls to win, got %q", got)
+	}
+	if _, ok := merged.LSP.Servers["pyright"]; !ok {
+		t.Fatal("expected lower-only server to be kept")
+	}
+	merged.LSP.Servers["pyright"].Args[0] = "mutated"
+	if lower.LSP.Servers["pyright"].Args[0] != "--stdio" {
+		t.Fatal("merge must not alias lower args")
+	}
+}
@@ -8,30 +8,35 @@ import (
 // Settings models the full contents of .agents/settings.json.
 // All optional booleans use *bool so nil means "unset" and caller defaults apply.
 type Settings struct {
-	APIKeyHelper         string             `json:"apiKeyHelper,omitempty"`         // /bin/sh script that returns an API key for outbound model calls.
-	CleanupPeriodDays    *int               `json:"cleanupPeriodDays,omitempty"`    // Days to retain chat history locally (default 30). Set to 0 to disable.
-	CompanyAnnouncements []string           `json:"companyAnnouncements,omitempty"` // Startup announcements rotated randomly.
-	Env                  map[string]string  `json:"env,omitempty"`                  // Environment variables applied to every session.
-	IncludeCoAuthoredBy  *bool              `json:"includeCoAuthoredBy,omitempty"`  // Whether to append "co-authored-by Claude" to commits/PRs.
-	Permissions          *PermissionsConfig `json:"permissions,omitempty"`          // Tool permission rules and defaults.
-	DisallowedTools      []string           `json:"disallowedTools,omitempty"`      // Tool blacklist; disallowed tools are not registered.
-	Hooks                *HooksConfig       `json:"hooks,omitempty"`                // Hook commands to run around tool execution.
-	DisableAllHooks      *bool              `json:"disableAllHooks,omitempty"`      // Force-disable all hooks.
-	Model                string             `json:"model,omitempty"`                // Override default model id.
-	StatusLine           *StatusLineConfig  `json:"statusLine,omitempty"`           // Custom status line settings.
-	OutputStyle          string             `json:"outputStyle,omitempty"`          // Optional named output style.
-	MCP                  *MCPConfig         `json:"mcp,omitempty"`                  // MCP server definitions keyed by name.
-	LegacyMCPServers     []string           `json:"mcpServers,omitempty"`           // Deprecated list format; kept for migration errors.
-	ForceLoginMethod     string             `json:"forceLoginMethod,omitempty"`     // Restrict login to "claudeai" or "console".
-	ForceLoginOrgUUID    string             `json:"forceLoginOrgUUID,omitempty"`    // Org UUID to auto-select during login when set.
-	Sandbox              *SandboxConfig     `json:"sandbox,omitempty"`              // Bash sandbox configuration.
-	BashOutput           *BashOutputConfig  `json:"bashOutput,omitempty"`           // Thresholds for spooling bash output to disk.
-	ToolOutput           *ToolOutputConfig  `json:"toolOutput,omitempty"`           // Thresholds for persisting large tool outputs to disk.
-	AllowedMcpServers    []MCPServerRule    `json:"allowedMcpServers,omitempty"`    // Managed allowlist of user-configurable MCP servers.
-	DeniedMcpServers     []MCPServerRule    `json:"deniedMcpServers,omitempty"`     // Managed denylist of user-configurable MCP servers.
-	AWSAuthRefresh       string             `json:"awsAuthRefresh,omitempty"`       // Script to refresh AWS SSO credentials.
-	AWSCredentialExport  string             `json:"awsCredentialExport,omitempty"`  // Script that prints JSON AWS credentials.
-	RespectGitignore     *bool              `json:"respectGitignore,omitempty"`     // Whether Glob/Grep tools should respect .gitignore patterns.
+	APIKeyHelper         string                      `json:"apiKeyHelper,omitempty"`         // /bin/sh script that returns an API key for outbound model calls.
+	CleanupPeriodDays    *int                        `json:"cleanupPeriodDays,omitempty"`    // Days to retain chat history locally (default 30). Set to 0 to disable.
+	CompanyAnnouncements []string                    `json:"companyAnnouncements,omitempty"` // Startup announcements rotated randomly.
+	Env                  map[string]string           `json:"env,omitempty"`                  // Environment variables applied to every session.
+	IncludeCoAuthoredBy  *bool                       `json:"includeCoAuthoredBy,omitempty"`  // Whether to append "co-authored-by Claude" to commits/PRs.
+	Permissions          *PermissionsConfig          `json:"permissions,omitempty"`          // Tool permission rules and defaults.
+	DisallowedTools      []string                    `json:"disallowedTools,omitempty"`      // Tool blacklist; disallowed tools are not registered.
+	Hooks                *HooksConfig                `json:"hooks,omitempty"`                // Hook commands to run around tool execution.
+	DisableAllHooks      *bool                       `json:"disableAllHooks,omitempty"`      // Force-disable all hooks.
+	Model                string                      `json:"model,omitempty"`                // Override default model id.
+	StatusLine           *StatusLineConfig           `json:"statusLine,omitempty"`           // Custom status line settings.
+	OutputStyle          string                      `json:"outputStyle,omitempty"`          // Optional named output style.
+	MCP                  *MCPConfig                  `json:"mcp,omitempty"`                  // MCP server definitions keyed by name.
+	LegacyMCPServers     []string                    `json:"mcpServers,omitempty"`           // Deprecated list format; kept for migration errors.
+	ForceLoginMethod     string                      `json:"forceLoginMethod,omitempty"`     // Restrict login to "claudeai" or "console".
+	ForceLoginOrgUUID    string                      `json:"forceLoginOrgUUID,omitempty"`    // Org UUID to auto-select during login when set.
+	Sandbox              *SandboxConfig              `json:"sandbox,omitempty"`              // Bash sandbox configuration.
+	BashOutput           *BashOutputConfig           `json:"bashOutput,omitempty"`           // Thresholds for spooling bash output to disk.
+	ToolOutput           *ToolOutputConfig           `json:"toolOutput,omitempty"`           // Thresholds for persisting large tool outputs to disk.
+	AllowedMcpServers    []MCPServerRule             `json:"allowedMcpServers,omitempty"`    // Managed allowlist of user-configurable MCP servers.
+	DeniedMcpServers     []MCPServerRule             `json:"deniedMcpServers,omitempty"`     // Managed denylist of user-configurable MCP servers.
+	AWSAuthRefresh       string                      `json:"awsAuthRefresh,omitempty"`       // Script to refresh AWS SSO credentials.
+	AWSCredentialExport  string                      `json:"awsCredentialExport,omitempty"`  // Script that prints JSON AWS credentials.
+	RespectGitignore     *bool                       `json:"respectGitignore,omitempty"`     // Whether Glob/Grep tools should respect .gitignore patterns.
+	LSP                  *LSPConfig                  `json:"lsp,omitempty"`                  // Language servers used by the lsp tool.
+	Git                  *GitConfig                  `json:"git,omitempty"`                  // Safety guards for the git tool.
+	SQL                  *SQLConfig                  `json:"sql,omitempty"`                  // Database connections for the sql_query tool.
+	ToolPolicies         map[string]ToolPolicyConfig `json:"toolPolicies,omitempty"`         // Per-tool timeout/retry overrides keyed by tool name.
+	OpenAPI              *OpenAPIConfig              `json:"openapi,omitempty"`              // HTTP services exposed as tools from their OpenAPI specs.
 }
 
 // PermissionsConfig defines per-tool permission rules.
@@ -116,16 +121,95 @@ type MCPConfig struct {
 
 // MCPServerConfig describes how to reach an MCP server.
 type MCPServerConfig struct {
-	Type               string            `json:"type"`              // stdio/http/sse
-	Command            string            `json:"command,omitempty"` // for stdio
-	Args               []string          `json:"args,omitempty"`
-	URL                string            `json:"url,omitempty"` // for http/sse
-	Env                map[string]string `json:"env,omitempty"`
-	Headers            map[string]string `json:"headers,omitempty"`
-	TimeoutSeconds     int               `json:"timeoutSeconds,omitempty"`     // optional connect/list timeout
-	EnabledTools       []string          `json:"enabledTools,omitempty"`       // optional remote tool allowlist
-	DisabledTools      []string          `json:"disabledTools,omitempty"`      // optional remote tool denylist
-	ToolTimeoutSeconds int               `json:"toolTimeoutSeconds,omitempty"` // optional timeout for each MCP tool call
+	Type               string             `json:"type"`              // stdio/http/sse
+	Command            string             `json:"command,omitempty"` // for stdio
+	Args               []string           `json:"args,omitempty"`
+	URL                string             `json:"url,omitempty"` // for http/sse
+	Env                map[string]string  `json:"env,omitempty"`
+	Headers            map[string]string  `json:"headers,omitempty"`
+	TimeoutSeconds     int                `json:"timeoutSeconds,omitempty"`     // optional connect/list timeout
+	EnabledTools       []string           `json:"enabledTools,omitempty"`       // optional remote tool allowlist
+	DisabledTools      []string           `json:"disabledTools,omitempty"`      // optional remote tool denylist
+	ToolTimeoutSeconds int                `json:"toolTimeoutSeconds,omitempty"` // optional timeout for each MCP tool call
+	Sampling           *MCPSamplingConfig `json:"sampling,omitempty"`           // optional policy for sampling requests from this server
+	OAuth              *MCPOAuthConfig    `json:"oauth,omitempty"`              // authorize http/sse servers with OAuth; {} uses discovery and dynamic registration
+}
+
+// MCPOAuthConfig enables the MCP authorization flow for an http/sse server.
+type MCPOAuthConfig struct {
+	ClientID     string   `json:"clientId,omitempty"`     // Pre-registered client; empty registers one dynamically.
+	ClientSecret string   `json:"clientSecret,omitempty"` // Secret of a confidential pre-registered client.
+	Scopes       []string `json:"scopes,omitempty"`       // Scopes to request; defaults to what the server advertises.
+	RedirectPort int      `json:"redirectPort,omitempty"` // Fixed loopback redirect port; 0 picks a free port.
+}
+
+// MCPSamplingConfig controls how the runtime answers sampling/createMessage
+// requests from one MCP server.
+type MCPSamplingConfig struct {
+	Enabled   *bool  `json:"enabled,omitempty"`   // Allow or refuse sampling; nil keeps the runtime default.
+	ModelTier string `json:"modelTier,omitempty"` // Model pool tier (low/mid/high); empty uses the runtime model.
+	MaxTokens int    `json:"maxTokens,omitempty"` // Cap on tokens sampled per request; 0 keeps the runtime default.
+}
+
+// LSPConfig configures the language servers behind the lsp tool.
+type LSPConfig struct {
+	Servers map[string]LSPServerConfig `json:"servers,omitempty"` // Keyed by server name; replaces the built-in default with the same name.
+}
+
+// LSPServerConfig describes how to launch a language server over stdio.
+type LSPServerConfig struct {
+	Command    string            `json:"command"`              // Executable, e.g. "gopls".
+	Args       []string          `json:"args,omitempty"`       // Extra arguments, e.g. ["--stdio"].
+	Extensions []string          `json:"extensions"`           // File extensions handled, e.g. [".go"].
+	LanguageID string            `json:"languageId,omitempty"` // LSP language id; derived from the extension when empty.
+	Env        map[string]string `json:"env,omitempty"`        // Extra environment variables.
+	Disabled   bool              `json:"disabled,omitempty"`   // Disable the server (including built-in defaults).
+}
+
+// GitConfig controls what the git tool may do to a repository.
+type GitConfig struct {
+	ProtectedBranches   []string `json:"protectedBranches,omitempty"`   // Branch names or globs that refuse commits and deletion (default ["main", "master"]).
+	AllowHistoryRewrite *bool    `json:"allowHistoryRewrite,omitempty"` // Permit amending commits and force-deleting branches (default false).
+	CoAuthor            string   `json:"coAuthor,omitempty"`            // Identity used for the Co-Authored-By trailer when includeCoAuthoredBy is set.
+}
+
+// SQLConfig declares the databases reachable through the sql_query tool.
+type SQLConfig struct {
+	Connections map[string]SQLConnectionConfig `json:"connections,omitempty"` // Keyed by connection name.
+}
+
+// SQLConnectionConfig describes one database/sql connection. The driver must
+// be registered by the host program (e.g. by importing a pgx or sqlite driver).
+type SQLConnectionConfig struct {
+	Driver         string `json:"driver"`                   // database/sql driver name, e.g. "pgx", "mysql" or "sqlite".
+	DSN            string `json:"dsn"`                      // Data source name; ${VAR} expands from env, then the process environment.
+	AllowWrites    *bool  `json:"allowWrites,omitempty"`    // Permit statements that are not read-only (default false).
+	MaxRows        int    `json:"maxRows,omitempty"`        // Upper bound on returned rows (default 1000).
+	MaxBytes       int    `json:"maxBytes,omitempty"`       // Upper bound on result size in bytes (default 65536).
+	TimeoutSeconds int    `json:"timeoutSeconds,omitempty"` // Per-statement timeout (default 30).
+}
+
+// OpenAPIConfig declares services whose OpenAPI 3 operations become tools.
+type OpenAPIConfig struct {
+	Services map[string]OpenAPIServiceConfig `json:"services,omitempty"` // Keyed by service name, which prefixes the tool names.
+}
+
+// OpenAPIServiceConfig describes one OpenAPI-described service.
+type OpenAPIServiceConfig struct {
+	Spec           string            `json:"spec"`                     // Path relative to the project root, or an http(s) URL, of the JSON/YAML document.
+	BaseURL        string            `json:"baseUrl,omitempty"`        // Overrides the document's first server URL.
+	Headers        map[string]string `json:"headers,omitempty"`        // Sent with every request (e.g. auth); ${VAR} expands from env, then the process environment.
+	Operations     []string          `json:"operations,omitempty"`     // Optional operationId or "METHOD /path" allowlist.
+	TimeoutSeconds int               `json:"timeoutSeconds,omitempty"` // Per-request timeout (default 30).
+}
+
+// ToolPolicyConfig overrides the execution policy a tool declares. Unset
+// fields keep the tool's own value.
+type ToolPolicyConfig struct {
+	TimeoutSeconds *int  `json:"timeoutSeconds,omitempty"` // Per-attempt timeout; 0 removes the executor timeout.
+	MaxRetries     *int  `json:"maxRetries,omitempty"`     // Extra attempts after retryable errors (idempotent tools only).
+	BackoffMs      *int  `json:"backoffMs,omitempty"`      // Delay before the first retry, doubling afterwards.
+	Idempotent     *bool `json:"idempotent,omitempty"`     // Whether repeating a call is safe.
 }
 
 // MCPServerRule constrains which MCP servers can be enabled.
@@ -3,13 +3,16 @@ package config
 import (
 	"errors"
 	"fmt"
+	"net/url"
+	"path"
 	"regexp"
 	"sort"
 	"strings"
 )
 
 var (
-	toolNamePattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	toolNamePattern           = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	openAPIServiceNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
 )
 
 // ValidateSettings checks the merged Settings structure for logical consistency.
@@ -43,6 +46,13 @@ func ValidateSettings(s *Settings) error {
 
 	// mcp
 	errs = append(errs, validateMCPConfig(s.MCP, s.LegacyMCPServers)...)
+	errs = append(errs, validateLSPConfig(s.LSP)...)
+
+	// git tool guards
+	errs = append(errs, validateGitConfig(s.Git)...)
+	errs = append(errs, validateSQLConfig(s.SQL)...)
+	errs = append(errs, validateToolPolicies(s.ToolPolicies)...)
+	errs = append(errs, validateOpenAPIConfig(s.OpenAPI)...)
 
 	// status line
 	errs = append(errs, validateStatusLineConfig(s.StatusLine)...)
@@ -345,6 +355,61 @@ func validateMCPConfig(cfg *MCPConfig, legacy []string) []error {
 		}
 		errs = append(errs, validateMCPToolList(name, "enabledTools", entry.EnabledTools)...)
 		errs = append(errs, validateMCPToolList(name, "disabledTools", entry.DisabledTools)...)
+		if sampling := entry.Sampling; sampling != nil {
+			if sampling.MaxTokens < 0 {
+				errs = append(errs, fmt.Errorf("mcp.servers[%s].sampling.maxTokens must be >=0", name))
+			}
+			switch strings.TrimSpace(sampling.ModelTier) {
+			case "", "low", "mid", "high":
+			default:
+				errs = append(errs, fmt.Errorf("mcp.servers[%s].sampling.modelTier %q must be low, mid or high", name, sampling.ModelTier))
+			}
+		}
+		if oauth := entry.OAuth; oauth != nil {
+			if serverType == "stdio" {
+				errs = append(errs, fmt.Errorf("mcp.servers[%s].oauth requires type http or sse", name))
+			}
+			if oauth.RedirectPort < 0 || oauth.RedirectPort > 65535 {
+				errs = append(errs, fmt.Errorf("mcp.servers[%s].oauth.redirectPort must be 0-65535", name))
+			}
+			if oauth.ClientSecret != "" && strings.TrimSpace(oauth.ClientID) == "" {
+				errs = append(errs, fmt.Errorf("mcp.servers[%s].oauth.clientSecret requires clientId", name))
+			}
+		}
+	}
+	return errs
+}
+
+func validateLSPConfig(cfg *LSPConfig) []error {
+	if cfg == nil || len(cfg.Servers) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(cfg.Servers))
+	for name := range cfg.Servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			errs = append(errs, errors.New("lsp.servers has an empty name"))
+			continue
+		}
+		entry := cfg.Servers[name]
+		if entry.Disabled {
+			continue
+		}
+		if strings.TrimSpace(entry.Command) == "" {
+			errs = append(errs, fmt.Errorf("lsp.servers[%s].command is required", name))
+		}
+		if len(entry.Extensions) == 0 {
+			errs = append(errs, fmt.Errorf("lsp.servers[%s].extensions is required", name))
+		}
+		for _, ext := range entry.Extensions {
+			if !strings.HasPrefix(strings.TrimSpace(ext), ".") {
+				errs = append(errs, fmt.Errorf("lsp.servers[%s].extensions entry %q must start with a dot", name, ext))
+			}
+		}
 	}
 	return errs
 }
@@ -424,3 +489,115 @@ func validateForceLoginConfig(method, org string) []error {
 	}
 	return errs
 }
+
+func validateGitConfig(cfg *GitConfig) []error {
+	if cfg == nil {
+		return nil
+	}
+	var errs []error
+	for i, pattern := range cfg.ProtectedBranches {
+		if strings.TrimSpace(pattern) == "" {
+			errs = append(errs, fmt.Errorf("git.protectedBranches[%d] cannot be empty", i))
+			continue
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			errs = append(errs, fmt.Errorf("git.protectedBranches[%d] %q is not a valid pattern: %w", i, pattern, err))
+		}
+	}
+	return errs
+}
+
+func validateSQLConfig(cfg *SQLConfig) []error {
+	if cfg == nil || len(cfg.Connections) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(cfg.Connections))
+	for name := range cfg.Connections {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			errs = append(errs, errors.New("sql.connections has an empty name"))
+			continue
+		}
+		entry := cfg.Connections[name]
+		if strings.TrimSpace(entry.Driver) == "" {
+			errs = append(errs, fmt.Errorf("sql.connections[%s].driver is required", name))
+		}
+		if strings.TrimSpace(entry.DSN) == "" {
+			errs = append(errs, fmt.Errorf("sql.connections[%s].dsn is required", name))
+		}
+		if entry.MaxRows < 0 {
+			errs = append(errs, fmt.Errorf("sql.connections[%s].maxRows cannot be negative", name))
+		}
+		if entry.MaxBytes < 0 {
+			errs = append(errs, fmt.Errorf("sql.connections[%s].maxBytes cannot be negative", name))
+		}
+		if entry.TimeoutSeconds < 0 {
+			errs = append(errs, fmt.Errorf("sql.connections[%s].timeoutSeconds cannot be negative", name))
+		}
+	}
+	return errs
+}
+
+func validateOpenAPIConfig(cfg *OpenAPIConfig) []error {
+	if cfg == nil || len(cfg.Services) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(cfg.Services))
+	for name := range cfg.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		if !openAPIServiceNamePattern.MatchString(name) {
+			errs = append(errs, fmt.Errorf("openapi.services name %q must match [A-Za-z0-9_-]+", name))
+			continue
+		}
+		entry := cfg.Services[name]
+		if strings.TrimSpace(entry.Spec) == "" {
+			errs = append(errs, fmt.Errorf("openapi.services[%s].spec is required", name))
+		}
+		if base := strings.TrimSpace(entry.BaseURL); base != "" {
+			if u, err := url.Parse(base); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				errs = append(errs, fmt.Errorf("openapi.services[%s].baseUrl %q must be an absolute http(s) URL", name, base))
+			}
+		}
+		if entry.TimeoutSeconds < 0 {
+			errs = append(errs, fmt.Errorf("openapi.services[%s].timeoutSeconds cannot be negative", name))
+		}
+	}
+	return errs
+}
+
+func validateToolPolicies(policies map[string]ToolPolicyConfig) []error {
+	if len(policies) == 0 {
+		return nil
+	}
+	names := make([]string, 0, len(policies))
+	for name := range policies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var errs []error
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			errs = append(errs, errors.New("toolPolicies has an empty tool name"))
+			continue
+		}
+		entry := policies[name]
+		if entry.TimeoutSeconds != nil && *entry.TimeoutSeconds < 0 {
+			errs = append(errs, fmt.Errorf("toolPolicies[%s].timeoutSeconds cannot be negative", name))
+		}
+		if entry.MaxRetries != nil && *entry.MaxRetries < 0 {
+			errs = append(errs, fmt.Errorf("toolPolicies[%s].maxRetries cannot be negative", name))
+		}
+		if entry.BackoffMs != nil && *entry.BackoffMs < 0 {
+			errs = append(errs, fmt.Errorf("toolPolicies[%s].backoffMs cannot be negative", name))
+		}
+	}
+	return errs
+}
@@ -191,3 +191,85 @@ func TestValidateForceLoginConfig_BlankOrg(t *testing.T) {
 
 	require.NoError(t, ValidateSettings(&Settings{Model: "m", Permissions: &PermissionsConfig{DefaultMode: "askBeforeRunningTools"}}))
 }
+
+func TestValidateLSPConfig(t *testing.T) {
+	require.NoError(t, ValidateSettings(&Settings{Model: "m", LSP: &LSPConfig{Servers: map[string]LSPServerConfig{
+		"gopls":   {Command: "gopls", Extensions: []string{".go"}},
+		"pyright": {Disabled: true},
+	}}}))
+
+	err := ValidateSettings(&Settings{Model: "m", LSP: &LSPConfig{Servers: map[string]LSPServerConfig{
+		"rust": {Extensions: []string{"rs"}},
+	}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "lsp.servers[rust].command is required")
+	require.Contains(t, err.Error(), `extensions entry "rs" must start with a dot`)
+}
+
+func TestValidateGitConfig(t *testing.T) {
+	require.NoError(t, ValidateSettings(&Settings{Model: "m", Git: &GitConfig{ProtectedBranches: []string{"main", "release/*"}}}))
+
+	err := ValidateSettings(&Settings{Model: "m", Git: &GitConfig{ProtectedBranches: []string{" ", "release/["}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "git.protectedBranches[0] cannot be empty")
+	require.Contains(t, err.Error(), "git.protectedBranches[1]")
+}
+
+func TestValidateSQLConfig(t *testing.T) {
+	require.NoError(t, ValidateSettings(&Settings{Model: "m", SQL: &SQLConfig{Connections: map[string]SQLConnectionConfig{
+		"warehouse": {Driver: "pgx", DSN: "postgres://ro@db/warehouse"},
+	}}}))
+
+	err := ValidateSettings(&Settings{Model: "m", SQL: &SQLConfig{Connections: map[string]SQLConnectionConfig{
+		"broken": {Driver: "pgx", MaxRows: -1},
+	}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "sql.connections[broken].dsn is required")
+	require.Contains(t, err.Error(), "sql.connections[broken].maxRows cannot be negative")
+}
+
+func TestOpenAPIConfigValidateAndMerge(t *testing.T) {
+	require.NoError(t, ValidateSettings(&Settings{Model: "m", OpenAPI: &OpenAPIConfig{Services: map[string]OpenAPIServiceConfig{
+		"billing": {Spec: "specs/billing.yaml", BaseURL: "https://billing.internal"},
+	}}}))
+
+	err := ValidateSettings(&Settings{Model: "m", OpenAPI: &OpenAPIConfig{Services: map[string]OpenAPIServiceConfig{
+		"broken":   {BaseURL: "/relative", TimeoutSeconds: -1},
+		"bad name": {Spec: "x.yaml"},
+	}}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "openapi.services[broken].spec is required")
+	require.Contains(t, err.Error(), "openapi.services[broken].baseUrl")
+	require.Contains(t, err.Error(), "openapi.services[broken].timeoutSeconds cannot be negative")
+	require.Contains(t, err.Error(), `openapi.services name "bad name"`)
+
+	lower := &Settings{OpenAPI: &OpenAPIConfig{Services: map[string]OpenAPIServiceConfig{
+		"billing": {Spec: "old.yaml", Headers: map[string]string{"X-Key": "a"}},
+		"users":   {Spec: "users.yaml"},
+	}}}
+	merged := MergeSettings(lower, &Settings{OpenAPI: &OpenAPIConfig{Services: map[string]OpenAPIServiceConfig{
+		"billing": {Spec: "new.yaml"},
+	}}})
+	require.Equal(t, "new.yaml", merged.OpenAPI.Services["billing"].Spec)
+	require.Nil(t, merged.OpenAPI.Services["billing"].Headers)
+	require.Equal(t, "users.yaml", merged.OpenAPI.Services["users"].Spec)
+
+	merged.OpenAPI.Services["users"] = OpenAPIServiceConfig{}
+	require.Equal(t, "users.yaml", lower.OpenAPI.Services["users"].Spec)
+}
+
+func TestToolPoliciesValidateAndMerge(t *testing.T) {
+	err := ValidateSettings(&Settings{Model: "m", ToolPolicies: map[string]ToolPolicyConfig{
+		"grep": {TimeoutSeconds: intPtr(30), MaxRetries: intPtr(-1)},
+	}})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "toolPolicies[grep].maxRetries cannot be negative")
+
+	merged := MergeSettings(
+		&Settings{ToolPolicies: map[string]ToolPolicyConfig{"grep": {TimeoutSeconds: intPtr(30), MaxRetries: intPtr(2)}}},
+		&Settings{ToolPolicies: map[string]ToolPolicyConfig{"grep": {TimeoutSeconds: intPtr(60)}, "mcp__db__query": {Idempotent: boolPtr(true)}}},
+	)
+	require.Equal(t, 60, *merged.ToolPolicies["grep"].TimeoutSeconds)
+	require.Equal(t, 2, *merged.ToolPolicies["grep"].MaxRetries)
+	require.True(t, *merged.ToolPolicies["mcp__db__query"].Idempotent)
+}
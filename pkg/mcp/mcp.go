@@ -45,6 +45,31 @@ type (
 	InitializeParams            = mcpsdk.InitializeParams
 	InitializeResult            = mcpsdk.InitializeResult
 	ServerCapabilities          = mcpsdk.ServerCapabilities
+	Resource                    = mcpsdk.Resource
+	ResourceContents            = mcpsdk.ResourceContents
+	ListResourcesParams         = mcpsdk.ListResourcesParams
+	ReadResourceParams          = mcpsdk.ReadResourceParams
+	ReadResourceResult          = mcpsdk.ReadResourceResult
+	SubscribeParams             = mcpsdk.SubscribeParams
+	ResourceListChangedRequest  = mcpsdk.ResourceListChangedRequest
+	ResourceUpdatedRequest      = mcpsdk.ResourceUpdatedNotificationRequest
+	Prompt                      = mcpsdk.Prompt
+	PromptArgument              = mcpsdk.PromptArgument
+	PromptMessage               = mcpsdk.PromptMessage
+	ListPromptsParams           = mcpsdk.ListPromptsParams
+	GetPromptParams             = mcpsdk.GetPromptParams
+	GetPromptResult             = mcpsdk.GetPromptResult
+	CreateMessageRequest        = mcpsdk.CreateMessageRequest
+	CreateMessageParams         = mcpsdk.CreateMessageParams
+	CreateMessageResult         = mcpsdk.CreateMessageResult
+	SamplingMessage             = mcpsdk.SamplingMessage
+	ModelPreferences            = mcpsdk.ModelPreferences
+	ModelHint                   = mcpsdk.ModelHint
+	ElicitRequest               = mcpsdk.ElicitRequest
+	ElicitParams                = mcpsdk.ElicitParams
+	ElicitResult                = mcpsdk.ElicitResult
+	Root                        = mcpsdk.Root
+	Role                        = mcpsdk.Role
 )
 
 var (
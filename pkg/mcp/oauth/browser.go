@@ -0,0 +1,30 @@
+package oauth
+
+import (
+	"context"
+	"log"
+	"os/exec"
+	"runtime"
+)
+
+// OpenBrowser opens authURL in the user's browser and logs it so the user can
+// open it by hand when no browser is available.
+func OpenBrowser(_ context.Context, authURL string) error {
+	log.Printf("oauth: open this URL to authorize the MCP server: %s", authURL)
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", authURL)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", authURL)
+	default:
+		cmd = exec.Command("xdg-open", authURL)
+	}
+	if err := cmd.Start(); err != nil {
+		// The URL is logged; the user can still complete the flow.
+		log.Printf("oauth: open browser: %v", err)
+		return nil
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
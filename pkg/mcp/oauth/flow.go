@@ -0,0 +1,411 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxMetadataBytes bounds discovery, registration and token responses.
+const maxMetadataBytes = 1 << 20
+
+// ResourceMetadata is the OAuth protected resource metadata of an MCP
+// server (RFC 9728).
+type ResourceMetadata struct {
+	Resource             string   `json:"resource"`
+	AuthorizationServers []string `json:"authorization_servers"`
+	ScopesSupported      []string `json:"scopes_supported,omitempty"`
+}
+
+// ServerMetadata is the authorization server metadata (RFC 8414).
+type ServerMetadata struct {
+	Issuer                        string   `json:"issuer"`
+	AuthorizationEndpoint         string   `json:"authorization_endpoint"`
+	TokenEndpoint                 string   `json:"token_endpoint"`
+	RegistrationEndpoint          string   `json:"registration_endpoint,omitempty"`
+	ScopesSupported               []string `json:"scopes_supported,omitempty"`
+	CodeChallengeMethodsSupported []string `json:"code_challenge_methods_supported,omitempty"`
+}
+
+// authorize runs discovery, registration when needed and the authorization
+// code flow, leaving the result in a.creds.
+func (a *Authorizer) authorize(ctx context.Context) (*Token, error) {
+	prm, err := a.discoverResource(ctx)
+	if err != nil {
+		return nil, err
+	}
+	meta, err := a.discoverServer(ctx, prm.AuthorizationServers[0])
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(meta.CodeChallengeMethodsSupported, "S256") {
+		return nil, fmt.Errorf("oauth: authorization server %s does not support PKCE S256", meta.Issuer)
+	}
+
+	listener, redirectURI, err := a.listen()
+	if err != nil {
+		return nil, err
+	}
+	defer listener.Close()
+
+	creds := *a.creds
+	if creds.ClientID == "" || (creds.Registered && creds.RedirectURI != redirectURI) {
+		if meta.RegistrationEndpoint == "" {
+			return nil, errors.New("oauth: no client id configured and the authorization server does not support dynamic registration")
+		}
+		if creds, err = a.register(ctx, meta.RegistrationEndpoint, redirectURI); err != nil {
+			return nil, err
+		}
+	}
+	creds.RedirectURI = redirectURI
+	creds.TokenEndpoint = meta.TokenEndpoint
+
+	verifier := randomString(32)
+	state := randomString(16)
+	authURL, err := url.Parse(meta.AuthorizationEndpoint)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: parse authorization endpoint: %w", err)
+	}
+	query := authURL.Query()
+	query.Set("response_type", "code")
+	query.Set("client_id", creds.ClientID)
+	query.Set("redirect_uri", redirectURI)
+	query.Set("code_challenge", pkceChallenge(verifier))
+	query.Set("code_challenge_method", "S256")
+	query.Set("state", state)
+	query.Set("resource", a.resource)
+	if scope := a.scope(prm); scope != "" {
+		query.Set("scope", scope)
+	}
+	authURL.RawQuery = query.Encode()
+
+	codes := make(chan callbackResult, 1)
+	srv := &http.Server{Handler: callbackHandler(state, codes), ReadHeaderTimeout: 10 * time.Second}
+	go func() { _ = srv.Serve(listener) }()
+	defer srv.Close()
+
+	if err := a.cfg.Authorize(ctx, authURL.String()); err != nil {
+		return nil, fmt.Errorf("oauth: start authorization: %w", err)
+	}
+	var res callbackResult
+	select {
+	case res = <-codes:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("oauth: waiting for authorization: %w", ctx.Err())
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {res.code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {verifier},
+		"resource":      {a.resource},
+	}
+	tok, err := a.tokenRequest(ctx, creds, form)
+	if err != nil {
+		return nil, err
+	}
+	creds.Token = tok
+	a.creds = &creds
+	return tok, nil
+}
+
+func (a *Authorizer) refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	form := url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"resource":      {a.resource},
+	}
+	tok, err := a.tokenRequest(ctx, *a.creds, form)
+	if err != nil {
+		return nil, err
+	}
+	if tok.RefreshToken == "" {
+		// Servers that do not rotate refresh tokens keep the old one valid.
+		tok.RefreshToken = refreshToken
+	}
+	return tok, nil
+}
+
+func (a *Authorizer) scope(prm *ResourceMetadata) string {
+	switch {
+	case len(a.cfg.Scopes) > 0:
+		return strings.Join(a.cfg.Scopes, " ")
+	case a.challengeScope != "":
+		return a.challengeScope
+	default:
+		return strings.Join(prm.ScopesSupported, " ")
+	}
+}
+
+// discoverResource fetches the protected resource metadata, first from the
+// URL of the last challenge and then from the well-known locations. Servers
+// without metadata are treated as their own authorization server.
+func (a *Authorizer) discoverResource(ctx context.Context) (*ResourceMetadata, error) {
+	u, err := url.Parse(a.resource)
+	if err != nil {
+		return nil, err
+	}
+	origin := u.Scheme + "://" + u.Host
+	var candidates []string
+	if a.resourceMetadata != "" {
+		candidates = append(candidates, a.resourceMetadata)
+	}
+	if path := strings.TrimSuffix(u.EscapedPath(), "/"); path != "" {
+		candidates = append(candidates, origin+"/.well-known/oauth-protected-resource"+path)
+	}
+	candidates = append(candidates, origin+"/.well-known/oauth-protected-resource")
+
+	for _, candidate := range candidates {
+		var prm ResourceMetadata
+		found, err := a.getJSON(ctx, candidate, &prm)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		if len(prm.AuthorizationServers) == 0 {
+			return nil, errNoAuthorizationServer
+		}
+		return &prm, nil
+	}
+	return &ResourceMetadata{Resource: a.resource, AuthorizationServers: []string{origin}}, nil
+}
+
+// discoverServer fetches authorization server metadata from the RFC 8414 and
+// OpenID Connect well-known locations for issuer.
+func (a *Authorizer) discoverServer(ctx context.Context, issuer string) (*ServerMetadata, error) {
+	u, err := url.Parse(issuer)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: parse issuer: %w", err)
+	}
+	origin := u.Scheme + "://" + u.Host
+	path := strings.TrimSuffix(u.EscapedPath(), "/")
+	candidates := []string{
+		origin + "/.well-known/oauth-authorization-server" + path,
+		origin + "/.well-known/openid-configuration" + path,
+	}
+	if path != "" {
+		candidates = append(candidates, origin+path+"/.well-known/openid-configuration")
+	}
+	for _, candidate := range candidates {
+		var meta ServerMetadata
+		found, err := a.getJSON(ctx, candidate, &meta)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			continue
+		}
+		if meta.AuthorizationEndpoint == "" || meta.TokenEndpoint == "" {
+			return nil, fmt.Errorf("oauth: metadata at %s lacks authorization or token endpoint", candidate)
+		}
+		return &meta, nil
+	}
+	return nil, fmt.Errorf("oauth: no authorization server metadata found for %s", issuer)
+}
+
+// getJSON decodes a metadata document. found is false for 4xx answers so the
+// next well-known location can be tried.
+func (a *Authorizer) getJSON(ctx context.Context, target string, out any) (found bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return false, fmt.Errorf("oauth: build request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := a.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("oauth: fetch %s: %w", target, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 && resp.StatusCode < 500 {
+		return false, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("oauth: fetch %s: status %d", target, resp.StatusCode)
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxMetadataBytes)).Decode(out); err != nil {
+		return false, fmt.Errorf("oauth: decode %s: %w", target, err)
+	}
+	return true, nil
+}
+
+// register performs dynamic client registration (RFC 7591) for a public
+// client using the loopback redirect.
+func (a *Authorizer) register(ctx context.Context, endpoint, redirectURI string) (Credentials, error) {
+	body, err := json.Marshal(map[string]any{
+		"client_name":                a.cfg.ClientName,
+		"redirect_uris":              []string{redirectURI},
+		"grant_types":                []string{"authorization_code", "refresh_token"},
+		"response_types":             []string{"code"},
+		"token_endpoint_auth_method": "none",
+	})
+	if err != nil {
+		return Credentials{}, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("oauth: build registration request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	resp, err := a.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return Credentials{}, fmt.Errorf("oauth: register client: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataBytes))
+	if err != nil {
+		return Credentials{}, fmt.Errorf("oauth: read registration response: %w", err)
+	}
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		return Credentials{}, fmt.Errorf("oauth: register client: %w", oauthError(resp.StatusCode, data))
+	}
+	var reg struct {
+		ClientID     string `json:"client_id"`
+		ClientSecret string `json:"client_secret"`
+	}
+	if err := json.Unmarshal(data, &reg); err != nil {
+		return Credentials{}, fmt.Errorf("oauth: decode registration response: %w", err)
+	}
+	if reg.ClientID == "" {
+		return Credentials{}, errors.New("oauth: registration response has no client_id")
+	}
+	return Credentials{ClientID: reg.ClientID, ClientSecret: reg.ClientSecret, Registered: true}, nil
+}
+
+func (a *Authorizer) tokenRequest(ctx context.Context, creds Credentials, form url.Values) (*Token, error) {
+	if creds.ClientSecret == "" {
+		form.Set("client_id", creds.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, creds.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("oauth: build token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if creds.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(creds.ClientID), url.QueryEscape(creds.ClientSecret))
+	}
+	resp, err := a.cfg.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("oauth: token request: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxMetadataBytes))
+	if err != nil {
+		return nil, fmt.Errorf("oauth: read token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, oauthError(resp.StatusCode, data)
+	}
+	var raw struct {
+		AccessToken  string          `json:"access_token"`
+		TokenType    string          `json:"token_type"`
+		RefreshToken string          `json:"refresh_token"`
+		ExpiresIn    json.RawMessage `json:"expires_in"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("oauth: decode token response: %w", err)
+	}
+	if raw.AccessToken == "" {
+		return nil, errors.New("oauth: token response has no access_token")
+	}
+	tok := &Token{AccessToken: raw.AccessToken, TokenType: raw.TokenType, RefreshToken: raw.RefreshToken}
+	// Some servers send expires_in as a string.
+	if seconds, err := strconv.ParseInt(strings.Trim(string(raw.ExpiresIn), `"`), 10, 64); err == nil && seconds > 0 {
+		tok.Expiry = a.now().Add(time.Duration(seconds) * time.Second)
+	}
+	return tok, nil
+}
+
+func oauthError(status int, body []byte) error {
+	var oerr Error
+	if err := json.Unmarshal(body, &oerr); err == nil && oerr.Code != "" {
+		return &oerr
+	}
+	return fmt.Errorf("oauth: status %d", status)
+}
+
+// listen opens the loopback redirect listener. A dynamically registered
+// client reuses its registered port when it is free.
+func (a *Authorizer) listen() (net.Listener, string, error) {
+	port := a.cfg.RedirectPort
+	if port == 0 && a.creds.Registered && a.creds.RedirectURI != "" {
+		if u, err := url.Parse(a.creds.RedirectURI); err == nil {
+			if p, err := strconv.Atoi(u.Port()); err == nil {
+				if l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(p))); err == nil {
+					return l, a.creds.RedirectURI, nil
+				}
+			}
+		}
+	}
+	l, err := net.Listen("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+	if err != nil {
+		return nil, "", fmt.Errorf("oauth: open loopback listener: %w", err)
+	}
+	return l, fmt.Sprintf("http://127.0.0.1:%d/callback", l.Addr().(*net.TCPAddr).Port), nil
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+func callbackHandler(state string, out chan<- callbackResult) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		var res callbackResult
+		switch {
+		case q.Get("state") != state:
+			http.Error(w, "state mismatch", http.StatusBadRequest)
+			return
+		case q.Get("error") != "":
+			res.err = &Error{Code: q.Get("error"), Description: q.Get("error_description")}
+		case q.Get("code") == "":
+			res.err = errors.New("oauth: authorization response has no code")
+		default:
+			res.code = q.Get("code")
+		}
+		select {
+		case out <- res:
+		default:
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		if res.err != nil {
+			fmt.Fprintf(w, "Authorization failed: %v\n", res.err)
+			return
+		}
+		fmt.Fprintln(w, "Authorization complete. You can close this window.")
+	})
+	return mux
+}
+
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomString(n int) string {
+	buf := make([]byte, n)
+	_, _ = rand.Read(buf) // never fails since Go 1.24
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,331 @@
+// Package oauth implements the MCP authorization flow for HTTP and SSE
+// servers: protected resource metadata discovery, dynamic client
+// registration, an OAuth 2.1 authorization code flow with PKCE and a loopback
+// redirect, token refresh and encrypted token storage.
+package oauth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// expiryDelta renews tokens slightly before they expire so in-flight
+// requests do not race the deadline.
+const expiryDelta = 30 * time.Second
+
+// Token is an OAuth access token with its optional refresh token.
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	TokenType    string    `json:"token_type,omitempty"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+func (t *Token) valid(now time.Time) bool {
+	if t == nil || t.AccessToken == "" {
+		return false
+	}
+	return t.Expiry.IsZero() || now.Add(expiryDelta).Before(t.Expiry)
+}
+
+// Credentials is what a Store keeps per MCP server: the OAuth client used for
+// it and the latest token.
+type Credentials struct {
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	// RedirectURI is the loopback URI the client was registered with.
+	RedirectURI string `json:"redirect_uri,omitempty"`
+	// Registered marks clients created through dynamic client registration.
+	Registered    bool   `json:"registered,omitempty"`
+	TokenEndpoint string `json:"token_endpoint,omitempty"`
+	Token         *Token `json:"token,omitempty"`
+}
+
+// Store persists credentials keyed by the MCP server URL. Load returns nil
+// without error when nothing is stored.
+type Store interface {
+	Load(resource string) (*Credentials, error)
+	Save(resource string, creds *Credentials) error
+}
+
+// Config describes how to authorize against one MCP server.
+type Config struct {
+	// ServerURL is the MCP endpoint; it is also the OAuth resource indicator.
+	ServerURL string
+	// ClientID and ClientSecret select a pre-registered client. When ClientID
+	// is empty the client is registered dynamically.
+	ClientID     string
+	ClientSecret string
+	// ClientName is sent during dynamic registration.
+	ClientName string
+	// Scopes requested; defaults to the scope of the server's challenge or
+	// the scopes its metadata advertises.
+	Scopes []string
+	// RedirectPort fixes the loopback redirect port; 0 picks a free port.
+	RedirectPort int
+	// Store persists credentials between runs; nil keeps them in memory.
+	Store Store
+	// HTTPClient is used for discovery, registration and token requests.
+	HTTPClient *http.Client
+	// Authorize sends the user to authURL, typically by opening a browser.
+	// The flow completes when the authorization server redirects back to
+	// the loopback listener. Defaults to OpenBrowser.
+	Authorize func(ctx context.Context, authURL string) error
+}
+
+// Authorizer obtains, refreshes and stores tokens for one MCP server.
+type Authorizer struct {
+	cfg      Config
+	resource string
+	now      func() time.Time
+
+	mu     sync.Mutex
+	creds  *Credentials
+	loaded bool
+	// resourceMetadata and challengeScope come from the last
+	// WWW-Authenticate challenge of the server.
+	resourceMetadata string
+	challengeScope   string
+}
+
+// NewAuthorizer validates cfg and returns an Authorizer for cfg.ServerURL.
+func NewAuthorizer(cfg Config) (*Authorizer, error) {
+	resource, err := canonicalResource(cfg.ServerURL)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.HTTPClient == nil {
+		cfg.HTTPClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	if cfg.Authorize == nil {
+		cfg.Authorize = OpenBrowser
+	}
+	if cfg.ClientName == "" {
+		cfg.ClientName = "agentsdk-go"
+	}
+	return &Authorizer{cfg: cfg, resource: resource, now: time.Now}, nil
+}
+
+// canonicalResource normalises an MCP server URL into the resource
+// indicator form of RFC 8707: lowercase scheme and host, no fragment.
+func canonicalResource(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("oauth: parse server url: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" || u.Host == "" {
+		return "", fmt.Errorf("oauth: server url %q must be an absolute http(s) url", raw)
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	u.Fragment = ""
+	u.RawFragment = ""
+	return u.String(), nil
+}
+
+// Token returns a valid access token, refreshing it or running the
+// interactive authorization flow when needed.
+func (a *Authorizer) Token(ctx context.Context) (*Token, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.tokenLocked(ctx, false)
+}
+
+// renew replaces stale after the server rejected it. Concurrent callers that
+// saw the same stale token share one renewal.
+func (a *Authorizer) renew(ctx context.Context, stale *Token) (*Token, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if current := a.currentTokenLocked(); current != nil && stale != nil && current.AccessToken != stale.AccessToken && current.valid(a.now()) {
+		return current, nil
+	}
+	return a.tokenLocked(ctx, true)
+}
+
+func (a *Authorizer) currentTokenLocked() *Token {
+	if a.creds == nil {
+		return nil
+	}
+	return a.creds.Token
+}
+
+func (a *Authorizer) tokenLocked(ctx context.Context, force bool) (*Token, error) {
+	if err := a.loadLocked(); err != nil {
+		return nil, err
+	}
+	if tok := a.creds.Token; !force && tok.valid(a.now()) {
+		return tok, nil
+	}
+	if tok := a.creds.Token; tok != nil && tok.RefreshToken != "" && a.creds.TokenEndpoint != "" && a.creds.ClientID != "" {
+		refreshed, err := a.refresh(ctx, tok.RefreshToken)
+		if err == nil {
+			a.creds.Token = refreshed
+			return refreshed, a.saveLocked()
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		// The refresh token was rejected; start over interactively.
+	}
+	tok, err := a.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return tok, a.saveLocked()
+}
+
+func (a *Authorizer) loadLocked() error {
+	if a.loaded {
+		return nil
+	}
+	a.creds = &Credentials{}
+	if a.cfg.Store != nil {
+		creds, err := a.cfg.Store.Load(a.resource)
+		if err != nil {
+			return fmt.Errorf("oauth: load credentials: %w", err)
+		}
+		if creds != nil {
+			a.creds = creds
+		}
+	}
+	// A configured client replaces whatever was registered before.
+	if a.cfg.ClientID != "" && a.creds.ClientID != a.cfg.ClientID {
+		a.creds = &Credentials{ClientID: a.cfg.ClientID, ClientSecret: a.cfg.ClientSecret}
+	}
+	a.loaded = true
+	return nil
+}
+
+func (a *Authorizer) saveLocked() error {
+	if a.cfg.Store == nil {
+		return nil
+	}
+	if err := a.cfg.Store.Save(a.resource, a.creds); err != nil {
+		return fmt.Errorf("oauth: save credentials: %w", err)
+	}
+	return nil
+}
+
+// observeChallenge records the resource metadata URL and scope of a
+// WWW-Authenticate Bearer challenge.
+func (a *Authorizer) observeChallenge(header string) {
+	params := parseBearerChallenge(header)
+	if len(params) == 0 {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if v := params["resource_metadata"]; v != "" {
+		a.resourceMetadata = v
+	}
+	if v := params["scope"]; v != "" {
+		a.challengeScope = v
+	}
+}
+
+// parseBearerChallenge extracts the auth-params of a Bearer challenge.
+func parseBearerChallenge(header string) map[string]string {
+	header = strings.TrimSpace(header)
+	if len(header) < len("Bearer") || !strings.EqualFold(header[:len("Bearer")], "Bearer") {
+		return nil
+	}
+	rest := header[len("Bearer"):]
+	out := map[string]string{}
+	for rest != "" {
+		rest = strings.TrimLeft(rest, " ,")
+		eq := strings.IndexByte(rest, '=')
+		if eq <= 0 {
+			break
+		}
+		key := strings.ToLower(strings.TrimSpace(rest[:eq]))
+		rest = strings.TrimLeft(rest[eq+1:], " ")
+		var value string
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.IndexByte(rest[1:], '"')
+			if end < 0 {
+				value, rest = rest[1:], ""
+			} else {
+				value, rest = rest[1:end+1], rest[end+2:]
+			}
+		} else {
+			end := strings.IndexByte(rest, ',')
+			if end < 0 {
+				end = len(rest)
+			}
+			value, rest = strings.TrimSpace(rest[:end]), rest[end:]
+		}
+		out[key] = value
+	}
+	return out
+}
+
+// Transport returns a RoundTripper that authorizes requests with bearer
+// tokens from a. A 401 answer triggers one renewal and retry when the request
+// body can be replayed.
+func (a *Authorizer) Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{auth: a, base: base}
+}
+
+type transport struct {
+	auth *Authorizer
+	base http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	tok, err := t.auth.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(withBearer(req, tok))
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return resp, nil
+	}
+	t.auth.observeChallenge(resp.Header.Get("WWW-Authenticate"))
+	renewed, renewErr := t.auth.renew(req.Context(), tok)
+	if renewErr != nil {
+		return resp, nil
+	}
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return resp, nil
+		}
+		retry.Body = body
+	}
+	resp.Body.Close()
+	return t.base.RoundTrip(withBearer(retry, renewed))
+}
+
+func withBearer(req *http.Request, tok *Token) *http.Request {
+	out := req.Clone(req.Context())
+	out.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+	return out
+}
+
+// Error is an OAuth error response from the authorization server.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+func (e *Error) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("oauth: %s: %s", e.Code, e.Description)
+	}
+	return "oauth: " + e.Code
+}
+
+var errNoAuthorizationServer = errors.New("oauth: protected resource metadata lists no authorization server")
@@ -0,0 +1,354 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeAuthServer is an MCP resource and authorization server in one.
+type fakeAuthServer struct {
+	*httptest.Server
+	t         *testing.T
+	expiresIn int
+	noPKCE    bool
+
+	mu            sync.Mutex
+	registrations int
+	refreshes     int
+	challenge     string
+	redirectURI   string
+	issued        int
+	valid         map[string]bool
+	refreshTokens map[string]bool
+}
+
+func newFakeAuthServer(t *testing.T) *fakeAuthServer {
+	f := &fakeAuthServer{t: t, expiresIn: 3600, valid: map[string]bool{}, refreshTokens: map[string]bool{}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/oauth-protected-resource/mcp", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"resource": f.URL + "/mcp", "authorization_servers": []string{f.URL}, "scopes_supported": []string{"mcp"}})
+	})
+	mux.HandleFunc("/.well-known/oauth-authorization-server", func(w http.ResponseWriter, r *http.Request) {
+		methods := []string{"S256"}
+		if f.noPKCE {
+			methods = nil
+		}
+		writeJSON(w, map[string]any{
+			"issuer":                           f.URL,
+			"authorization_endpoint":           f.URL + "/authorize",
+			"token_endpoint":                   f.URL + "/token",
+			"registration_endpoint":            f.URL + "/register",
+			"code_challenge_methods_supported": methods,
+		})
+	})
+	mux.HandleFunc("/register", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			RedirectURIs []string `json:"redirect_uris"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.mu.Lock()
+		f.registrations++
+		id := fmt.Sprintf("client-%d", f.registrations)
+		f.mu.Unlock()
+		w.WriteHeader(http.StatusCreated)
+		writeJSON(w, map[string]any{"client_id": id, "redirect_uris": req.RedirectURIs})
+	})
+	mux.HandleFunc("/authorize", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("code_challenge_method") != "S256" || q.Get("resource") != f.URL+"/mcp" || q.Get("scope") != "mcp" {
+			http.Error(w, "bad authorization request "+q.Encode(), http.StatusBadRequest)
+			return
+		}
+		f.mu.Lock()
+		f.challenge, f.redirectURI = q.Get("code_challenge"), q.Get("redirect_uri")
+		f.mu.Unlock()
+		http.Redirect(w, r, q.Get("redirect_uri")+"?code=code-1&state="+url.QueryEscape(q.Get("state")), http.StatusFound)
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		switch r.Form.Get("grant_type") {
+		case "authorization_code":
+			if r.Form.Get("code") != "code-1" || pkceChallenge(r.Form.Get("code_verifier")) != f.challenge || r.Form.Get("redirect_uri") != f.redirectURI {
+				w.WriteHeader(http.StatusBadRequest)
+				writeJSON(w, map[string]any{"error": "invalid_grant"})
+				return
+			}
+		case "refresh_token":
+			if !f.refreshTokens[r.Form.Get("refresh_token")] {
+				w.WriteHeader(http.StatusBadRequest)
+				writeJSON(w, map[string]any{"error": "invalid_grant"})
+				return
+			}
+			f.refreshes++
+		}
+		f.issued++
+		access, refresh := fmt.Sprintf("access-%d", f.issued), fmt.Sprintf("refresh-%d", f.issued)
+		f.valid[access], f.refreshTokens[refresh] = true, true
+		writeJSON(w, map[string]any{"access_token": access, "token_type": "Bearer", "refresh_token": refresh, "expires_in": f.expiresIn})
+	})
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		ok := f.valid[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]
+		f.mu.Unlock()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer resource_metadata="%s/.well-known/oauth-protected-resource/mcp"`, f.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = io.WriteString(w, "hello")
+	})
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+func (f *fakeAuthServer) revokeAll() {
+	f.mu.Lock()
+	f.valid = map[string]bool{}
+	f.mu.Unlock()
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// browser follows the authorization redirect to the loopback listener like a
+// user agent would.
+type browser struct {
+	mu     sync.Mutex
+	visits int
+}
+
+func (b *browser) open(ctx context.Context, authURL string) error {
+	b.mu.Lock()
+	b.visits++
+	b.mu.Unlock()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, authURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("authorize: %d %s", resp.StatusCode, body)
+	}
+	return nil
+}
+
+func get(t *testing.T, client *http.Client, target string) string {
+	t.Helper()
+	resp, err := client.Get(target)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get: status %d %s", resp.StatusCode, body)
+	}
+	return string(body)
+}
+
+func TestAuthorizerFlowAndStore(t *testing.T) {
+	srv := newFakeAuthServer(t)
+	base := t.TempDir()
+	store := NewKeyFileStore(filepath.Join(base, "creds"), filepath.Join(base, "creds.key"))
+	b := &browser{}
+	auth, err := NewAuthorizer(Config{ServerURL: srv.URL + "/mcp", Store: store, Authorize: b.open})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &http.Client{Transport: auth.Transport(nil)}
+	if got := get(t, client, srv.URL+"/mcp"); got != "hello" {
+		t.Fatalf("unexpected body %q", got)
+	}
+	if b.visits != 1 || srv.registrations != 1 {
+		t.Fatalf("expected one browser visit and registration, got %d/%d", b.visits, srv.registrations)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(store.dir, "*.json.enc"))
+	if entries, _ := os.ReadDir(store.dir); len(files) != 1 || len(entries) != 1 {
+		t.Fatalf("expected only one credentials file and no key in the directory, got %v", entries)
+	}
+	raw, _ := os.ReadFile(files[0])
+	if strings.Contains(string(raw), "access-") || strings.Contains(string(raw), "client-1") {
+		t.Fatal("credentials must be stored encrypted")
+	}
+
+	// A new process reuses the stored token without user interaction.
+	again, _ := NewAuthorizer(Config{ServerURL: srv.URL + "/mcp", Store: NewKeyFileStore(store.dir, store.keyPath), Authorize: b.open})
+	if got := get(t, &http.Client{Transport: again.Transport(nil)}, srv.URL+"/mcp"); got != "hello" || b.visits != 1 {
+		t.Fatalf("stored token not reused: %q, %d visits", got, b.visits)
+	}
+}
+
+func TestAuthorizerRefreshesExpiredTokens(t *testing.T) {
+	srv := newFakeAuthServer(t)
+	srv.expiresIn = 1 // inside expiryDelta, so every token is due for refresh
+	b := &browser{}
+	auth, _ := NewAuthorizer(Config{ServerURL: srv.URL + "/mcp", Authorize: b.open})
+	first, err := auth.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := auth.Token(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second.AccessToken == first.AccessToken || srv.refreshes != 1 || b.visits != 1 {
+		t.Fatalf("expected a silent refresh: %s -> %s, refreshes=%d visits=%d", first.AccessToken, second.AccessToken, srv.refreshes, b.visits)
+	}
+}
+
+func TestTransportRenewsRejectedToken(t *testing.T) {
+	srv := newFakeAuthServer(t)
+	b := &browser{}
+	auth, _ := NewAuthorizer(Config{ServerURL: srv.URL + "/mcp", Authorize: b.open})
+	client := &http.Client{Transport: auth.Transport(nil)}
+	get(t, client, srv.URL+"/mcp")
+
+	srv.revokeAll()
+	resp, err := client.Post(srv.URL+"/mcp", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || srv.refreshes != 1 || b.visits != 1 {
+		t.Fatalf("expected refresh and retry: status=%d refreshes=%d visits=%d", resp.StatusCode, srv.refreshes, b.visits)
+	}
+}
+
+func TestAuthorizerRequiresPKCE(t *testing.T) {
+	srv := newFakeAuthServer(t)
+	srv.noPKCE = true
+	auth, _ := NewAuthorizer(Config{ServerURL: srv.URL + "/mcp", Authorize: (&browser{}).open})
+	if _, err := auth.Token(context.Background()); err == nil || !strings.Contains(err.Error(), "S256") {
+		t.Fatalf("expected PKCE error, got %v", err)
+	}
+}
+
+func TestAuthorizerUsesConfiguredClient(t *testing.T) {
+	srv := newFakeAuthServer(t)
+	auth, _ := NewAuthorizer(Config{ServerURL: srv.URL + "/mcp", ClientID: "static", Authorize: (&browser{}).open})
+	if _, err := auth.Token(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if srv.registrations != 0 {
+		t.Fatal("configured clients must not be registered dynamically")
+	}
+}
+
+func TestNewAuthorizerRejectsBadURL(t *testing.T) {
+	if _, err := NewAuthorizer(Config{ServerURL: "stdio://server"}); err == nil {
+		t.Fatal("non-http servers cannot use OAuth")
+	}
+}
+
+func TestParseBearerChallenge(t *testing.T) {
+	params := parseBearerChallenge(`Bearer realm="mcp", resource_metadata="https://x/.well-known/oauth-protected-resource", scope=files`)
+	if params["resource_metadata"] != "https://x/.well-known/oauth-protected-resource" || params["scope"] != "files" || params["realm"] != "mcp" {
+		t.Fatalf("unexpected params %v", params)
+	}
+	if parseBearerChallenge(`Basic realm="x"`) != nil {
+		t.Fatal("non-bearer challenges are ignored")
+	}
+}
+
+func TestFileStoreMissingAndTampered(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir, []byte("secret"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds, err := store.Load("https://a"); creds != nil || err != nil {
+		t.Fatalf("missing credentials should load as nil: %v %v", creds, err)
+	}
+	if err := store.Save("https://a", &Credentials{ClientID: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("a derived key must not be written: %v", entries)
+	}
+	if creds, err := store.Load("https://a"); err != nil || creds == nil || creds.ClientID != "c" {
+		t.Fatalf("unexpected credentials %+v %v", creds, err)
+	}
+	other, _ := NewFileStore(dir, []byte("other"))
+	if _, err := other.Load("https://a"); err == nil {
+		t.Fatal("a different secret must not decrypt the credentials")
+	}
+	if err := os.Rename(store.path("https://a"), store.path("https://b")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Load("https://b"); err == nil {
+		t.Fatal("credentials are bound to their server")
+	}
+	if _, err := NewFileStore(dir, nil); err == nil {
+		t.Fatal("expected an empty secret to be rejected")
+	}
+}
+
+func TestKeyFileStoreCreatesKeyOnce(t *testing.T) {
+	base := t.TempDir()
+	keyPath := filepath.Join(base, "creds.key")
+	var wg sync.WaitGroup
+	keys := make([][]byte, 8)
+	errs := make([]error, len(keys))
+	for i := range keys {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			keys[i], errs[i] = NewKeyFileStore(filepath.Join(base, "creds"), keyPath).key(true)
+		}()
+	}
+	wg.Wait()
+	for i := range keys {
+		if errs[i] != nil || !bytes.Equal(keys[i], keys[0]) {
+			t.Fatalf("concurrent creators must agree on one key: %v", errs[i])
+		}
+	}
+	info, err := os.Stat(keyPath)
+	if err != nil || info.Size() != 32 {
+		t.Fatalf("unexpected key file %v %v", info, err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0o600 {
+		t.Fatalf("key file must be owner-only, got %v", info.Mode())
+	}
+	if leftovers, _ := filepath.Glob(filepath.Join(base, ".creds-*")); len(leftovers) != 0 {
+		t.Fatalf("temp files left behind: %v", leftovers)
+	}
+}
+
+func TestMemoryStoreCopiesCredentials(t *testing.T) {
+	store := NewMemoryStore()
+	creds := &Credentials{ClientID: "c", Token: &Token{AccessToken: "a"}}
+	if err := store.Save("https://a", creds); err != nil {
+		t.Fatal(err)
+	}
+	creds.Token.AccessToken = "changed"
+	loaded, err := store.Load("https://a")
+	if err != nil || loaded == nil || loaded.Token.AccessToken != "a" {
+		t.Fatalf("unexpected credentials %+v %v", loaded, err)
+	}
+	if missing, err := store.Load("https://b"); missing != nil || err != nil {
+		t.Fatalf("missing credentials should load as nil: %v %v", missing, err)
+	}
+}
@@ -0,0 +1,238 @@
+package oauth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// SecretEnv names the environment variable DefaultStore derives its key from.
+const SecretEnv = "AGENTSDK_MCP_OAUTH_SECRET"
+
+// FileStore keeps credentials as AES-256-GCM encrypted files in a directory,
+// one per server. The key is derived from a secret supplied by the caller or
+// kept in a key file outside the directory, so copying the credentials alone
+// does not expose them.
+type FileStore struct {
+	dir     string
+	secret  []byte
+	keyPath string
+}
+
+// NewFileStore stores credentials under dir, encrypted with a key derived from
+// secret. The secret itself is never written to disk.
+func NewFileStore(dir string, secret []byte) (*FileStore, error) {
+	if len(secret) == 0 {
+		return nil, errors.New("oauth: credentials secret is empty")
+	}
+	return &FileStore{dir: dir, secret: append([]byte(nil), secret...)}, nil
+}
+
+// NewKeyFileStore stores credentials under dir, encrypted with a random key
+// that is created in keyPath with owner-only permissions on first use.
+// keyPath should not be inside dir.
+func NewKeyFileStore(dir, keyPath string) *FileStore {
+	return &FileStore{dir: dir, keyPath: keyPath}
+}
+
+// DefaultStore stores credentials under the user config directory, e.g.
+// ~/.config/agentsdk-go/mcp-oauth on Linux. The key is derived from
+// $AGENTSDK_MCP_OAUTH_SECRET when it is set and otherwise kept in
+// agentsdk-go/mcp-oauth.key next to that directory.
+func DefaultStore() (*FileStore, error) {
+	base, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("oauth: locate user config dir: %w", err)
+	}
+	dir := filepath.Join(base, "agentsdk-go", "mcp-oauth")
+	if secret := os.Getenv(SecretEnv); secret != "" {
+		return NewFileStore(dir, []byte(secret))
+	}
+	return NewKeyFileStore(dir, dir+".key"), nil
+}
+
+// Load implements Store.
+func (s *FileStore) Load(resource string) (*Credentials, error) {
+	data, err := os.ReadFile(s.path(resource))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, err := s.key(false)
+	if err != nil || key == nil {
+		// Without the key the file cannot be read; treat it as absent.
+		return nil, err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < gcm.NonceSize() {
+		return nil, errors.New("credentials file is truncated")
+	}
+	plain, err := gcm.Open(nil, data[:gcm.NonceSize()], data[gcm.NonceSize():], []byte(resource))
+	if err != nil {
+		return nil, fmt.Errorf("decrypt credentials: %w", err)
+	}
+	var creds Credentials
+	if err := json.Unmarshal(plain, &creds); err != nil {
+		return nil, fmt.Errorf("decode credentials: %w", err)
+	}
+	return &creds, nil
+}
+
+// Save implements Store.
+func (s *FileStore) Save(resource string, creds *Credentials) error {
+	if err := os.MkdirAll(s.dir, 0o700); err != nil {
+		return err
+	}
+	key, err := s.key(true)
+	if err != nil {
+		return err
+	}
+	plain, err := json.Marshal(creds)
+	if err != nil {
+		return err
+	}
+	gcm, err := newGCM(key)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	_, _ = rand.Read(nonce)
+	return writeFileAtomic(s.path(resource), gcm.Seal(nonce, nonce, plain, []byte(resource)))
+}
+
+func (s *FileStore) path(resource string) string {
+	sum := sha256.Sum256([]byte(resource))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:16])+".json.enc")
+}
+
+// key returns the store key. A missing key file is created when create is
+// set and reported as a nil key otherwise.
+func (s *FileStore) key(create bool) ([]byte, error) {
+	if s.secret != nil {
+		return hkdf.Key(sha256.New, s.secret, nil, "agentsdk-go mcp-oauth credentials", 32)
+	}
+	key, err := os.ReadFile(s.keyPath)
+	switch {
+	case err == nil:
+		if len(key) != 32 {
+			return nil, errors.New("credentials key has the wrong size")
+		}
+		return key, nil
+	case !errors.Is(err, fs.ErrNotExist):
+		return nil, err
+	case !create:
+		return nil, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.keyPath), 0o700); err != nil {
+		return nil, err
+	}
+	key = make([]byte, 32)
+	_, _ = rand.Read(key)
+	tmp, err := writeTemp(filepath.Dir(s.keyPath), key)
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(tmp)
+	// Link publishes the complete file and fails if another process got
+	// there first, in which case its key wins.
+	if err := os.Link(tmp, s.keyPath); err != nil {
+		if errors.Is(err, fs.ErrExist) {
+			return s.key(false)
+		}
+		return nil, err
+	}
+	return key, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := writeTemp(filepath.Dir(path), data)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	return os.Rename(tmp, path)
+}
+
+// writeTemp writes data to a new owner-only file in dir and returns its name.
+func writeTemp(dir string, data []byte) (string, error) {
+	tmp, err := os.CreateTemp(dir, ".creds-*")
+	if err != nil {
+		return "", err
+	}
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
+
+// MemoryStore keeps credentials for the lifetime of the process.
+type MemoryStore struct {
+	mu    sync.Mutex
+	creds map[string]Credentials
+}
+
+// NewMemoryStore returns an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{creds: map[string]Credentials{}}
+}
+
+// Load implements Store.
+func (s *MemoryStore) Load(resource string) (*Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	creds, ok := s.creds[resource]
+	if !ok {
+		return nil, nil
+	}
+	return cloneCredentials(creds), nil
+}
+
+// Save implements Store.
+func (s *MemoryStore) Save(resource string, creds *Credentials) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.creds[resource] = *cloneCredentials(*creds)
+	return nil
+}
+
+func cloneCredentials(creds Credentials) *Credentials {
+	if creds.Token != nil {
+		tok := *creds.Token
+		creds.Token = &tok
+	}
+	return &creds
+}
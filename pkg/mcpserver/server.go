@@ -0,0 +1,347 @@
+// Package mcpserver publishes an api.Runtime over the Model Context Protocol
+// so other MCP clients can call its tools, run the agent and use its skills.
+package mcpserver
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/api"
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/skills"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+	toolbuiltin "github.com/stellarlinkco/agentsdk-go/pkg/tool/builtin"
+)
+
+const (
+	// RunAgentToolName is the tool that runs a full agent turn.
+	RunAgentToolName = "run_agent"
+
+	defaultServerName    = "agentsdk-go"
+	defaultServerVersion = "dev"
+	defaultSessionID     = "mcp"
+	skillInputArgument   = "input"
+)
+
+var errNilRuntime = errors.New("mcpserver: runtime is nil")
+
+type config struct {
+	impl  mcpsdk.Implementation
+	tools map[string]struct{}
+}
+
+// Option customises the published server.
+type Option func(*config)
+
+// WithImplementation sets the server name and version reported to clients.
+func WithImplementation(name, version string) Option {
+	return func(cfg *config) {
+		if name = strings.TrimSpace(name); name != "" {
+			cfg.impl.Name = name
+		}
+		if version = strings.TrimSpace(version); version != "" {
+			cfg.impl.Version = version
+		}
+	}
+}
+
+// WithTools limits the published runtime tools to names. run_agent and
+// prompts are always published.
+func WithTools(names ...string) Option {
+	return func(cfg *config) {
+		if cfg.tools == nil {
+			cfg.tools = map[string]struct{}{}
+		}
+		for _, name := range names {
+			if name = strings.ToLower(strings.TrimSpace(name)); name != "" {
+				cfg.tools[name] = struct{}{}
+			}
+		}
+	}
+}
+
+// NewServer builds an MCP server exposing rt's registered tools, a run_agent
+// tool and one prompt per skill.
+func NewServer(rt *api.Runtime, opts ...Option) (*mcpsdk.Server, error) {
+	if rt == nil {
+		return nil, errNilRuntime
+	}
+	cfg := config{impl: mcpsdk.Implementation{Name: defaultServerName, Version: defaultServerVersion}}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+	impl := cfg.impl
+	server := mcpsdk.NewServer(&impl, nil)
+
+	server.AddTool(runAgentTool(), runAgentHandler(rt))
+	for _, impl := range rt.Tools() {
+		if impl == nil {
+			continue
+		}
+		name := impl.Name()
+		canon := strings.ToLower(name)
+		// Clients see every tool up front, so tool_search has nothing to add.
+		if canon == RunAgentToolName || canon == toolbuiltin.ToolSearchName {
+			continue
+		}
+		if cfg.tools != nil {
+			if _, ok := cfg.tools[canon]; !ok {
+				continue
+			}
+		}
+		descriptor, err := toolDescriptor(impl)
+		if err != nil {
+			log.Printf("mcpserver: tool %s not published: %v", name, err)
+			continue
+		}
+		server.AddTool(descriptor, toolHandler(rt, name))
+	}
+	for _, def := range rt.Skills() {
+		server.AddPrompt(skillPrompt(def), skillHandler(rt, def.Name))
+	}
+	return server, nil
+}
+
+// Serve publishes rt over transport, for example &mcp.StdioTransport{}, and
+// blocks until the client disconnects or ctx is cancelled.
+func Serve(ctx context.Context, rt *api.Runtime, transport mcpsdk.Transport, opts ...Option) error {
+	if transport == nil {
+		return errors.New("mcpserver: transport is nil")
+	}
+	server, err := NewServer(rt, opts...)
+	if err != nil {
+		return err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return server.Run(ctx, transport)
+}
+
+// NewHTTPHandler publishes rt over the streamable HTTP transport. Mount the
+// handler on any path; each client gets its own MCP session.
+//
+// The handler does no authentication, and every published tool (including
+// bash, write or git when the runtime registers them) runs with the
+// runtime's permissions. Wrap it in authentication middleware, or limit the
+// tools with WithTools, before exposing it beyond a trusted network.
+func NewHTTPHandler(rt *api.Runtime, opts ...Option) (http.Handler, error) {
+	server, err := NewServer(rt, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return mcpsdk.NewStreamableHTTPHandler(func(*http.Request) *mcpsdk.Server { return server }, nil), nil
+}
+
+func runAgentTool() *mcpsdk.Tool {
+	return &mcpsdk.Tool{
+		Name:        RunAgentToolName,
+		Description: "Run the agent on a prompt and return its final answer. Reuse session_id to continue a conversation.",
+		InputSchema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"prompt":     map[string]any{"type": "string", "description": "Task or question for the agent."},
+				"session_id": map[string]any{"type": "string", "description": "Conversation to continue within this MCP session; defaults to the MCP session itself."},
+			},
+			"required": []string{"prompt"},
+		},
+	}
+}
+
+func runAgentHandler(rt *api.Runtime) mcpsdk.ToolHandler {
+	return func(ctx context.Context, req *mcpsdk.CallToolRequest) (*mcpsdk.CallToolResult, error) {
+		var args struct {
+			Prompt    string `json:"prompt"`
+			SessionID string `json:"session_id"`
+		}
+		if err := decodeArguments(req, &args); err != nil {
+			return errorResult(err), nil
+		}
+		if strings.TrimSpace(args.Prompt) == "" {
+			return errorResult(errors.New("prompt is required")), nil
+		}
+		// Conversations named by the client live under its MCP session, so
+		// one client cannot continue or read another's history.
+		sessionID := strings.TrimSpace(args.SessionID)
+		runSession := sessionIDFor(req)
+		if sessionID == "" {
+			sessionID = runSession
+		} else {
+			runSession += "/" + sessionID
+		}
+		resp, err := rt.Run(ctx, api.Request{Prompt: args.Prompt, SessionID: runSession})
+		if err != nil {
+			return errorResult(err), nil
+		}
+		structured := map[string]any{"session_id": sessionID}
+		output := ""
+		if resp != nil && resp.Result != nil {
+			output = resp.Result.Output
+			structured["stop_reason"] = resp.Result.StopReason
+		}
+		structured["output"] = output
+		return &mcpsdk.CallToolResult{
+			Content:           []mcpsdk.Content{&mcpsdk.TextContent{Text: output}},
+			StructuredContent: structured,
+		}, nil
+	}
+}
+
+// toolDescriptor maps a runtime tool onto an MCP tool definition. Schemas
+// without a type are published as objects; anything else is rejected because
+// MCP tool inputs must be objects.
+func toolDescriptor(impl tool.Tool) (*mcpsdk.Tool, error) {
+	schema := map[string]any{"type": "object"}
+	if s := impl.Schema(); s != nil {
+		data, err := json.Marshal(s)
+		if err != nil {
+			return nil, fmt.Errorf("marshal schema: %w", err)
+		}
+		if err := json.Unmarshal(data, &schema); err != nil {
+			return nil, fmt.Errorf("marshal schema: %w", err)
+		}
+		switch schema["type"] {
+		case "object":
+		case "", nil:
+			schema["type"] = "object"
+		default:
+			return nil, fmt.Errorf("input schema must be an object, got %v", schema["type"])
+		}
+		if schema["properties"] == nil {
+			delete(schema, "properties")
+		}
+		if schema["required"] == nil {
+			delete(schema, "required")
+		}
+	}
+
+	meta := tool.MetadataOf(impl)
+	policy := tool.PolicyOf(impl)
+	annotations := &mcpsdk.ToolAnnotations{
+		ReadOnlyHint:   meta.IsReadOnly,
+		IdempotentHint: policy.Idempotent || meta.IsReadOnly,
+	}
+	if !meta.IsReadOnly {
+		destructive := meta.IsDestructive
+		annotations.DestructiveHint = &destructive
+	}
+	return &mcpsdk.Tool{
+		Name:        impl.Name(),
+		Description: impl.Description(),
+		InputSchema: schema,
+		Annotations: annotations,
+	}, nil
+}
+
+func toolHandler(rt *api.Runtime, name string) mcpsdk.ToolHandler {
+	return func(ctx context.Context, req *mcpsdk.CallToolRequest) (*mcpsdk.CallToolResult, error) {
+		var params map[string]any
+		if err := decodeArguments(req, &params); err != nil {
+			return errorResult(err), nil
+		}
+		if params == nil {
+			params = map[string]any{}
+		}
+		res, err := rt.ExecuteTool(ctx, sessionIDFor(req), name, params)
+		if res == nil || res.Result == nil {
+			if err == nil {
+				err = fmt.Errorf("tool %s returned no result", name)
+			}
+			return errorResult(err), nil
+		}
+		out := &mcpsdk.CallToolResult{IsError: err != nil || !res.Result.Success}
+		text := res.Result.Output
+		if text == "" && err != nil {
+			text = err.Error()
+		}
+		if text != "" {
+			out.Content = append(out.Content, &mcpsdk.TextContent{Text: text})
+		}
+		for _, block := range res.Result.ContentBlocks {
+			if block.Type != model.ContentBlockImage || block.Data == "" {
+				continue
+			}
+			data, decodeErr := base64.StdEncoding.DecodeString(block.Data)
+			if decodeErr != nil {
+				continue
+			}
+			out.Content = append(out.Content, &mcpsdk.ImageContent{Data: data, MIMEType: block.MediaType})
+		}
+		if len(out.Content) == 0 {
+			out.Content = []mcpsdk.Content{&mcpsdk.TextContent{Text: ""}}
+		}
+		return out, nil
+	}
+}
+
+func skillPrompt(def skills.Definition) *mcpsdk.Prompt {
+	return &mcpsdk.Prompt{
+		Name:        def.Name,
+		Description: def.Description,
+		Arguments: []*mcpsdk.PromptArgument{{
+			Name:        skillInputArgument,
+			Description: "Text the skill should act on.",
+		}},
+	}
+}
+
+func skillHandler(rt *api.Runtime, name string) mcpsdk.PromptHandler {
+	return func(ctx context.Context, req *mcpsdk.GetPromptRequest) (*mcpsdk.GetPromptResult, error) {
+		var input string
+		if req != nil && req.Params != nil {
+			input = req.Params.Arguments[skillInputArgument]
+		}
+		res, err := rt.ExecuteSkill(ctx, name, skills.ActivationContext{Prompt: input})
+		if err != nil {
+			return nil, fmt.Errorf("skill %s: %w", name, err)
+		}
+		text := toolbuiltin.FormatSkillOutput(res)
+		if strings.TrimSpace(input) != "" {
+			text += "\n\n" + input
+		}
+		return &mcpsdk.GetPromptResult{
+			Description: fmt.Sprintf("Skill %s", name),
+			Messages: []*mcpsdk.PromptMessage{{
+				Role:    "user",
+				Content: &mcpsdk.TextContent{Text: text},
+			}},
+		}, nil
+	}
+}
+
+func decodeArguments(req *mcpsdk.CallToolRequest, dst any) error {
+	if req == nil || req.Params == nil || len(req.Params.Arguments) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(req.Params.Arguments, dst); err != nil {
+		return fmt.Errorf("invalid arguments: %w", err)
+	}
+	return nil
+}
+
+// sessionIDFor keys runtime state such as history, todos and shells by the
+// MCP session so concurrent HTTP clients stay isolated.
+func sessionIDFor(req *mcpsdk.CallToolRequest) string {
+	if req != nil && req.Session != nil {
+		if id := strings.TrimSpace(req.Session.ID()); id != "" {
+			return defaultSessionID + "-" + id
+		}
+	}
+	return defaultSessionID
+}
+
+func errorResult(err error) *mcpsdk.CallToolResult {
+	return &mcpsdk.CallToolResult{
+		IsError: true,
+		Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: err.Error()}},
+	}
+}
@@ -0,0 +1,258 @@
+package mcpserver
+
+import (
+	"context"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/api"
+	"github.com/stellarlinkco/agentsdk-go/pkg/middleware"
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/skills"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+type replyModel struct {
+	mu      sync.Mutex
+	prompts []string
+	// history records how many messages each request carried.
+	history []int
+}
+
+func (m *replyModel) Complete(_ context.Context, req model.Request) (*model.Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	last := req.Messages[len(req.Messages)-1]
+	m.prompts = append(m.prompts, last.Content)
+	m.history = append(m.history, len(req.Messages))
+	return &model.Response{Message: model.Message{Role: "assistant", Content: "echo: " + last.Content}, StopReason: "end_turn"}, nil
+}
+
+func (m *replyModel) CompleteStream(ctx context.Context, req model.Request, cb model.StreamHandler) error {
+	resp, err := m.Complete(ctx, req)
+	if err != nil {
+		return err
+	}
+	return cb(model.StreamResult{Final: true, Response: resp})
+}
+
+type upperTool struct{}
+
+func (upperTool) Name() string        { return "upper" }
+func (upperTool) Description() string { return "Upper-case text" }
+func (upperTool) Schema() *tool.JSONSchema {
+	return &tool.JSONSchema{Type: "object", Properties: map[string]interface{}{"text": map[string]interface{}{"type": "string"}}, Required: []string{"text"}}
+}
+func (upperTool) Metadata() tool.Metadata { return tool.Metadata{IsReadOnly: true} }
+func (upperTool) Execute(_ context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	return &tool.ToolResult{Success: true, Output: strings.ToUpper(params["text"].(string))}, nil
+}
+
+// sessionTool reports the session the runtime attached to the call, which
+// builtin tools use to key shells and read-before-write state.
+type sessionTool struct{}
+
+func (sessionTool) Name() string             { return "whoami" }
+func (sessionTool) Description() string      { return "Report the session" }
+func (sessionTool) Schema() *tool.JSONSchema { return &tool.JSONSchema{Type: "object"} }
+func (sessionTool) Execute(ctx context.Context, _ map[string]interface{}) (*tool.ToolResult, error) {
+	st, _ := ctx.Value(model.MiddlewareStateKey).(*middleware.State)
+	if st == nil {
+		return &tool.ToolResult{Success: true}, nil
+	}
+	session, _ := st.Values["session_id"].(string)
+	return &tool.ToolResult{Success: true, Output: session}, nil
+}
+
+func newTestRuntime(t *testing.T) (*api.Runtime, *replyModel) {
+	t.Helper()
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, ".agents"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	mdl := &replyModel{}
+	rt, err := api.New(context.Background(), api.Options{
+		ProjectRoot:         root,
+		Model:               mdl,
+		EnabledBuiltinTools: []string{},
+		CustomTools:         []tool.Tool{upperTool{}, sessionTool{}},
+		Skills: []api.SkillRegistration{{
+			Definition: skills.Definition{Name: "reviewer", Description: "Review code", DisableAutoActivation: true},
+			Handler: skills.HandlerFunc(func(context.Context, skills.ActivationContext) (skills.Result, error) {
+				return skills.Result{Output: "Review carefully."}, nil
+			}),
+		}},
+	})
+	if err != nil {
+		t.Fatalf("runtime: %v", err)
+	}
+	t.Cleanup(func() { _ = rt.Close() })
+	return rt, mdl
+}
+
+func connect(t *testing.T, rt *api.Runtime, opts ...Option) *mcpsdk.ClientSession {
+	t.Helper()
+	serverTransport, clientTransport := mcpsdk.NewInMemoryTransports()
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, rt, serverTransport, opts...) }()
+	client := mcpsdk.NewClient(&mcpsdk.Implementation{Name: "test", Version: "1"}, nil)
+	session, err := client.Connect(context.Background(), clientTransport, nil)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = session.Close()
+		cancel()
+		<-done
+	})
+	return session
+}
+
+func TestServePublishesToolsAndPrompts(t *testing.T) {
+	rt, _ := newTestRuntime(t)
+	session := connect(t, rt)
+	ctx := context.Background()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("list tools: %v", err)
+	}
+	byName := map[string]*mcpsdk.Tool{}
+	for _, tl := range tools.Tools {
+		byName[tl.Name] = tl
+	}
+	if len(byName) != 3 || byName["upper"] == nil || byName[RunAgentToolName] == nil {
+		t.Fatalf("unexpected tools %v", byName)
+	}
+	if !byName["upper"].Annotations.ReadOnlyHint {
+		t.Fatal("read-only metadata should become an annotation")
+	}
+
+	res, err := session.CallTool(ctx, &mcpsdk.CallToolParams{Name: "upper", Arguments: map[string]any{"text": "hi"}})
+	if err != nil || res.IsError || res.Content[0].(*mcpsdk.TextContent).Text != "HI" {
+		t.Fatalf("call upper: %+v %v", res, err)
+	}
+	res, err = session.CallTool(ctx, &mcpsdk.CallToolParams{Name: "upper", Arguments: map[string]any{"text": map[string]any{}}})
+	if err != nil || !res.IsError || !strings.Contains(res.Content[0].(*mcpsdk.TextContent).Text, "invalid_arguments") {
+		t.Fatalf("invalid arguments should be a tool error: %+v %v", res, err)
+	}
+
+	prompts, err := session.ListPrompts(ctx, nil)
+	if err != nil || len(prompts.Prompts) != 1 || prompts.Prompts[0].Name != "reviewer" {
+		t.Fatalf("list prompts: %+v %v", prompts, err)
+	}
+	prompt, err := session.GetPrompt(ctx, &mcpsdk.GetPromptParams{Name: "reviewer", Arguments: map[string]string{"input": "main.go"}})
+	if err != nil {
+		t.Fatalf("get prompt: %v", err)
+	}
+	if text := prompt.Messages[0].Content.(*mcpsdk.TextContent).Text; text != "Review carefully.\n\nmain.go" {
+		t.Fatalf("unexpected prompt text %q", text)
+	}
+}
+
+func TestServeRunAgent(t *testing.T) {
+	rt, mdl := newTestRuntime(t)
+	session := connect(t, rt, WithTools("none"))
+	ctx := context.Background()
+
+	tools, err := session.ListTools(ctx, nil)
+	if err != nil || len(tools.Tools) != 1 {
+		t.Fatalf("WithTools should hide runtime tools: %+v %v", tools, err)
+	}
+
+	res, err := session.CallTool(ctx, &mcpsdk.CallToolParams{Name: RunAgentToolName, Arguments: map[string]any{"prompt": "hello", "session_id": "s1"}})
+	if err != nil || res.IsError {
+		t.Fatalf("run_agent: %+v %v", res, err)
+	}
+	if text := res.Content[0].(*mcpsdk.TextContent).Text; text != "echo: hello" {
+		t.Fatalf("unexpected output %q", text)
+	}
+	structured := res.StructuredContent.(map[string]any)
+	if structured["session_id"] != "s1" || structured["stop_reason"] != "end_turn" {
+		t.Fatalf("unexpected structured content %v", structured)
+	}
+	if len(mdl.prompts) != 1 || mdl.prompts[0] != "hello" {
+		t.Fatalf("model not driven: %v", mdl.prompts)
+	}
+
+	res, err = session.CallTool(ctx, &mcpsdk.CallToolParams{Name: RunAgentToolName, Arguments: map[string]any{}})
+	if err != nil || !res.IsError {
+		t.Fatalf("missing prompt should fail: %+v %v", res, err)
+	}
+}
+
+func TestNewHTTPHandler(t *testing.T) {
+	rt, _ := newTestRuntime(t)
+	handler, err := NewHTTPHandler(rt, WithImplementation("agents", "1.2.3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	client := mcpsdk.NewClient(&mcpsdk.Implementation{Name: "test", Version: "1"}, nil)
+	session, err := client.Connect(context.Background(), &mcpsdk.StreamableClientTransport{Endpoint: srv.URL}, nil)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	defer session.Close()
+	if info := session.InitializeResult().ServerInfo; info.Name != "agents" || info.Version != "1.2.3" {
+		t.Fatalf("unexpected server info %+v", info)
+	}
+	res, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{Name: "upper", Arguments: map[string]any{"text": "http"}})
+	if err != nil || res.Content[0].(*mcpsdk.TextContent).Text != "HTTP" {
+		t.Fatalf("call over http: %+v %v", res, err)
+	}
+
+	if _, err := NewServer(nil); err == nil {
+		t.Fatal("nil runtime should be rejected")
+	}
+}
+
+func TestNewHTTPHandlerIsolatesSessions(t *testing.T) {
+	rt, mdl := newTestRuntime(t)
+	handler, err := NewHTTPHandler(rt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(handler)
+	defer srv.Close()
+
+	seen := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		client := mcpsdk.NewClient(&mcpsdk.Implementation{Name: "test", Version: "1"}, nil)
+		session, err := client.Connect(context.Background(), &mcpsdk.StreamableClientTransport{Endpoint: srv.URL}, nil)
+		if err != nil {
+			t.Fatalf("connect: %v", err)
+		}
+		defer session.Close()
+		res, err := session.CallTool(context.Background(), &mcpsdk.CallToolParams{Name: "whoami"})
+		if err != nil || res.IsError {
+			t.Fatalf("whoami: %+v %v", res, err)
+		}
+		id := res.Content[0].(*mcpsdk.TextContent).Text
+		if !strings.HasPrefix(id, defaultSessionID+"-") || seen[id] {
+			t.Fatalf("each MCP session needs its own tool session, got %q after %v", id, seen)
+		}
+		seen[id] = true
+
+		// Both clients name the same conversation; neither sees the other's.
+		for turn := 0; turn < 2; turn++ {
+			res, err = session.CallTool(context.Background(), &mcpsdk.CallToolParams{Name: RunAgentToolName, Arguments: map[string]any{"prompt": "hi", "session_id": "shared"}})
+			if err != nil || res.IsError || res.StructuredContent.(map[string]any)["session_id"] != "shared" {
+				t.Fatalf("run_agent: %+v %v", res, err)
+			}
+		}
+	}
+	mdl.mu.Lock()
+	defer mdl.mu.Unlock()
+	if len(mdl.history) != 4 || mdl.history[0] != mdl.history[2] || mdl.history[1] <= mdl.history[0] {
+		t.Fatalf("conversations leaked across MCP sessions: %v", mdl.history)
+	}
+}
@@ -509,6 +509,7 @@ func buildToolResults(msg Message) []anthropicsdk.ContentBlockParamUnion {
 	}
 
 	blocks := make([]anthropicsdk.ContentBlockParamUnion, 0, len(msg.ToolCalls))
+	media := toolResultMediaBlocks(msg.ContentBlocks)
 	for _, call := range msg.ToolCalls {
 		id := strings.TrimSpace(call.ID)
 		if id == "" {
@@ -518,7 +519,13 @@ func buildToolResults(msg Message) []anthropicsdk.ContentBlockParamUnion {
 		if strings.TrimSpace(text) == "" {
 			text = msg.Content
 		}
-		blocks = append(blocks, anthropicsdk.NewToolResultBlock(id, text, toolResultIsError(text)))
+		block := anthropicsdk.NewToolResultBlock(id, text, toolResultIsError(text))
+		if len(media) > 0 {
+			// Multimodal output belongs to the message's (single) tool call.
+			block.OfToolResult.Content = append(block.OfToolResult.Content, media...)
+			media = nil
+		}
+		blocks = append(blocks, block)
 	}
 	if len(blocks) == 0 {
 		blocks = append(blocks, anthropicsdk.NewTextBlock(msg.Content))
@@ -526,6 +533,33 @@ func buildToolResults(msg Message) []anthropicsdk.ContentBlockParamUnion {
 	return blocks
 }
 
+// toolResultMediaBlocks maps image and document blocks to tool_result content.
+func toolResultMediaBlocks(blocks []ContentBlock) []anthropicsdk.ToolResultBlockParamContentUnion {
+	var out []anthropicsdk.ToolResultBlockParamContentUnion
+	for _, b := range blocks {
+		switch b.Type {
+		case ContentBlockText:
+			if strings.TrimSpace(b.Text) != "" {
+				out = append(out, anthropicsdk.ToolResultBlockParamContentUnion{OfText: &anthropicsdk.TextBlockParam{Text: b.Text}})
+			}
+		case ContentBlockImage:
+			if b.URL != "" {
+				img := anthropicsdk.NewImageBlock(anthropicsdk.URLImageSourceParam{URL: b.URL})
+				out = append(out, anthropicsdk.ToolResultBlockParamContentUnion{OfImage: img.OfImage})
+			} else if b.Data != "" {
+				img := anthropicsdk.NewImageBlockBase64(b.MediaType, b.Data)
+				out = append(out, anthropicsdk.ToolResultBlockParamContentUnion{OfImage: img.OfImage})
+			}
+		case ContentBlockDocument:
+			if b.Data != "" {
+				doc := anthropicsdk.NewDocumentBlock(anthropicsdk.Base64PDFSourceParam{Data: b.Data})
+				out = append(out, anthropicsdk.ToolResultBlockParamContentUnion{OfDocument: doc.OfDocument})
+			}
+		}
+	}
+	return out
+}
+
 // convertContentBlocks maps SDK ContentBlocks to Anthropic API content blocks.
 func convertContentBlocks(blocks []ContentBlock) []anthropicsdk.ContentBlockParamUnion {
 	out := make([]anthropicsdk.ContentBlockParamUnion, 0, len(blocks))
@@ -409,3 +409,18 @@ func TestBuildToolResultsFallbacks(t *testing.T) {
 		t.Fatalf("expected single block fallback")
 	}
 }
+
+func TestBuildToolResultsAttachesImages(t *testing.T) {
+	msg := Message{
+		ToolCalls:     []ToolCall{{ID: "id1", Name: "read", Result: "image"}},
+		ContentBlocks: []ContentBlock{{Type: ContentBlockImage, MediaType: "image/png", Data: "Zg=="}},
+	}
+	blocks := buildToolResults(msg)
+	if len(blocks) != 1 || blocks[0].OfToolResult == nil {
+		t.Fatalf("expected tool result block, got %#v", blocks)
+	}
+	content := blocks[0].OfToolResult.Content
+	if len(content) != 2 || content[0].OfText == nil || content[1].OfImage == nil {
+		t.Fatalf("expected text and image content, got %#v", content)
+	}
+}
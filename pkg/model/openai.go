@@ -512,9 +512,36 @@ func buildOpenAIToolResults(msg Message) []openai.ChatCompletionMessageParamUnio
 		results = append(results, openai.ToolMessage(msg.Content, ""))
 	}
 
+	// Chat Completions tool messages are text-only, so images returned by a
+	// tool follow as a user message.
+	if hasImageBlocks(msg.ContentBlocks) {
+		name := "tool"
+		if len(msg.ToolCalls) > 0 && strings.TrimSpace(msg.ToolCalls[0].Name) != "" {
+			name = msg.ToolCalls[0].Name
+		}
+		userParam := openai.ChatCompletionUserMessageParam{
+			Content: openai.ChatCompletionUserMessageParamContentUnion{
+				OfArrayOfContentParts: buildOpenAIUserContentParts(Message{
+					Content:       "Image output from " + name + ":",
+					ContentBlocks: msg.ContentBlocks,
+				}),
+			},
+		}
+		results = append(results, openai.ChatCompletionMessageParamUnion{OfUser: &userParam})
+	}
+
 	return results
 }
 
+func hasImageBlocks(blocks []ContentBlock) bool {
+	for _, block := range blocks {
+		if block.Type == ContentBlockImage && openAIImageURL(block) != "" {
+			return true
+		}
+	}
+	return false
+}
+
 func convertToolsToOpenAI(tools []ToolDefinition) []openai.ChatCompletionToolParam {
 	var result []openai.ChatCompletionToolParam
 	for _, def := range tools {
@@ -63,3 +63,17 @@ func TestBuildOpenAIToolResultsEdgeCases(t *testing.T) {
 		t.Fatalf("unexpected tool result content %#v", out[0].OfTool)
 	}
 }
+
+func TestBuildOpenAIToolResultsForwardsImages(t *testing.T) {
+	out := buildOpenAIToolResults(Message{
+		Role:          "tool",
+		ToolCalls:     []ToolCall{{ID: "t1", Name: "read", Result: "image"}},
+		ContentBlocks: []ContentBlock{{Type: ContentBlockImage, MediaType: "image/png", Data: "Zg=="}},
+	})
+	if len(out) != 2 || out[0].OfTool == nil || out[1].OfUser == nil {
+		t.Fatalf("expected tool message followed by user image message, got %#v", out)
+	}
+	if parts := out[1].OfUser.Content.OfArrayOfContentParts; len(parts) != 2 || parts[1].OfImageURL == nil {
+		t.Fatalf("unexpected image parts %#v", parts)
+	}
+}
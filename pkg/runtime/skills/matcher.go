@@ -8,6 +8,10 @@ import (
 	"strings"
 )
 
+// ArgumentsMetadataKey is the ActivationContext.Metadata key holding the
+// arguments of a manual invocation, as a map of argument name to value.
+const ArgumentsMetadataKey = "skill.arguments"
+
 // ActivationContext captures conversational state used for auto-activation.
 type ActivationContext struct {
 	Prompt       string
@@ -304,7 +304,21 @@ func (m *Manager) SetMaxConcurrentBackground(n int) {
 }
 
 func (m *Manager) DispatchAsync(ctx context.Context, name, instruction string) (string, error) {
-	return m.dispatchAsync(ctx, Request{Target: name, Instruction: instruction})
+	return m.dispatchAsync(ctx, Request{Target: name, Instruction: instruction}, m.Dispatch)
+}
+
+// DispatchAsyncWith queues req as a background task executed by run instead of
+// the registered handler. It shares the task table, concurrency slots and
+// completion handler with DispatchAsync so callers that drive a full agent
+// loop can still be tracked through TaskStatus.
+func (m *Manager) DispatchAsyncWith(ctx context.Context, req Request, run func(context.Context, Request) (Result, error)) (string, error) {
+	if m == nil {
+		return "", errors.New("subagents: manager is nil")
+	}
+	if run == nil {
+		return "", errors.New("subagents: run func is nil")
+	}
+	return m.dispatchAsync(ctx, req, run)
 }
 
 func (m *Manager) TaskStatus(taskID string) (Status, error) {
@@ -324,7 +338,7 @@ func (m *Manager) TaskStatus(taskID string) (Status, error) {
 	return status.clone(), nil
 }
 
-func (m *Manager) dispatchAsync(ctx context.Context, req Request) (string, error) {
+func (m *Manager) dispatchAsync(ctx context.Context, req Request, run func(context.Context, Request) (Result, error)) (string, error) {
 	if strings.TrimSpace(req.Instruction) == "" {
 		return "", ErrEmptyInstruction
 	}
@@ -368,7 +382,7 @@ func (m *Manager) dispatchAsync(ctx context.Context, req Request) (string, error
 			current.State = StatusRunning
 		})
 
-		result, err := m.Dispatch(dispatchCtx, req)
+		result, err := run(dispatchCtx, req)
 		output := ""
 		if result.Output != nil {
 			output = strings.TrimSpace(fmt.Sprint(result.Output))
@@ -515,3 +515,49 @@ func TestManagerDispatchAsyncAndTaskStatus(t *testing.T) {
 		t.Fatalf("TaskStatus(missing) err = %v, want ErrUnknownTask", err)
 	}
 }
+
+func TestManagerDispatchAsyncWithUsesCustomRunner(t *testing.T) {
+	m := NewManager()
+	completed := make(chan Status, 1)
+	m.SetCompletionHandler(func(status Status) {
+		completed <- status
+	})
+
+	taskID, err := m.DispatchAsyncWith(context.Background(), Request{
+		Target:      "Explore",
+		Instruction: "find callers",
+		Metadata:    map[string]any{"session_id": "parent"},
+	}, func(_ context.Context, req Request) (Result, error) {
+		return Result{Output: "ran " + req.Instruction}, nil
+	})
+	if err != nil {
+		t.Fatalf("dispatch async with: %v", err)
+	}
+
+	select {
+	case status := <-completed:
+		if status.TaskID != taskID || status.State != StatusSuccess {
+			t.Fatalf("unexpected status: %+v", status)
+		}
+		if status.Name != "explore" || status.SessionID != "parent" || status.Output != "ran find callers" {
+			t.Fatalf("unexpected status payload: %+v", status)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for completion")
+	}
+
+	if _, err := m.DispatchAsyncWith(context.Background(), Request{Instruction: "x"}, nil); err == nil {
+		t.Fatal("expected error for nil run func")
+	}
+	if _, err := m.DispatchAsyncWith(context.Background(), Request{}, func(context.Context, Request) (Result, error) {
+		return Result{}, nil
+	}); !errors.Is(err, ErrEmptyInstruction) {
+		t.Fatalf("expected ErrEmptyInstruction, got %v", err)
+	}
+	var nilMgr *Manager
+	if _, err := nilMgr.DispatchAsyncWith(context.Background(), Request{Instruction: "x"}, func(context.Context, Request) (Result, error) {
+		return Result{}, nil
+	}); err == nil {
+		t.Fatal("expected error for nil manager")
+	}
+}
@@ -0,0 +1,465 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const ApplyPatchName = "apply_patch"
+
+const applyPatchDescription = `Applies a patch that may add, update, delete or move several files at once.
+Accepted formats:
+- Unified diff (git diff / diff -u output with ---/+++ headers and @@ hunks).
+- The apply_patch envelope:
+  *** Begin Patch
+  *** Update File: path/to/file
+  *** Move to: new/path (optional)
+  @@ optional line to anchor the hunk
+   context line
+  -removed line
+  +added line
+  *** Add File: path/to/new
+  +file contents
+  *** Delete File: path/to/old
+  *** End Patch
+Every hunk is verified against the current file contents (tolerating whitespace drift). If any hunk or path fails, no file is changed.`
+
+var applyPatchSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"patch": map[string]interface{}{
+			"type":        "string",
+			"description": "Unified diff or apply_patch envelope describing the changes.",
+		},
+	},
+	Required: []string{"patch"},
+}
+
+// ApplyPatchTool applies multi-file patches atomically within the sandbox.
+type ApplyPatchTool struct {
+	base *fileSandbox
+}
+
+// NewApplyPatchTool builds an ApplyPatchTool rooted at the current directory.
+func NewApplyPatchTool() *ApplyPatchTool {
+	return NewApplyPatchToolWithRoot("")
+}
+
+// NewApplyPatchToolWithRoot builds an ApplyPatchTool rooted at the provided directory.
+func NewApplyPatchToolWithRoot(root string) *ApplyPatchTool {
+	return &ApplyPatchTool{base: newFileSandbox(root)}
+}
+
+// NewApplyPatchToolWithSandbox builds an ApplyPatchTool using a custom sandbox.
+func NewApplyPatchToolWithSandbox(root string, policy sandbox.FileSystemPolicy) *ApplyPatchTool {
+	return &ApplyPatchTool{base: newFileSandboxWithSandbox(root, policy)}
+}
+
+func (a *ApplyPatchTool) Name() string { return ApplyPatchName }
+
+func (a *ApplyPatchTool) Description() string { return applyPatchDescription }
+
+func (a *ApplyPatchTool) Schema() *tool.JSONSchema { return applyPatchSchema }
+
+func (a *ApplyPatchTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsDestructive: true}
+}
+
+// SetFileState attaches the tracker used to enforce read-before-write.
+func (a *ApplyPatchTool) SetFileState(files *FileStateTracker) {
+	if a != nil && a.base != nil {
+		a.base.files = files
+	}
+}
+
+func (a *ApplyPatchTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if a == nil || a.base == nil {
+		return nil, errors.New("apply_patch tool is not initialised")
+	}
+	if params == nil {
+		return nil, errors.New("params is nil")
+	}
+	raw, ok := params["patch"]
+	if !ok || raw == nil {
+		return nil, errors.New("patch is required")
+	}
+	text, err := coerceString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("patch must be string: %w", err)
+	}
+	files, err := parsePatch(text)
+	if err != nil {
+		return nil, fmt.Errorf("parse patch: %w", err)
+	}
+
+	plan := newPatchPlan(a.base)
+	summaries := make([]map[string]interface{}, 0, len(files))
+	lines := make([]string, 0, len(files))
+	for _, file := range files {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		summary, err := plan.stage(file)
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, summary)
+		lines = append(lines, formatPatchSummary(summary))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	// Like edit and write, changing or deleting an existing file requires
+	// that the session has read its current contents.
+	for _, path := range plan.order {
+		state := plan.files[path]
+		if !state.touched || state.original == nil || sameContent(state.content, state.original) {
+			continue
+		}
+		if err := a.base.checkWrite(ctx, path); err != nil {
+			return nil, err
+		}
+	}
+	if err := plan.commit(); err != nil {
+		return nil, err
+	}
+	for _, path := range plan.order {
+		state := plan.files[path]
+		switch {
+		case !state.touched:
+		case state.content == nil:
+			a.base.forget(ctx, path)
+		default:
+			a.base.recordRead(ctx, path, []byte(*state.content))
+		}
+	}
+
+	return &tool.ToolResult{
+		Success: true,
+		Output:  fmt.Sprintf("applied patch to %d file(s)\n%s", len(summaries), strings.Join(lines, "\n")),
+		Data: map[string]interface{}{
+			"files": summaries,
+		},
+	}, nil
+}
+
+func formatPatchSummary(summary map[string]interface{}) string {
+	path, _ := summary["path"].(string)
+	switch summary["action"] {
+	case string(patchAdd):
+		return "A " + path
+	case string(patchDelete):
+		return "D " + path
+	}
+	if moved, _ := summary["move_to"].(string); moved != "" {
+		return fmt.Sprintf("R %s -> %s", path, moved)
+	}
+	return "M " + path
+}
+
+// stagedFile is the staged view of one path: nil content means the path is
+// (or will be) absent.
+type stagedFile struct {
+	content  *string
+	mode     os.FileMode
+	original *string
+	origMode os.FileMode
+	touched  bool
+}
+
+// patchPlan stages every change in memory so nothing touches disk until all
+// files have been validated.
+type patchPlan struct {
+	base  *fileSandbox
+	files map[string]*stagedFile
+	order []string
+}
+
+func newPatchPlan(base *fileSandbox) *patchPlan {
+	return &patchPlan{base: base, files: map[string]*stagedFile{}}
+}
+
+func (p *patchPlan) load(path string) (*stagedFile, error) {
+	if state, ok := p.files[path]; ok {
+		return state, nil
+	}
+	state := &stagedFile{mode: 0o644}
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		if info.IsDir() {
+			return nil, fmt.Errorf("%s is a directory", displayPath(path, p.base.root))
+		}
+		content, err := p.base.readFile(path)
+		if err != nil {
+			return nil, err
+		}
+		state.content = &content
+		state.original = &content
+		state.mode = info.Mode().Perm()
+		state.origMode = state.mode
+	case errors.Is(err, os.ErrNotExist):
+	default:
+		return nil, fmt.Errorf("stat file: %w", err)
+	}
+	p.files[path] = state
+	p.order = append(p.order, path)
+	return state, nil
+}
+
+func (p *patchPlan) stage(file patchFile) (map[string]interface{}, error) {
+	path, err := p.base.resolvePath(file.path)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file.path, err)
+	}
+	display := displayPath(path, p.base.root)
+	state, err := p.load(path)
+	if err != nil {
+		return nil, err
+	}
+	summary := map[string]interface{}{"path": display, "action": string(file.action)}
+
+	switch file.action {
+	case patchAdd:
+		if state.content != nil {
+			return nil, fmt.Errorf("cannot add %s: file already exists", display)
+		}
+		content := joinPatchLines(file.content, !file.noNewline && len(file.content) > 0)
+		state.content = &content
+		state.touched = true
+		return summary, nil
+	case patchDelete:
+		if state.content == nil {
+			return nil, fmt.Errorf("cannot delete %s: file does not exist", display)
+		}
+		state.content = nil
+		state.touched = true
+		return summary, nil
+	}
+
+	if state.content == nil {
+		return nil, fmt.Errorf("cannot update %s: file does not exist", display)
+	}
+	updated, fuzz, err := applyHunks(*state.content, file.hunks, file.noNewline)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", display, err)
+	}
+	if p.base.maxBytes > 0 && int64(len(updated)) > p.base.maxBytes {
+		return nil, fmt.Errorf("%s: patched content exceeds %d bytes limit", display, p.base.maxBytes)
+	}
+	summary["hunks"] = len(file.hunks)
+	if fuzz > 0 {
+		summary["fuzz"] = fuzz
+	}
+
+	if file.movePath == "" {
+		state.content = &updated
+		state.touched = true
+		return summary, nil
+	}
+	dest, err := p.base.resolvePath(file.movePath)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", file.movePath, err)
+	}
+	if dest != path {
+		destState, err := p.load(dest)
+		if err != nil {
+			return nil, err
+		}
+		if destState.content != nil {
+			return nil, fmt.Errorf("cannot move %s to %s: destination exists", display, displayPath(dest, p.base.root))
+		}
+		destState.content = &updated
+		destState.mode = state.mode
+		destState.touched = true
+		state.content = nil
+	} else {
+		state.content = &updated
+	}
+	state.touched = true
+	summary["move_to"] = displayPath(dest, p.base.root)
+	return summary, nil
+}
+
+// commit writes and deletes the staged files in order. A failure part way
+// through restores the files already changed.
+func (p *patchPlan) commit() error {
+	var done []string
+	for _, path := range p.order {
+		state := p.files[path]
+		if !state.touched || sameContent(state.content, state.original) {
+			continue
+		}
+		var err error
+		if state.content != nil {
+			err = os.MkdirAll(filepath.Dir(path), 0o755)
+			if err == nil {
+				err = writeFileAtomic(path, []byte(*state.content), state.mode)
+			}
+		} else {
+			err = os.Remove(path)
+		}
+		if err != nil {
+			for _, prev := range done {
+				restorePatchedFile(prev, p.files[prev])
+			}
+			return fmt.Errorf("apply %s: %w", displayPath(path, p.base.root), err)
+		}
+		done = append(done, path)
+	}
+	return nil
+}
+
+func restorePatchedFile(path string, state *stagedFile) {
+	if state.original == nil {
+		_ = os.Remove(path)
+		return
+	}
+	_ = writeFileAtomic(path, []byte(*state.original), state.origMode)
+}
+
+func sameContent(a, b *string) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func joinPatchLines(lines []string, trailingNewline bool) string {
+	out := strings.Join(lines, "\n")
+	if trailingNewline {
+		out += "\n"
+	}
+	return out
+}
+
+// applyHunks applies hunks in order and reports the highest fuzz level used
+// (0 exact, 1 trailing whitespace ignored, 2 surrounding whitespace ignored).
+func applyHunks(content string, hunks []patchHunk, noNewline bool) (string, int, error) {
+	trailingNewline := strings.HasSuffix(content, "\n")
+	lines := strings.Split(content, "\n")
+	if trailingNewline {
+		lines = lines[:len(lines)-1]
+	}
+	if content == "" {
+		lines = nil
+	}
+
+	cursor, delta, maxFuzz := 0, 0, 0
+	for i, hunk := range hunks {
+		start := cursor
+		if hunk.anchor != "" {
+			idx, fuzz, matches := findLines(lines, []string{hunk.anchor}, cursor, -1)
+			if idx < 0 {
+				return "", 0, fmt.Errorf("hunk %d: anchor %q not found", i+1, hunk.anchor)
+			}
+			if matches > 1 {
+				return "", 0, fmt.Errorf("hunk %d: ambiguous anchor %q matches %d lines; use a more specific anchor", i+1, hunk.anchor, matches)
+			}
+			maxFuzz = max(maxFuzz, fuzz)
+			start = idx + 1
+		}
+		expected := -1
+		if hunk.oldStart > 0 {
+			expected = hunk.oldStart - 1 + delta
+		}
+
+		var idx, fuzz, matches int
+		switch {
+		case len(hunk.oldLines) == 0 && expected >= 0:
+			// Pure insertion: "@@ -N,0" inserts after line N.
+			idx = min(max(hunk.oldStart+delta, start), len(lines))
+		case len(hunk.oldLines) == 0:
+			idx = len(lines)
+		case hunk.endOfFile:
+			idx, fuzz, _ = findLines(lines, hunk.oldLines, max(start, len(lines)-len(hunk.oldLines)), -1)
+			if idx < 0 {
+				idx, fuzz, matches = findLines(lines, hunk.oldLines, start, expected)
+			}
+		default:
+			idx, fuzz, matches = findLines(lines, hunk.oldLines, start, expected)
+		}
+		if idx < 0 {
+			return "", 0, fmt.Errorf("hunk %d does not match the current file contents:\n%s", i+1, strings.Join(hunk.oldLines, "\n"))
+		}
+		// Without a line number or anchor the context alone must pick the
+		// location, as edit requires a unique old_string.
+		if expected < 0 && hunk.anchor == "" && matches > 1 {
+			return "", 0, fmt.Errorf("hunk %d: ambiguous context matches %d locations; add more context lines or an @@ anchor:\n%s", i+1, matches, strings.Join(hunk.oldLines, "\n"))
+		}
+		maxFuzz = max(maxFuzz, fuzz)
+
+		replaced := make([]string, 0, len(lines)-len(hunk.oldLines)+len(hunk.newLines))
+		replaced = append(replaced, lines[:idx]...)
+		replaced = append(replaced, hunk.newLines...)
+		replaced = append(replaced, lines[idx+len(hunk.oldLines):]...)
+		lines = replaced
+		cursor = idx + len(hunk.newLines)
+		delta += len(hunk.newLines) - len(hunk.oldLines)
+	}
+	if content == "" {
+		trailingNewline = true
+	}
+	if noNewline {
+		trailingNewline = false
+	}
+	return joinPatchLines(lines, trailingNewline && len(lines) > 0), maxFuzz, nil
+}
+
+var patchLineNormalizers = []func(string) string{
+	func(s string) string { return s },
+	func(s string) string { return strings.TrimRight(s, " \t") },
+	strings.TrimSpace,
+}
+
+// findLines locates needle in lines at or after from. When expected is
+// non-negative the match closest to it wins, otherwise the first. The
+// strictest normaliser that matches determines the reported fuzz level; the
+// last result counts the matches at that level.
+func findLines(lines, needle []string, from, expected int) (int, int, int) {
+	if from < 0 {
+		from = 0
+	}
+	for fuzz, normalize := range patchLineNormalizers {
+		best, matches := -1, 0
+		for idx := from; idx+len(needle) <= len(lines); idx++ {
+			if !linesMatch(lines[idx:idx+len(needle)], needle, normalize) {
+				continue
+			}
+			matches++
+			if best < 0 || expected >= 0 && absInt(idx-expected) < absInt(best-expected) {
+				best = idx
+			}
+		}
+		if best >= 0 {
+			return best, fuzz, matches
+		}
+	}
+	return -1, 0, 0
+}
+
+func linesMatch(window, needle []string, normalize func(string) string) bool {
+	for i := range needle {
+		if normalize(window[i]) != normalize(needle[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
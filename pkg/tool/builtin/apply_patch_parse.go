@@ -0,0 +1,403 @@
+package toolbuiltin
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type patchAction string
+
+const (
+	patchAdd    patchAction = "add"
+	patchUpdate patchAction = "update"
+	patchDelete patchAction = "delete"
+)
+
+// patchFile is one file-level change parsed from either patch dialect.
+type patchFile struct {
+	action   patchAction
+	path     string
+	movePath string
+	content  []string // full contents for patchAdd
+	hunks    []patchHunk
+	// noNewline records that the new file must not end with a newline.
+	noNewline bool
+}
+
+// patchHunk holds the old/new line sequences of a single hunk. oldStart is
+// the 1-based line from a unified diff header (0 when unknown); anchor is the
+// optional context line that follows "@@" in the apply_patch envelope.
+type patchHunk struct {
+	oldStart  int
+	anchor    string
+	oldLines  []string
+	newLines  []string
+	endOfFile bool
+}
+
+const (
+	envelopeBegin     = "*** Begin Patch"
+	envelopeEnd       = "*** End Patch"
+	envelopeAdd       = "*** Add File: "
+	envelopeUpdate    = "*** Update File: "
+	envelopeDelete    = "*** Delete File: "
+	envelopeMove      = "*** Move to: "
+	envelopeEndOfFile = "*** End of File"
+	noNewlineMarker   = `\ No newline at end of file`
+)
+
+// parsePatch detects the patch dialect and returns the file changes it
+// describes in order.
+func parsePatch(text string) ([]patchFile, error) {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return nil, errors.New("patch cannot be empty")
+	}
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	var (
+		files []patchFile
+		err   error
+	)
+	if strings.HasPrefix(trimmed, envelopeBegin) {
+		files, err = parseEnvelopePatch(lines)
+	} else {
+		files, err = parseUnifiedPatch(lines)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		return nil, errors.New("patch contains no file changes")
+	}
+	return files, nil
+}
+
+func parseEnvelopePatch(lines []string) ([]patchFile, error) {
+	i := 0
+	for i < len(lines) && strings.TrimSpace(lines[i]) == "" {
+		i++
+	}
+	if i >= len(lines) || strings.TrimSpace(lines[i]) != envelopeBegin {
+		return nil, fmt.Errorf("patch must start with %q", envelopeBegin)
+	}
+	i++
+	var files []patchFile
+	ended := false
+	for i < len(lines) && !ended {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == envelopeEnd:
+			ended = true
+			i++
+		case strings.HasPrefix(line, envelopeAdd):
+			file := patchFile{action: patchAdd, path: strings.TrimSpace(strings.TrimPrefix(line, envelopeAdd))}
+			i++
+			for i < len(lines) && !strings.HasPrefix(lines[i], "*** ") {
+				if !strings.HasPrefix(lines[i], "+") {
+					return nil, fmt.Errorf("line %d: added file lines must start with '+'", i+1)
+				}
+				file.content = append(file.content, lines[i][1:])
+				i++
+			}
+			files = append(files, file)
+		case strings.HasPrefix(line, envelopeDelete):
+			files = append(files, patchFile{action: patchDelete, path: strings.TrimSpace(strings.TrimPrefix(line, envelopeDelete))})
+			i++
+		case strings.HasPrefix(line, envelopeUpdate):
+			file := patchFile{action: patchUpdate, path: strings.TrimSpace(strings.TrimPrefix(line, envelopeUpdate))}
+			i++
+			if i < len(lines) && strings.HasPrefix(lines[i], envelopeMove) {
+				file.movePath = strings.TrimSpace(strings.TrimPrefix(lines[i], envelopeMove))
+				i++
+			}
+			next, hunks, err := parseEnvelopeHunks(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			if len(hunks) == 0 && file.movePath == "" {
+				return nil, fmt.Errorf("update of %s contains no hunks", file.path)
+			}
+			file.hunks = hunks
+			files = append(files, file)
+			i = next
+		case strings.TrimSpace(line) == "":
+			i++
+		default:
+			return nil, fmt.Errorf("line %d: unexpected %q", i+1, line)
+		}
+	}
+	if !ended {
+		return nil, fmt.Errorf("patch must end with %q", envelopeEnd)
+	}
+	return files, nil
+}
+
+func parseEnvelopeHunks(lines []string, i int) (int, []patchHunk, error) {
+	var hunks []patchHunk
+	var current *patchHunk
+	flush := func() {
+		if current != nil && (len(current.oldLines) > 0 || len(current.newLines) > 0) {
+			hunks = append(hunks, *current)
+		}
+		current = nil
+	}
+	for i < len(lines) {
+		line := lines[i]
+		if strings.TrimSpace(line) == envelopeEndOfFile {
+			if current != nil {
+				current.endOfFile = true
+			}
+			i++
+			continue
+		}
+		if strings.HasPrefix(line, "*** ") {
+			break
+		}
+		if strings.HasPrefix(line, "@@") {
+			flush()
+			current = &patchHunk{anchor: strings.TrimSpace(strings.TrimPrefix(line, "@@"))}
+			i++
+			continue
+		}
+		if current == nil {
+			// The first hunk may omit its "@@" header.
+			current = &patchHunk{}
+		}
+		switch {
+		case line == "":
+			current.oldLines = append(current.oldLines, "")
+			current.newLines = append(current.newLines, "")
+		case line[0] == ' ':
+			current.oldLines = append(current.oldLines, line[1:])
+			current.newLines = append(current.newLines, line[1:])
+		case line[0] == '-':
+			current.oldLines = append(current.oldLines, line[1:])
+		case line[0] == '+':
+			current.newLines = append(current.newLines, line[1:])
+		default:
+			return i, nil, fmt.Errorf("line %d: hunk lines must start with ' ', '-' or '+': %q", i+1, line)
+		}
+		i++
+	}
+	flush()
+	return i, hunks, nil
+}
+
+func parseUnifiedPatch(lines []string) ([]patchFile, error) {
+	var files []patchFile
+	i := 0
+	for i < len(lines) {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			file, next, err := parseGitFileHeader(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, file)
+			i = next
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			file, next, err := parseUnifiedFile(lines, i, patchFile{})
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, file)
+			i = next
+		default:
+			// Ignore preamble such as commit messages or "Index:" lines.
+			i++
+		}
+	}
+	if len(files) == 0 {
+		return nil, errors.New("no unified diff file headers (---/+++) found")
+	}
+	return files, nil
+}
+
+// parseGitFileHeader handles the extended git header so pure renames and mode
+// changes without hunks are still recognised.
+func parseGitFileHeader(lines []string, i int) (patchFile, int, error) {
+	var file patchFile
+	fields := strings.Fields(strings.TrimPrefix(lines[i], "diff --git "))
+	if len(fields) == 2 {
+		file.path = stripDiffPrefix(fields[0], "a/")
+		file.movePath = stripDiffPrefix(fields[1], "b/")
+	}
+	i++
+	for i < len(lines) {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "rename from "):
+			file.path = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to "):
+			file.movePath = strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "new file mode"):
+			file.action = patchAdd
+		case strings.HasPrefix(line, "deleted file mode"):
+			file.action = patchDelete
+		case strings.HasPrefix(line, "--- "):
+			return parseUnifiedFile(lines, i, file)
+		case strings.HasPrefix(line, "diff --git "):
+			return finishGitFile(file), i, nil
+		case strings.HasPrefix(line, "Binary files "), strings.HasPrefix(line, "GIT binary patch"):
+			return patchFile{}, i, fmt.Errorf("binary patches are not supported (%s)", file.path)
+		}
+		i++
+	}
+	return finishGitFile(file), i, nil
+}
+
+func finishGitFile(file patchFile) patchFile {
+	if file.action == "" {
+		file.action = patchUpdate
+	}
+	if file.action != patchUpdate || file.movePath == file.path {
+		file.movePath = ""
+	}
+	return file
+}
+
+func parseUnifiedFile(lines []string, i int, file patchFile) (patchFile, int, error) {
+	oldPath := diffHeaderPath(strings.TrimPrefix(lines[i], "--- "))
+	newPath := diffHeaderPath(strings.TrimPrefix(lines[i+1], "+++ "))
+	i += 2
+	switch {
+	case oldPath == "/dev/null" && newPath == "/dev/null":
+		return patchFile{}, i, errors.New("diff header has /dev/null on both sides")
+	case oldPath == "/dev/null":
+		file.action = patchAdd
+		file.path = stripDiffPrefix(newPath, "b/")
+	case newPath == "/dev/null":
+		file.action = patchDelete
+		file.path = stripDiffPrefix(oldPath, "a/")
+	default:
+		file.action = patchUpdate
+		file.path = stripDiffPrefix(oldPath, "a/")
+		file.movePath = stripDiffPrefix(newPath, "b/")
+		if file.movePath == file.path {
+			file.movePath = ""
+		}
+	}
+
+	for i < len(lines) && strings.HasPrefix(lines[i], "@@") {
+		hunk, next, noNewline, err := parseUnifiedHunk(lines, i)
+		if err != nil {
+			return patchFile{}, i, err
+		}
+		if noNewline {
+			file.noNewline = true
+		}
+		file.hunks = append(file.hunks, hunk)
+		i = next
+	}
+	if file.action == patchAdd {
+		for _, hunk := range file.hunks {
+			file.content = append(file.content, hunk.newLines...)
+		}
+		file.hunks = nil
+	}
+	return file, i, nil
+}
+
+func parseUnifiedHunk(lines []string, i int) (patchHunk, int, bool, error) {
+	header := lines[i]
+	oldStart, oldCount, newCount, err := parseHunkHeader(header)
+	if err != nil {
+		return patchHunk{}, i, false, fmt.Errorf("line %d: %w", i+1, err)
+	}
+	hunk := patchHunk{oldStart: oldStart}
+	i++
+	oldSeen, newSeen := 0, 0
+	noNewline := false
+	lastOp := byte(0)
+	for i < len(lines) && (oldSeen < oldCount || newSeen < newCount) {
+		line := lines[i]
+		op := byte(' ')
+		text := ""
+		if line != "" {
+			op, text = line[0], line[1:]
+		}
+		switch op {
+		case ' ':
+			hunk.oldLines = append(hunk.oldLines, text)
+			hunk.newLines = append(hunk.newLines, text)
+			oldSeen++
+			newSeen++
+		case '-':
+			hunk.oldLines = append(hunk.oldLines, text)
+			oldSeen++
+		case '+':
+			hunk.newLines = append(hunk.newLines, text)
+			newSeen++
+		case '\\':
+			if lastOp != '-' {
+				noNewline = true
+			}
+		default:
+			return patchHunk{}, i, false, fmt.Errorf("line %d: unexpected hunk line %q", i+1, line)
+		}
+		lastOp = op
+		i++
+	}
+	if oldSeen != oldCount || newSeen != newCount {
+		return patchHunk{}, i, false, fmt.Errorf("hunk %q is truncated (expected -%d +%d lines, got -%d +%d)", header, oldCount, newCount, oldSeen, newSeen)
+	}
+	for i < len(lines) && strings.HasPrefix(lines[i], `\`) {
+		if strings.TrimSpace(lines[i]) == noNewlineMarker && lastOp != '-' {
+			noNewline = true
+		}
+		i++
+	}
+	return hunk, i, noNewline, nil
+}
+
+// parseHunkHeader parses "@@ -l[,s] +l[,s] @@".
+func parseHunkHeader(header string) (int, int, int, error) {
+	rest := strings.TrimPrefix(header, "@@")
+	end := strings.Index(rest, "@@")
+	if end < 0 {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q", header)
+	}
+	fields := strings.Fields(rest[:end])
+	if len(fields) != 2 || !strings.HasPrefix(fields[0], "-") || !strings.HasPrefix(fields[1], "+") {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q", header)
+	}
+	oldStart, oldCount, err := parseHunkRange(fields[0][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q: %w", header, err)
+	}
+	_, newCount, err := parseHunkRange(fields[1][1:])
+	if err != nil {
+		return 0, 0, 0, fmt.Errorf("malformed hunk header %q: %w", header, err)
+	}
+	return oldStart, oldCount, newCount, nil
+}
+
+func parseHunkRange(raw string) (int, int, error) {
+	startRaw, countRaw, hasCount := strings.Cut(raw, ",")
+	start, err := strconv.Atoi(startRaw)
+	if err != nil {
+		return 0, 0, err
+	}
+	count := 1
+	if hasCount {
+		if count, err = strconv.Atoi(countRaw); err != nil {
+			return 0, 0, err
+		}
+	}
+	return start, count, nil
+}
+
+func diffHeaderPath(raw string) string {
+	if idx := strings.IndexByte(raw, '\t'); idx >= 0 {
+		raw = raw[:idx]
+	}
+	return strings.Trim(strings.TrimSpace(raw), `"`)
+}
+
+func stripDiffPrefix(path, prefix string) string {
+	return strings.TrimPrefix(path, prefix)
+}
@@ -0,0 +1,275 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writePatchFixture(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	return path
+}
+
+func readPatchFixture(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+func TestApplyPatchEnvelope(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	writePatchFixture(t, dir, "pkg/main.go", "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n\nfunc helper() {\n\treturn\n}\n")
+	writePatchFixture(t, dir, "old.txt", "obsolete\n")
+	writePatchFixture(t, dir, "rename_me.txt", "one\ntwo\n")
+
+	patch := `*** Begin Patch
+*** Update File: pkg/main.go
+@@ func helper() {
+-	return
++	println("helper")
+*** Add File: docs/NOTES.md
++# Notes
++
++hello
+*** Delete File: old.txt
+*** Update File: rename_me.txt
+*** Move to: renamed.txt
+@@
+ one
+-two
++three
+*** End Patch`
+	res, err := NewApplyPatchToolWithRoot(dir).Execute(context.Background(), map[string]interface{}{"patch": patch})
+	if err != nil {
+		t.Fatalf("apply_patch: %v", err)
+	}
+	if got := readPatchFixture(t, filepath.Join(dir, "pkg/main.go")); !strings.Contains(got, "func helper() {\n\tprintln(\"helper\")\n}\n") {
+		t.Fatalf("update not applied: %q", got)
+	}
+	if got := readPatchFixture(t, filepath.Join(dir, "docs/NOTES.md")); got != "# Notes\n\nhello\n" {
+		t.Fatalf("unexpected added file %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "old.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("old.txt should be deleted, stat=%v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "rename_me.txt")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("moved source should be removed, stat=%v", err)
+	}
+	if got := readPatchFixture(t, filepath.Join(dir, "renamed.txt")); got != "one\nthree\n" {
+		t.Fatalf("unexpected moved content %q", got)
+	}
+	for _, want := range []string{"M pkg/main.go", "A docs/NOTES.md", "D old.txt", "R rename_me.txt -> renamed.txt"} {
+		if !strings.Contains(res.Output, want) {
+			t.Fatalf("output missing %q:\n%s", want, res.Output)
+		}
+	}
+}
+
+func TestApplyPatchUnifiedDiff(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	writePatchFixture(t, dir, "a.txt", "alpha\nbeta\ngamma\ndelta\nepsilon\n")
+	// Context lines carry trailing whitespace drift relative to the file.
+	writePatchFixture(t, dir, "b.txt", "keep\nchange me   \nkeep too\n")
+
+	patch := `diff --git a/a.txt b/a.txt
+index 1111111..2222222 100644
+--- a/a.txt
++++ b/a.txt
+@@ -1,3 +1,3 @@
+ alpha
+-beta
++BETA
+ gamma
+@@ -4,2 +4,3 @@
+ delta
+ epsilon
++zeta
+diff --git a/b.txt b/b.txt
+--- a/b.txt
++++ b/b.txt
+@@ -1,3 +1,3 @@
+ keep
+-change me
++changed
+ keep too
+diff --git a/new.txt b/new.txt
+new file mode 100644
+--- /dev/null
++++ b/new.txt
+@@ -0,0 +1,2 @@
++fresh
++file
+\ No newline at end of file
+`
+	res, err := NewApplyPatchToolWithRoot(dir).Execute(context.Background(), map[string]interface{}{"patch": patch})
+	if err != nil {
+		t.Fatalf("apply_patch: %v", err)
+	}
+	if got := readPatchFixture(t, filepath.Join(dir, "a.txt")); got != "alpha\nBETA\ngamma\ndelta\nepsilon\nzeta\n" {
+		t.Fatalf("unexpected a.txt %q", got)
+	}
+	if got := readPatchFixture(t, filepath.Join(dir, "b.txt")); got != "keep\nchanged\nkeep too\n" {
+		t.Fatalf("unexpected b.txt %q", got)
+	}
+	if got := readPatchFixture(t, filepath.Join(dir, "new.txt")); got != "fresh\nfile" {
+		t.Fatalf("unexpected new.txt %q", got)
+	}
+	files := res.Data.(map[string]interface{})["files"].([]map[string]interface{})
+	if files[1]["fuzz"] != 1 {
+		t.Fatalf("expected fuzz to be reported for b.txt: %#v", files[1])
+	}
+}
+
+func TestApplyPatchIsAtomic(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	first := writePatchFixture(t, dir, "first.txt", "one\ntwo\n")
+	second := writePatchFixture(t, dir, "second.txt", "three\nfour\n")
+
+	tool := NewApplyPatchToolWithRoot(dir)
+	tests := []struct {
+		name  string
+		patch string
+		want  string
+	}{
+		{
+			name:  "hunk mismatch",
+			patch: "*** Begin Patch\n*** Update File: first.txt\n-two\n+TWO\n*** Update File: second.txt\n-missing\n+x\n*** End Patch",
+			want:  "hunk 1 does not match",
+		},
+		{
+			name:  "add existing",
+			patch: "*** Begin Patch\n*** Update File: first.txt\n-two\n+TWO\n*** Add File: second.txt\n+x\n*** End Patch",
+			want:  "already exists",
+		},
+		{
+			name:  "escape sandbox",
+			patch: "*** Begin Patch\n*** Update File: first.txt\n-two\n+TWO\n*** Add File: ../outside.txt\n+x\n*** End Patch",
+			want:  "outside.txt",
+		},
+		{
+			name:  "delete missing",
+			patch: "*** Begin Patch\n*** Delete File: ghost.txt\n*** End Patch",
+			want:  "does not exist",
+		},
+		{
+			name:  "missing end",
+			patch: "*** Begin Patch\n*** Delete File: first.txt",
+			want:  "must end with",
+		},
+		{
+			name:  "truncated hunk",
+			patch: "--- a/first.txt\n+++ b/first.txt\n@@ -1,2 +1,2 @@\n one\n",
+			want:  "truncated",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), map[string]interface{}{"patch": tt.patch})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			if readPatchFixture(t, first) != "one\ntwo\n" || readPatchFixture(t, second) != "three\nfour\n" {
+				t.Fatal("files changed despite failure")
+			}
+		})
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 2 {
+		t.Fatalf("unexpected leftovers: %v", entries)
+	}
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{}); err == nil {
+		t.Fatal("expected missing patch error")
+	}
+}
+
+func TestApplyPatchRequiresReadBeforeWrite(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	target := writePatchFixture(t, dir, "target.txt", "one\ntwo\n")
+	writePatchFixture(t, dir, "doomed.txt", "bye\n")
+
+	files := NewFileStateTracker()
+	read := NewReadToolWithRoot(dir)
+	read.SetFileState(files)
+	patcher := NewApplyPatchToolWithRoot(dir)
+	patcher.SetFileState(files)
+	ctx := sessionContext("sess")
+
+	update := map[string]interface{}{"patch": "*** Begin Patch\n*** Update File: target.txt\n one\n-two\n+TWO\n*** End Patch"}
+	remove := map[string]interface{}{"patch": "*** Begin Patch\n*** Delete File: doomed.txt\n*** End Patch"}
+	for _, params := range []map[string]interface{}{update, remove} {
+		if _, err := patcher.Execute(ctx, params); err == nil || !strings.Contains(err.Error(), "read it before modifying") {
+			t.Fatalf("expected unread file to be rejected, got %v", err)
+		}
+	}
+	if _, err := patcher.Execute(ctx, map[string]interface{}{"patch": "*** Begin Patch\n*** Add File: new.txt\n+x\n*** End Patch"}); err != nil {
+		t.Fatalf("adding a file should not need a read: %v", err)
+	}
+
+	for _, name := range []string{"target.txt", "doomed.txt"} {
+		if _, err := read.Execute(ctx, map[string]interface{}{"file_path": name}); err != nil {
+			t.Fatalf("read %s: %v", name, err)
+		}
+	}
+	for _, params := range []map[string]interface{}{update, remove} {
+		if _, err := patcher.Execute(ctx, params); err != nil {
+			t.Fatalf("apply after read: %v", err)
+		}
+	}
+	if got := readPatchFixture(t, target); got != "one\nTWO\n" {
+		t.Fatalf("unexpected content %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "doomed.txt")); !os.IsNotExist(err) {
+		t.Fatalf("expected doomed.txt to be deleted, got %v", err)
+	}
+}
+
+func TestApplyHunksPositioning(t *testing.T) {
+	content := "x\ny\nx\ny\n"
+	// The second "x/y" block is chosen because the header points at line 3.
+	got, _, err := applyHunks(content, []patchHunk{{oldStart: 3, oldLines: []string{"x", "y"}, newLines: []string{"x", "Y"}}}, false)
+	if err != nil || got != "x\ny\nx\nY\n" {
+		t.Fatalf("unexpected result %q err=%v", got, err)
+	}
+	got, _, err = applyHunks("a\nb\n", []patchHunk{{oldStart: 1, newLines: []string{"inserted"}}}, false)
+	if err != nil || got != "a\ninserted\nb\n" {
+		t.Fatalf("unexpected insertion %q err=%v", got, err)
+	}
+	got, _, err = applyHunks("end\nmid\nend\n", []patchHunk{{oldLines: []string{"end"}, newLines: []string{"END"}, endOfFile: true}}, false)
+	if err != nil || got != "end\nmid\nEND\n" {
+		t.Fatalf("unexpected end-of-file match %q err=%v", got, err)
+	}
+	got, fuzz, err := applyHunks("\tindented\n", []patchHunk{{oldLines: []string{"indented"}, newLines: []string{"\tdone"}}}, false)
+	if err != nil || got != "\tdone\n" || fuzz != 2 {
+		t.Fatalf("unexpected fuzzy result %q fuzz=%d err=%v", got, fuzz, err)
+	}
+
+	// Repeated context without a line number or anchor is ambiguous.
+	if _, _, err := applyHunks(content, []patchHunk{{oldLines: []string{"x", "y"}, newLines: []string{"x", "Y"}}}, false); err == nil || !strings.Contains(err.Error(), "ambiguous context matches 2 locations") {
+		t.Fatalf("expected ambiguous context error, got %v", err)
+	}
+	if _, _, err := applyHunks("f\nx\nf\nx\n", []patchHunk{{anchor: "f", oldLines: []string{"x"}, newLines: []string{"X"}}}, false); err == nil || !strings.Contains(err.Error(), "ambiguous anchor") {
+		t.Fatalf("expected ambiguous anchor error, got %v", err)
+	}
+	got, _, err = applyHunks("f\nx\ng\nx\n", []patchHunk{{anchor: "g", oldLines: []string{"x"}, newLines: []string{"X"}}}, false)
+	if err != nil || got != "f\nx\ng\nX\n" {
+		t.Fatalf("an anchor should disambiguate: %q err=%v", got, err)
+	}
+}
@@ -30,6 +30,9 @@ const (
 	bashDescript       = `
 	Execute a bash command with a configurable timeout.
 	Prefer dedicated file tools (read/write/edit/glob/grep) over shell pipelines.
+	Set run_in_background for long-running processes (servers, watchers) and poll them with bash_output.
+	`
+	bashPersistentNote = `The shell is persistent: the working directory, exported variables and sourced environments carry over between calls, so there is no need to prefix commands with cd.
 	`
 )
 
@@ -58,6 +61,10 @@ var bashSchema = &tool.JSONSchema{
 			"type":        "string",
 			"description": "Optional working directory relative to the sandbox root.",
 		},
+		"run_in_background": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Run the command in the background and return a shell_id immediately. Poll output with bash_output and stop it with kill_shell.",
+		},
 	},
 	Required: []string{"command"},
 }
@@ -72,6 +79,10 @@ type BashTool struct {
 
 	outputThresholdBytes int
 	openPipes            func(*exec.Cmd) (io.ReadCloser, io.ReadCloser, error)
+
+	shellMu    sync.Mutex
+	shells     *ShellManager
+	persistent bool
 }
 
 // NewBashTool builds a BashTool rooted at the current directory.
@@ -143,6 +154,9 @@ func (b *BashTool) AllowShellMetachars(allow bool) {
 func (b *BashTool) Name() string { return "bash" }
 
 func (b *BashTool) Description() string {
+	if b != nil && b.persistentEnabled() {
+		return bashDescript + bashPersistentNote
+	}
 	return bashDescript
 }
 
@@ -170,10 +184,20 @@ func (b *BashTool) Execute(ctx context.Context, params map[string]interface{}) (
 	if err != nil {
 		return nil, err
 	}
+	background, err := wantsBackground(params)
+	if err != nil {
+		return nil, err
+	}
+	if background {
+		return b.startBackground(ctx, command, workdir)
+	}
 	timeout, err := b.resolveTimeout(params)
 	if err != nil {
 		return nil, err
 	}
+	if b.persistentEnabled() {
+		return b.executePersistent(ctx, command, explicitWorkdir(params, workdir), timeout, nil)
+	}
 
 	execCtx := ctx
 	var cancel context.CancelFunc
@@ -0,0 +1,591 @@
+package toolbuiltin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const (
+	BashOutputName = "bash_output"
+	KillShellName  = "kill_shell"
+
+	// maxBackgroundOutputBytes bounds the unread output retained per stream;
+	// older bytes are dropped once a process outpaces its reader.
+	maxBackgroundOutputBytes = 1 << 20
+	backgroundKillGrace      = 2 * time.Second
+
+	bashOutputDescription = `Retrieves new output from a background bash shell started with run_in_background.
+Usage:
+- Returns only stdout/stderr produced since the previous bash_output call for the same shell, plus its current status.
+- Supply filter (a regular expression) to keep only matching lines; non-matching lines are discarded.
+- Use kill_shell to stop a shell that is no longer needed.`
+	killShellDescription = `Terminates a background bash shell (and its child processes) started with run_in_background.`
+)
+
+var errUnknownShell = errors.New("unknown shell")
+
+// ShellState reports the lifecycle of a background shell.
+type ShellState string
+
+const (
+	ShellRunning   ShellState = "running"
+	ShellCompleted ShellState = "completed"
+	ShellFailed    ShellState = "failed"
+	ShellKilled    ShellState = "killed"
+)
+
+// ShellOutput is the incremental output drained from a background shell.
+type ShellOutput struct {
+	ShellID      string
+	Command      string
+	State        ShellState
+	ExitCode     *int
+	Stdout       string
+	Stderr       string
+	DroppedBytes int64
+}
+
+// ShellInfo summarises a background shell without draining its output.
+type ShellInfo struct {
+	ShellID   string
+	Command   string
+	Workdir   string
+	PID       int
+	State     ShellState
+	StartedAt time.Time
+}
+
+type backgroundShell struct {
+	id        string
+	sessionID string
+	command   string
+	workdir   string
+	startedAt time.Time
+	cmd       *exec.Cmd
+	done      chan struct{}
+
+	mu       sync.Mutex
+	stdout   bytes.Buffer
+	stderr   bytes.Buffer
+	dropped  int64
+	state    ShellState
+	exitCode *int
+	killed   bool
+}
+
+func (s *backgroundShell) append(p []byte, isStderr bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	buf := &s.stdout
+	if isStderr {
+		buf = &s.stderr
+	}
+	buf.Write(p)
+	if over := buf.Len() - maxBackgroundOutputBytes; over > 0 {
+		buf.Next(over)
+		s.dropped += int64(over)
+	}
+}
+
+func (s *backgroundShell) drain(filter *regexp.Regexp) ShellOutput {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := ShellOutput{
+		ShellID:      s.id,
+		Command:      s.command,
+		State:        s.state,
+		Stdout:       filterLines(s.stdout.String(), filter),
+		Stderr:       filterLines(s.stderr.String(), filter),
+		DroppedBytes: s.dropped,
+	}
+	if s.exitCode != nil {
+		code := *s.exitCode
+		out.ExitCode = &code
+	}
+	s.stdout.Reset()
+	s.stderr.Reset()
+	s.dropped = 0
+	return out
+}
+
+func (s *backgroundShell) info() ShellInfo {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	info := ShellInfo{
+		ShellID:   s.id,
+		Command:   s.command,
+		Workdir:   s.workdir,
+		State:     s.state,
+		StartedAt: s.startedAt,
+	}
+	if s.cmd != nil && s.cmd.Process != nil {
+		info.PID = s.cmd.Process.Pid
+	}
+	return info
+}
+
+func (s *backgroundShell) wait() {
+	err := s.cmd.Wait()
+	s.mu.Lock()
+	code := 0
+	if s.cmd.ProcessState != nil {
+		code = s.cmd.ProcessState.ExitCode()
+	}
+	s.exitCode = &code
+	switch {
+	case s.killed:
+		s.state = ShellKilled
+	case err != nil:
+		s.state = ShellFailed
+	default:
+		s.state = ShellCompleted
+	}
+	s.mu.Unlock()
+	close(s.done)
+}
+
+func (s *backgroundShell) kill() {
+	s.mu.Lock()
+	running := s.state == ShellRunning
+	if running {
+		s.killed = true
+	}
+	s.mu.Unlock()
+	if !running {
+		return
+	}
+	terminateProcessTree(s.cmd)
+	select {
+	case <-s.done:
+		return
+	case <-time.After(backgroundKillGrace):
+	}
+	killProcessTree(s.cmd)
+	<-s.done
+}
+
+type shellStreamWriter struct {
+	shell    *backgroundShell
+	isStderr bool
+}
+
+func (w shellStreamWriter) Write(p []byte) (int, error) {
+	w.shell.append(p, w.isStderr)
+	return len(p), nil
+}
+
+// ShellManager keeps the per-session table of background shells started by
+// BashTool. It is safe for concurrent use.
+type ShellManager struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]*backgroundShell
+	nextID   uint64
+	closed   bool
+
+	// persistent holds the per-session shells used by BashTool when
+	// SetPersistentShell is enabled; persistMu serialises their startup.
+	persistMu  sync.Mutex
+	persistent map[string]*persistentShell
+}
+
+// NewShellManager builds an empty process table.
+func NewShellManager() *ShellManager {
+	return &ShellManager{sessions: map[string]map[string]*backgroundShell{}}
+}
+
+func (m *ShellManager) start(sessionID, command, workdir string) (*backgroundShell, error) {
+	if m == nil {
+		return nil, errors.New("shell manager is nil")
+	}
+	cmd := exec.Command("bash", "-c", command)
+	cmd.Env = os.Environ()
+	cmd.Dir = workdir
+	configureBackgroundCommand(cmd)
+
+	shell := &backgroundShell{
+		sessionID: sessionID,
+		command:   command,
+		workdir:   workdir,
+		cmd:       cmd,
+		done:      make(chan struct{}),
+		state:     ShellRunning,
+	}
+	cmd.Stdout = shellStreamWriter{shell: shell}
+	cmd.Stderr = shellStreamWriter{shell: shell, isStderr: true}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, errors.New("shell manager is closed")
+	}
+	m.nextID++
+	shell.id = fmt.Sprintf("shell-%d", m.nextID)
+	shell.startedAt = time.Now()
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start command: %w", err)
+	}
+	table := m.sessions[sessionID]
+	if table == nil {
+		table = map[string]*backgroundShell{}
+		m.sessions[sessionID] = table
+	}
+	table[shell.id] = shell
+	go shell.wait()
+	return shell, nil
+}
+
+func (m *ShellManager) lookup(sessionID, shellID string) (*backgroundShell, error) {
+	if m == nil {
+		return nil, fmt.Errorf("%w %q", errUnknownShell, shellID)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	shell := m.sessions[sessionID][shellID]
+	if shell == nil {
+		return nil, fmt.Errorf("%w %q", errUnknownShell, shellID)
+	}
+	return shell, nil
+}
+
+// Output drains new output of shellID owned by sessionID. A nil filter keeps
+// every line.
+func (m *ShellManager) Output(sessionID, shellID string, filter *regexp.Regexp) (ShellOutput, error) {
+	shell, err := m.lookup(sessionID, shellID)
+	if err != nil {
+		return ShellOutput{}, err
+	}
+	return shell.drain(filter), nil
+}
+
+// Kill terminates shellID and removes it from the session table.
+func (m *ShellManager) Kill(sessionID, shellID string) (ShellInfo, error) {
+	shell, err := m.lookup(sessionID, shellID)
+	if err != nil {
+		return ShellInfo{}, err
+	}
+	shell.kill()
+	m.mu.Lock()
+	if table := m.sessions[sessionID]; table != nil {
+		delete(table, shellID)
+		if len(table) == 0 {
+			delete(m.sessions, sessionID)
+		}
+	}
+	m.mu.Unlock()
+	return shell.info(), nil
+}
+
+// List reports the shells owned by sessionID ordered by start time.
+func (m *ShellManager) List(sessionID string) []ShellInfo {
+	if m == nil {
+		return nil
+	}
+	m.mu.Lock()
+	shells := make([]*backgroundShell, 0, len(m.sessions[sessionID]))
+	for _, shell := range m.sessions[sessionID] {
+		shells = append(shells, shell)
+	}
+	m.mu.Unlock()
+	infos := make([]ShellInfo, 0, len(shells))
+	for _, shell := range shells {
+		infos = append(infos, shell.info())
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].StartedAt.Before(infos[j].StartedAt) })
+	return infos
+}
+
+// KillSession terminates every shell owned by sessionID, including its
+// persistent shell.
+func (m *ShellManager) KillSession(sessionID string) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	table := m.sessions[sessionID]
+	delete(m.sessions, sessionID)
+	persistent := m.persistent[sessionID]
+	delete(m.persistent, sessionID)
+	m.mu.Unlock()
+	killShells(table)
+	persistent.close()
+}
+
+// Close terminates all shells and rejects further starts.
+func (m *ShellManager) Close() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.closed = true
+	sessions := m.sessions
+	m.sessions = map[string]map[string]*backgroundShell{}
+	persistent := m.persistent
+	m.persistent = nil
+	m.mu.Unlock()
+	for _, table := range sessions {
+		killShells(table)
+	}
+	for _, shell := range persistent {
+		shell.close()
+	}
+}
+
+func killShells(table map[string]*backgroundShell) {
+	var wg sync.WaitGroup
+	for _, shell := range table {
+		wg.Add(1)
+		go func(s *backgroundShell) {
+			defer wg.Done()
+			s.kill()
+		}(shell)
+	}
+	wg.Wait()
+}
+
+func filterLines(text string, filter *regexp.Regexp) string {
+	if filter == nil || text == "" {
+		return text
+	}
+	lines := strings.Split(text, "\n")
+	kept := lines[:0]
+	for _, line := range lines {
+		if filter.MatchString(line) {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "\n")
+}
+
+func parseShellID(params map[string]interface{}) (string, error) {
+	if params == nil {
+		return "", errors.New("params is nil")
+	}
+	raw, ok := params["shell_id"]
+	if !ok || raw == nil {
+		return "", errors.New("shell_id is required")
+	}
+	id, err := coerceString(raw)
+	if err != nil {
+		return "", fmt.Errorf("shell_id must be string: %w", err)
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", errors.New("shell_id cannot be empty")
+	}
+	return id, nil
+}
+
+func wantsBackground(params map[string]interface{}) (bool, error) {
+	raw, ok := params["run_in_background"]
+	if !ok || raw == nil {
+		return false, nil
+	}
+	background, err := coerceBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("run_in_background must be boolean: %w", err)
+	}
+	return background, nil
+}
+
+// SetShellManager shares the background process table with the bash_output
+// and kill_shell tools.
+func (b *BashTool) SetShellManager(m *ShellManager) {
+	if b == nil {
+		return
+	}
+	b.shellMu.Lock()
+	b.shells = m
+	b.shellMu.Unlock()
+}
+
+func (b *BashTool) shellManager() *ShellManager {
+	b.shellMu.Lock()
+	defer b.shellMu.Unlock()
+	if b.shells == nil {
+		b.shells = NewShellManager()
+	}
+	return b.shells
+}
+
+func (b *BashTool) startBackground(ctx context.Context, command, workdir string) (*tool.ToolResult, error) {
+	shell, err := b.shellManager().start(bashSessionID(ctx), command, workdir)
+	if err != nil {
+		return nil, err
+	}
+	info := shell.info()
+	return &tool.ToolResult{
+		Success: true,
+		Output:  fmt.Sprintf("Started background shell %s (pid %d). Use bash_output with this shell_id to read its output and kill_shell to stop it.", info.ShellID, info.PID),
+		Data: map[string]interface{}{
+			"shell_id": info.ShellID,
+			"pid":      info.PID,
+			"workdir":  workdir,
+		},
+	}, nil
+}
+
+var bashOutputSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"shell_id": map[string]interface{}{
+			"type":        "string",
+			"description": "Identifier returned by bash when run_in_background was set.",
+		},
+		"filter": map[string]interface{}{
+			"type":        "string",
+			"description": "Optional regular expression; only matching output lines are returned.",
+		},
+	},
+	Required: []string{"shell_id"},
+}
+
+var killShellSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"shell_id": map[string]interface{}{
+			"type":        "string",
+			"description": "Identifier of the background shell to terminate.",
+		},
+	},
+	Required: []string{"shell_id"},
+}
+
+// BashOutputTool reads incremental output from background shells.
+type BashOutputTool struct {
+	shells *ShellManager
+}
+
+// NewBashOutputTool builds a BashOutputTool over the shared process table.
+func NewBashOutputTool(shells *ShellManager) *BashOutputTool {
+	return &BashOutputTool{shells: shells}
+}
+
+func (t *BashOutputTool) Name() string { return BashOutputName }
+
+func (t *BashOutputTool) Description() string { return bashOutputDescription }
+
+func (t *BashOutputTool) Schema() *tool.JSONSchema { return bashOutputSchema }
+
+func (t *BashOutputTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if t == nil || t.shells == nil {
+		return nil, errors.New("bash_output tool is not initialised")
+	}
+	shellID, err := parseShellID(params)
+	if err != nil {
+		return nil, err
+	}
+	var filter *regexp.Regexp
+	if raw, ok := params["filter"]; ok && raw != nil {
+		pattern, err := coerceString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("filter must be string: %w", err)
+		}
+		if strings.TrimSpace(pattern) != "" {
+			if filter, err = regexp.Compile(pattern); err != nil {
+				return nil, fmt.Errorf("invalid filter: %w", err)
+			}
+		}
+	}
+	out, err := t.shells.Output(bashSessionID(ctx), shellID, filter)
+	if err != nil {
+		return nil, err
+	}
+	data := map[string]interface{}{
+		"shell_id": out.ShellID,
+		"state":    string(out.State),
+		"stdout":   out.Stdout,
+		"stderr":   out.Stderr,
+	}
+	if out.ExitCode != nil {
+		data["exit_code"] = *out.ExitCode
+	}
+	if out.DroppedBytes > 0 {
+		data["dropped_bytes"] = out.DroppedBytes
+	}
+	return &tool.ToolResult{Success: true, Output: formatShellOutput(out), Data: data}, nil
+}
+
+func formatShellOutput(out ShellOutput) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Shell %s: %s", out.ShellID, out.State)
+	if out.ExitCode != nil {
+		fmt.Fprintf(&b, " (exit code %d)", *out.ExitCode)
+	}
+	if out.DroppedBytes > 0 {
+		fmt.Fprintf(&b, "\n[%d bytes of earlier output were dropped]", out.DroppedBytes)
+	}
+	stdout := strings.TrimRight(out.Stdout, "\r\n")
+	stderr := strings.TrimRight(out.Stderr, "\r\n")
+	if stdout == "" && stderr == "" {
+		b.WriteString("\n(no new output)")
+		return b.String()
+	}
+	if stdout != "" {
+		b.WriteString("\n[stdout]\n")
+		b.WriteString(stdout)
+	}
+	if stderr != "" {
+		b.WriteString("\n[stderr]\n")
+		b.WriteString(stderr)
+	}
+	return b.String()
+}
+
+// KillShellTool terminates background shells.
+type KillShellTool struct {
+	shells *ShellManager
+}
+
+// NewKillShellTool builds a KillShellTool over the shared process table.
+func NewKillShellTool(shells *ShellManager) *KillShellTool {
+	return &KillShellTool{shells: shells}
+}
+
+func (t *KillShellTool) Name() string { return KillShellName }
+
+func (t *KillShellTool) Description() string { return killShellDescription }
+
+func (t *KillShellTool) Schema() *tool.JSONSchema { return killShellSchema }
+
+func (t *KillShellTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsDestructive: true}
+}
+
+func (t *KillShellTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if t == nil || t.shells == nil {
+		return nil, errors.New("kill_shell tool is not initialised")
+	}
+	shellID, err := parseShellID(params)
+	if err != nil {
+		return nil, err
+	}
+	info, err := t.shells.Kill(bashSessionID(ctx), shellID)
+	if err != nil {
+		return nil, err
+	}
+	return &tool.ToolResult{
+		Success: true,
+		Output:  fmt.Sprintf("Shell %s terminated (state: %s).", info.ShellID, info.State),
+		Data: map[string]interface{}{
+			"shell_id": info.ShellID,
+			"state":    string(info.State),
+		},
+	}, nil
+}
@@ -0,0 +1,165 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/middleware"
+)
+
+func startBackgroundShell(t *testing.T, bash *BashTool, ctx context.Context, command string) string {
+	t.Helper()
+	res, err := bash.Execute(ctx, map[string]interface{}{"command": command, "run_in_background": true})
+	if err != nil {
+		t.Fatalf("start background: %v", err)
+	}
+	data := res.Data.(map[string]interface{})
+	id, _ := data["shell_id"].(string)
+	if id == "" || data["pid"].(int) <= 0 {
+		t.Fatalf("unexpected start data %#v", data)
+	}
+	return id
+}
+
+func waitShellState(t *testing.T, shells *ShellManager, sessionID, shellID string, want ShellState) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		for _, info := range shells.List(sessionID) {
+			if info.ShellID == shellID && info.State == want {
+				return
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("shell %s did not reach %s: %+v", shellID, want, shells.List(sessionID))
+}
+
+func TestBashBackgroundIncrementalOutput(t *testing.T) {
+	skipIfWindows(t)
+	shells := NewShellManager()
+	t.Cleanup(shells.Close)
+	bash := NewBashToolWithRoot(cleanTempDir(t))
+	bash.AllowShellMetachars(true)
+	bash.SetShellManager(shells)
+	ctx := context.WithValue(context.Background(), middleware.SessionIDContextKey, "sess")
+
+	id := startBackgroundShell(t, bash, ctx, "echo ready; echo warn >&2; echo skip; exit 3")
+	waitShellState(t, shells, "sess", id, ShellFailed)
+
+	out := NewBashOutputTool(shells)
+	res, err := out.Execute(ctx, map[string]interface{}{"shell_id": id, "filter": "^(ready|warn)$"})
+	if err != nil {
+		t.Fatalf("bash_output: %v", err)
+	}
+	data := res.Data.(map[string]interface{})
+	if data["stdout"] != "ready" || data["stderr"] != "warn" || data["exit_code"] != 3 || data["state"] != "failed" {
+		t.Fatalf("unexpected data %#v", data)
+	}
+	if !strings.Contains(res.Output, "exit code 3") || strings.Contains(res.Output, "skip") {
+		t.Fatalf("unexpected output %q", res.Output)
+	}
+
+	res, err = out.Execute(ctx, map[string]interface{}{"shell_id": id})
+	if err != nil {
+		t.Fatalf("second read: %v", err)
+	}
+	if !strings.Contains(res.Output, "(no new output)") {
+		t.Fatalf("expected output to be consumed, got %q", res.Output)
+	}
+
+	if _, err := out.Execute(context.Background(), map[string]interface{}{"shell_id": id}); !errors.Is(err, errUnknownShell) {
+		t.Fatalf("shells must be session scoped, got %v", err)
+	}
+	if _, err := out.Execute(ctx, map[string]interface{}{"shell_id": id, "filter": "("}); err == nil {
+		t.Fatal("expected invalid filter error")
+	}
+}
+
+func TestKillShellTerminatesProcessTree(t *testing.T) {
+	skipIfWindows(t)
+	shells := NewShellManager()
+	t.Cleanup(shells.Close)
+	bash := NewBashToolWithRoot(cleanTempDir(t))
+	bash.AllowShellMetachars(true)
+	bash.SetShellManager(shells)
+	ctx := context.Background()
+
+	id := startBackgroundShell(t, bash, ctx, "sleep 30 & wait")
+	res, err := NewKillShellTool(shells).Execute(ctx, map[string]interface{}{"shell_id": id})
+	if err != nil {
+		t.Fatalf("kill_shell: %v", err)
+	}
+	if res.Data.(map[string]interface{})["state"] != "killed" {
+		t.Fatalf("unexpected kill result %#v", res.Data)
+	}
+	if len(shells.List(bashSessionID(ctx))) != 0 {
+		t.Fatal("killed shell should leave the session table")
+	}
+	if _, err := NewKillShellTool(shells).Execute(ctx, map[string]interface{}{"shell_id": id}); !errors.Is(err, errUnknownShell) {
+		t.Fatalf("expected unknown shell, got %v", err)
+	}
+	if _, err := NewKillShellTool(shells).Execute(ctx, map[string]interface{}{}); err == nil {
+		t.Fatal("expected missing shell_id error")
+	}
+}
+
+func TestShellManagerSessionCleanup(t *testing.T) {
+	skipIfWindows(t)
+	shells := NewShellManager()
+	bash := NewBashToolWithRoot(cleanTempDir(t))
+	bash.SetShellManager(shells)
+	ctxA := context.WithValue(context.Background(), middleware.SessionIDContextKey, "a")
+	ctxB := context.WithValue(context.Background(), middleware.SessionIDContextKey, "b")
+
+	startBackgroundShell(t, bash, ctxA, "sleep 30")
+	idB := startBackgroundShell(t, bash, ctxB, "sleep 30")
+
+	shells.KillSession("a")
+	if len(shells.List("a")) != 0 {
+		t.Fatal("session a should be empty")
+	}
+	if got := shells.List("b"); len(got) != 1 || got[0].ShellID != idB || got[0].State != ShellRunning {
+		t.Fatalf("session b should be untouched: %+v", got)
+	}
+
+	shells.Close()
+	if len(shells.List("b")) != 0 {
+		t.Fatal("close should drop every shell")
+	}
+	if _, err := bash.Execute(ctxB, map[string]interface{}{"command": "true", "run_in_background": true}); err == nil {
+		t.Fatal("expected start to fail after close")
+	}
+}
+
+func TestBashBackgroundDropsOldestOutput(t *testing.T) {
+	shell := &backgroundShell{id: "shell-1", state: ShellRunning}
+	chunk := strings.Repeat("x", maxBackgroundOutputBytes)
+	shell.append([]byte(chunk), false)
+	shell.append([]byte("tail"), false)
+
+	out := shell.drain(nil)
+	if out.DroppedBytes != 4 || len(out.Stdout) != maxBackgroundOutputBytes || !strings.HasSuffix(out.Stdout, "tail") {
+		t.Fatalf("unexpected drain dropped=%d len=%d", out.DroppedBytes, len(out.Stdout))
+	}
+	if !strings.Contains(formatShellOutput(out), "4 bytes of earlier output were dropped") {
+		t.Fatal("dropped bytes should be reported")
+	}
+	if got := filterLines("a\nb\nab\n", regexp.MustCompile("a")); got != "a\nab" {
+		t.Fatalf("filterLines = %q", got)
+	}
+}
+
+func TestBashBackgroundParamValidation(t *testing.T) {
+	bash := NewBashToolWithRoot(cleanTempDir(t))
+	if _, err := bash.Execute(context.Background(), map[string]interface{}{"command": "true", "run_in_background": "maybe"}); err == nil || !strings.Contains(err.Error(), "run_in_background must be boolean") {
+		t.Fatalf("expected boolean error, got %v", err)
+	}
+	if _, err := NewBashOutputTool(nil).Execute(context.Background(), map[string]interface{}{"shell_id": "shell-1"}); err == nil {
+		t.Fatal("expected error for nil manager")
+	}
+}
@@ -0,0 +1,36 @@
+//go:build !windows
+
+package toolbuiltin
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// configureBackgroundCommand places the shell in its own process group so the
+// whole tree can be signalled on kill.
+func configureBackgroundCommand(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+func terminateProcessTree(cmd *exec.Cmd) {
+	signalProcessGroup(cmd, syscall.SIGTERM)
+}
+
+// interruptProcessTree delivers SIGINT to the group, as Ctrl-C would.
+func interruptProcessTree(cmd *exec.Cmd) {
+	signalProcessGroup(cmd, syscall.SIGINT)
+}
+
+func killProcessTree(cmd *exec.Cmd) {
+	signalProcessGroup(cmd, syscall.SIGKILL)
+}
+
+func signalProcessGroup(cmd *exec.Cmd, sig syscall.Signal) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	if err := syscall.Kill(-cmd.Process.Pid, sig); err != nil {
+		_ = cmd.Process.Signal(sig)
+	}
+}
@@ -0,0 +1,30 @@
+//go:build windows
+
+package toolbuiltin
+
+import "os/exec"
+
+// configureBackgroundCommand bounds how long Wait blocks on pipes still held
+// by grandchildren, since only the direct child is killed on Windows.
+func configureBackgroundCommand(cmd *exec.Cmd) {
+	cmd.WaitDelay = backgroundKillGrace
+}
+
+// terminateProcessTree has no graceful equivalent on Windows; the process is
+// killed outright.
+func terminateProcessTree(cmd *exec.Cmd) {
+	killProcessTree(cmd)
+}
+
+// interruptProcessTree cannot deliver Ctrl-C to a console-less child on
+// Windows, so the process is killed outright.
+func interruptProcessTree(cmd *exec.Cmd) {
+	killProcessTree(cmd)
+}
+
+func killProcessTree(cmd *exec.Cmd) {
+	if cmd == nil || cmd.Process == nil {
+		return
+	}
+	_ = cmd.Process.Kill()
+}
@@ -0,0 +1,536 @@
+package toolbuiltin
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+// persistentInterruptGrace bounds how long a timed-out command may take to
+// unwind after SIGINT before the whole shell is killed.
+var persistentInterruptGrace = 2 * time.Second
+
+// persistentShell is a long-lived bash process that runs one command at a
+// time, so cwd, exported variables and sourced environments carry over
+// between calls. Commands are written to a file and sourced; each run ends
+// with a sentinel line on stdout (carrying $? and $PWD) and on stderr.
+type persistentShell struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	tmpDir string
+
+	runMu sync.Mutex // serialises commands
+	seq   int
+
+	mu      sync.Mutex
+	active  *persistentRun
+	exited  chan struct{} // closed once the bash process has exited
+	drained chan struct{} // closed once both output pipes hit EOF
+}
+
+// persistentRun tracks the framing state of one command.
+type persistentRun struct {
+	mu      sync.Mutex
+	stdout  *sentinelScanner
+	stderr  *sentinelScanner
+	done    chan struct{}
+	closed  bool
+	signals int
+}
+
+func startPersistentShell(dir string) (*persistentShell, error) {
+	tmpDir, err := os.MkdirTemp("", "agentsdk-shell-*")
+	if err != nil {
+		return nil, fmt.Errorf("create shell dir: %w", err)
+	}
+	stdoutR, stdoutW, err := os.Pipe()
+	if err != nil {
+		_ = os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("stdout pipe: %w", err)
+	}
+	stderrR, stderrW, err := os.Pipe()
+	if err != nil {
+		_ = os.RemoveAll(tmpDir)
+		closeAll(stdoutR, stdoutW)
+		return nil, fmt.Errorf("stderr pipe: %w", err)
+	}
+
+	cmd := exec.Command("bash", "--noprofile", "--norc")
+	cmd.Env = os.Environ()
+	cmd.Dir = dir
+	cmd.Stdout = stdoutW
+	cmd.Stderr = stderrW
+	configureBackgroundCommand(cmd)
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	// The child holds its own copies; readers see EOF once every process
+	// sharing the pipes (including background jobs) has exited.
+	closeAll(stdoutW, stderrW)
+	if err != nil {
+		closeAll(stdoutR, stderrR)
+		_ = os.RemoveAll(tmpDir)
+		return nil, fmt.Errorf("start shell: %w", err)
+	}
+
+	s := &persistentShell{
+		cmd:     cmd,
+		stdin:   stdin,
+		tmpDir:  tmpDir,
+		exited:  make(chan struct{}),
+		drained: make(chan struct{}),
+	}
+	var readers sync.WaitGroup
+	readers.Add(2)
+	go s.read(stdoutR, false, &readers)
+	go s.read(stderrR, true, &readers)
+	go func() {
+		readers.Wait()
+		close(s.drained)
+	}()
+	go func() {
+		_ = cmd.Wait()
+		close(s.exited)
+	}()
+
+	// The shell itself survives SIGINT; only the foreground job is interrupted.
+	if _, err := io.WriteString(stdin, "trap : INT\n"); err != nil {
+		s.close()
+		return nil, fmt.Errorf("initialise shell: %w", err)
+	}
+	return s, nil
+}
+
+func closeAll(files ...*os.File) {
+	for _, f := range files {
+		_ = f.Close()
+	}
+}
+
+func (s *persistentShell) read(r *os.File, isStderr bool, wg *sync.WaitGroup) {
+	defer wg.Done()
+	defer r.Close()
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			s.mu.Lock()
+			run := s.active
+			s.mu.Unlock()
+			// Output produced between commands (e.g. by background jobs)
+			// has no owner and is dropped.
+			if run != nil {
+				run.feed(buf[:n], isStderr)
+			}
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func (r *persistentRun) feed(p []byte, isStderr bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	scanner := r.stdout
+	if isStderr {
+		scanner = r.stderr
+	}
+	if scanner.write(p) {
+		r.signals++
+		if r.signals == 2 {
+			close(r.done)
+		}
+	}
+}
+
+// detach stops routing output to the run and flushes anything held back
+// while looking for the sentinel.
+func (r *persistentRun) detach() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed {
+		return
+	}
+	r.closed = true
+	r.stdout.flush()
+	r.stderr.flush()
+}
+
+func (s *persistentShell) alive() bool {
+	select {
+	case <-s.exited:
+		return false
+	default:
+		return true
+	}
+}
+
+// persistentResult describes how a command in a persistent shell finished.
+type persistentResult struct {
+	exitCode int
+	cwd      string
+	timedOut bool
+	// exited reports that the shell itself terminated (exit, set -e or a
+	// forced kill), so its state is gone.
+	exited bool
+}
+
+// run executes command in the shell, streaming output to stdout/stderr.
+// workdir, when set, is entered before the command runs.
+func (s *persistentShell) run(ctx context.Context, command, workdir string, timeout time.Duration, stdout, stderr func([]byte)) (persistentResult, error) {
+	s.runMu.Lock()
+	defer s.runMu.Unlock()
+	if !s.alive() {
+		return persistentResult{exited: true}, errors.New("shell has exited")
+	}
+
+	marker, err := newShellMarker()
+	if err != nil {
+		return persistentResult{}, err
+	}
+	s.seq++
+	script := filepath.Join(s.tmpDir, fmt.Sprintf("cmd-%d.sh", s.seq))
+	var body strings.Builder
+	// A trap set inside the sourced file turns SIGINT into an early return
+	// from the whole command instead of moving on to its next statement.
+	body.WriteString("trap 'trap : INT; return 130' INT\n")
+	if workdir != "" {
+		body.WriteString("cd -- " + shellQuote(workdir) + " || return\n")
+	}
+	body.WriteString(command)
+	body.WriteString("\n")
+	if err := os.WriteFile(script, []byte(body.String()), 0o600); err != nil {
+		return persistentResult{}, fmt.Errorf("write command: %w", err)
+	}
+	defer os.Remove(script)
+
+	run := &persistentRun{
+		stdout: &sentinelScanner{marker: []byte("\n" + marker), sink: stdout},
+		stderr: &sentinelScanner{marker: []byte("\n" + marker), sink: stderr},
+		done:   make(chan struct{}),
+	}
+	s.mu.Lock()
+	s.active = run
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		s.active = nil
+		s.mu.Unlock()
+		run.detach()
+	}()
+
+	line := fmt.Sprintf(". %s </dev/null; printf '\\n%%s %%d %%s\\n' %s \"$?\" \"$PWD\"; trap : INT; printf '\\n%%s\\n' %s >&2\n",
+		shellQuote(script), shellQuote(marker), shellQuote(marker))
+	if _, err := io.WriteString(s.stdin, line); err != nil {
+		return persistentResult{exited: !s.alive()}, fmt.Errorf("write to shell: %w", err)
+	}
+
+	var deadline <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+	var res persistentResult
+	select {
+	case <-run.done:
+	case <-s.exited:
+	case <-deadline:
+		res.timedOut = true
+	case <-ctx.Done():
+		res.timedOut = true
+	}
+	if res.timedOut {
+		interruptProcessTree(s.cmd)
+		select {
+		case <-run.done:
+		case <-s.exited:
+		case <-time.After(persistentInterruptGrace):
+			killProcessTree(s.cmd)
+			<-s.exited
+		}
+	}
+
+	select {
+	case <-run.done:
+		code, cwd := run.stdout.status()
+		res.exitCode = code
+		res.cwd = cwd
+		return res, nil
+	default:
+	}
+	// The shell died mid-command; give the readers a moment to deliver
+	// whatever it wrote before exiting.
+	select {
+	case <-s.drained:
+	case <-time.After(100 * time.Millisecond):
+	}
+	res.exited = true
+	res.exitCode = -1
+	if state := s.cmd.ProcessState; state != nil {
+		res.exitCode = state.ExitCode()
+	}
+	return res, nil
+}
+
+// close ends the shell, killing the process group if it does not exit on EOF.
+func (s *persistentShell) close() {
+	if s == nil {
+		return
+	}
+	_ = s.stdin.Close()
+	select {
+	case <-s.exited:
+	case <-time.After(backgroundKillGrace):
+		killProcessTree(s.cmd)
+		<-s.exited
+	}
+	// Background jobs started from the shell share its process group.
+	killProcessTree(s.cmd)
+	_ = os.RemoveAll(s.tmpDir)
+}
+
+func newShellMarker() (string, error) {
+	var buf [12]byte
+	if _, err := bashRandRead(buf[:]); err != nil {
+		return "", fmt.Errorf("generate marker: %w", err)
+	}
+	return "__AGENTSDK_DONE_" + hex.EncodeToString(buf[:]) + "__", nil
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// sentinelScanner forwards a stream to sink until marker appears, holding
+// back just enough bytes to recognise a marker split across reads. The rest
+// of the marker line is kept for status parsing.
+type sentinelScanner struct {
+	marker  []byte
+	sink    func([]byte)
+	pending []byte
+	found   bool
+	tail    []byte
+	done    bool
+}
+
+// write consumes p and reports whether the sentinel line just completed.
+func (s *sentinelScanner) write(p []byte) bool {
+	if s.done {
+		return false
+	}
+	if s.found {
+		s.tail = append(s.tail, p...)
+		if idx := bytes.IndexByte(s.tail, '\n'); idx >= 0 {
+			s.tail = s.tail[:idx]
+			s.done = true
+			return true
+		}
+		return false
+	}
+	s.pending = append(s.pending, p...)
+	if idx := bytes.Index(s.pending, s.marker); idx >= 0 {
+		s.emit(s.pending[:idx])
+		rest := append([]byte(nil), s.pending[idx+len(s.marker):]...)
+		s.pending = nil
+		s.found = true
+		return s.write(rest)
+	}
+	if keep := len(s.marker) - 1; len(s.pending) > keep {
+		cut := len(s.pending) - keep
+		s.emit(s.pending[:cut])
+		s.pending = append([]byte(nil), s.pending[cut:]...)
+	}
+	return false
+}
+
+func (s *sentinelScanner) flush() {
+	if !s.found {
+		s.emit(s.pending)
+		s.pending = nil
+	}
+}
+
+func (s *sentinelScanner) emit(p []byte) {
+	if len(p) > 0 && s.sink != nil {
+		s.sink(p)
+	}
+}
+
+// status parses " <exit code> <cwd>" following the stdout marker.
+func (s *sentinelScanner) status() (int, string) {
+	code, cwd, _ := strings.Cut(strings.TrimPrefix(string(s.tail), " "), " ")
+	n, err := strconv.Atoi(code)
+	if err != nil {
+		n = -1
+	}
+	return n, cwd
+}
+
+// persistentShell returns the session's shell, starting one in dir when none
+// is running. restarted reports that a previous shell had exited.
+func (m *ShellManager) persistentShell(sessionID, dir string) (shell *persistentShell, restarted bool, err error) {
+	if m == nil {
+		return nil, false, errors.New("shell manager is nil")
+	}
+	m.persistMu.Lock()
+	defer m.persistMu.Unlock()
+	m.mu.Lock()
+	closed := m.closed
+	existing := m.persistent[sessionID]
+	m.mu.Unlock()
+	if closed {
+		return nil, false, errors.New("shell manager is closed")
+	}
+	if existing != nil {
+		if existing.alive() {
+			return existing, false, nil
+		}
+		existing.close()
+		restarted = true
+	}
+	shell, err = startPersistentShell(dir)
+	if err != nil {
+		return nil, false, err
+	}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		shell.close()
+		return nil, false, errors.New("shell manager is closed")
+	}
+	if m.persistent == nil {
+		m.persistent = map[string]*persistentShell{}
+	}
+	m.persistent[sessionID] = shell
+	m.mu.Unlock()
+	return shell, restarted, nil
+}
+
+// SetPersistentShell makes foreground commands run in a long-lived bash
+// process per session, so cd, export and sourced environments persist
+// between calls. Background commands are unaffected.
+func (b *BashTool) SetPersistentShell(enabled bool) {
+	if b == nil {
+		return
+	}
+	b.shellMu.Lock()
+	b.persistent = enabled
+	b.shellMu.Unlock()
+}
+
+func (b *BashTool) persistentEnabled() bool {
+	b.shellMu.Lock()
+	defer b.shellMu.Unlock()
+	return b.persistent
+}
+
+func (b *BashTool) executePersistent(ctx context.Context, command, workdir string, timeout time.Duration, emit func(chunk string, isStderr bool)) (*tool.ToolResult, error) {
+	shell, restarted, err := b.shellManager().persistentShell(bashSessionID(ctx), b.root)
+	if err != nil {
+		return nil, err
+	}
+
+	spool := newBashOutputSpool(ctx, b.effectiveOutputThresholdBytes())
+	sink := func(w io.Writer, isStderr bool) func([]byte) {
+		return func(p []byte) {
+			_, _ = w.Write(p)
+			if emit != nil {
+				emit(string(p), isStderr)
+			}
+		}
+	}
+	start := time.Now()
+	res, runErr := shell.run(ctx, command, workdir, timeout, sink(spool.StdoutWriter(), false), sink(spool.StderrWriter(), true))
+	duration := time.Since(start)
+	output, outputFile, spoolErr := spool.Finalize()
+
+	cwd := res.cwd
+	if cwd == "" {
+		cwd = workdir
+	}
+	// The shell's cwd carries over to later calls, so a cd out of the
+	// sandbox is undone before anything else can run there.
+	var escapeErr error
+	if runErr == nil && !res.exited && res.cwd != "" && b.policy != nil {
+		if err := b.policy.Validate(res.cwd); err != nil {
+			escapeErr = fmt.Errorf("command left the sandbox (%s); the shell was returned to %s", res.cwd, b.root)
+			if back, err := shell.run(ctx, ":", b.root, timeout, func([]byte) {}, func([]byte) {}); err != nil || back.exitCode != 0 || back.exited {
+				escapeErr = fmt.Errorf("command left the sandbox (%s) and the shell could not return to %s", res.cwd, b.root)
+			}
+			cwd = b.root
+		}
+	}
+	data := map[string]interface{}{
+		"workdir":     cwd,
+		"duration_ms": duration.Milliseconds(),
+		"timeout_ms":  timeout.Milliseconds(),
+		"persistent":  true,
+		"exit_code":   res.exitCode,
+	}
+	if restarted {
+		data["shell_restarted"] = true
+	}
+	if res.exited {
+		data["shell_exited"] = true
+	}
+	if outputFile != "" {
+		data["output_file"] = outputFile
+	}
+	if spoolErr != nil {
+		data["spool_error"] = spoolErr.Error()
+	}
+	result := &tool.ToolResult{
+		Success: runErr == nil && escapeErr == nil && !res.timedOut && !res.exited && res.exitCode == 0,
+		Output:  output,
+		Data:    data,
+	}
+
+	switch {
+	case runErr != nil:
+		return result, fmt.Errorf("command failed: %w", runErr)
+	case escapeErr != nil:
+		return result, escapeErr
+	case res.timedOut && ctx.Err() != nil:
+		return result, fmt.Errorf("command failed: %w", ctx.Err())
+	case res.timedOut && res.exited:
+		return result, fmt.Errorf("command timeout after %s; the shell was restarted and its state was lost", timeout)
+	case res.timedOut:
+		return result, fmt.Errorf("command timeout after %s", timeout)
+	case res.exited:
+		return result, fmt.Errorf("command failed: shell exited with status %d; its state was lost", res.exitCode)
+	case res.exitCode != 0:
+		return result, fmt.Errorf("command failed: exit status %d", res.exitCode)
+	}
+	return result, nil
+}
+
+// explicitWorkdir reports the workdir parameter when the caller supplied one;
+// persistent shells otherwise stay in their current directory.
+func explicitWorkdir(params map[string]interface{}, resolved string) string {
+	raw, ok := params["workdir"]
+	if !ok || raw == nil {
+		return ""
+	}
+	if value, err := coerceString(raw); err != nil || strings.TrimSpace(value) == "" {
+		return ""
+	}
+	return resolved
+}
@@ -0,0 +1,206 @@
+package toolbuiltin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/middleware"
+)
+
+func newPersistentBash(t *testing.T) (*BashTool, *ShellManager, string) {
+	t.Helper()
+	root := cleanTempDir(t)
+	shells := NewShellManager()
+	t.Cleanup(shells.Close)
+	bash := NewBashToolWithRoot(root)
+	bash.AllowShellMetachars(true)
+	bash.SetShellManager(shells)
+	bash.SetPersistentShell(true)
+	return bash, shells, root
+}
+
+func sessionCtx(id string) context.Context {
+	return context.WithValue(context.Background(), middleware.SessionIDContextKey, id)
+}
+
+func TestPersistentShellKeepsState(t *testing.T) {
+	skipIfWindows(t)
+	bash, _, root := newPersistentBash(t)
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	ctx := sessionCtx("s")
+
+	if _, err := bash.Execute(ctx, map[string]interface{}{"command": "cd sub && export GREETING=hello && alias ll='ls -l'"}); err != nil {
+		t.Fatalf("setup: %v", err)
+	}
+	res, err := bash.Execute(ctx, map[string]interface{}{"command": "pwd; printf %s \"$GREETING\"; echo oops >&2"})
+	if err != nil {
+		t.Fatalf("second command: %v", err)
+	}
+	want := filepath.Join(root, "sub") + "\nhello\noops"
+	if res.Output != want {
+		t.Fatalf("output = %q, want %q", res.Output, want)
+	}
+	data := res.Data.(map[string]interface{})
+	if data["workdir"] != filepath.Join(root, "sub") || data["persistent"] != true || data["exit_code"] != 0 {
+		t.Fatalf("unexpected data %#v", data)
+	}
+
+	res, err = bash.Execute(ctx, map[string]interface{}{"command": "cat; false"})
+	if err == nil || !strings.Contains(err.Error(), "exit status 1") || res.Success {
+		t.Fatalf("expected failure without blocking on stdin, got %v", err)
+	}
+
+	res, err = bash.Execute(ctx, map[string]interface{}{"command": "pwd", "workdir": "."})
+	if err != nil || res.Output != root {
+		t.Fatalf("explicit workdir should cd, got %q, %v", res.Output, err)
+	}
+	if res, _ := bash.Execute(ctx, map[string]interface{}{"command": "pwd"}); res.Output != root {
+		t.Fatalf("workdir change should persist, got %q", res.Output)
+	}
+}
+
+func TestPersistentShellStaysInSandbox(t *testing.T) {
+	skipIfWindows(t)
+	bash, _, root := newPersistentBash(t)
+	ctx := sessionCtx("s")
+
+	for _, command := range []string{"cd /", "cd ../.."} {
+		res, err := bash.Execute(ctx, map[string]interface{}{"command": command})
+		if err == nil || !strings.Contains(err.Error(), "left the sandbox") || res.Success {
+			t.Fatalf("%s: expected sandbox escape error, got %v", command, err)
+		}
+		if data := res.Data.(map[string]interface{}); data["workdir"] != root {
+			t.Fatalf("%s: expected workdir reset to root, got %#v", command, data)
+		}
+		res, err = bash.Execute(ctx, map[string]interface{}{"command": "pwd"})
+		if err != nil || res.Output != root {
+			t.Fatalf("%s: later commands must run in the root, got %q, %v", command, res.Output, err)
+		}
+	}
+}
+
+func TestPersistentShellTimeoutInterruptsForegroundJob(t *testing.T) {
+	skipIfWindows(t)
+	restore := persistentInterruptGrace
+	persistentInterruptGrace = 300 * time.Millisecond
+	t.Cleanup(func() { persistentInterruptGrace = restore })
+	bash, _, _ := newPersistentBash(t)
+	ctx := sessionCtx("s")
+
+	if _, err := bash.Execute(ctx, map[string]interface{}{"command": "export KEEP=1"}); err != nil {
+		t.Fatalf("export: %v", err)
+	}
+	start := time.Now()
+	res, err := bash.Execute(ctx, map[string]interface{}{"command": "echo before; sleep 30; echo after", "timeout": 0.3})
+	if err == nil || !strings.Contains(err.Error(), "command timeout") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatalf("interrupt took %s", time.Since(start))
+	}
+	data := res.Data.(map[string]interface{})
+	if res.Output != "before" || data["exit_code"] != 130 || data["shell_exited"] != nil {
+		t.Fatalf("unexpected interrupted result %q %#v", res.Output, data)
+	}
+	if res, err := bash.Execute(ctx, map[string]interface{}{"command": "echo $KEEP"}); err != nil || res.Output != "1" {
+		t.Fatalf("state should survive an interrupt, got %q, %v", res.Output, err)
+	}
+
+	if _, err := bash.Execute(ctx, map[string]interface{}{"command": "while :; do :; done", "timeout": 0.2}); err == nil || strings.Contains(err.Error(), "state was lost") {
+		t.Fatalf("builtin loops should be interrupted in place, got %v", err)
+	}
+	// A command that ignores SIGINT outlives the grace period, so the shell
+	// is replaced.
+	_, err = bash.Execute(ctx, map[string]interface{}{"command": "trap '' INT; sleep 30", "timeout": 0.2})
+	if err == nil || !strings.Contains(err.Error(), "state was lost") {
+		t.Fatalf("expected shell restart error, got %v", err)
+	}
+	res, err = bash.Execute(ctx, map[string]interface{}{"command": "echo \"[$KEEP]\""})
+	if err != nil || res.Output != "[]" || res.Data.(map[string]interface{})["shell_restarted"] != true {
+		t.Fatalf("expected fresh shell, got %q %#v %v", res.Output, res.Data, err)
+	}
+}
+
+func TestPersistentShellExitAndSessions(t *testing.T) {
+	skipIfWindows(t)
+	bash, shells, root := newPersistentBash(t)
+	ctxA, ctxB := sessionCtx("a"), sessionCtx("b")
+
+	res, err := bash.Execute(ctxA, map[string]interface{}{"command": "echo bye; exit 5"})
+	if err == nil || !strings.Contains(err.Error(), "shell exited with status 5") || res.Output != "bye" {
+		t.Fatalf("expected shell exit, got %q, %v", res.Output, err)
+	}
+
+	if _, err := bash.Execute(ctxA, map[string]interface{}{"command": "mkdir sub && cd sub"}); err != nil {
+		t.Fatalf("cd: %v", err)
+	}
+	if res, _ := bash.Execute(ctxB, map[string]interface{}{"command": "pwd"}); res.Output != root {
+		t.Fatalf("sessions must not share a shell, got %q", res.Output)
+	}
+
+	shells.mu.Lock()
+	shellA := shells.persistent["a"]
+	shells.mu.Unlock()
+	shells.KillSession("a")
+	if shellA == nil || shellA.alive() {
+		t.Fatal("KillSession should stop the persistent shell")
+	}
+	if res, _ := bash.Execute(ctxA, map[string]interface{}{"command": "pwd"}); res.Output != root {
+		t.Fatalf("new session shell should start at the root, got %q", res.Output)
+	}
+
+	shells.Close()
+	if _, err := bash.Execute(ctxB, map[string]interface{}{"command": "true"}); err == nil {
+		t.Fatal("expected execution to fail after close")
+	}
+}
+
+func TestPersistentShellStreamExecute(t *testing.T) {
+	skipIfWindows(t)
+	bash, _, _ := newPersistentBash(t)
+	ctx := sessionCtx("s")
+	var stdout, stderr strings.Builder
+	res, err := bash.StreamExecute(ctx, map[string]interface{}{"command": "export X=1; echo out; echo err >&2"}, func(chunk string, isStderr bool) {
+		if isStderr {
+			stderr.WriteString(chunk)
+		} else {
+			stdout.WriteString(chunk)
+		}
+	})
+	if err != nil {
+		t.Fatalf("stream: %v", err)
+	}
+	if stdout.String() != "out\n" || stderr.String() != "err\n" || res.Output != "out\nerr" {
+		t.Fatalf("unexpected stream %q %q %q", stdout.String(), stderr.String(), res.Output)
+	}
+	if res, _ := bash.Execute(ctx, map[string]interface{}{"command": "echo $X"}); res.Output != "1" {
+		t.Fatalf("stream and execute should share the shell, got %q", res.Output)
+	}
+}
+
+func TestSentinelScannerSplitReads(t *testing.T) {
+	var got strings.Builder
+	s := &sentinelScanner{marker: []byte("\n__MARK__"), sink: func(p []byte) { got.Write(p) }}
+	input := "line one\nno newline\n__MA" + "RK__ 7 /tmp/a b\nleftover"
+	var done bool
+	for i := 0; i < len(input); i += 3 {
+		if s.write([]byte(input[i:min(i+3, len(input))])) {
+			done = true
+		}
+	}
+	if !done {
+		t.Fatal("sentinel not detected")
+	}
+	if got.String() != "line one\nno newline" {
+		t.Fatalf("unexpected forwarded output %q", got.String())
+	}
+	if code, cwd := s.status(); code != 7 || cwd != "/tmp/a b" {
+		t.Fatalf("unexpected status %d %q", code, cwd)
+	}
+}
@@ -48,10 +48,20 @@ func (b *BashTool) StreamExecute(ctx context.Context, params map[string]interfac
 	if err != nil {
 		return nil, err
 	}
+	background, err := wantsBackground(params)
+	if err != nil {
+		return nil, err
+	}
+	if background {
+		return b.startBackground(ctx, command, workdir)
+	}
 	timeout, err := b.resolveTimeout(params)
 	if err != nil {
 		return nil, err
 	}
+	if b.persistentEnabled() {
+		return b.executePersistent(ctx, command, explicitWorkdir(params, workdir), timeout, emit)
+	}
 
 	execCtx := ctx
 	var cancel context.CancelFunc
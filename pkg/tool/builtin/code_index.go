@@ -0,0 +1,692 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stellarlinkco/agentsdk-go/pkg/gitignore"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+// maxIndexedFileBytes bounds the files whose content is indexed; larger ones
+// are always treated as candidates.
+const maxIndexedFileBytes = 4 << 20
+
+// CodeIndex is an in-memory trigram index over the files under a root. It
+// only narrows candidates: grep still runs the regexp over every candidate
+// and any file the index has no fresh record of is searched as before, so
+// results are identical with or without it. Paths ignored by the root
+// .gitignore are not indexed. The index follows changes through a
+// tool.TreeWatcher, shared with other consumers via SetTreeWatcher, and is
+// rebuilt when the watcher reports an error such as an event overflow.
+type CodeIndex struct {
+	root    string
+	matcher *gitignore.Matcher
+
+	startOnce   sync.Once
+	closeOnce   sync.Once
+	ready       chan struct{}
+	done        chan struct{}
+	tree        *tool.TreeWatcher
+	ownsTree    bool
+	unsubscribe func()
+	wg          sync.WaitGroup
+
+	// pending queues watcher events until the watch loop applies them, so
+	// the shared watcher never waits for a build.
+	eventsMu sync.Mutex
+	pending  []tool.TreeEvent
+	wake     chan struct{}
+	// rebuilding is set while the index is rebuilt after the watcher lost
+	// events; queries fall back to unindexed behaviour meanwhile.
+	rebuilding atomic.Bool
+
+	mu       sync.RWMutex
+	files    map[string]*indexedFile
+	dirs     map[string]time.Time
+	postings map[uint32][]uint32
+	nextID   uint32
+	dead     int
+}
+
+type indexedFile struct {
+	id      uint32
+	size    int64
+	modTime time.Time
+	// indexed is false for symlinks and oversized or unreadable files,
+	// which are listed but never excluded.
+	indexed bool
+	// linkDir marks symlinks to directories, which filepath.Glob follows
+	// but the index does not.
+	linkDir bool
+}
+
+// NewCodeIndex builds an empty index for root; call Start to populate it.
+func NewCodeIndex(root string) *CodeIndex {
+	resolved := resolveRoot(root)
+	matcher, _ := gitignore.NewMatcher(resolved) //nolint:errcheck // best-effort gitignore
+	return &CodeIndex{
+		root:     resolved,
+		matcher:  matcher,
+		ready:    make(chan struct{}),
+		done:     make(chan struct{}),
+		wake:     make(chan struct{}, 1),
+		files:    map[string]*indexedFile{},
+		dirs:     map[string]time.Time{},
+		postings: map[uint32][]uint32{},
+	}
+}
+
+// Root reports the indexed directory.
+func (x *CodeIndex) Root() string {
+	if x == nil {
+		return ""
+	}
+	return x.root
+}
+
+// SetTreeWatcher makes the index follow changes through tree instead of a
+// watcher of its own. Call it before Start; Close leaves tree open. Watchers
+// of a different root are ignored.
+func (x *CodeIndex) SetTreeWatcher(tree *tool.TreeWatcher) {
+	if x != nil && tree != nil && tree.Root() == x.root {
+		x.tree = tree
+	}
+}
+
+// Start builds the index in the background and begins watching for changes.
+// Queries made before the build finishes fall back to unindexed behaviour.
+func (x *CodeIndex) Start() {
+	if x == nil {
+		return
+	}
+	x.startOnce.Do(func() {
+		if x.tree == nil {
+			if tree, err := tool.NewTreeWatcher(x.root); err == nil {
+				x.tree, x.ownsTree = tree, true
+			}
+		}
+		if x.tree != nil {
+			x.unsubscribe = x.tree.Subscribe(x.enqueue)
+		}
+		x.wg.Add(1)
+		go func() {
+			defer x.wg.Done()
+			x.addTree(x.root)
+			close(x.ready)
+			if x.tree != nil {
+				x.watch()
+			}
+		}()
+	})
+}
+
+func (x *CodeIndex) enqueue(event tool.TreeEvent) {
+	x.eventsMu.Lock()
+	x.pending = append(x.pending, event)
+	x.eventsMu.Unlock()
+	select {
+	case x.wake <- struct{}{}:
+	default:
+	}
+}
+
+// WaitReady blocks until the initial build completes.
+func (x *CodeIndex) WaitReady(ctx context.Context) error {
+	if x == nil {
+		return errors.New("code index is nil")
+	}
+	if x.closed() {
+		return errors.New("code index is closed")
+	}
+	select {
+	case <-x.ready:
+		return nil
+	case <-x.done:
+		return errors.New("code index is closed")
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (x *CodeIndex) isReady() bool {
+	if x == nil || x.rebuilding.Load() {
+		return false
+	}
+	select {
+	case <-x.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close stops watching and releases the index.
+func (x *CodeIndex) Close() error {
+	if x == nil {
+		return nil
+	}
+	var err error
+	x.closeOnce.Do(func() {
+		close(x.done)
+		if x.unsubscribe != nil {
+			x.unsubscribe()
+		}
+		if x.ownsTree {
+			err = x.tree.Close()
+		}
+		x.wg.Wait()
+		x.mu.Lock()
+		x.files, x.dirs, x.postings = map[string]*indexedFile{}, map[string]time.Time{}, map[uint32][]uint32{}
+		x.mu.Unlock()
+	})
+	return err
+}
+
+func (x *CodeIndex) closed() bool {
+	select {
+	case <-x.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (x *CodeIndex) ignored(path string, isDir bool) bool {
+	if path == x.root {
+		return false
+	}
+	rel, err := filepath.Rel(x.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return true
+	}
+	return x.matcher != nil && x.matcher.Match(rel, isDir)
+}
+
+type indexedContent struct {
+	path    string
+	info    fs.FileInfo
+	grams   []uint32
+	ok      bool
+	linkDir bool
+}
+
+// addTree indexes every file below dir, hashing contents on all CPUs.
+func (x *CodeIndex) addTree(dir string) {
+	paths := make(chan string, 256)
+	results := make(chan indexedContent, 256)
+	var workers sync.WaitGroup
+	for i := 0; i < runtime.GOMAXPROCS(0); i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			scratch := newTrigramScratch()
+			for path := range paths {
+				results <- scratch.load(path)
+			}
+		}()
+	}
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+	go func() {
+		defer close(paths)
+		_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || x.closed() {
+				if d != nil && d.IsDir() && path != dir {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if x.ignored(path, d.IsDir()) {
+				if d.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				if info, err := d.Info(); err == nil {
+					x.mu.Lock()
+					x.dirs[path] = info.ModTime()
+					x.mu.Unlock()
+				}
+				return nil
+			}
+			paths <- path
+			return nil
+		})
+	}()
+	for res := range results {
+		x.store(res)
+	}
+}
+
+func (x *CodeIndex) store(res indexedContent) {
+	if res.info == nil {
+		return
+	}
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.dropLocked(res.path)
+	x.nextID++
+	entry := &indexedFile{id: x.nextID, size: res.info.Size(), modTime: res.info.ModTime(), indexed: res.ok, linkDir: res.linkDir}
+	x.files[res.path] = entry
+	for _, g := range res.grams {
+		x.postings[g] = append(x.postings[g], entry.id)
+	}
+	x.compactLocked()
+}
+
+// dropLocked forgets path; its postings are purged lazily by compaction.
+func (x *CodeIndex) dropLocked(path string) {
+	if old := x.files[path]; old != nil {
+		delete(x.files, path)
+		x.dead++
+	}
+}
+
+func (x *CodeIndex) removeTree(path string) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	prefix := path + string(filepath.Separator)
+	x.dropLocked(path)
+	for p := range x.files {
+		if strings.HasPrefix(p, prefix) {
+			x.dropLocked(p)
+		}
+	}
+	delete(x.dirs, path)
+	for d := range x.dirs {
+		if strings.HasPrefix(d, prefix) {
+			delete(x.dirs, d)
+		}
+	}
+	x.compactLocked()
+}
+
+// compactLocked purges postings of dropped files once they outnumber the
+// live ones.
+func (x *CodeIndex) compactLocked() {
+	if x.dead < 1024 || x.dead < len(x.files) {
+		return
+	}
+	live := make(map[uint32]struct{}, len(x.files))
+	for _, f := range x.files {
+		live[f.id] = struct{}{}
+	}
+	for g, ids := range x.postings {
+		kept := ids[:0]
+		for _, id := range ids {
+			if _, ok := live[id]; ok {
+				kept = append(kept, id)
+			}
+		}
+		if len(kept) == 0 {
+			delete(x.postings, g)
+		} else {
+			x.postings[g] = kept
+		}
+	}
+	x.dead = 0
+}
+
+// refresh re-indexes path (file or directory) after a change.
+func (x *CodeIndex) refresh(path string) {
+	info, err := os.Lstat(path)
+	if err != nil {
+		x.removeTree(path)
+		return
+	}
+	if x.ignored(path, info.IsDir()) {
+		return
+	}
+	if info.IsDir() {
+		x.mu.RLock()
+		_, known := x.dirs[path]
+		x.mu.RUnlock()
+		if !known {
+			x.addTree(path)
+		}
+		return
+	}
+	scratch := trigramScratchPool.Get().(*trigramScratch)
+	defer trigramScratchPool.Put(scratch)
+	x.store(scratch.load(path))
+}
+
+func (x *CodeIndex) watch() {
+	for {
+		select {
+		case <-x.done:
+			return
+		case <-x.wake:
+		}
+		x.eventsMu.Lock()
+		events := x.pending
+		x.pending = nil
+		x.eventsMu.Unlock()
+	apply:
+		for _, event := range events {
+			if x.closed() {
+				return
+			}
+			switch {
+			case event.Err != nil:
+				// Changes may have gone unreported; the rebuild also covers
+				// the rest of this batch.
+				x.rebuild()
+				break apply
+			case event.Op&(fsnotify.Remove|fsnotify.Rename) != 0:
+				x.removeTree(event.Path)
+			case event.Op&(fsnotify.Create|fsnotify.Write) != 0:
+				x.refresh(event.Path)
+			}
+		}
+	}
+}
+
+// rebuild discards the index and builds it again from disk.
+func (x *CodeIndex) rebuild() {
+	x.rebuilding.Store(true)
+	defer x.rebuilding.Store(false)
+	x.mu.Lock()
+	// nextID keeps counting so filters issued earlier treat rebuilt entries
+	// as unknown.
+	x.files, x.dirs, x.postings = map[string]*indexedFile{}, map[string]time.Time{}, map[uint32][]uint32{}
+	x.dead = 0
+	x.mu.Unlock()
+	x.addTree(x.root)
+}
+
+// syncDirs re-scans directories under prefix whose listing changed since
+// they were indexed, covering events the watcher has not delivered yet.
+func (x *CodeIndex) syncDirs(prefix string) {
+	x.mu.RLock()
+	var stale []string
+	for dir, modTime := range x.dirs {
+		if dir != prefix && !strings.HasPrefix(dir, prefix+string(filepath.Separator)) {
+			continue
+		}
+		info, err := os.Lstat(dir)
+		if err != nil || !info.IsDir() || !info.ModTime().Equal(modTime) {
+			stale = append(stale, dir)
+		}
+	}
+	x.mu.RUnlock()
+	for _, dir := range stale {
+		x.rescanDir(dir)
+	}
+}
+
+func (x *CodeIndex) rescanDir(dir string) {
+	info, err := os.Lstat(dir)
+	if err != nil || !info.IsDir() {
+		x.removeTree(dir)
+		return
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+	present := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		present[path] = true
+		x.mu.RLock()
+		_, isFile := x.files[path]
+		_, isDir := x.dirs[path]
+		x.mu.RUnlock()
+		if !isFile && !isDir {
+			x.refresh(path)
+		}
+	}
+	x.mu.RLock()
+	var gone []string
+	for path := range x.childrenLocked(dir) {
+		if !present[path] {
+			gone = append(gone, path)
+		}
+	}
+	x.mu.RUnlock()
+	for _, path := range gone {
+		x.removeTree(path)
+	}
+	x.mu.Lock()
+	x.dirs[dir] = info.ModTime()
+	x.mu.Unlock()
+}
+
+func (x *CodeIndex) childrenLocked(dir string) map[string]bool {
+	children := map[string]bool{}
+	for path := range x.files {
+		if filepath.Dir(path) == dir {
+			children[path] = true
+		}
+	}
+	for path := range x.dirs {
+		if path != dir && filepath.Dir(path) == dir {
+			children[path] = true
+		}
+	}
+	return children
+}
+
+// Candidates returns a filter reporting whether the file at path may contain
+// a match for pattern (a Go regexp). It returns nil when the index cannot
+// narrow the search. Files the index has no current record of always pass.
+func (x *CodeIndex) Candidates(pattern string) func(path string) bool {
+	if !x.isReady() {
+		return nil
+	}
+	query := planTrigramQuery(pattern)
+	if query == nil {
+		return nil
+	}
+	x.mu.RLock()
+	ids := x.evalLocked(query)
+	maxID := x.nextID
+	x.mu.RUnlock()
+	return func(path string) bool {
+		info, err := os.Lstat(path)
+		if err != nil {
+			return true
+		}
+		x.mu.RLock()
+		entry := x.files[path]
+		x.mu.RUnlock()
+		if entry == nil || !entry.indexed || entry.id > maxID ||
+			entry.size != info.Size() || !entry.modTime.Equal(info.ModTime()) {
+			return true
+		}
+		_, found := slices.BinarySearch(ids, entry.id)
+		return found
+	}
+}
+
+// evalLocked returns the sorted ids satisfying query.
+func (x *CodeIndex) evalLocked(q *trigramQuery) []uint32 {
+	if q.or {
+		var out []uint32
+		for _, sub := range q.subs {
+			out = unionIDs(out, x.evalLocked(sub))
+		}
+		return out
+	}
+	var out []uint32
+	first := true
+	for _, g := range q.grams {
+		out = x.intersect(out, x.postings[g], &first)
+		if !first && len(out) == 0 {
+			return nil
+		}
+	}
+	for _, sub := range q.subs {
+		out = x.intersect(out, x.evalLocked(sub), &first)
+		if !first && len(out) == 0 {
+			return nil
+		}
+	}
+	return out
+}
+
+func (x *CodeIndex) intersect(acc, ids []uint32, first *bool) []uint32 {
+	if *first {
+		*first = false
+		return slices.Clone(ids)
+	}
+	out := acc[:0]
+	i, j := 0, 0
+	for i < len(acc) && j < len(ids) {
+		switch {
+		case acc[i] < ids[j]:
+			i++
+		case acc[i] > ids[j]:
+			j++
+		default:
+			out = append(out, acc[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+func unionIDs(a, b []uint32) []uint32 {
+	out := make([]uint32, 0, len(a)+len(b))
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j >= len(b) || (i < len(a) && a[i] < b[j]):
+			out = append(out, a[i])
+			i++
+		case i >= len(a) || b[j] < a[i]:
+			out = append(out, b[j])
+			j++
+		default:
+			out = append(out, a[i])
+			i++
+			j++
+		}
+	}
+	return out
+}
+
+// Glob answers absPattern from the index with filepath.Glob ordering. ok is
+// false when the index cannot answer (not ready, or the pattern reaches
+// outside the root or has no wildcard). Gitignored paths are never returned.
+func (x *CodeIndex) Glob(absPattern string) (matches []string, ok bool, err error) {
+	if !x.isReady() || hasGlobMeta(x.root) {
+		return nil, false, nil
+	}
+	prefix := x.root + string(filepath.Separator)
+	if !strings.HasPrefix(absPattern, prefix) || !hasGlobMeta(absPattern) {
+		return nil, false, nil
+	}
+	if _, err := filepath.Match(absPattern, ""); err != nil {
+		return nil, true, err
+	}
+	// The literal directory in front of the first wildcard bounds both the
+	// freshness check and the match.
+	literal := absPattern[:strings.IndexAny(absPattern, globMagic())]
+	base := filepath.Dir(literal + "x")
+	x.syncDirs(base)
+
+	x.mu.RLock()
+	for path, entry := range x.files {
+		if entry.linkDir && (path == base || strings.HasPrefix(path, base+string(filepath.Separator))) {
+			x.mu.RUnlock()
+			return nil, false, nil
+		}
+		if ok, _ := filepath.Match(absPattern, path); ok {
+			matches = append(matches, path)
+		}
+	}
+	for path := range x.dirs {
+		if ok, _ := filepath.Match(absPattern, path); ok {
+			matches = append(matches, path)
+		}
+	}
+	x.mu.RUnlock()
+	// filepath.Glob lists directory by directory, which is byte order with
+	// the separator sorting before every other byte.
+	sep := string(filepath.Separator)
+	slices.SortFunc(matches, func(a, b string) int {
+		return strings.Compare(strings.ReplaceAll(a, sep, "\x00"), strings.ReplaceAll(b, sep, "\x00"))
+	})
+	return matches, true, nil
+}
+
+func hasGlobMeta(path string) bool {
+	return strings.ContainsAny(path, globMagic())
+}
+
+// globMagic mirrors filepath.Match: backslash escapes except on Windows,
+// where it is the separator.
+func globMagic() string {
+	if runtime.GOOS == "windows" {
+		return `*?[`
+	}
+	return `*?[\`
+}
+
+// trigramScratch collects a file's distinct trigrams using a bitmap over
+// the 2^24 possible values, reset after each file.
+type trigramScratch struct {
+	seen  []uint64
+	grams []uint32
+}
+
+var trigramScratchPool = sync.Pool{New: func() any { return newTrigramScratch() }}
+
+func newTrigramScratch() *trigramScratch {
+	return &trigramScratch{seen: make([]uint64, 1<<24/64)}
+}
+
+func (s *trigramScratch) load(path string) indexedContent {
+	res := indexedContent{path: path}
+	info, err := os.Lstat(path)
+	if err != nil {
+		return res
+	}
+	res.info = info
+	if info.Mode()&fs.ModeSymlink != 0 {
+		target, err := os.Stat(path)
+		res.linkDir = err == nil && target.IsDir()
+		return res
+	}
+	if !info.Mode().IsRegular() || info.Size() > maxIndexedFileBytes {
+		return res
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || int64(len(data)) != info.Size() {
+		return res
+	}
+	s.grams = s.grams[:0]
+	if len(data) >= 3 {
+		g := uint32(foldByte(data[0]))<<8 | uint32(foldByte(data[1]))
+		for _, c := range data[2:] {
+			g = (g<<8 | uint32(foldByte(c))) & 0xffffff
+			if word, bit := g/64, uint64(1)<<(g%64); s.seen[word]&bit == 0 {
+				s.seen[word] |= bit
+				s.grams = append(s.grams, g)
+			}
+		}
+	}
+	for _, g := range s.grams {
+		s.seen[g/64] = 0
+	}
+	res.grams = slices.Clone(s.grams)
+	res.ok = true
+	return res
+}
@@ -0,0 +1,246 @@
+package toolbuiltin
+
+import (
+	"regexp/syntax"
+	"slices"
+	"unicode"
+	"unicode/utf8"
+)
+
+// maxExactStrings bounds the literal sets tracked while planning; beyond it
+// the set is converted to a trigram condition.
+const maxExactStrings = 16
+
+// trigramQuery is a necessary condition on a file's (ASCII case-folded)
+// trigram set: every gram and sub-query for AND, any sub-query for OR.
+// A nil query matches every file.
+type trigramQuery struct {
+	or    bool
+	grams []uint32
+	subs  []*trigramQuery
+}
+
+func andQuery(parts ...*trigramQuery) *trigramQuery {
+	out := &trigramQuery{}
+	for _, part := range parts {
+		switch {
+		case part == nil:
+		case !part.or:
+			out.grams = append(out.grams, part.grams...)
+			out.subs = append(out.subs, part.subs...)
+		default:
+			out.subs = append(out.subs, part)
+		}
+	}
+	if len(out.grams) == 0 && len(out.subs) == 0 {
+		return nil
+	}
+	if len(out.grams) == 0 && len(out.subs) == 1 {
+		return out.subs[0]
+	}
+	slices.Sort(out.grams)
+	out.grams = slices.Compact(out.grams)
+	return out
+}
+
+func orQuery(parts ...*trigramQuery) *trigramQuery {
+	out := &trigramQuery{or: true}
+	for _, part := range parts {
+		if part == nil {
+			return nil
+		}
+		out.subs = append(out.subs, part)
+	}
+	if len(out.subs) == 1 {
+		return out.subs[0]
+	}
+	return out
+}
+
+// regexInfo summarises what a sub-expression requires. When exact is set
+// and partial is false, the expression matches exactly one of those strings.
+// When partial is true, one of them ends every match and match constrains
+// the rest. Without exact, match is the whole condition.
+type regexInfo struct {
+	exact   []string
+	partial bool
+	match   *trigramQuery
+}
+
+var unknownInfo = regexInfo{}
+
+// planTrigramQuery derives a trigram condition every file matching pattern
+// must satisfy. Patterns that cannot be analysed yield nil (all files).
+func planTrigramQuery(pattern string) *trigramQuery {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil
+	}
+	return analyzeRegex(re.Simplify()).query()
+}
+
+func (info regexInfo) query() *trigramQuery {
+	if info.exact == nil {
+		return info.match
+	}
+	parts := make([]*trigramQuery, 0, len(info.exact))
+	for _, s := range info.exact {
+		if len(s) < 3 {
+			return info.match
+		}
+		parts = append(parts, &trigramQuery{grams: stringTrigrams(s)})
+	}
+	return andQuery(info.match, orQuery(parts...))
+}
+
+func (info regexInfo) complete() bool {
+	return info.exact != nil && !info.partial
+}
+
+func analyzeRegex(re *syntax.Regexp) regexInfo {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return unknownInfo
+	case syntax.OpEmptyMatch, syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpWordBoundary, syntax.OpNoWordBoundary:
+		return regexInfo{exact: []string{""}}
+	case syntax.OpLiteral:
+		fold := re.Flags&syntax.FoldCase != 0
+		cur := regexInfo{exact: []string{""}}
+		for _, r := range re.Rune {
+			next := unknownInfo
+			if predictableRune(r, fold) {
+				next = regexInfo{exact: []string{foldASCII(string(r))}}
+			}
+			cur = concatInfo(cur, next)
+		}
+		return cur
+	case syntax.OpCharClass:
+		var exact []string
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			lo, hi := re.Rune[i], re.Rune[i+1]
+			if hi-lo >= maxExactStrings {
+				return unknownInfo
+			}
+			for r := lo; r <= hi; r++ {
+				if !predictableRune(r, false) {
+					return unknownInfo
+				}
+				exact = append(exact, foldASCII(string(r)))
+			}
+		}
+		slices.Sort(exact)
+		exact = slices.Compact(exact)
+		if len(exact) == 0 || len(exact) > maxExactStrings {
+			return unknownInfo
+		}
+		return regexInfo{exact: exact}
+	case syntax.OpCapture:
+		return analyzeRegex(re.Sub[0])
+	case syntax.OpQuest:
+		sub := analyzeRegex(re.Sub[0])
+		if sub.complete() && len(sub.exact) < maxExactStrings {
+			return regexInfo{exact: append([]string{""}, sub.exact...)}
+		}
+		return unknownInfo
+	case syntax.OpPlus:
+		return regexInfo{match: analyzeRegex(re.Sub[0]).query()}
+	case syntax.OpRepeat:
+		if re.Min == 0 {
+			return unknownInfo
+		}
+		return regexInfo{match: analyzeRegex(re.Sub[0]).query()}
+	case syntax.OpConcat:
+		cur := regexInfo{exact: []string{""}}
+		for _, sub := range re.Sub {
+			cur = concatInfo(cur, analyzeRegex(sub))
+		}
+		return cur
+	case syntax.OpAlternate:
+		var exact []string
+		parts := make([]*trigramQuery, 0, len(re.Sub))
+		allExact := true
+		for _, sub := range re.Sub {
+			info := analyzeRegex(sub)
+			if !info.complete() {
+				allExact = false
+			} else {
+				exact = append(exact, info.exact...)
+			}
+			parts = append(parts, info.query())
+		}
+		if allExact && len(exact) <= maxExactStrings {
+			slices.Sort(exact)
+			return regexInfo{exact: slices.Compact(exact)}
+		}
+		return regexInfo{match: orQuery(parts...)}
+	default:
+		// OpAnyChar, OpAnyCharNotNL, OpStar and anything unrecognised.
+		return unknownInfo
+	}
+}
+
+// predictableRune reports whether every byte sequence r can match is known.
+// Go's regexp matches invalid UTF-8 as U+FFFD, and the index only folds ASCII,
+// so under case folding a rune with variants outside ASCII (é/É, but also
+// k/K and s/ſ) is unpredictable.
+func predictableRune(r rune, fold bool) bool {
+	if r == utf8.RuneError {
+		return false
+	}
+	if !fold {
+		return true
+	}
+	for f := unicode.SimpleFold(r); f != r; f = unicode.SimpleFold(f) {
+		if r >= utf8.RuneSelf || f >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func concatInfo(a, b regexInfo) regexInfo {
+	switch {
+	case b.exact == nil:
+		return regexInfo{match: andQuery(a.query(), b.match)}
+	case b.partial:
+		return regexInfo{exact: b.exact, partial: true, match: andQuery(a.query(), b.match)}
+	case a.exact != nil && len(a.exact)*len(b.exact) <= maxExactStrings:
+		exact := make([]string, 0, len(a.exact)*len(b.exact))
+		for _, x := range a.exact {
+			for _, y := range b.exact {
+				exact = append(exact, x+y)
+			}
+		}
+		slices.Sort(exact)
+		return regexInfo{exact: slices.Compact(exact), partial: a.partial, match: a.match}
+	default:
+		// Keep b's strings as a suffix so later literals can extend them.
+		return regexInfo{exact: b.exact, partial: true, match: a.query()}
+	}
+}
+
+// foldASCII lower-cases ASCII letters only, mirroring how files are indexed.
+func foldASCII(s string) string {
+	b := []byte(s)
+	for i, c := range b {
+		b[i] = foldByte(c)
+	}
+	return string(b)
+}
+
+func foldByte(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+	return c
+}
+
+func stringTrigrams(s string) []uint32 {
+	grams := make([]uint32, 0, len(s))
+	for i := 0; i+2 < len(s); i++ {
+		grams = append(grams, uint32(s[i])<<16|uint32(s[i+1])<<8|uint32(s[i+2]))
+	}
+	slices.Sort(grams)
+	return slices.Compact(grams)
+}
@@ -0,0 +1,319 @@
+package toolbuiltin
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+// queryAccepts evaluates q against the folded trigrams of text.
+func queryAccepts(q *trigramQuery, text string) bool {
+	if q == nil {
+		return true
+	}
+	grams := stringTrigrams(foldASCII(text))
+	if q.or {
+		for _, sub := range q.subs {
+			if queryAccepts(sub, text) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, g := range q.grams {
+		if _, ok := slices.BinarySearch(grams, g); !ok {
+			return false
+		}
+	}
+	for _, sub := range q.subs {
+		if !queryAccepts(sub, text) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestPlanTrigramQuery(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		reject  []string
+	}{
+		{`func\s+Handle`, []string{"func  Handle()"}, []string{"func process", "Handle"}},
+		{`(?i)ReadFile`, []string{"os.readfile(x)", "READFILE"}, []string{"read file"}},
+		{`foo(bar|baz)+qux`, []string{"foobarbazqux"}, []string{"fooqux"}},
+		{`ab[cd]ef`, []string{"xxabdefxx"}, []string{"abxef"}},
+		{`colou?r`, []string{"color", "colour"}, []string{"colr"}},
+		{`(?i)kelvin`, []string{"Kelvin", "KELVIN"}, []string{"celsius"}},
+		{`(?i)straße`, []string{"STRASSE straße", "ſtraße"}, nil},
+		{`x\x{FFFD}yz`, []string{"x\xffyz"}, nil},
+		{`(?s)begin.*end`, []string{"begin\n\nend"}, []string{"begin only"}},
+		{`a.b`, []string{"axb"}, nil},
+		{`[a-z]+`, []string{"anything"}, nil},
+		{`^$`, []string{""}, nil},
+	}
+	for _, tt := range tests {
+		q := planTrigramQuery(tt.pattern)
+		re := regexp.MustCompile(tt.pattern)
+		for _, text := range tt.match {
+			if !re.MatchString(text) {
+				t.Fatalf("bad fixture: %q should match %q", tt.pattern, text)
+			}
+			if !queryAccepts(q, text) {
+				t.Fatalf("%q: query rejects matching text %q", tt.pattern, text)
+			}
+		}
+		for _, text := range tt.reject {
+			if queryAccepts(q, text) {
+				t.Fatalf("%q: query should reject %q", tt.pattern, text)
+			}
+		}
+	}
+}
+
+func writeIndexFixture(t *testing.T) string {
+	t.Helper()
+	root := cleanTempDir(t)
+	files := map[string]string{
+		".gitignore":          "build/\n*.log\n",
+		"main.go":             "package main\n\nfunc main() {\n\tHandleRequest()\n}\n",
+		"handler.go":          "package main\n\n// HandleRequest serves a request.\nfunc HandleRequest() {}\n",
+		"pkg/util/strings.go": "package util\n\nfunc Reverse(s string) string { return s }\n",
+		"pkg/util/README.md":  "Utilities; see HandleRequest.\n",
+		"pkg/a-b/x.go":        "package ab\n",
+		"build/gen.go":        "package build\n\nfunc HandleRequest() {}\n",
+		"debug.log":           "HandleRequest failed\n",
+	}
+	for i := 0; i < 30; i++ {
+		files[fmt.Sprintf("pkg/gen/file%02d.go", i)] = fmt.Sprintf("package gen\n\nconst Value%d = %d\n", i, i)
+	}
+	for name, content := range files {
+		writeTestFile(t, root, name, content)
+	}
+	return root
+}
+
+func newReadyIndex(t *testing.T, root string) *CodeIndex {
+	t.Helper()
+	index := NewCodeIndex(root)
+	index.Start()
+	t.Cleanup(func() { _ = index.Close() })
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := index.WaitReady(ctx); err != nil {
+		t.Fatalf("index not ready: %v", err)
+	}
+	return index
+}
+
+func TestCodeIndexGrepMatchesUnindexedSearch(t *testing.T) {
+	skipIfWindows(t)
+	root := writeIndexFixture(t)
+	index := newReadyIndex(t, root)
+
+	for _, respect := range []bool{true, false} {
+		plain := NewGrepToolWithRoot(root)
+		plain.SetRespectGitignore(respect)
+		indexed := NewGrepToolWithRoot(root)
+		indexed.SetRespectGitignore(respect)
+		indexed.SetCodeIndex(index)
+		for _, params := range []map[string]interface{}{
+			{"pattern": "HandleRequest"},
+			{"pattern": "handlerequest", "-i": true, "output_mode": "content"},
+			{"pattern": `Value(1|2)\d`, "output_mode": "count"},
+			{"pattern": `func \w+\(`, "output_mode": "content", "-C": 1},
+			{"pattern": "package (main|util)", "path": "pkg"},
+			{"pattern": `Reverse.*\n.*`, "multiline": true},
+			{"pattern": "nothing-matches-this"},
+		} {
+			if params["path"] == nil {
+				params["path"] = "."
+			}
+			want, err := plain.Execute(context.Background(), params)
+			if err != nil {
+				t.Fatalf("plain grep %v: %v", params, err)
+			}
+			got, err := indexed.Execute(context.Background(), params)
+			if err != nil {
+				t.Fatalf("indexed grep %v: %v", params, err)
+			}
+			if got.Output != want.Output || !reflect.DeepEqual(got.Data, want.Data) {
+				t.Fatalf("respect=%v %v: indexed output differs\nwant %q\ngot  %q", respect, params, want.Output, got.Output)
+			}
+		}
+	}
+
+	filter := index.Candidates("HandleRequest")
+	if filter == nil {
+		t.Fatal("expected a narrowing filter")
+	}
+	if !filter(filepath.Join(root, "handler.go")) || filter(filepath.Join(root, "pkg", "util", "strings.go")) {
+		t.Fatal("filter should keep only files containing the literal")
+	}
+	// Ignored files are not indexed, so they are never ruled out.
+	if !filter(filepath.Join(root, "build", "gen.go")) {
+		t.Fatal("unindexed files must pass the filter")
+	}
+	if index.Candidates(".*") != nil {
+		t.Fatal("patterns without literals cannot narrow the search")
+	}
+}
+
+func TestCodeIndexTracksChanges(t *testing.T) {
+	skipIfWindows(t)
+	root := writeIndexFixture(t)
+	index := newReadyIndex(t, root)
+	grep := NewGrepToolWithRoot(root)
+	grep.SetCodeIndex(index)
+	glob := NewGlobToolWithRoot(root)
+	glob.SetCodeIndex(index)
+
+	// Changes are visible immediately, before the watcher catches up.
+	writeTestFile(t, root, "pkg/util/strings.go", "package util\n\nfunc FreshToken() {}\n")
+	writeTestFile(t, root, "pkg/newdir/new.go", "package newdir\n")
+	res, err := grep.Execute(context.Background(), map[string]interface{}{"pattern": "FreshToken", "path": "."})
+	if err != nil || res.Output != "pkg/util/strings.go" {
+		t.Fatalf("modified file not searched: %q, %v", res.Output, err)
+	}
+	res, err = glob.Execute(context.Background(), map[string]interface{}{"pattern": "pkg/*/*.go"})
+	if err != nil || !slices.Contains(res.Data.(map[string]interface{})["matches"].([]string), "pkg/newdir/new.go") {
+		t.Fatalf("new file missing from glob: %q, %v", res.Output, err)
+	}
+
+	// The watcher re-indexes changed content.
+	path := filepath.Join(root, "pkg", "util", "strings.go")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if filter := index.Candidates("FreshToken"); filter != nil && filter(path) && !index.Candidates("Reverse")(path) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("watcher did not re-index the modified file")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	if err := os.RemoveAll(filepath.Join(root, "pkg", "gen")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	res, err = glob.Execute(context.Background(), map[string]interface{}{"pattern": "pkg/gen/*.go"})
+	if err != nil || res.Output != "no matches" {
+		t.Fatalf("deleted files still globbed: %q, %v", res.Output, err)
+	}
+}
+
+func TestCodeIndexSharesTreeWatcher(t *testing.T) {
+	skipIfWindows(t)
+	root := writeIndexFixture(t)
+	tree, err := tool.NewTreeWatcher(root)
+	if err != nil {
+		t.Fatalf("tree watcher: %v", err)
+	}
+	t.Cleanup(func() { _ = tree.Close() })
+	cache := tool.NewResultCache(0)
+	if err := cache.WatchTree(tree); err != nil {
+		t.Fatalf("cache watch: %v", err)
+	}
+	t.Cleanup(func() { _ = cache.Close() })
+
+	index := NewCodeIndex(root)
+	index.SetTreeWatcher(tree)
+	index.Start()
+	if err := index.WaitReady(context.Background()); err != nil {
+		t.Fatalf("index not ready: %v", err)
+	}
+	gen := cache.Generation()
+	writeTestFile(t, root, "pkg/util/strings.go", "package util\n\nfunc SharedToken() {}\n")
+	path := filepath.Join(root, "pkg", "util", "strings.go")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if filter := index.Candidates("SharedToken"); filter != nil && filter(path) && !index.Candidates("Reverse")(path) && cache.Generation() != gen {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("shared watcher did not reach both the index and the cache")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	if err := index.Close(); err != nil {
+		t.Fatalf("close index: %v", err)
+	}
+	gen = cache.Generation()
+	writeTestFile(t, root, "pkg/util/other.go", "package util\n")
+	for cache.Generation() == gen {
+		if time.Now().After(deadline.Add(5 * time.Second)) {
+			t.Fatal("closing the index must not close the shared watcher")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestCodeIndexRebuildsAfterWatcherError(t *testing.T) {
+	skipIfWindows(t)
+	root := writeIndexFixture(t)
+	index := newReadyIndex(t, root)
+
+	index.rebuilding.Store(true)
+	if index.Candidates("HandleRequest") != nil {
+		t.Fatal("queries must not use the index while it is rebuilt")
+	}
+	index.rebuilding.Store(false)
+
+	// Simulate lost events by emptying the index, then report an overflow.
+	index.mu.Lock()
+	index.files = map[string]*indexedFile{}
+	index.mu.Unlock()
+	index.enqueue(tool.TreeEvent{Err: fsnotify.ErrEventOverflow})
+	path := filepath.Join(root, "pkg", "util", "strings.go")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if filter := index.Candidates("HandleRequest"); filter != nil && !filter(path) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("index was not rebuilt after a watcher error")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func TestCodeIndexGlobMatchesFilepathGlob(t *testing.T) {
+	skipIfWindows(t)
+	root := writeIndexFixture(t)
+	if err := os.Symlink(filepath.Join(root, "pkg", "util"), filepath.Join(root, "linked")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	index := newReadyIndex(t, root)
+	plain := NewGlobToolWithRoot(root)
+	indexed := NewGlobToolWithRoot(root)
+	indexed.SetCodeIndex(index)
+
+	for _, pattern := range []string{"*.go", "pkg/*", "pkg/*/*.go", "*/*/file0?.go", "build/*.go", "*.log", "main.go", "pkg/[au]*"} {
+		want, err := plain.Execute(context.Background(), map[string]interface{}{"pattern": pattern})
+		if err != nil {
+			t.Fatalf("plain glob %q: %v", pattern, err)
+		}
+		got, err := indexed.Execute(context.Background(), map[string]interface{}{"pattern": pattern})
+		if err != nil {
+			t.Fatalf("indexed glob %q: %v", pattern, err)
+		}
+		if got.Output != want.Output {
+			t.Fatalf("%q: indexed glob differs\nwant %q\ngot  %q", pattern, want.Output, got.Output)
+		}
+	}
+	if _, ok, _ := index.Glob(filepath.Join(root, "linked", "*")); ok {
+		t.Fatal("patterns crossing directory symlinks should fall back to filepath.Glob")
+	}
+	if _, err := indexed.Execute(context.Background(), map[string]interface{}{"pattern": "pkg/[a"}); err == nil {
+		t.Fatal("expected bad pattern error")
+	}
+}
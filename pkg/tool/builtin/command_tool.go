@@ -0,0 +1,500 @@
+package toolbuiltin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/config"
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	commandToolNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+	shellSafeRegexp       = regexp.MustCompile(`^[A-Za-z0-9_./:=@%+,-]+$`)
+)
+
+// CommandToolSpec is a declarative tool read from .agents/tools/*.yaml. The
+// command is a text/template rendered with the call's arguments, every
+// printed value shell-quoted, and run with bash. Placeholders must therefore not sit inside
+// quotes, and string arguments may not start with "-":
+//
+//	name: run_tests
+//	description: Run the tests of one package
+//	parameters:
+//	  type: object
+//	  properties:
+//	    package: {type: string, default: ./...}
+//	command: make test PKG={{.package}}
+//	timeout: 5m
+//	env:
+//	  GOFLAGS: -count=1
+type CommandToolSpec struct {
+	Name        string                 `yaml:"name"`
+	Description string                 `yaml:"description"`
+	Parameters  map[string]interface{} `yaml:"parameters"` // JSON Schema of the arguments.
+	Command     string                 `yaml:"command"`
+	Workdir     string                 `yaml:"workdir"` // Relative to the project root.
+	Timeout     interface{}            `yaml:"timeout"` // Duration string or seconds (default 10m).
+	Env         map[string]string      `yaml:"env"`
+	ReadOnly    bool                   `yaml:"readOnly"`
+	// Path is the file the spec was loaded from, if any.
+	Path string `yaml:"-"`
+}
+
+// CommandToolLoaderOptions controls where command tools are discovered.
+type CommandToolLoaderOptions struct {
+	ProjectRoot string
+	FS          *config.FS
+}
+
+// LoadCommandTools reads every .yaml/.yml file under .agents/tools. Errors are
+// aggregated so one bad file does not hide the others.
+func LoadCommandTools(opts CommandToolLoaderOptions) ([]CommandToolSpec, []error) {
+	fsLayer := opts.FS
+	if fsLayer == nil {
+		fsLayer = config.NewFS(opts.ProjectRoot, nil)
+	}
+	dir := filepath.Join(opts.ProjectRoot, ".agents", "tools")
+	info, err := fsLayer.Stat(dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, []error{fmt.Errorf("command tools: stat %s: %w", dir, err)}
+	}
+	if !info.IsDir() {
+		return nil, []error{fmt.Errorf("command tools: path %s is not a directory", dir)}
+	}
+
+	var (
+		specs []CommandToolSpec
+		errs  []error
+		seen  = map[string]string{}
+	)
+	walkErr := fsLayer.WalkDir(dir, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			errs = append(errs, fmt.Errorf("command tools: walk %s: %w", path, walkErr))
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if ext := strings.ToLower(filepath.Ext(d.Name())); ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+		spec, err := parseCommandToolFile(path, fsLayer)
+		if err != nil {
+			errs = append(errs, err)
+			return nil
+		}
+		if prev, ok := seen[spec.Name]; ok {
+			errs = append(errs, fmt.Errorf("command tools: duplicate tool %q in %s (first defined in %s)", spec.Name, path, prev))
+			return nil
+		}
+		seen[spec.Name] = path
+		specs = append(specs, spec)
+		return nil
+	})
+	if walkErr != nil {
+		errs = append(errs, walkErr)
+	}
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Name < specs[j].Name })
+	return specs, errs
+}
+
+func parseCommandToolFile(path string, fsLayer *config.FS) (CommandToolSpec, error) {
+	data, err := fsLayer.ReadFile(path)
+	if err != nil {
+		return CommandToolSpec{}, fmt.Errorf("command tools: read %s: %w", path, err)
+	}
+	var spec CommandToolSpec
+	if err := yaml.Unmarshal(data, &spec); err != nil {
+		return CommandToolSpec{}, fmt.Errorf("command tools: parse %s: %w", path, err)
+	}
+	spec.Path = path
+	if strings.TrimSpace(spec.Name) == "" {
+		spec.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	if _, err := compileCommandTool(spec); err != nil {
+		return CommandToolSpec{}, fmt.Errorf("command tools: validate %s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// CommandTool runs a CommandToolSpec. Arguments are repaired and validated
+// against the spec's parameters by the executor before the command renders.
+type CommandTool struct {
+	spec    CommandToolSpec
+	schema  *tool.JSONSchema
+	command *template.Template
+	timeout time.Duration
+	env     []string
+	policy  sandbox.FileSystemPolicy
+	root    string
+}
+
+type compiledCommandTool struct {
+	schema  *tool.JSONSchema
+	command *template.Template
+	timeout time.Duration
+	env     []string
+}
+
+// NewCommandToolWithRoot builds a CommandTool confined to root.
+func NewCommandToolWithRoot(spec CommandToolSpec, root string) (*CommandTool, error) {
+	resolved := resolveRoot(root)
+	return newCommandTool(spec, resolved, sandbox.NewFileSystemAllowList(resolved))
+}
+
+// NewCommandToolWithSandbox builds a CommandTool with a custom sandbox. A nil
+// policy disables workdir confinement.
+func NewCommandToolWithSandbox(spec CommandToolSpec, root string, policy sandbox.FileSystemPolicy) (*CommandTool, error) {
+	return newCommandTool(spec, resolveRoot(root), policy)
+}
+
+func newCommandTool(spec CommandToolSpec, root string, policy sandbox.FileSystemPolicy) (*CommandTool, error) {
+	compiled, err := compileCommandTool(spec)
+	if err != nil {
+		return nil, err
+	}
+	spec.Name = strings.TrimSpace(spec.Name)
+	return &CommandTool{
+		spec:    spec,
+		schema:  compiled.schema,
+		command: compiled.command,
+		timeout: compiled.timeout,
+		env:     compiled.env,
+		policy:  policy,
+		root:    root,
+	}, nil
+}
+
+func compileCommandTool(spec CommandToolSpec) (compiledCommandTool, error) {
+	name := strings.TrimSpace(spec.Name)
+	if !commandToolNameRegexp.MatchString(name) {
+		return compiledCommandTool{}, fmt.Errorf("invalid tool name %q", name)
+	}
+	if strings.TrimSpace(spec.Command) == "" {
+		return compiledCommandTool{}, errors.New("command is required")
+	}
+	if filepath.IsAbs(spec.Workdir) {
+		return compiledCommandTool{}, fmt.Errorf("workdir %q must be relative to the project root", spec.Workdir)
+	}
+	command, err := template.New(name).Option("missingkey=zero").Funcs(template.FuncMap{shellWordFunc: shellWord}).Parse(spec.Command)
+	if err != nil {
+		return compiledCommandTool{}, fmt.Errorf("command template: %w", err)
+	}
+	for _, tmpl := range command.Templates() {
+		if tmpl.Tree == nil {
+			continue
+		}
+		if err := checkUnquotedActions(tmpl.Tree.Root); err != nil {
+			return compiledCommandTool{}, fmt.Errorf("command template: %w", err)
+		}
+		quotePrintedActions(tmpl.Tree.Root)
+	}
+
+	params := spec.Parameters
+	if params == nil {
+		params = map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return compiledCommandTool{}, fmt.Errorf("parameters: %w", err)
+	}
+	var schema tool.JSONSchema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return compiledCommandTool{}, fmt.Errorf("parameters: %w", err)
+	}
+	if schema.Type == "" && len(schema.Types) == 0 {
+		schema.Type = "object"
+	}
+	if schema.Type != "object" {
+		return compiledCommandTool{}, fmt.Errorf("parameters must describe an object, got type %q", schema.Type)
+	}
+
+	timeout := defaultBashTimeout
+	if spec.Timeout != nil {
+		dur, err := durationFromParam(spec.Timeout)
+		if err != nil {
+			return compiledCommandTool{}, fmt.Errorf("invalid timeout: %w", err)
+		}
+		if dur > 0 {
+			timeout = min(dur, maxBashTimeout)
+		}
+	}
+
+	keys := make([]string, 0, len(spec.Env))
+	for key := range spec.Env {
+		if key == "" || strings.ContainsAny(key, "=\x00") {
+			return compiledCommandTool{}, fmt.Errorf("invalid env name %q", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	env := make([]string, 0, len(keys))
+	for _, key := range keys {
+		env = append(env, key+"="+spec.Env[key])
+	}
+	return compiledCommandTool{schema: &schema, command: command, timeout: timeout, env: env}, nil
+}
+
+func (c *CommandTool) Name() string { return c.spec.Name }
+
+func (c *CommandTool) Description() string {
+	if desc := strings.TrimSpace(c.spec.Description); desc != "" {
+		return desc
+	}
+	return fmt.Sprintf("Run the project command %q.", c.spec.Command)
+}
+
+func (c *CommandTool) Schema() *tool.JSONSchema { return c.schema }
+
+func (c *CommandTool) Metadata() tool.Metadata {
+	if c.spec.ReadOnly {
+		return tool.Metadata{IsReadOnly: true}
+	}
+	return tool.Metadata{IsDestructive: true}
+}
+
+// Cacheable is false: a read-only command may still depend on state outside
+// the watched tree, such as the network or the clock.
+func (c *CommandTool) Cacheable() bool { return false }
+
+// Spec returns the definition the tool was built from.
+func (c *CommandTool) Spec() CommandToolSpec { return c.spec }
+
+func (c *CommandTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if c == nil || c.command == nil {
+		return nil, errors.New("command tool is not initialised")
+	}
+	command, err := c.render(params)
+	if err != nil {
+		return nil, err
+	}
+	workdir, err := c.resolveWorkdir()
+	if err != nil {
+		return nil, err
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+	cmd := exec.CommandContext(execCtx, "bash", "-c", command)
+	cmd.Env = append(os.Environ(), c.env...)
+	cmd.Dir = workdir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	runErr := cmd.Run()
+	duration := time.Since(start)
+
+	exitCode := 0
+	if cmd.ProcessState != nil {
+		exitCode = cmd.ProcessState.ExitCode()
+	}
+	result := &tool.ToolResult{
+		Success: runErr == nil,
+		Output:  combineOutput(stdout.String(), stderr.String()),
+		Data: map[string]interface{}{
+			"command":     command,
+			"workdir":     workdir,
+			"exit_code":   exitCode,
+			"duration_ms": duration.Milliseconds(),
+		},
+	}
+	if runErr != nil {
+		if errors.Is(execCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil {
+			return result, fmt.Errorf("command timeout after %s", c.timeout)
+		}
+		return result, fmt.Errorf("command failed: %w", runErr)
+	}
+	return result, nil
+}
+
+// render executes the command template with the call's typed arguments, so
+// conditionals and range see the real values; every printed value is
+// shell-quoted by the shellword call quotePrintedActions added.
+func (c *CommandTool) render(params map[string]interface{}) (string, error) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	var buf strings.Builder
+	if err := c.command.Execute(&buf, params); err != nil {
+		return "", fmt.Errorf("render command: %w", err)
+	}
+	command := strings.TrimSpace(buf.String())
+	if command == "" {
+		return "", errors.New("rendered command is empty")
+	}
+	return command, nil
+}
+
+func (c *CommandTool) resolveWorkdir() (string, error) {
+	dir := filepath.Clean(filepath.Join(c.root, c.spec.Workdir))
+	if c.policy != nil {
+		if err := c.policy.Validate(dir); err != nil {
+			return "", err
+		}
+	}
+	info, err := os.Stat(dir)
+	if err != nil {
+		return "", fmt.Errorf("workdir stat: %w", err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("workdir %s is not a directory", dir)
+	}
+	return dir, nil
+}
+
+// shellWordFunc is the template function quotePrintedActions appends to
+// every printed action.
+const shellWordFunc = "_command_tool_shellword"
+
+// shellWord renders value as one bash word, quoting it only when needed. A
+// nil value, like a missing argument, renders empty.
+func shellWord(value interface{}) (string, error) {
+	var text string
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		text = v
+	case bool:
+		text = strconv.FormatBool(v)
+	case float64:
+		if math.Trunc(v) == v && math.Abs(v) < 1e15 {
+			text = strconv.FormatInt(int64(v), 10)
+		} else {
+			text = strconv.FormatFloat(v, 'g', -1, 64)
+		}
+	case json.Number:
+		text = v.String()
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		text = string(raw)
+	}
+	if strings.ContainsRune(text, 0) {
+		return "", errors.New("value contains a NUL byte")
+	}
+	if _, isString := value.(string); isString && strings.HasPrefix(text, "-") {
+		// Quoting cannot stop the command from reading it as an option.
+		return "", fmt.Errorf("value %q must not start with \"-\"", text)
+	}
+	if shellSafeRegexp.MatchString(text) {
+		return text, nil
+	}
+	return shellQuote(text), nil
+}
+
+// checkUnquotedActions rejects printed template actions inside shell quotes,
+// where the quoting shellWord adds would become part of the value. Branches
+// of if, range and with are scanned in template order.
+func checkUnquotedActions(root *parse.ListNode) error {
+	var quote rune
+	escaped := false
+	var walk func(list *parse.ListNode) error
+	walk = func(list *parse.ListNode) error {
+		if list == nil {
+			return nil
+		}
+		for _, node := range list.Nodes {
+			switch n := node.(type) {
+			case *parse.TextNode:
+				for _, r := range string(n.Text) {
+					switch {
+					case escaped:
+						escaped = false
+					case r == '\\' && quote != '\'':
+						escaped = true
+					case quote == 0 && (r == '\'' || r == '"'):
+						quote = r
+					case r == quote:
+						quote = 0
+					}
+				}
+			case *parse.ActionNode:
+				if quote != 0 && len(n.Pipe.Decl) == 0 {
+					return fmt.Errorf("placeholder %s is inside %c quotes; arguments are quoted already", n, quote)
+				}
+				escaped = false
+			case *parse.IfNode:
+				if err := walkBranch(walk, &n.BranchNode); err != nil {
+					return err
+				}
+			case *parse.RangeNode:
+				if err := walkBranch(walk, &n.BranchNode); err != nil {
+					return err
+				}
+			case *parse.WithNode:
+				if err := walkBranch(walk, &n.BranchNode); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+	return walk(root)
+}
+
+func walkBranch(walk func(*parse.ListNode) error, branch *parse.BranchNode) error {
+	if err := walk(branch.List); err != nil {
+		return err
+	}
+	return walk(branch.ElseList)
+}
+
+// quotePrintedActions pipes every action that prints a value through
+// shellWord, the way html/template inserts its escapers.
+func quotePrintedActions(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			quotePrintedActions(child)
+		}
+	case *parse.ActionNode:
+		if len(n.Pipe.Decl) == 0 {
+			n.Pipe.Cmds = append(n.Pipe.Cmds, &parse.CommandNode{
+				NodeType: parse.NodeCommand,
+				Pos:      n.Pos,
+				Args:     []parse.Node{parse.NewIdentifier(shellWordFunc).SetPos(n.Pos)},
+			})
+		}
+	case *parse.IfNode:
+		quotePrintedActions(n.List)
+		quotePrintedActions(n.ElseList)
+	case *parse.RangeNode:
+		quotePrintedActions(n.List)
+		quotePrintedActions(n.ElseList)
+	case *parse.WithNode:
+		quotePrintedActions(n.List)
+		quotePrintedActions(n.ElseList)
+	}
+}
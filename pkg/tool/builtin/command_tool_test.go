@@ -0,0 +1,233 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/config"
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+func TestLoadCommandTools(t *testing.T) {
+	root := cleanTempDir(t)
+	dir := filepath.Join(root, ".agents", "tools")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"echo.yaml": "description: Echo a message\nparameters:\n  type: object\n  properties:\n    msg: {type: string}\n  required: [msg]\ncommand: echo {{.msg}}\ntimeout: 30s\n",
+		"lint.yml":  "name: lint\ncommand: make lint\nreadOnly: true\n",
+		"bad.yaml":  "name: bad\ncommand: echo {{.msg\n",
+		"zz.yaml":   "name: lint\ncommand: make lint\n",
+		"notes.txt": "ignored",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	specs, errs := LoadCommandTools(CommandToolLoaderOptions{ProjectRoot: root})
+	if len(specs) != 2 || specs[0].Name != "echo" || specs[1].Name != "lint" {
+		t.Fatalf("unexpected specs %+v", specs)
+	}
+	if len(errs) != 2 {
+		t.Fatalf("expected template and duplicate errors, got %v", errs)
+	}
+	joined := errors.Join(errs...).Error()
+	if !strings.Contains(joined, "command template") || !strings.Contains(joined, `duplicate tool "lint"`) {
+		t.Fatalf("unexpected errors: %v", joined)
+	}
+
+	impl, err := NewCommandToolWithRoot(specs[0], root)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	if impl.Description() != "Echo a message" || len(impl.Schema().Required) != 1 || impl.timeout.Seconds() != 30 {
+		t.Fatalf("unexpected tool %+v", impl)
+	}
+	lint, err := NewCommandToolWithRoot(specs[1], root)
+	if err != nil || !lint.Metadata().IsReadOnly {
+		t.Fatalf("lint should be read-only: %v", err)
+	}
+	if tool.IsCacheable(lint) {
+		t.Fatal("command tools must not be cached even when read-only")
+	}
+
+	if specs, errs := LoadCommandTools(CommandToolLoaderOptions{ProjectRoot: cleanTempDir(t)}); specs != nil || errs != nil {
+		t.Fatalf("missing directory should load nothing: %v %v", specs, errs)
+	}
+}
+
+func TestLoadCommandToolsFromEmbedFS(t *testing.T) {
+	root := cleanTempDir(t)
+	embedded := fstest.MapFS{
+		".agents/tools/hello.yaml": {Data: []byte("command: echo hello\n")},
+	}
+	specs, errs := LoadCommandTools(CommandToolLoaderOptions{ProjectRoot: root, FS: config.NewFS(root, embedded)})
+	if len(errs) != 0 || len(specs) != 1 || specs[0].Name != "hello" {
+		t.Fatalf("unexpected load result %+v %v", specs, errs)
+	}
+}
+
+func TestCommandToolExecuteQuotesArguments(t *testing.T) {
+	skipIfWindows(t)
+	root := cleanTempDir(t)
+	impl, err := NewCommandToolWithRoot(CommandToolSpec{
+		Name:    "show",
+		Command: `printf '%s|%s|%s|%s\n' {{.text}} {{.count}} "$GREETING" {{.missing}}`,
+		Env:     map[string]string{"GREETING": "hi there"},
+	}, root)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	marker := filepath.Join(root, "pwned")
+	res, err := impl.Execute(context.Background(), map[string]interface{}{
+		"text":  "a b'; touch " + marker + "; echo '",
+		"count": 3.0,
+	})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	want := "a b'; touch " + marker + "; echo '|3|hi there|"
+	if res.Output != want {
+		t.Fatalf("output = %q, want %q", res.Output, want)
+	}
+	if _, err := os.Stat(marker); !os.IsNotExist(err) {
+		t.Fatal("argument escaped its quoting")
+	}
+	if data := res.Data.(map[string]interface{}); data["exit_code"] != 0 || data["workdir"] != root {
+		t.Fatalf("unexpected data %v", data)
+	}
+
+	// Quoting cannot stop an argument from being read as an option.
+	if _, err := impl.Execute(context.Background(), map[string]interface{}{"text": "--output=/etc/passwd"}); err == nil || !strings.Contains(err.Error(), `must not start with "-"`) {
+		t.Fatalf("expected leading dash to be rejected, got %v", err)
+	}
+	if res, err := impl.Execute(context.Background(), map[string]interface{}{"text": "x", "count": -2.0}); err != nil || res.Output != "x|-2|hi there|" {
+		t.Fatalf("negative numbers are plain values: %+v %v", res, err)
+	}
+	escaped, err := NewCommandToolWithRoot(CommandToolSpec{Name: "escaped", Command: `echo \" {{.text}} \"`}, root)
+	if err != nil {
+		t.Fatalf("escaped quotes do not open a quoted string: %v", err)
+	}
+	if res, err := escaped.Execute(context.Background(), map[string]interface{}{"text": "a b"}); err != nil || res.Output != `" a b "` {
+		t.Fatalf("unexpected output %+v %v", res, err)
+	}
+}
+
+func TestCommandToolTemplateSeesTypedArguments(t *testing.T) {
+	skipIfWindows(t)
+	root := cleanTempDir(t)
+	impl, err := NewCommandToolWithRoot(CommandToolSpec{
+		Name:    "run",
+		Command: `echo run{{if .verbose}} -v{{end}}{{with .tag}} --tag={{.}}{{end}}{{range .files}} {{.}}{{end}}`,
+	}, root)
+	if err != nil {
+		t.Fatalf("build: %v", err)
+	}
+	for _, tc := range []struct {
+		params map[string]interface{}
+		want   string
+	}{
+		{map[string]interface{}{"verbose": false}, "run"},
+		{map[string]interface{}{"verbose": true}, "run -v"},
+		{map[string]interface{}{"verbose": nil, "tag": nil}, "run"},
+		{map[string]interface{}{"tag": "a b"}, "run --tag=a b"},
+		{map[string]interface{}{"files": []interface{}{"x.go", "my file.go", "y; rm -rf /"}}, "run x.go my file.go y; rm -rf /"},
+	} {
+		res, err := impl.Execute(context.Background(), tc.params)
+		if err != nil || res.Output != tc.want {
+			t.Fatalf("%v: got %+v %v, want %q", tc.params, res, err, tc.want)
+		}
+	}
+	command, err := impl.render(map[string]interface{}{"files": []interface{}{"my file.go", 2.0}, "tag": nil})
+	if err != nil || command != `echo run 'my file.go' 2` {
+		t.Fatalf("each list element should be one quoted word: %q %v", command, err)
+	}
+	if _, err := impl.render(map[string]interface{}{"files": []interface{}{"-rf"}}); err == nil || !strings.Contains(err.Error(), `must not start with "-"`) {
+		t.Fatalf("expected printed list elements to be checked, got %v", err)
+	}
+}
+
+func TestCommandToolFailuresAndSandbox(t *testing.T) {
+	skipIfWindows(t)
+	root := cleanTempDir(t)
+
+	failing, err := NewCommandToolWithRoot(CommandToolSpec{Name: "fail", Command: "echo oops >&2; exit 3"}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := failing.Execute(context.Background(), nil)
+	if err == nil || res == nil || res.Success || res.Output != "oops" || res.Data.(map[string]interface{})["exit_code"] != 3 {
+		t.Fatalf("expected failed result, got %+v %v", res, err)
+	}
+
+	slow, err := NewCommandToolWithRoot(CommandToolSpec{Name: "slow", Command: "sleep 5", Timeout: 0.1}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := slow.Execute(context.Background(), nil); err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("expected timeout, got %v", err)
+	}
+
+	escape, err := NewCommandToolWithRoot(CommandToolSpec{Name: "escape", Command: "pwd", Workdir: "../"}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := escape.Execute(context.Background(), nil); !errors.Is(err, sandbox.ErrPathDenied) {
+		t.Fatalf("workdir outside the root should be denied, got %v", err)
+	}
+	unconfined, err := NewCommandToolWithSandbox(CommandToolSpec{Name: "escape", Command: "pwd", Workdir: "../"}, root, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res, err := unconfined.Execute(context.Background(), nil); err != nil || res.Output != filepath.Dir(root) {
+		t.Fatalf("nil policy should not confine workdir: %+v %v", res, err)
+	}
+}
+
+func TestCommandToolSpecValidation(t *testing.T) {
+	cases := map[string]CommandToolSpec{
+		"invalid tool name":       {Name: "bad name", Command: "true"},
+		"command is required":     {Name: "empty"},
+		"must be relative":        {Name: "abs", Command: "true", Workdir: string(filepath.Separator) + "tmp"},
+		"must describe an object": {Name: "arr", Command: "true", Parameters: map[string]interface{}{"type": "array"}},
+		"invalid timeout":         {Name: "slow", Command: "true", Timeout: "soon"},
+		"invalid env name":        {Name: "env", Command: "true", Env: map[string]string{"A=B": "x"}},
+		`inside " quotes`:         {Name: "dq", Command: `echo "hi {{.name}}"`},
+		"inside ' quotes":         {Name: "sq", Command: `echo 'a" {{if .x}}{{.x}}{{end}}'`},
+	}
+	for want, spec := range cases {
+		if _, err := NewCommandToolWithRoot(spec, ""); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: got %v", want, err)
+		}
+	}
+}
+
+func TestCommandToolArgumentsRepairedByExecutor(t *testing.T) {
+	skipIfWindows(t)
+	root := cleanTempDir(t)
+	impl, err := NewCommandToolWithRoot(CommandToolSpec{
+		Name:       "count",
+		Command:    "echo {{.n}} {{.mode}}",
+		Parameters: map[string]interface{}{"type": "object", "properties": map[string]interface{}{"n": map[string]interface{}{"type": "integer"}, "mode": map[string]interface{}{"type": "string", "default": "fast"}}},
+	}, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reg := tool.NewRegistry()
+	if err := reg.Register(impl); err != nil {
+		t.Fatal(err)
+	}
+	res, err := tool.NewExecutor(reg, nil).WithParamRepair(true).Execute(context.Background(), tool.Call{Name: "count", Params: map[string]any{"n": "7"}})
+	if err != nil || res.Result.Output != "7 fast" {
+		t.Fatalf("unexpected result %+v %v", res, err)
+	}
+}
@@ -11,7 +11,8 @@ import (
 	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
 )
 
-const editDescription = `Performs exact string replacements within the configured sandbox (old_string must be unique unless replace_all).`
+const editDescription = `Performs exact string replacements within the configured sandbox (old_string must be unique unless replace_all).
+The file must have been read in this session and must not have changed since.`
 
 var editSchema = &tool.JSONSchema{
 	Type: "object",
@@ -67,6 +68,13 @@ func (e *EditTool) Metadata() tool.Metadata {
 	return tool.Metadata{}
 }
 
+// SetFileState attaches the tracker used to enforce read-before-write.
+func (e *EditTool) SetFileState(files *FileStateTracker) {
+	if e != nil && e.base != nil {
+		e.base.files = files
+	}
+}
+
 func (e *EditTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
 	if ctx == nil {
 		return nil, errors.New("context is nil")
@@ -108,34 +116,29 @@ func (e *EditTool) Execute(ctx context.Context, params map[string]interface{}) (
 		return nil, fmt.Errorf("%s is a directory", path)
 	}
 
+	if err := e.base.checkWrite(ctx, path); err != nil {
+		return nil, err
+	}
 	content, err := e.base.readFile(path)
 	if err != nil {
 		return nil, err
 	}
 
-	matches := strings.Count(content, oldString)
-	if matches == 0 {
-		return nil, fmt.Errorf("old_string not found in %s", displayPath(path, e.base.root))
-	}
-	if !replaceAll && matches != 1 {
-		return nil, fmt.Errorf("old_string must be unique when replace_all is false (found %d matches)", matches)
-	}
-
-	replacements := matches
-	var updated string
-	if replaceAll {
-		updated = strings.ReplaceAll(content, oldString, newString)
-	} else {
-		updated = strings.Replace(content, oldString, newString, 1)
-		replacements = 1
+	updated, matches, replacements, err := replaceExact(content, oldString, newString, replaceAll)
+	if err != nil {
+		if errors.Is(err, errOldStringNotFound) {
+			return nil, fmt.Errorf("old_string not found in %s", displayPath(path, e.base.root))
+		}
+		return nil, err
 	}
 
 	if e.base.maxBytes > 0 && int64(len(updated)) > e.base.maxBytes {
 		return nil, fmt.Errorf("edited content exceeds %d bytes limit", e.base.maxBytes)
 	}
-	if err := os.WriteFile(path, []byte(updated), info.Mode()); err != nil {
-		return nil, fmt.Errorf("write file: %w", err)
+	if err := writeFileAtomic(path, []byte(updated), info.Mode()); err != nil {
+		return nil, err
 	}
+	e.base.recordRead(ctx, path, []byte(updated))
 
 	return &tool.ToolResult{
 		Success: true,
@@ -149,6 +152,25 @@ func (e *EditTool) Execute(ctx context.Context, params map[string]interface{}) (
 	}, nil
 }
 
+var errOldStringNotFound = errors.New("old_string not found")
+
+// replaceExact replaces oldString in content, requiring a unique match unless
+// replaceAll is set. It returns the updated content, the number of matches and
+// the number of replacements performed.
+func replaceExact(content, oldString, newString string, replaceAll bool) (string, int, int, error) {
+	matches := strings.Count(content, oldString)
+	if matches == 0 {
+		return "", 0, 0, errOldStringNotFound
+	}
+	if replaceAll {
+		return strings.ReplaceAll(content, oldString, newString), matches, matches, nil
+	}
+	if matches != 1 {
+		return "", matches, 0, fmt.Errorf("old_string must be unique when replace_all is false (found %d matches)", matches)
+	}
+	return strings.Replace(content, oldString, newString, 1), matches, 1, nil
+}
+
 func (e *EditTool) resolveFilePath(params map[string]interface{}) (string, error) {
 	if params == nil {
 		return "", errors.New("params is nil")
@@ -2,6 +2,7 @@ package toolbuiltin
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -18,6 +19,7 @@ type fileSandbox struct {
 	policy   sandbox.FileSystemPolicy
 	root     string
 	maxBytes int64
+	files    *FileStateTracker
 }
 
 func newFileSandbox(root string) *fileSandbox {
@@ -96,7 +98,95 @@ func (f *fileSandbox) writeFile(path string, content string) error {
 	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
 		return fmt.Errorf("ensure directory: %w", err)
 	}
-	if err := os.WriteFile(path, data, 0o666); err != nil { //nolint:gosec // respect umask for created files
+	perm := os.FileMode(0o666)
+	created := false
+	if info, err := os.Stat(path); err == nil {
+		perm = info.Mode()
+	} else {
+		// Creating the file first lets the process umask pick the mode of
+		// new files, which the atomic rename then preserves.
+		fh, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666) //nolint:gosec // respect umask for created files
+		if err != nil {
+			return fmt.Errorf("write file: %w", err)
+		}
+		if info, err := fh.Stat(); err == nil {
+			perm = info.Mode()
+		}
+		_ = fh.Close()
+		created = true
+	}
+	if err := writeFileAtomic(path, data, perm); err != nil {
+		if created {
+			_ = os.Remove(path)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkWrite enforces read-before-write when a FileStateTracker is attached.
+func (f *fileSandbox) checkWrite(ctx context.Context, path string) error {
+	if f == nil || f.files == nil {
+		return nil
+	}
+	return f.files.CheckWrite(bashSessionID(ctx), path, displayPath(path, f.root))
+}
+
+// forget drops the calling session's record of path.
+func (f *fileSandbox) forget(ctx context.Context, path string) {
+	if f == nil || f.files == nil {
+		return
+	}
+	f.files.Forget(bashSessionID(ctx), path)
+}
+
+// recordRead notes that the calling session has observed content at path.
+func (f *fileSandbox) recordRead(ctx context.Context, path string, content []byte) {
+	if f == nil || f.files == nil {
+		return
+	}
+	f.files.RecordRead(bashSessionID(ctx), path, content)
+}
+
+// writeFileAtomic replaces path with data by writing a sibling temp file and
+// renaming it into place, so readers never observe a partial write.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	// Replace the target of a symlink rather than the link itself.
+	if target, err := filepath.EvalSymlinks(path); err == nil {
+		path = target
+		// A rename would sidestep the permissions of a read-only file.
+		probe, err := os.OpenFile(path, os.O_WRONLY, 0)
+		if err != nil {
+			return fmt.Errorf("write file: %w", err)
+		}
+		_ = probe.Close()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("create temp file: %w", err)
+	}
+	tmpName := tmp.Name()
+	cleanup := func() { _ = os.Remove(tmpName) }
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return fmt.Errorf("write temp file: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return fmt.Errorf("sync temp file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		cleanup()
+		return fmt.Errorf("close temp file: %w", err)
+	}
+	if err := os.Chmod(tmpName, perm.Perm()); err != nil {
+		cleanup()
+		return fmt.Errorf("chmod temp file: %w", err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		cleanup()
 		return fmt.Errorf("write file: %w", err)
 	}
 	return nil
@@ -0,0 +1,138 @@
+package toolbuiltin
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FileState records what a session last observed for one file.
+type FileState struct {
+	Hash    [sha256.Size]byte
+	ModTime time.Time
+	Size    int64
+}
+
+// FileStateTracker remembers, per session, the content hash and mtime of the
+// files a model has read or written. Mutating file tools consult it so that
+// edits to files the model never saw, or that changed on disk since it last
+// looked, are rejected instead of silently clobbering someone else's work.
+// It is safe for concurrent use.
+type FileStateTracker struct {
+	mu       sync.Mutex
+	sessions map[string]map[string]FileState
+}
+
+// NewFileStateTracker builds an empty tracker.
+func NewFileStateTracker() *FileStateTracker {
+	return &FileStateTracker{sessions: map[string]map[string]FileState{}}
+}
+
+// RecordRead stores the observed content of path for sessionID. Reads and
+// successful writes both count as observations.
+func (t *FileStateTracker) RecordRead(sessionID, path string, content []byte) {
+	if t == nil {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+	t.store(sessionID, path, FileState{Hash: sha256.Sum256(content), ModTime: info.ModTime(), Size: info.Size()})
+}
+
+// RecordWrite is an alias of RecordRead used after a tool writes content.
+func (t *FileStateTracker) RecordWrite(sessionID, path string, content []byte) {
+	t.RecordRead(sessionID, path, content)
+}
+
+// Lookup returns the recorded state of path for sessionID.
+func (t *FileStateTracker) Lookup(sessionID, path string) (FileState, bool) {
+	if t == nil {
+		return FileState{}, false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.sessions[strings.TrimSpace(sessionID)][fileStateKey(path)]
+	return state, ok
+}
+
+// CheckWrite verifies that sessionID may overwrite path. Files that do not
+// exist yet are always writable. Existing files must have been read in this
+// session and their content hash must still match what was read; a changed
+// mtime alone is tolerated. The hash is always compared because RecordRead
+// stats the file after its caller read it, so a write in between leaves a
+// current mtime next to a stale hash.
+func (t *FileStateTracker) CheckWrite(sessionID, path, display string) error {
+	if t == nil {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil // callers report directories themselves
+	}
+	state, ok := t.Lookup(sessionID, path)
+	if !ok {
+		return fmt.Errorf("%s has not been read in this session; read it before modifying it", display)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read file: %w", err)
+	}
+	if sha256.Sum256(data) != state.Hash {
+		return fmt.Errorf("%s was modified since it was last read (by the user or another process); read it again before modifying it", display)
+	}
+	if !info.ModTime().Equal(state.ModTime) || info.Size() != state.Size {
+		t.store(sessionID, path, FileState{Hash: state.Hash, ModTime: info.ModTime(), Size: info.Size()})
+	}
+	return nil
+}
+
+// Forget drops the record of path for sessionID, e.g. after deletion.
+func (t *FileStateTracker) Forget(sessionID, path string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.sessions[strings.TrimSpace(sessionID)], fileStateKey(path))
+}
+
+// Delete drops all state tracked for sessionID.
+func (t *FileStateTracker) Delete(sessionID string) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.sessions, strings.TrimSpace(sessionID))
+}
+
+func (t *FileStateTracker) store(sessionID, path string, state FileState) {
+	key := strings.TrimSpace(sessionID)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	files := t.sessions[key]
+	if files == nil {
+		files = map[string]FileState{}
+		t.sessions[key] = files
+	}
+	files[fileStateKey(path)] = state
+}
+
+func fileStateKey(path string) string {
+	if resolved, err := filepath.EvalSymlinks(path); err == nil {
+		return resolved
+	}
+	return filepath.Clean(path)
+}
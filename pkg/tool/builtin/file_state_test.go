@@ -0,0 +1,147 @@
+package toolbuiltin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/middleware"
+)
+
+func sessionContext(id string) context.Context {
+	return context.WithValue(context.Background(), middleware.TraceSessionIDContextKey, id)
+}
+
+func TestFileStateTrackerCheckWrite(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("one"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	files := NewFileStateTracker()
+
+	if err := files.CheckWrite("s1", filepath.Join(dir, "new.txt"), "new.txt"); err != nil {
+		t.Fatalf("new files should be writable: %v", err)
+	}
+	if err := files.CheckWrite("s1", path, "a.txt"); err == nil || !strings.Contains(err.Error(), "has not been read") {
+		t.Fatalf("expected unread error, got %v", err)
+	}
+	files.RecordRead("s1", path, []byte("one"))
+	if err := files.CheckWrite("s1", path, "a.txt"); err != nil {
+		t.Fatalf("expected write allowed after read: %v", err)
+	}
+	if err := files.CheckWrite("s2", path, "a.txt"); err == nil {
+		t.Fatal("reads must not leak across sessions")
+	}
+
+	// Touching the file without changing content is tolerated.
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("chtimes: %v", err)
+	}
+	if err := files.CheckWrite("s1", path, "a.txt"); err != nil {
+		t.Fatalf("mtime-only change should pass: %v", err)
+	}
+	if state, _ := files.Lookup("s1", path); !state.ModTime.Equal(later) {
+		t.Fatalf("expected refreshed mtime, got %v", state.ModTime)
+	}
+
+	if err := os.WriteFile(path, []byte("two"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := files.CheckWrite("s1", path, "a.txt"); err == nil || !strings.Contains(err.Error(), "modified since it was last read") {
+		t.Fatalf("expected stale error, got %v", err)
+	}
+
+	// A write between a tool's read and RecordRead's stat leaves a current
+	// mtime next to the hash of the old content; it must still be caught.
+	files.RecordRead("s1", path, []byte("one"))
+	if err := files.CheckWrite("s1", path, "a.txt"); err == nil || !strings.Contains(err.Error(), "modified since it was last read") {
+		t.Fatalf("expected stale hash to be caught, got %v", err)
+	}
+
+	files.Forget("s1", path)
+	if _, ok := files.Lookup("s1", path); ok {
+		t.Fatal("expected record to be forgotten")
+	}
+	files.RecordRead("s1", path, []byte("two"))
+	files.Delete("s1")
+	if _, ok := files.Lookup("s1", path); ok {
+		t.Fatal("expected session to be cleared")
+	}
+
+	var nilTracker *FileStateTracker
+	if err := nilTracker.CheckWrite("s1", path, "a.txt"); err != nil {
+		t.Fatalf("nil tracker should not enforce: %v", err)
+	}
+}
+
+func TestFileToolsEnforceReadBeforeWrite(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o640); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	files := NewFileStateTracker()
+	read := NewReadToolWithRoot(dir)
+	read.SetFileState(files)
+	write := NewWriteToolWithRoot(dir)
+	write.SetFileState(files)
+	edit := NewEditToolWithRoot(dir)
+	edit.SetFileState(files)
+	grep := NewGrepToolWithRoot(dir)
+	grep.SetFileState(files)
+	ctx := sessionContext("sess")
+
+	editParams := map[string]interface{}{"file_path": "main.go", "old_string": "main", "new_string": "app"}
+	if _, err := edit.Execute(ctx, editParams); err == nil || !strings.Contains(err.Error(), "read it before modifying") {
+		t.Fatalf("expected unread edit to fail, got %v", err)
+	}
+	if _, err := write.Execute(ctx, map[string]interface{}{"file_path": "main.go", "content": "x"}); err == nil {
+		t.Fatal("expected unread overwrite to fail")
+	}
+	if _, err := write.Execute(ctx, map[string]interface{}{"file_path": "fresh.txt", "content": "new"}); err != nil {
+		t.Fatalf("creating a file should not need a read: %v", err)
+	}
+
+	if _, err := grep.Execute(ctx, map[string]interface{}{"pattern": "package", "path": "main.go"}); err != nil {
+		t.Fatalf("grep: %v", err)
+	}
+	if _, err := edit.Execute(ctx, editParams); err != nil {
+		t.Fatalf("edit after grep: %v", err)
+	}
+	// The tool's own write refreshes the record, so a follow-up edit works.
+	if _, err := edit.Execute(ctx, map[string]interface{}{"file_path": "main.go", "old_string": "app", "new_string": "svc"}); err != nil {
+		t.Fatalf("second edit: %v", err)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o640 {
+		t.Fatalf("atomic edit should keep the file mode, got %v err=%v", info.Mode(), err)
+	}
+
+	if err := os.WriteFile(path, []byte("package human\n"), 0o640); err != nil {
+		t.Fatalf("external write: %v", err)
+	}
+	if _, err := edit.Execute(ctx, map[string]interface{}{"file_path": "main.go", "old_string": "human", "new_string": "bot"}); err == nil || !strings.Contains(err.Error(), "modified since") {
+		t.Fatalf("expected stale edit to fail, got %v", err)
+	}
+	if _, err := read.Execute(ctx, map[string]interface{}{"file_path": "main.go"}); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if _, err := write.Execute(ctx, map[string]interface{}{"file_path": "main.go", "content": "package bot\n"}); err != nil {
+		t.Fatalf("write after re-read: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "package bot\n" {
+		t.Fatalf("unexpected content %q", data)
+	}
+	entries, _ := os.ReadDir(dir)
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".tmp") {
+			t.Fatalf("temp file left behind: %s", entry.Name())
+		}
+	}
+}
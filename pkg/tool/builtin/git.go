@@ -0,0 +1,773 @@
+package toolbuiltin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const GitName = "git"
+
+// DefaultGitCoAuthor is the identity added as a Co-Authored-By trailer.
+const DefaultGitCoAuthor = "Claude <noreply@anthropic.com>"
+
+const (
+	gitDefaultTimeout  = 60 * time.Second
+	gitMaxPatchBytes   = 64 * 1024
+	gitDefaultLogCount = 20
+	gitMaxLogCount     = 200
+	gitMaxBlameLines   = 500
+)
+
+const gitDescription = `Runs common git operations in a repository under the sandbox root and returns structured JSON.
+Usage:
+- operation is one of status, diff, log, show, blame, branch, add, commit, stash.
+- diff shows unstaged changes, or staged ones with staged=true; ref compares against a commit instead.
+- log lists up to max_count commits (default 20) from ref, optionally limited to paths; show describes one commit.
+- blame annotates file_path, optionally between start_line and end_line.
+- branch takes action list (default), create, delete or switch with name.
+- add stages paths (or everything with all=true); commit records message, optionally with all=true or limited to paths.
+- stash takes action push (default), list, pop, apply or drop.
+- Guards: commits to protected branches and history rewrites (amend, force branch delete) are refused unless the project settings allow them.
+- Prefer this over running git through bash.`
+
+var gitSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"operation": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"status", "diff", "log", "show", "blame", "branch", "add", "commit", "stash"},
+			"description": "The git operation to run.",
+		},
+		"repo": map[string]interface{}{
+			"type":        "string",
+			"description": "Repository directory (defaults to the sandbox root).",
+		},
+		"paths": map[string]interface{}{
+			"type":        "array",
+			"items":       map[string]interface{}{"type": "string"},
+			"description": "Limit status, diff, log, add, commit or stash to these paths.",
+		},
+		"staged": map[string]interface{}{
+			"type":        "boolean",
+			"description": "diff: show staged changes instead of unstaged ones.",
+		},
+		"ref": map[string]interface{}{
+			"type":        "string",
+			"description": "Commit, branch or tag for diff, log, show and blame.",
+		},
+		"max_count": map[string]interface{}{
+			"type":        "integer",
+			"description": "log: maximum number of commits (default 20, max 200).",
+		},
+		"file_path": map[string]interface{}{
+			"type":        "string",
+			"description": "blame: file to annotate.",
+		},
+		"start_line": map[string]interface{}{
+			"type":        "integer",
+			"description": "blame: first line (1-based).",
+		},
+		"end_line": map[string]interface{}{
+			"type":        "integer",
+			"description": "blame: last line (1-based, inclusive).",
+		},
+		"action": map[string]interface{}{
+			"type":        "string",
+			"description": "branch: list, create, delete or switch. stash: push, list, pop, apply or drop.",
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "branch: branch name. stash: stash entry such as stash@{0}.",
+		},
+		"start_point": map[string]interface{}{
+			"type":        "string",
+			"description": "branch create: commit to start the branch from.",
+		},
+		"message": map[string]interface{}{
+			"type":        "string",
+			"description": "commit or stash push message.",
+		},
+		"all": map[string]interface{}{
+			"type":        "boolean",
+			"description": "add: stage all changes. commit: include all tracked modifications.",
+		},
+		"amend": map[string]interface{}{
+			"type":        "boolean",
+			"description": "commit: amend the previous commit (a history rewrite).",
+		},
+		"force": map[string]interface{}{
+			"type":        "boolean",
+			"description": "branch delete: delete even if unmerged.",
+		},
+		"include_untracked": map[string]interface{}{
+			"type":        "boolean",
+			"description": "stash push: include untracked files.",
+		},
+	},
+	Required: []string{"operation"},
+}
+
+// GitPolicy holds the guards GitTool enforces.
+type GitPolicy struct {
+	// ProtectedBranches lists branch names or path.Match globs that refuse
+	// commits and deletion.
+	ProtectedBranches   []string
+	AllowHistoryRewrite bool
+	// CoAuthoredBy appends a Co-Authored-By trailer naming CoAuthor to commits.
+	CoAuthoredBy bool
+	CoAuthor     string
+}
+
+// DefaultGitPolicy protects main and master and adds the co-author trailer.
+func DefaultGitPolicy() GitPolicy {
+	return GitPolicy{
+		ProtectedBranches: []string{"main", "master"},
+		CoAuthoredBy:      true,
+		CoAuthor:          DefaultGitCoAuthor,
+	}
+}
+
+func (p GitPolicy) protected(branch string) bool {
+	for _, pattern := range p.ProtectedBranches {
+		if ok, _ := path.Match(strings.TrimSpace(pattern), branch); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// GitTool runs git subcommands with structured output and safety guards.
+type GitTool struct {
+	base    *fileSandbox
+	policy  GitPolicy
+	timeout time.Duration
+}
+
+// NewGitTool builds a GitTool rooted at the current directory.
+func NewGitTool() *GitTool {
+	return NewGitToolWithRoot("")
+}
+
+// NewGitToolWithRoot builds a GitTool rooted at the provided directory.
+func NewGitToolWithRoot(root string) *GitTool {
+	return &GitTool{base: newFileSandbox(root), policy: DefaultGitPolicy(), timeout: gitDefaultTimeout}
+}
+
+// NewGitToolWithSandbox builds a GitTool using a custom sandbox.
+func NewGitToolWithSandbox(root string, policy sandbox.FileSystemPolicy) *GitTool {
+	return &GitTool{base: newFileSandboxWithSandbox(root, policy), policy: DefaultGitPolicy(), timeout: gitDefaultTimeout}
+}
+
+// SetPolicy replaces the guards enforced by the tool.
+func (g *GitTool) SetPolicy(policy GitPolicy) {
+	if g != nil {
+		g.policy = policy
+	}
+}
+
+func (g *GitTool) Name() string { return GitName }
+
+func (g *GitTool) Description() string { return gitDescription }
+
+func (g *GitTool) Schema() *tool.JSONSchema { return gitSchema }
+
+func (g *GitTool) Metadata() tool.Metadata {
+	return tool.Metadata{}
+}
+
+// gitRequest carries the parsed parameters of one call.
+type gitRequest struct {
+	ctx    context.Context
+	dir    string
+	params map[string]interface{}
+}
+
+func (r gitRequest) str(key string) (string, error) {
+	raw, ok := r.params[key]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	value, err := coerceString(raw)
+	if err != nil {
+		return "", fmt.Errorf("%s must be string: %w", key, err)
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// ref reads an optional revision-like argument and refuses values git would
+// parse as options.
+func (r gitRequest) ref(key string) (string, error) {
+	value, err := r.str(key)
+	if err != nil || value == "" {
+		return value, err
+	}
+	if strings.HasPrefix(value, "-") || strings.ContainsAny(value, "\x00\n") {
+		return "", fmt.Errorf("%s %q is not a valid git reference", key, value)
+	}
+	return value, nil
+}
+
+func (r gitRequest) flag(key string) (bool, error) {
+	raw, ok := r.params[key]
+	if !ok || raw == nil {
+		return false, nil
+	}
+	value, err := coerceBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("%s must be boolean: %w", key, err)
+	}
+	return value, nil
+}
+
+func (g *GitTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if g == nil || g.base == nil {
+		return nil, errors.New("git tool is not initialised")
+	}
+	if params == nil {
+		return nil, errors.New("params is nil")
+	}
+	req := gitRequest{ctx: ctx, params: params}
+	op, err := req.str("operation")
+	if err != nil {
+		return nil, err
+	}
+	if op == "" {
+		return nil, errors.New("operation is required")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return nil, errors.New("git executable not found in PATH")
+	}
+
+	req.dir = g.base.root
+	if raw, ok := params["repo"]; ok && raw != nil {
+		if req.dir, err = g.base.resolvePath(raw); err != nil {
+			return nil, err
+		}
+		info, err := os.Stat(req.dir)
+		if err != nil {
+			return nil, fmt.Errorf("stat repo: %w", err)
+		}
+		if !info.IsDir() {
+			return nil, fmt.Errorf("repo %s is not a directory", displayPath(req.dir, g.base.root))
+		}
+	}
+	if g.timeout > 0 {
+		var cancel context.CancelFunc
+		req.ctx, cancel = context.WithTimeout(ctx, g.timeout)
+		defer cancel()
+	}
+
+	var result map[string]interface{}
+	switch strings.ToLower(op) {
+	case "status":
+		result, err = g.status(req)
+	case "diff":
+		result, err = g.diff(req)
+	case "log":
+		result, err = g.log(req)
+	case "show":
+		result, err = g.show(req)
+	case "blame":
+		result, err = g.blame(req)
+	case "branch":
+		result, err = g.branch(req)
+	case "add":
+		result, err = g.add(req)
+	case "commit":
+		result, err = g.commit(req)
+	case "stash":
+		result, err = g.stash(req)
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", op)
+	}
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(result)
+	if err != nil {
+		return nil, err
+	}
+	return &tool.ToolResult{Success: true, Output: string(raw), Data: result}, nil
+}
+
+// run executes git in dir and returns stdout. Non-zero exits surface stderr.
+func (g *GitTool) run(req gitRequest, stdin string, args ...string) (string, error) {
+	full := append([]string{"-c", "core.quotepath=off", "-c", "color.ui=never", "--no-pager"}, args...)
+	cmd := exec.CommandContext(req.ctx, "git", full...) //nolint:gosec // arguments are validated by the caller
+	cmd.Dir = req.dir
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GIT_EDITOR=true", "LC_ALL=C")
+	if stdin != "" {
+		cmd.Stdin = strings.NewReader(stdin)
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if ctxErr := req.ctx.Err(); ctxErr != nil {
+			return "", fmt.Errorf("git %s: %w", args[0], ctxErr)
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if msg == "" {
+			msg = strings.TrimSpace(stdout.String())
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git %s: %s", args[0], msg)
+	}
+	return stdout.String(), nil
+}
+
+// paths resolves the optional paths parameter through the sandbox.
+func (g *GitTool) paths(req gitRequest) ([]string, error) {
+	raw, ok := req.params["paths"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	var items []interface{}
+	switch v := raw.(type) {
+	case []interface{}:
+		items = v
+	case []string:
+		for _, s := range v {
+			items = append(items, s)
+		}
+	case string:
+		items = []interface{}{v}
+	default:
+		return nil, fmt.Errorf("paths must be an array of strings, got %T", raw)
+	}
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		resolved, err := g.base.resolvePath(item)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, resolved)
+	}
+	return out, nil
+}
+
+func (g *GitTool) currentBranch(req gitRequest) (string, error) {
+	out, err := g.run(req, "", "symbolic-ref", "--short", "-q", "HEAD")
+	if err != nil {
+		// Detached HEAD exits non-zero without output.
+		if _, revErr := g.run(req, "", "rev-parse", "--git-dir"); revErr != nil {
+			return "", revErr
+		}
+		return "", nil
+	}
+	return strings.TrimSpace(out), nil
+}
+
+func (g *GitTool) status(req gitRequest) (map[string]interface{}, error) {
+	paths, err := g.paths(req)
+	if err != nil {
+		return nil, err
+	}
+	args := append([]string{"status", "--porcelain=v2", "--branch", "-z", "--untracked-files=all", "--"}, paths...)
+	out, err := g.run(req, "", args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseGitStatus(out), nil
+}
+
+func (g *GitTool) diff(req gitRequest) (map[string]interface{}, error) {
+	staged, err := req.flag("staged")
+	if err != nil {
+		return nil, err
+	}
+	ref, err := req.ref("ref")
+	if err != nil {
+		return nil, err
+	}
+	paths, err := g.paths(req)
+	if err != nil {
+		return nil, err
+	}
+	base := []string{"diff"}
+	if staged {
+		base = append(base, "--cached")
+	}
+	if ref != "" {
+		base = append(base, ref)
+	}
+	numstat, err := g.run(req, "", append(append(append([]string{}, base...), "--numstat", "-z", "--"), paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	patch, err := g.run(req, "", append(append(append([]string{}, base...), "--"), paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	patch, truncated := truncateGitPatch(patch)
+	result := map[string]interface{}{
+		"staged":    staged,
+		"files":     parseGitNumstat(numstat),
+		"patch":     patch,
+		"truncated": truncated,
+	}
+	if ref != "" {
+		result["ref"] = ref
+	}
+	return result, nil
+}
+
+func (g *GitTool) log(req gitRequest) (map[string]interface{}, error) {
+	ref, err := req.ref("ref")
+	if err != nil {
+		return nil, err
+	}
+	paths, err := g.paths(req)
+	if err != nil {
+		return nil, err
+	}
+	count := gitDefaultLogCount
+	if _, ok := req.params["max_count"]; ok {
+		n, err := parseLineNumber(req.params, "max_count")
+		if err != nil {
+			return nil, err
+		}
+		if n < 1 {
+			return nil, errors.New("max_count must be >= 1")
+		}
+		count = min(n, gitMaxLogCount)
+	}
+	args := []string{"log", "--format=" + gitLogFormat, "-n", strconv.Itoa(count)}
+	if ref != "" {
+		args = append(args, ref)
+	}
+	args = append(append(args, "--"), paths...)
+	out, err := g.run(req, "", args...)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"commits": parseGitLog(out)}, nil
+}
+
+func (g *GitTool) show(req gitRequest) (map[string]interface{}, error) {
+	ref, err := req.ref("ref")
+	if err != nil {
+		return nil, err
+	}
+	if ref == "" {
+		ref = "HEAD"
+	}
+	meta, err := g.run(req, "", "log", "-1", "--format="+gitShowFormat, ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	commits := parseGitLog(meta)
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("commit %q not found", ref)
+	}
+	numstat, err := g.run(req, "", "show", "--format=", "--numstat", "-z", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	patch, err := g.run(req, "", "show", "--format=", ref, "--")
+	if err != nil {
+		return nil, err
+	}
+	patch, truncated := truncateGitPatch(patch)
+	result := commits[0]
+	result["files"] = parseGitNumstat(numstat)
+	result["patch"] = strings.TrimLeft(patch, "\n")
+	result["truncated"] = truncated
+	return result, nil
+}
+
+func (g *GitTool) blame(req gitRequest) (map[string]interface{}, error) {
+	raw, ok := req.params["file_path"]
+	if !ok || raw == nil {
+		return nil, errors.New("file_path is required for blame")
+	}
+	file, err := g.base.resolvePath(raw)
+	if err != nil {
+		return nil, err
+	}
+	ref, err := req.ref("ref")
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"blame", "--porcelain"}
+	_, hasStart := req.params["start_line"]
+	_, hasEnd := req.params["end_line"]
+	if hasStart || hasEnd {
+		start, end := 1, 0
+		if hasStart {
+			if start, err = parseLineNumber(req.params, "start_line"); err != nil {
+				return nil, err
+			}
+		}
+		if hasEnd {
+			if end, err = parseLineNumber(req.params, "end_line"); err != nil {
+				return nil, err
+			}
+			if end < start {
+				return nil, errors.New("end_line must be >= start_line")
+			}
+		}
+		if end > 0 {
+			args = append(args, "-L", fmt.Sprintf("%d,%d", start, end))
+		} else {
+			args = append(args, "-L", fmt.Sprintf("%d,", start))
+		}
+	}
+	if ref != "" {
+		args = append(args, ref)
+	}
+	args = append(args, "--", file)
+	out, err := g.run(req, "", args...)
+	if err != nil {
+		return nil, err
+	}
+	lines := parseGitBlame(out)
+	truncated := len(lines) > gitMaxBlameLines
+	if truncated {
+		lines = lines[:gitMaxBlameLines]
+	}
+	return map[string]interface{}{
+		"file":      displayPath(file, g.base.root),
+		"lines":     lines,
+		"truncated": truncated,
+	}, nil
+}
+
+func (g *GitTool) branch(req gitRequest) (map[string]interface{}, error) {
+	action, err := req.str("action")
+	if err != nil {
+		return nil, err
+	}
+	name, err := req.ref("name")
+	if err != nil {
+		return nil, err
+	}
+	action = strings.ToLower(action)
+	if action != "" && action != "list" && name == "" {
+		return nil, fmt.Errorf("name is required for branch %s", action)
+	}
+	switch action {
+	case "", "list":
+		out, err := g.run(req, "", "branch", "--format="+gitBranchFormat)
+		if err != nil {
+			return nil, err
+		}
+		branches, current := parseGitBranches(out)
+		return map[string]interface{}{"current": current, "branches": branches}, nil
+	case "create":
+		start, err := req.ref("start_point")
+		if err != nil {
+			return nil, err
+		}
+		args := []string{"branch", name}
+		if start != "" {
+			args = append(args, start)
+		}
+		if _, err := g.run(req, "", args...); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"created": name}, nil
+	case "delete":
+		if g.policy.protected(name) {
+			return nil, fmt.Errorf("refusing to delete protected branch %q", name)
+		}
+		force, err := req.flag("force")
+		if err != nil {
+			return nil, err
+		}
+		flag := "-d"
+		if force {
+			if !g.policy.AllowHistoryRewrite {
+				return nil, errors.New("refusing to force-delete a branch: history rewrites are disabled (set git.allowHistoryRewrite)")
+			}
+			flag = "-D"
+		}
+		if _, err := g.run(req, "", "branch", flag, name); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"deleted": name}, nil
+	case "switch":
+		if _, err := g.run(req, "", "switch", name); err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"current": name}, nil
+	default:
+		return nil, fmt.Errorf("unsupported branch action %q", action)
+	}
+}
+
+func (g *GitTool) add(req gitRequest) (map[string]interface{}, error) {
+	all, err := req.flag("all")
+	if err != nil {
+		return nil, err
+	}
+	paths, err := g.paths(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case all:
+		_, err = g.run(req, "", append([]string{"add", "-A", "--"}, paths...)...)
+	case len(paths) > 0:
+		_, err = g.run(req, "", append([]string{"add", "--"}, paths...)...)
+	default:
+		return nil, errors.New("paths is required for add unless all is true")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return g.status(gitRequest{ctx: req.ctx, dir: req.dir, params: map[string]interface{}{}})
+}
+
+func (g *GitTool) commit(req gitRequest) (map[string]interface{}, error) {
+	message, err := req.str("message")
+	if err != nil {
+		return nil, err
+	}
+	if message == "" {
+		return nil, errors.New("message is required for commit")
+	}
+	amend, err := req.flag("amend")
+	if err != nil {
+		return nil, err
+	}
+	all, err := req.flag("all")
+	if err != nil {
+		return nil, err
+	}
+	paths, err := g.paths(req)
+	if err != nil {
+		return nil, err
+	}
+	if amend && !g.policy.AllowHistoryRewrite {
+		return nil, errors.New("refusing to amend: history rewrites are disabled (set git.allowHistoryRewrite)")
+	}
+	branch, err := g.currentBranch(req)
+	if err != nil {
+		return nil, err
+	}
+	if branch != "" && g.policy.protected(branch) {
+		return nil, fmt.Errorf("refusing to commit to protected branch %q; create or switch to another branch first", branch)
+	}
+
+	coAuthored := false
+	if g.policy.CoAuthoredBy && strings.TrimSpace(g.policy.CoAuthor) != "" {
+		trailer := "Co-Authored-By: " + strings.TrimSpace(g.policy.CoAuthor)
+		if !strings.Contains(message, trailer) {
+			message += "\n\n" + trailer
+		}
+		coAuthored = true
+	}
+	args := []string{"commit", "-F", "-"}
+	if amend {
+		args = append(args, "--amend")
+	}
+	if all {
+		args = append(args, "-a")
+	}
+	if len(paths) > 0 {
+		args = append(append(args, "--"), paths...)
+	}
+	if _, err := g.run(req, message+"\n", args...); err != nil {
+		return nil, err
+	}
+	meta, err := g.run(req, "", "log", "-1", "--format="+gitLogFormat, "HEAD", "--")
+	if err != nil {
+		return nil, err
+	}
+	commits := parseGitLog(meta)
+	if len(commits) == 0 {
+		return nil, errors.New("commit succeeded but HEAD could not be read")
+	}
+	result := commits[0]
+	result["branch"] = branch
+	result["amended"] = amend
+	result["co_authored_by"] = coAuthored
+	return result, nil
+}
+
+func (g *GitTool) stash(req gitRequest) (map[string]interface{}, error) {
+	action, err := req.str("action")
+	if err != nil {
+		return nil, err
+	}
+	name, err := req.ref("name")
+	if err != nil {
+		return nil, err
+	}
+	switch strings.ToLower(action) {
+	case "", "push":
+		message, err := req.str("message")
+		if err != nil {
+			return nil, err
+		}
+		untracked, err := req.flag("include_untracked")
+		if err != nil {
+			return nil, err
+		}
+		paths, err := g.paths(req)
+		if err != nil {
+			return nil, err
+		}
+		args := []string{"stash", "push"}
+		if untracked {
+			args = append(args, "--include-untracked")
+		}
+		if message != "" {
+			args = append(args, "-m", message)
+		}
+		if len(paths) > 0 {
+			args = append(append(args, "--"), paths...)
+		}
+		out, err := g.run(req, "", args...)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"stashed": !strings.Contains(out, "No local changes"), "message": strings.TrimSpace(out)}, nil
+	case "list":
+		out, err := g.run(req, "", "stash", "list", "--format="+gitStashFormat)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"stashes": parseGitStashes(out)}, nil
+	case "pop", "apply", "drop":
+		args := []string{"stash", strings.ToLower(action)}
+		if name != "" {
+			args = append(args, name)
+		}
+		out, err := g.run(req, "", args...)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"action": strings.ToLower(action), "message": strings.TrimSpace(out)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported stash action %q", action)
+	}
+}
+
+func truncateGitPatch(patch string) (string, bool) {
+	if len(patch) <= gitMaxPatchBytes {
+		return patch, false
+	}
+	if cut := strings.LastIndexByte(patch[:gitMaxPatchBytes], '\n'); cut > 0 {
+		return patch[:cut+1], true
+	}
+	return patch[:gitMaxPatchBytes], true
+}
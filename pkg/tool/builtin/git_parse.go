@@ -0,0 +1,263 @@
+package toolbuiltin
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	gitLogFormat    = "%H%x1f%h%x1f%an%x1f%ae%x1f%aI%x1f%P%x1f%s%x1e"
+	gitShowFormat   = "%H%x1f%h%x1f%an%x1f%ae%x1f%aI%x1f%P%x1f%s%x1f%b%x1e"
+	gitBranchFormat = "%(refname:short)%1f%(objectname:short)%1f%(HEAD)%1f%(upstream:short)%1f%(contents:subject)"
+	gitStashFormat  = "%gd%x1f%H%x1f%gs"
+)
+
+var gitStatusWords = map[byte]string{
+	'M': "modified",
+	'T': "type_changed",
+	'A': "added",
+	'D': "deleted",
+	'R': "renamed",
+	'C': "copied",
+	'U': "unmerged",
+}
+
+// parseGitStatus decodes `git status --porcelain=v2 --branch -z`.
+func parseGitStatus(out string) map[string]interface{} {
+	result := map[string]interface{}{}
+	files := []map[string]interface{}{}
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if entry == "" {
+			continue
+		}
+		switch entry[0] {
+		case '#':
+			key, value, _ := strings.Cut(strings.TrimPrefix(entry, "# "), " ")
+			switch key {
+			case "branch.oid":
+				if value != "(initial)" {
+					result["commit"] = value
+				}
+			case "branch.head":
+				if value != "(detached)" {
+					result["branch"] = value
+				} else {
+					result["detached"] = true
+				}
+			case "branch.upstream":
+				result["upstream"] = value
+			case "branch.ab":
+				var ahead, behind int
+				for _, part := range strings.Fields(value) {
+					n, _ := strconv.Atoi(part[1:])
+					if part[0] == '+' {
+						ahead = n
+					} else {
+						behind = n
+					}
+				}
+				result["ahead"], result["behind"] = ahead, behind
+			}
+		case '1', '2', 'u':
+			// "1 XY sub mH mI mW hH hI path"; "2" adds a score and is followed
+			// by the original path; "u" has three stage modes and hashes.
+			parts := 9
+			if entry[0] == '2' {
+				parts = 10
+			} else if entry[0] == 'u' {
+				parts = 11
+			}
+			cols := strings.SplitN(entry, " ", parts)
+			if len(cols) < parts {
+				continue
+			}
+			xy := cols[1]
+			file := map[string]interface{}{"path": cols[parts-1]}
+			if word := gitStatusWords[xy[0]]; word != "" {
+				file["staged"] = word
+			}
+			if word := gitStatusWords[xy[1]]; word != "" {
+				file["unstaged"] = word
+			}
+			if entry[0] == 'u' {
+				file["conflicted"] = true
+			}
+			if entry[0] == '2' && i+1 < len(fields) {
+				i++
+				file["orig_path"] = fields[i]
+			}
+			files = append(files, file)
+		case '?':
+			files = append(files, map[string]interface{}{"path": entry[2:], "unstaged": "untracked"})
+		}
+	}
+	result["files"] = files
+	result["clean"] = len(files) == 0
+	return result
+}
+
+// parseGitNumstat decodes `--numstat -z` output.
+func parseGitNumstat(out string) []map[string]interface{} {
+	files := []map[string]interface{}{}
+	fields := strings.Split(out, "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := strings.TrimLeft(fields[i], "\n")
+		if entry == "" {
+			continue
+		}
+		cols := strings.SplitN(entry, "\t", 3)
+		if len(cols) != 3 {
+			continue
+		}
+		file := map[string]interface{}{}
+		if cols[0] == "-" && cols[1] == "-" {
+			file["binary"] = true
+		} else {
+			file["additions"], _ = strconv.Atoi(cols[0])
+			file["deletions"], _ = strconv.Atoi(cols[1])
+		}
+		if cols[2] == "" && i+2 < len(fields) {
+			file["old_path"] = fields[i+1]
+			file["path"] = fields[i+2]
+			i += 2
+		} else {
+			file["path"] = cols[2]
+		}
+		files = append(files, file)
+	}
+	return files
+}
+
+// parseGitLog decodes records written with gitLogFormat or gitShowFormat.
+func parseGitLog(out string) []map[string]interface{} {
+	commits := []map[string]interface{}{}
+	for _, record := range strings.Split(out, "\x1e") {
+		record = strings.TrimLeft(record, "\n")
+		if strings.TrimSpace(record) == "" {
+			continue
+		}
+		cols := strings.Split(record, "\x1f")
+		if len(cols) < 7 {
+			continue
+		}
+		commit := map[string]interface{}{
+			"hash":         cols[0],
+			"short_hash":   cols[1],
+			"author":       cols[2],
+			"author_email": cols[3],
+			"date":         cols[4],
+			"parents":      strings.Fields(cols[5]),
+			"subject":      cols[6],
+		}
+		if len(cols) > 7 {
+			commit["body"] = strings.TrimSpace(cols[7])
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// parseGitBlame decodes `git blame --porcelain`.
+func parseGitBlame(out string) []map[string]interface{} {
+	type commitInfo struct {
+		author, date, summary string
+	}
+	infos := map[string]*commitInfo{}
+	lines := []map[string]interface{}{}
+	var sha string
+	var finalLine int
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "\t") {
+			info := infos[sha]
+			if info == nil {
+				info = &commitInfo{}
+			}
+			lines = append(lines, map[string]interface{}{
+				"line":    finalLine,
+				"commit":  sha[:min(len(sha), 12)],
+				"author":  info.author,
+				"date":    info.date,
+				"summary": info.summary,
+				"content": line[1:],
+			})
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		if len(key) == 40 && gitIsHex(key) {
+			sha = key
+			cols := strings.Fields(value)
+			if len(cols) >= 2 {
+				finalLine, _ = strconv.Atoi(cols[1])
+			}
+			if infos[sha] == nil {
+				infos[sha] = &commitInfo{}
+			}
+			continue
+		}
+		info := infos[sha]
+		if info == nil {
+			continue
+		}
+		switch key {
+		case "author":
+			info.author = value
+		case "author-time":
+			if secs, err := strconv.ParseInt(value, 10, 64); err == nil {
+				info.date = time.Unix(secs, 0).UTC().Format(time.RFC3339)
+			}
+		case "summary":
+			info.summary = value
+		}
+	}
+	return lines
+}
+
+func parseGitBranches(out string) ([]map[string]interface{}, string) {
+	branches := []map[string]interface{}{}
+	current := ""
+	for _, line := range strings.Split(out, "\n") {
+		cols := strings.Split(line, "\x1f")
+		if len(cols) != 5 {
+			continue
+		}
+		branch := map[string]interface{}{
+			"name":    cols[0],
+			"commit":  cols[1],
+			"current": cols[2] == "*",
+			"subject": cols[4],
+		}
+		if cols[3] != "" {
+			branch["upstream"] = cols[3]
+		}
+		if cols[2] == "*" {
+			current = cols[0]
+		}
+		branches = append(branches, branch)
+	}
+	return branches, current
+}
+
+func parseGitStashes(out string) []map[string]interface{} {
+	stashes := []map[string]interface{}{}
+	for _, line := range strings.Split(out, "\n") {
+		cols := strings.Split(line, "\x1f")
+		if len(cols) != 3 {
+			continue
+		}
+		stashes = append(stashes, map[string]interface{}{"name": cols[0], "commit": cols[1], "message": cols[2]})
+	}
+	return stashes
+}
+
+func gitIsHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,256 @@
+package toolbuiltin
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func skipIfNoGit(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+}
+
+func runGitCmd(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// newGitTestRepo creates a repository on main with one commit of a.txt.
+func newGitTestRepo(t *testing.T) string {
+	t.Helper()
+	skipIfWindows(t)
+	skipIfNoGit(t)
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	dir := cleanTempDir(t)
+	runGitCmd(t, dir, "init", "-q", "-b", "main")
+	runGitCmd(t, dir, "config", "user.name", "Tester")
+	runGitCmd(t, dir, "config", "user.email", "tester@example.com")
+	writeTestFile(t, dir, "a.txt", "one\ntwo\n")
+	runGitCmd(t, dir, "add", "a.txt")
+	runGitCmd(t, dir, "commit", "-q", "-m", "initial")
+	return dir
+}
+
+func writeTestFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+}
+
+func runGitTool(t *testing.T, g *GitTool, params map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	res, err := g.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("git %v: %v", params, err)
+	}
+	data, ok := res.Data.(map[string]interface{})
+	if !ok || !strings.HasPrefix(res.Output, "{") {
+		t.Fatalf("expected JSON output, got %q", res.Output)
+	}
+	return data
+}
+
+func TestGitToolReadOperations(t *testing.T) {
+	dir := newGitTestRepo(t)
+	g := NewGitToolWithRoot(dir)
+
+	writeTestFile(t, dir, "a.txt", "one\nTWO\nthree\n")
+	writeTestFile(t, dir, "new.txt", "fresh\n")
+	writeTestFile(t, dir, "staged.txt", "s\n")
+	runGitCmd(t, dir, "add", "staged.txt")
+
+	status := runGitTool(t, g, map[string]interface{}{"operation": "status"})
+	if status["branch"] != "main" || status["clean"] != false {
+		t.Fatalf("unexpected status header %v", status)
+	}
+	files := status["files"].([]map[string]interface{})
+	got := map[string]string{}
+	for _, f := range files {
+		got[f["path"].(string)] = strings.TrimSpace(strings.Join([]string{gitField(f["staged"]), gitField(f["unstaged"])}, " "))
+	}
+	if got["a.txt"] != "modified" || got["staged.txt"] != "added" || got["new.txt"] != "untracked" {
+		t.Fatalf("unexpected file states %v", got)
+	}
+
+	diff := runGitTool(t, g, map[string]interface{}{"operation": "diff"})
+	diffFiles := diff["files"].([]map[string]interface{})
+	if len(diffFiles) != 1 || diffFiles[0]["path"] != "a.txt" || diffFiles[0]["additions"] != 2 || diffFiles[0]["deletions"] != 1 {
+		t.Fatalf("unexpected unstaged diff %v", diffFiles)
+	}
+	if !strings.Contains(diff["patch"].(string), "+TWO") {
+		t.Fatalf("patch missing change: %q", diff["patch"])
+	}
+	staged := runGitTool(t, g, map[string]interface{}{"operation": "diff", "staged": true})
+	if files := staged["files"].([]map[string]interface{}); len(files) != 1 || files[0]["path"] != "staged.txt" {
+		t.Fatalf("unexpected staged diff %v", files)
+	}
+
+	runGitCmd(t, dir, "commit", "-q", "-am", "second\n\nbody text")
+	log := runGitTool(t, g, map[string]interface{}{"operation": "log", "max_count": 5})
+	commits := log["commits"].([]map[string]interface{})
+	if len(commits) != 2 || commits[0]["subject"] != "second" || commits[1]["subject"] != "initial" || commits[0]["author"] != "Tester" {
+		t.Fatalf("unexpected log %v", commits)
+	}
+	if parents := commits[0]["parents"].([]string); len(parents) != 1 || parents[0] != commits[1]["hash"] {
+		t.Fatalf("unexpected parents %v", parents)
+	}
+	if limited := runGitTool(t, g, map[string]interface{}{"operation": "log", "paths": []interface{}{"staged.txt"}}); len(limited["commits"].([]map[string]interface{})) != 1 {
+		t.Fatalf("path-limited log should only include the second commit")
+	}
+
+	show := runGitTool(t, g, map[string]interface{}{"operation": "show"})
+	if show["subject"] != "second" || show["body"] != "body text" || len(show["files"].([]map[string]interface{})) != 2 {
+		t.Fatalf("unexpected show %v", show)
+	}
+
+	blame := runGitTool(t, g, map[string]interface{}{"operation": "blame", "file_path": "a.txt", "start_line": 2, "end_line": 3})
+	lines := blame["lines"].([]map[string]interface{})
+	if len(lines) != 2 || lines[0]["line"] != 2 || lines[0]["content"] != "TWO" || lines[0]["summary"] != "second" || lines[0]["author"] != "Tester" {
+		t.Fatalf("unexpected blame %v", lines)
+	}
+	if full := runGitTool(t, g, map[string]interface{}{"operation": "blame", "file_path": "a.txt"}); full["lines"].([]map[string]interface{})[0]["summary"] != "initial" {
+		t.Fatalf("first line should blame the initial commit: %v", full["lines"])
+	}
+
+	branches := runGitTool(t, g, map[string]interface{}{"operation": "branch"})
+	if branches["current"] != "main" || len(branches["branches"].([]map[string]interface{})) != 1 {
+		t.Fatalf("unexpected branches %v", branches)
+	}
+}
+
+func gitField(v interface{}) string {
+	s, _ := v.(string)
+	return s
+}
+
+func TestGitToolCommitGuards(t *testing.T) {
+	dir := newGitTestRepo(t)
+	g := NewGitToolWithRoot(dir)
+	ctx := context.Background()
+
+	writeTestFile(t, dir, "b.txt", "b\n")
+	runGitTool(t, g, map[string]interface{}{"operation": "add", "paths": []interface{}{"b.txt"}})
+	if _, err := g.Execute(ctx, map[string]interface{}{"operation": "commit", "message": "on main"}); err == nil || !strings.Contains(err.Error(), `protected branch "main"`) {
+		t.Fatalf("expected protected branch refusal, got %v", err)
+	}
+
+	runGitTool(t, g, map[string]interface{}{"operation": "branch", "action": "create", "name": "feature"})
+	runGitTool(t, g, map[string]interface{}{"operation": "branch", "action": "switch", "name": "feature"})
+	commit := runGitTool(t, g, map[string]interface{}{"operation": "commit", "message": "add b"})
+	if commit["branch"] != "feature" || commit["subject"] != "add b" || commit["co_authored_by"] != true {
+		t.Fatalf("unexpected commit result %v", commit)
+	}
+	if body := runGitCmd(t, dir, "log", "-1", "--format=%B"); !strings.HasSuffix(body, "Co-Authored-By: "+DefaultGitCoAuthor) {
+		t.Fatalf("expected co-author trailer, got %q", body)
+	}
+
+	if _, err := g.Execute(ctx, map[string]interface{}{"operation": "commit", "message": "x", "amend": true}); err == nil || !strings.Contains(err.Error(), "history rewrites are disabled") {
+		t.Fatalf("expected amend refusal, got %v", err)
+	}
+	policy := DefaultGitPolicy()
+	policy.AllowHistoryRewrite = true
+	policy.CoAuthoredBy = false
+	g.SetPolicy(policy)
+	writeTestFile(t, dir, "b.txt", "bb\n")
+	runGitTool(t, g, map[string]interface{}{"operation": "commit", "message": "add b (amended)", "amend": true, "all": true})
+	if body := runGitCmd(t, dir, "log", "-1", "--format=%B"); body != "add b (amended)" {
+		t.Fatalf("expected amended message without trailer, got %q", body)
+	}
+	if count := runGitCmd(t, dir, "rev-list", "--count", "HEAD"); count != "2" {
+		t.Fatalf("amend should not add a commit, got %s", count)
+	}
+
+	if _, err := g.Execute(ctx, map[string]interface{}{"operation": "branch", "action": "delete", "name": "main"}); err == nil || !strings.Contains(err.Error(), "protected") {
+		t.Fatalf("expected protected delete refusal, got %v", err)
+	}
+	runGitTool(t, g, map[string]interface{}{"operation": "branch", "action": "create", "name": "scratch"})
+	g.SetPolicy(DefaultGitPolicy())
+	if _, err := g.Execute(ctx, map[string]interface{}{"operation": "branch", "action": "delete", "name": "scratch", "force": true}); err == nil || !strings.Contains(err.Error(), "history rewrites are disabled") {
+		t.Fatalf("expected force delete refusal, got %v", err)
+	}
+	runGitTool(t, g, map[string]interface{}{"operation": "branch", "action": "delete", "name": "scratch"})
+
+	g.SetPolicy(GitPolicy{ProtectedBranches: []string{"release/*"}})
+	runGitTool(t, g, map[string]interface{}{"operation": "branch", "action": "switch", "name": "main"})
+	writeTestFile(t, dir, "c.txt", "c\n")
+	runGitTool(t, g, map[string]interface{}{"operation": "add", "all": true})
+	runGitTool(t, g, map[string]interface{}{"operation": "commit", "message": "allowed on main"})
+	runGitTool(t, g, map[string]interface{}{"operation": "branch", "action": "create", "name": "release/1.0"})
+	runGitTool(t, g, map[string]interface{}{"operation": "branch", "action": "switch", "name": "release/1.0"})
+	if _, err := g.Execute(ctx, map[string]interface{}{"operation": "commit", "message": "nope"}); err == nil || !strings.Contains(err.Error(), "release/1.0") {
+		t.Fatalf("expected glob-protected refusal, got %v", err)
+	}
+}
+
+func TestGitToolStash(t *testing.T) {
+	dir := newGitTestRepo(t)
+	g := NewGitToolWithRoot(dir)
+
+	writeTestFile(t, dir, "a.txt", "changed\n")
+	pushed := runGitTool(t, g, map[string]interface{}{"operation": "stash", "message": "wip"})
+	if pushed["stashed"] != true {
+		t.Fatalf("expected stash, got %v", pushed)
+	}
+	list := runGitTool(t, g, map[string]interface{}{"operation": "stash", "action": "list"})
+	stashes := list["stashes"].([]map[string]interface{})
+	if len(stashes) != 1 || stashes[0]["name"] != "stash@{0}" || !strings.Contains(stashes[0]["message"].(string), "wip") {
+		t.Fatalf("unexpected stash list %v", stashes)
+	}
+	runGitTool(t, g, map[string]interface{}{"operation": "stash", "action": "pop"})
+	if data, _ := os.ReadFile(filepath.Join(dir, "a.txt")); string(data) != "changed\n" {
+		t.Fatalf("stash pop did not restore changes: %q", data)
+	}
+	if empty := runGitTool(t, g, map[string]interface{}{"operation": "stash", "action": "list"}); len(empty["stashes"].([]map[string]interface{})) != 0 {
+		t.Fatal("expected empty stash list")
+	}
+}
+
+func TestGitToolErrors(t *testing.T) {
+	dir := newGitTestRepo(t)
+	g := NewGitToolWithRoot(dir)
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{"missing operation", map[string]interface{}{}, "operation is required"},
+		{"unknown operation", map[string]interface{}{"operation": "rebase"}, "unsupported operation"},
+		{"option injection", map[string]interface{}{"operation": "log", "ref": "--output=/tmp/x"}, "not a valid git reference"},
+		{"path escape", map[string]interface{}{"operation": "add", "paths": []interface{}{"../outside"}}, ""},
+		{"missing message", map[string]interface{}{"operation": "commit"}, "message is required"},
+		{"missing add paths", map[string]interface{}{"operation": "add"}, "paths is required"},
+		{"missing blame file", map[string]interface{}{"operation": "blame"}, "file_path is required"},
+		{"bad branch action", map[string]interface{}{"operation": "branch", "action": "rename", "name": "x"}, "unsupported branch action"},
+		{"unknown ref", map[string]interface{}{"operation": "show", "ref": "nope"}, "git log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := g.Execute(context.Background(), tt.params); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	notRepo := NewGitToolWithRoot(cleanTempDir(t))
+	if _, err := notRepo.Execute(context.Background(), map[string]interface{}{"operation": "status"}); err == nil || !strings.Contains(err.Error(), "not a git repository") {
+		t.Fatalf("expected not a repository error, got %v", err)
+	}
+}
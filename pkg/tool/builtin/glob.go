@@ -44,6 +44,7 @@ type GlobTool struct {
 	maxResults       int
 	respectGitignore bool
 	gitignoreMatcher *gitignore.Matcher
+	index            *CodeIndex
 }
 
 // NewGlobTool builds a GlobTool rooted at the current directory.
@@ -79,6 +80,15 @@ func (g *GlobTool) SetRespectGitignore(respect bool) {
 	}
 }
 
+// SetCodeIndex answers wildcard patterns under the root from the index's
+// file list instead of reading directories. It is only consulted while
+// gitignore filtering is on, since the index skips ignored paths.
+func (g *GlobTool) SetCodeIndex(index *CodeIndex) {
+	if g != nil {
+		g.index = index
+	}
+}
+
 func (g *GlobTool) Name() string { return "glob" }
 
 func (g *GlobTool) Description() string { return globToolDesc }
@@ -118,7 +128,7 @@ func (g *GlobTool) Execute(ctx context.Context, params map[string]interface{}) (
 		g.gitignoreMatcher, _ = gitignore.NewMatcher(g.root) //nolint:errcheck // best-effort gitignore
 	}
 
-	matches, err := filepath.Glob(absPattern)
+	matches, err := g.glob(absPattern)
 	if err != nil {
 		return nil, fmt.Errorf("glob failed: %w", err)
 	}
@@ -163,6 +173,15 @@ func (g *GlobTool) Execute(ctx context.Context, params map[string]interface{}) (
 	}, nil
 }
 
+func (g *GlobTool) glob(absPattern string) ([]string, error) {
+	if g.index != nil && g.respectGitignore && g.index.Root() == g.root {
+		if matches, ok, err := g.index.Glob(absPattern); ok {
+			return matches, err
+		}
+	}
+	return filepath.Glob(absPattern)
+}
+
 func parseGlobPattern(params map[string]interface{}) (string, error) {
 	if params == nil {
 		return "", errors.New("params is nil")
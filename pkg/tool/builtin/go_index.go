@@ -0,0 +1,368 @@
+package toolbuiltin
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	goIndexMaxFiles      = 20000
+	goIndexMaxTypeErrors = 10
+)
+
+var errGoImportCycle = errors.New("import cycle")
+
+// goIndex parses and type-checks the Go packages under a root. Files are
+// re-parsed only when their mtime or size changes and packages are re-checked
+// only when one of their files or in-module dependencies changed.
+type goIndex struct {
+	mu     sync.Mutex
+	root   string
+	fset   *token.FileSet
+	module string
+	files  map[string]*goFileEntry
+	pkgs   map[string]*goPackage
+	std    types.ImporterFrom
+}
+
+type goFileEntry struct {
+	modTime time.Time
+	size    int64
+	src     []byte
+	file    *ast.File
+}
+
+type goPackage struct {
+	path  string
+	dir   string
+	name  string
+	files []string
+	asts  []*ast.File
+
+	filesKey string
+	checkKey string
+	checking bool
+	types    *types.Package
+	info     *types.Info
+	errs     []error
+}
+
+func newGoIndex(root string) *goIndex {
+	fset := token.NewFileSet()
+	idx := &goIndex{
+		root:  root,
+		fset:  fset,
+		files: map[string]*goFileEntry{},
+		pkgs:  map[string]*goPackage{},
+	}
+	if std, ok := importer.ForCompiler(fset, "source", nil).(types.ImporterFrom); ok {
+		idx.std = std
+	}
+	return idx
+}
+
+// refresh rescans the tree and brings every package up to date. The caller
+// must hold idx.mu.
+func (idx *goIndex) refresh(ctx context.Context) error {
+	idx.module = readModulePath(filepath.Join(idx.root, "go.mod"))
+	dirs, err := idx.scan(ctx)
+	if err != nil {
+		return err
+	}
+
+	pkgs := make(map[string]*goPackage, len(dirs))
+	for dir, files := range dirs {
+		pkg := idx.buildPackage(dir, files)
+		if pkg == nil {
+			continue
+		}
+		if prev := idx.pkgs[pkg.path]; prev != nil && prev.filesKey == pkg.filesKey {
+			pkg = prev
+		}
+		pkgs[pkg.path] = pkg
+	}
+	idx.pkgs = pkgs
+
+	paths := make([]string, 0, len(pkgs))
+	for p := range pkgs {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		idx.check(pkgs[p])
+	}
+	return nil
+}
+
+// scan walks the root, parses new or modified files and returns the buildable
+// non-test files grouped by directory.
+func (idx *goIndex) scan(ctx context.Context) (map[string][]string, error) {
+	dirs := map[string][]string{}
+	seen := map[string]struct{}{}
+	count := 0
+	err := filepath.WalkDir(idx.root, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if p == idx.root {
+				return walkErr
+			}
+			return nil
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if d.Type()&fs.ModeSymlink != 0 {
+			return nil
+		}
+		name := d.Name()
+		if d.IsDir() {
+			if p == idx.root {
+				return nil
+			}
+			if name == "vendor" || name == "testdata" || name == "node_modules" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			// Nested modules are indexed on their own.
+			if _, err := os.Stat(filepath.Join(p, "go.mod")); err == nil {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			return nil
+		}
+		dir := filepath.Dir(p)
+		if ok, err := build.Default.MatchFile(dir, name); err != nil || !ok {
+			return nil
+		}
+		count++
+		if count > goIndexMaxFiles {
+			return fmt.Errorf("more than %d Go files under %s", goIndexMaxFiles, idx.root)
+		}
+		if err := idx.parseFile(p); err != nil {
+			return nil
+		}
+		seen[p] = struct{}{}
+		dirs[dir] = append(dirs[dir], p)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for p := range idx.files {
+		if _, ok := seen[p]; !ok {
+			delete(idx.files, p)
+		}
+	}
+	return dirs, nil
+}
+
+func (idx *goIndex) parseFile(p string) error {
+	info, err := os.Stat(p)
+	if err != nil {
+		return err
+	}
+	if entry := idx.files[p]; entry != nil && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		return nil
+	}
+	src, err := os.ReadFile(p)
+	if err != nil {
+		return err
+	}
+	// Syntax errors still yield a partial AST worth indexing.
+	file, _ := parser.ParseFile(idx.fset, p, src, parser.ParseComments|parser.SkipObjectResolution)
+	if file == nil {
+		return errors.New("unparseable file")
+	}
+	idx.files[p] = &goFileEntry{modTime: info.ModTime(), size: info.Size(), src: src, file: file}
+	return nil
+}
+
+func (idx *goIndex) buildPackage(dir string, files []string) *goPackage {
+	sort.Strings(files)
+	// A directory holds one package; stray files (for example "package
+	// main" generators with a build tag) under another name are ignored.
+	counts := map[string]int{}
+	for _, f := range files {
+		counts[idx.files[f].file.Name.Name]++
+	}
+	name := ""
+	for candidate, n := range counts {
+		if n > counts[name] || (n == counts[name] && candidate < name) {
+			name = candidate
+		}
+	}
+	pkg := &goPackage{path: idx.importPath(dir), dir: dir, name: name}
+	var key strings.Builder
+	for _, f := range files {
+		entry := idx.files[f]
+		if entry.file.Name.Name != name {
+			continue
+		}
+		pkg.files = append(pkg.files, f)
+		pkg.asts = append(pkg.asts, entry.file)
+		fmt.Fprintf(&key, "%s\x00%d\x00%d\n", f, entry.modTime.UnixNano(), entry.size)
+	}
+	if len(pkg.files) == 0 {
+		return nil
+	}
+	pkg.filesKey = key.String()
+	return pkg
+}
+
+func (idx *goIndex) importPath(dir string) string {
+	rel, err := filepath.Rel(idx.root, dir)
+	if err != nil {
+		rel = dir
+	}
+	rel = filepath.ToSlash(rel)
+	switch {
+	case idx.module == "":
+		return rel
+	case rel == ".":
+		return idx.module
+	default:
+		return path.Join(idx.module, rel)
+	}
+}
+
+// check type-checks pkg after its in-module dependencies, skipping the work
+// when neither its files nor its dependencies changed.
+func (idx *goIndex) check(pkg *goPackage) {
+	if pkg.checking {
+		return
+	}
+	pkg.checking = true
+	defer func() { pkg.checking = false }()
+
+	var key strings.Builder
+	key.WriteString(pkg.filesKey)
+	for _, imp := range pkg.imports() {
+		if dep := idx.pkgs[imp]; dep != nil && !dep.checking {
+			idx.check(dep)
+			fmt.Fprintf(&key, "%s=%s\n", imp, dep.checkKey)
+		}
+	}
+	if pkg.types != nil && pkg.checkKey == key.String() {
+		return
+	}
+
+	pkg.errs = nil
+	pkg.info = &types.Info{
+		Defs: map[*ast.Ident]types.Object{},
+		Uses: map[*ast.Ident]types.Object{},
+	}
+	conf := types.Config{
+		Importer:    goPackageImporter{idx: idx, from: pkg},
+		FakeImportC: true,
+		Error: func(err error) {
+			if len(pkg.errs) < goIndexMaxTypeErrors {
+				pkg.errs = append(pkg.errs, err)
+			}
+		},
+	}
+	pkg.types, _ = conf.Check(pkg.path, idx.fset, pkg.asts, pkg.info)
+	pkg.checkKey = key.String()
+}
+
+func (pkg *goPackage) imports() []string {
+	seen := map[string]struct{}{}
+	var out []string
+	for _, file := range pkg.asts {
+		for _, spec := range file.Imports {
+			p := strings.Trim(spec.Path.Value, "\"`")
+			if _, ok := seen[p]; ok {
+				continue
+			}
+			seen[p] = struct{}{}
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// goPackageImporter resolves in-module imports from the index and everything
+// else from source via go/build.
+type goPackageImporter struct {
+	idx  *goIndex
+	from *goPackage
+}
+
+func (imp goPackageImporter) Import(p string) (*types.Package, error) {
+	if dep := imp.idx.pkgs[p]; dep != nil {
+		if dep.checking {
+			return nil, fmt.Errorf("%w via %s", errGoImportCycle, p)
+		}
+		imp.idx.check(dep)
+		if dep.types == nil {
+			return nil, fmt.Errorf("package %s could not be type-checked", p)
+		}
+		return dep.types, nil
+	}
+	if imp.idx.std == nil {
+		return nil, fmt.Errorf("cannot import %s", p)
+	}
+	return imp.idx.std.ImportFrom(p, imp.from.dir, 0)
+}
+
+// packageList returns the indexed packages ordered by import path.
+func (idx *goIndex) packageList() []*goPackage {
+	out := make([]*goPackage, 0, len(idx.pkgs))
+	for _, pkg := range idx.pkgs {
+		if pkg.types != nil {
+			out = append(out, pkg)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].path < out[j].path })
+	return out
+}
+
+// sourceLine returns the trimmed source line at pos.
+func (idx *goIndex) sourceLine(pos token.Position) string {
+	entry := idx.files[pos.Filename]
+	if entry == nil || pos.Line <= 0 {
+		return ""
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(entry.src))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if line == pos.Line {
+			return strings.TrimSpace(scanner.Text())
+		}
+	}
+	return ""
+}
+
+func readModulePath(goMod string) string {
+	data, err := os.ReadFile(goMod)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if rest, ok := strings.CutPrefix(line, "module"); ok && (rest == "" || rest[0] == ' ' || rest[0] == '\t') {
+			return strings.Trim(strings.TrimSpace(rest), "\"`")
+		}
+	}
+	return ""
+}
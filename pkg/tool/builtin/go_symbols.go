@@ -0,0 +1,569 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const GoSymbolsName = "go_symbols"
+
+const goSymbolsMaxResults = 200
+
+const goSymbolsDescription = `Answers questions about Go code under the sandbox root by parsing and type-checking it with the standard library (no language server needed).
+Usage:
+- operation package_api lists the exported API of package (import path, directory or package name).
+- operation definition finds where symbol is declared; symbol may be Name, Type.Method, pkg.Name or pkg.Type.Method.
+- operation callers lists the static call sites of the function or method named by symbol.
+- operation implementations lists the types that implement the interface named by symbol.
+- package optionally narrows definition, callers and implementations to one package.
+- Results are cached and refreshed incrementally as files change; test files are not indexed.`
+
+var goSymbolsSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"operation": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"package_api", "definition", "callers", "implementations"},
+			"description": "The query to run.",
+		},
+		"package": map[string]interface{}{
+			"type":        "string",
+			"description": "Import path, directory (relative to the sandbox root) or package name.",
+		},
+		"symbol": map[string]interface{}{
+			"type":        "string",
+			"description": "Symbol to look up, e.g. NewClient, Client.Do, http.Client or (*Client).Do.",
+		},
+	},
+	Required: []string{"operation"},
+}
+
+// GoSymbolsTool answers definition, caller, implementation and package API
+// queries over the Go packages under the sandbox root.
+type GoSymbolsTool struct {
+	base *fileSandbox
+
+	once  sync.Once
+	index *goIndex
+}
+
+// NewGoSymbolsTool builds a GoSymbolsTool rooted at the current directory.
+func NewGoSymbolsTool() *GoSymbolsTool {
+	return NewGoSymbolsToolWithRoot("")
+}
+
+// NewGoSymbolsToolWithRoot builds a GoSymbolsTool rooted at the provided directory.
+func NewGoSymbolsToolWithRoot(root string) *GoSymbolsTool {
+	return &GoSymbolsTool{base: newFileSandbox(root)}
+}
+
+// NewGoSymbolsToolWithSandbox builds a GoSymbolsTool using a custom sandbox.
+func NewGoSymbolsToolWithSandbox(root string, policy sandbox.FileSystemPolicy) *GoSymbolsTool {
+	return &GoSymbolsTool{base: newFileSandboxWithSandbox(root, policy)}
+}
+
+func (g *GoSymbolsTool) Name() string { return GoSymbolsName }
+
+func (g *GoSymbolsTool) Description() string { return goSymbolsDescription }
+
+func (g *GoSymbolsTool) Schema() *tool.JSONSchema { return goSymbolsSchema }
+
+func (g *GoSymbolsTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsReadOnly: true, IsConcurrencySafe: true}
+}
+
+func (g *GoSymbolsTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if g == nil || g.base == nil {
+		return nil, errors.New("go_symbols tool is not initialised")
+	}
+	if params == nil {
+		return nil, errors.New("params is nil")
+	}
+	op, err := goSymbolsParam(params, "operation")
+	if err != nil {
+		return nil, err
+	}
+	op = strings.ToLower(op)
+	if op == "" {
+		return nil, errors.New("operation is required")
+	}
+	pkgArg, err := goSymbolsParam(params, "package")
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := goSymbolsParam(params, "symbol")
+	if err != nil {
+		return nil, err
+	}
+	switch op {
+	case "package_api":
+		if pkgArg == "" {
+			return nil, errors.New("package is required for package_api")
+		}
+	case "definition", "callers", "implementations":
+		if symbol == "" {
+			return nil, fmt.Errorf("symbol is required for %s", op)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", op)
+	}
+
+	g.once.Do(func() { g.index = newGoIndex(g.base.root) })
+	idx := g.index
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	if err := idx.refresh(ctx); err != nil {
+		return nil, fmt.Errorf("index go packages: %w", err)
+	}
+	if len(idx.pkgs) == 0 {
+		return nil, fmt.Errorf("no Go packages found under %s", g.base.root)
+	}
+
+	var scope []*goPackage
+	if pkgArg != "" {
+		pkg, err := g.findPackage(idx, pkgArg)
+		if err != nil {
+			return nil, err
+		}
+		scope = []*goPackage{pkg}
+	} else {
+		scope = idx.packageList()
+	}
+
+	var lines []string
+	switch op {
+	case "package_api":
+		lines = g.packageAPI(scope[0])
+	case "definition":
+		objs := g.resolveSymbol(idx, scope, symbol)
+		if len(objs) == 0 {
+			return nil, fmt.Errorf("symbol %q not found", symbol)
+		}
+		for _, obj := range objs {
+			lines = append(lines, fmt.Sprintf("%s: %s", g.position(idx, obj.Pos()), goObjectString(obj)))
+		}
+	case "callers":
+		lines, err = g.callers(idx, scope, symbol)
+	case "implementations":
+		lines, err = g.implementations(idx, scope, symbol)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	truncated := false
+	if op != "package_api" && len(lines) > goSymbolsMaxResults {
+		lines = append(lines[:goSymbolsMaxResults], fmt.Sprintf("... %d more results omitted", len(lines)-goSymbolsMaxResults))
+		truncated = true
+	}
+	output := strings.Join(lines, "\n")
+	if output == "" {
+		output = "no results"
+	}
+	var typeErrors []string
+	for _, pkg := range scope {
+		for _, e := range pkg.errs {
+			typeErrors = append(typeErrors, e.Error())
+		}
+	}
+	if len(typeErrors) > 0 {
+		output += fmt.Sprintf("\n\nnote: %d type error(s) in the queried packages; results may be incomplete (first: %s)", len(typeErrors), typeErrors[0])
+	}
+	return &tool.ToolResult{
+		Success: true,
+		Output:  output,
+		Data: map[string]interface{}{
+			"operation":   op,
+			"results":     len(lines),
+			"truncated":   truncated,
+			"type_errors": typeErrors,
+		},
+	}, nil
+}
+
+// findPackage matches arg against import paths, directories, import path
+// suffixes and package names, in that order.
+func (g *GoSymbolsTool) findPackage(idx *goIndex, arg string) (*goPackage, error) {
+	if pkg := idx.pkgs[arg]; pkg != nil && pkg.types != nil {
+		return pkg, nil
+	}
+	if dir, err := g.base.resolvePath(arg); err == nil {
+		for _, pkg := range idx.packageList() {
+			if pkg.dir == dir {
+				return pkg, nil
+			}
+		}
+	}
+	var matches []*goPackage
+	for _, pkg := range idx.packageList() {
+		if strings.HasSuffix(pkg.path, "/"+strings.Trim(arg, "/")) || pkg.name == arg {
+			matches = append(matches, pkg)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("package %q not found", arg)
+	case 1:
+		return matches[0], nil
+	default:
+		paths := make([]string, len(matches))
+		for i, pkg := range matches {
+			paths[i] = pkg.path
+		}
+		return nil, fmt.Errorf("package %q is ambiguous: %s", arg, strings.Join(paths, ", "))
+	}
+}
+
+func (g *GoSymbolsTool) packageAPI(pkg *goPackage) []string {
+	lines := []string{fmt.Sprintf("package %s // import %q", pkg.name, pkg.path), ""}
+	scope := pkg.types.Scope()
+	var consts, vars, funcs, typeNames []types.Object
+	for _, name := range scope.Names() {
+		obj := scope.Lookup(name)
+		if !obj.Exported() {
+			continue
+		}
+		switch obj.(type) {
+		case *types.Const:
+			consts = append(consts, obj)
+		case *types.Var:
+			vars = append(vars, obj)
+		case *types.Func:
+			funcs = append(funcs, obj)
+		case *types.TypeName:
+			typeNames = append(typeNames, obj)
+		}
+	}
+	for _, group := range [][]types.Object{consts, vars, funcs} {
+		for _, obj := range group {
+			lines = append(lines, fmt.Sprintf("%s  // %s", goObjectString(obj), g.shortPosition(obj.Pos())))
+		}
+	}
+	for _, obj := range typeNames {
+		lines = append(lines, fmt.Sprintf("%s  // %s", goObjectString(obj), g.shortPosition(obj.Pos())))
+		named, ok := obj.Type().(*types.Named)
+		if !ok || obj.(*types.TypeName).IsAlias() {
+			continue
+		}
+		qualifier := goQualifier(pkg.types)
+		switch u := named.Underlying().(type) {
+		case *types.Struct:
+			for i := 0; i < u.NumFields(); i++ {
+				if field := u.Field(i); field.Exported() {
+					if field.Embedded() {
+						lines = append(lines, "\t"+types.TypeString(field.Type(), qualifier))
+					} else {
+						lines = append(lines, fmt.Sprintf("\t%s %s", field.Name(), types.TypeString(field.Type(), qualifier)))
+					}
+				}
+			}
+		case *types.Interface:
+			for i := 0; i < u.NumEmbeddeds(); i++ {
+				lines = append(lines, "\t"+types.TypeString(u.EmbeddedType(i), qualifier))
+			}
+			for i := 0; i < u.NumExplicitMethods(); i++ {
+				if m := u.ExplicitMethod(i); m.Exported() {
+					lines = append(lines, "\t"+m.Name()+strings.TrimPrefix(types.TypeString(m.Type(), qualifier), "func"))
+				}
+			}
+		}
+		for i := 0; i < named.NumMethods(); i++ {
+			if m := named.Method(i); m.Exported() {
+				lines = append(lines, "\t"+goObjectString(m))
+			}
+		}
+	}
+	return lines
+}
+
+// resolveSymbol finds the objects named by symbol. Qualifiers may name a
+// package (in the module or imported by it) and/or a receiver type.
+func (g *GoSymbolsTool) resolveSymbol(idx *goIndex, scope []*goPackage, symbol string) []types.Object {
+	symbol = strings.NewReplacer("(", "", ")", "", "*", "").Replace(strings.TrimSpace(symbol))
+	parts := strings.Split(symbol, ".")
+	var out []types.Object
+	seen := map[types.Object]struct{}{}
+	add := func(obj types.Object) {
+		if obj == nil {
+			return
+		}
+		if _, ok := seen[obj]; !ok {
+			seen[obj] = struct{}{}
+			out = append(out, obj)
+		}
+	}
+	lookup := func(pkgs []*types.Package, names []string) {
+		for _, p := range pkgs {
+			obj := p.Scope().Lookup(names[0])
+			if obj == nil {
+				continue
+			}
+			if len(names) == 1 {
+				add(obj)
+			} else if len(names) == 2 {
+				if _, ok := obj.(*types.TypeName); ok {
+					member, _, _ := types.LookupFieldOrMethod(obj.Type(), true, obj.Pkg(), names[1])
+					add(member)
+				}
+			}
+		}
+	}
+
+	local := make([]*types.Package, 0, len(scope))
+	for _, pkg := range scope {
+		local = append(local, pkg.types)
+	}
+	if len(parts) <= 2 {
+		lookup(local, parts)
+	}
+	if len(parts) >= 2 {
+		lookup(goPackagesNamed(idx, parts[0]), parts[1:])
+	}
+	if len(out) == 0 && len(parts) == 1 {
+		add(types.Universe.Lookup(parts[0]))
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return goObjectPath(out[i]) < goObjectPath(out[j])
+	})
+	return out
+}
+
+// goPackagesNamed returns module and imported packages whose name, import
+// path or import path suffix equals qualifier.
+func goPackagesNamed(idx *goIndex, qualifier string) []*types.Package {
+	seen := map[*types.Package]struct{}{}
+	var out []*types.Package
+	consider := func(p *types.Package) {
+		if _, ok := seen[p]; ok {
+			return
+		}
+		seen[p] = struct{}{}
+		if p.Name() == qualifier || p.Path() == qualifier || strings.HasSuffix(p.Path(), "/"+qualifier) {
+			out = append(out, p)
+		}
+	}
+	for _, pkg := range idx.packageList() {
+		consider(pkg.types)
+		for _, imp := range pkg.types.Imports() {
+			consider(imp)
+		}
+	}
+	return out
+}
+
+func (g *GoSymbolsTool) callers(idx *goIndex, scope []*goPackage, symbol string) ([]string, error) {
+	targets := map[*types.Func]struct{}{}
+	for _, obj := range g.resolveSymbol(idx, idx.packageList(), symbol) {
+		if fn, ok := obj.(*types.Func); ok {
+			targets[fn] = struct{}{}
+		}
+	}
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("function %q not found", symbol)
+	}
+	var lines []string
+	for _, pkg := range scope {
+		for _, file := range pkg.asts {
+			for _, decl := range file.Decls {
+				caller := "package initialisation"
+				if fn, ok := decl.(*ast.FuncDecl); ok {
+					caller = goFuncDeclName(fn)
+				}
+				ast.Inspect(decl, func(n ast.Node) bool {
+					call, ok := n.(*ast.CallExpr)
+					if !ok {
+						return true
+					}
+					ident := goCalleeIdent(call.Fun)
+					if ident == nil {
+						return true
+					}
+					fn, ok := pkg.info.Uses[ident].(*types.Func)
+					if !ok {
+						return true
+					}
+					if _, hit := targets[fn.Origin()]; hit {
+						pos := idx.fset.Position(call.Pos())
+						lines = append(lines, fmt.Sprintf("%s: %s: %s", g.position(idx, call.Pos()), caller, idx.sourceLine(pos)))
+					}
+					return true
+				})
+			}
+		}
+	}
+	return lines, nil
+}
+
+func (g *GoSymbolsTool) implementations(idx *goIndex, scope []*goPackage, symbol string) ([]string, error) {
+	var iface *types.Interface
+	var ifaceObj types.Object
+	for _, obj := range g.resolveSymbol(idx, idx.packageList(), symbol) {
+		if tn, ok := obj.(*types.TypeName); ok {
+			if it, ok := tn.Type().Underlying().(*types.Interface); ok {
+				iface, ifaceObj = it, obj
+				break
+			}
+		}
+	}
+	if iface == nil {
+		return nil, fmt.Errorf("interface %q not found", symbol)
+	}
+	var lines []string
+	for _, pkg := range scope {
+		s := pkg.types.Scope()
+		for _, name := range s.Names() {
+			tn, ok := s.Lookup(name).(*types.TypeName)
+			if !ok || tn == ifaceObj || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 || types.IsInterface(named) {
+				continue
+			}
+			var impl string
+			switch {
+			case types.Implements(named, iface):
+				impl = tn.Name()
+			case types.Implements(types.NewPointer(named), iface):
+				impl = "*" + tn.Name()
+			default:
+				continue
+			}
+			lines = append(lines, fmt.Sprintf("%s: %s.%s", g.position(idx, tn.Pos()), pkg.name, impl))
+		}
+	}
+	return lines, nil
+}
+
+func (g *GoSymbolsTool) position(idx *goIndex, pos token.Pos) string {
+	p := idx.fset.Position(pos)
+	if !p.IsValid() {
+		return "<builtin>"
+	}
+	return fmt.Sprintf("%s:%d:%d", displayPath(p.Filename, g.base.root), p.Line, p.Column)
+}
+
+func (g *GoSymbolsTool) shortPosition(pos token.Pos) string {
+	p := g.index.fset.Position(pos)
+	return fmt.Sprintf("%s:%d", filepath.Base(p.Filename), p.Line)
+}
+
+func goSymbolsParam(params map[string]interface{}, key string) (string, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	value, err := coerceString(raw)
+	if err != nil {
+		return "", fmt.Errorf("%s must be string: %w", key, err)
+	}
+	return strings.TrimSpace(value), nil
+}
+
+func goQualifier(pkg *types.Package) types.Qualifier {
+	return func(other *types.Package) string {
+		if other == pkg {
+			return ""
+		}
+		return other.Name()
+	}
+}
+
+// goObjectString renders obj without repeating its own package name and
+// without spelling out struct or interface bodies.
+func goObjectString(obj types.Object) string {
+	qualifier := goQualifier(obj.Pkg())
+	if c, ok := obj.(*types.Const); ok {
+		return types.ObjectString(c, qualifier) + " = " + c.Val().String()
+	}
+	tn, ok := obj.(*types.TypeName)
+	if !ok {
+		return types.ObjectString(obj, qualifier)
+	}
+	if tn.IsAlias() {
+		return fmt.Sprintf("type %s = %s", tn.Name(), types.TypeString(tn.Type(), qualifier))
+	}
+	var params string
+	if named, ok := tn.Type().(*types.Named); ok && named.TypeParams().Len() > 0 {
+		names := make([]string, named.TypeParams().Len())
+		for i := range names {
+			tp := named.TypeParams().At(i)
+			names[i] = tp.Obj().Name() + " " + types.TypeString(tp.Constraint(), qualifier)
+		}
+		params = "[" + strings.Join(names, ", ") + "]"
+	}
+	switch u := tn.Type().Underlying().(type) {
+	case *types.Struct:
+		return fmt.Sprintf("type %s%s struct", tn.Name(), params)
+	case *types.Interface:
+		return fmt.Sprintf("type %s%s interface", tn.Name(), params)
+	default:
+		return fmt.Sprintf("type %s%s %s", tn.Name(), params, types.TypeString(u, qualifier))
+	}
+}
+
+func goObjectPath(obj types.Object) string {
+	if obj.Pkg() == nil {
+		return ""
+	}
+	return obj.Pkg().Path() + "." + obj.Name()
+}
+
+func goFuncDeclName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	pointer := ""
+	if star, ok := recv.(*ast.StarExpr); ok {
+		pointer = "*"
+		recv = star.X
+	}
+	switch r := recv.(type) {
+	case *ast.IndexExpr:
+		recv = r.X
+	case *ast.IndexListExpr:
+		recv = r.X
+	}
+	name := "?"
+	if ident, ok := recv.(*ast.Ident); ok {
+		name = ident.Name
+	}
+	if pointer != "" {
+		return fmt.Sprintf("(*%s).%s", name, fn.Name.Name)
+	}
+	return name + "." + fn.Name.Name
+}
+
+func goCalleeIdent(fun ast.Expr) *ast.Ident {
+	for {
+		switch f := fun.(type) {
+		case *ast.ParenExpr:
+			fun = f.X
+		case *ast.IndexExpr:
+			fun = f.X
+		case *ast.IndexListExpr:
+			fun = f.X
+		case *ast.SelectorExpr:
+			return f.Sel
+		case *ast.Ident:
+			return f
+		default:
+			return nil
+		}
+	}
+}
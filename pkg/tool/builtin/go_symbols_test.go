@@ -0,0 +1,221 @@
+package toolbuiltin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var goSymbolsFixture = map[string]string{
+	"go.mod": "module example.com/demo\n\ngo 1.22\n",
+	"shapes/shapes.go": `package shapes
+
+// Shape is anything with an area.
+type Shape interface {
+	Area() float64
+}
+
+// Version of the shapes API.
+const Version = "1"
+
+type Square struct {
+	Side  float64
+	label string
+}
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+type Circle struct{ R float64 }
+
+func (c *Circle) Area() float64 { return 3 * c.R * c.R }
+
+// Total sums the areas of shapes.
+func Total(shapes ...Shape) float64 {
+	var sum float64
+	for _, s := range shapes {
+		sum += s.Area()
+	}
+	return sum
+}
+
+func helper() {}
+`,
+	"app/main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/demo/shapes"
+)
+
+func main() {
+	fmt.Println(shapes.Total(shapes.Square{Side: 2}))
+	run()
+}
+
+func run() {
+	_ = shapes.Total(&shapes.Circle{R: 1})
+}
+`,
+	"skipped/ignored_test.go": "package skipped\n\nfunc TestOnly() {}\n",
+}
+
+func writeGoSymbolsFixture(t *testing.T) string {
+	t.Helper()
+	dir := cleanTempDir(t)
+	for name, content := range goSymbolsFixture {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func runGoSymbols(t *testing.T, g *GoSymbolsTool, params map[string]interface{}) string {
+	t.Helper()
+	res, err := g.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("go_symbols %v: %v", params, err)
+	}
+	return res.Output
+}
+
+func TestGoSymbolsQueries(t *testing.T) {
+	skipIfWindows(t)
+	dir := writeGoSymbolsFixture(t)
+	g := NewGoSymbolsToolWithRoot(dir)
+
+	api := runGoSymbols(t, g, map[string]interface{}{"operation": "package_api", "package": "shapes"})
+	for _, want := range []string{
+		`package shapes // import "example.com/demo/shapes"`,
+		`const Version untyped string = "1"  // shapes.go:9`,
+		"func Total(shapes ...Shape) float64  // shapes.go:23",
+		"type Shape interface  // shapes.go:4",
+		"\tArea() float64",
+		"type Square struct  // shapes.go:11",
+		"\tSide float64",
+		"\tfunc (Square).Area() float64",
+		"\tfunc (*Circle).Area() float64",
+	} {
+		if !strings.Contains(api, want) {
+			t.Fatalf("package_api missing %q:\n%s", want, api)
+		}
+	}
+	if strings.Contains(api, "helper") || strings.Contains(api, "label") {
+		t.Fatalf("package_api leaked unexported names:\n%s", api)
+	}
+	if got := runGoSymbols(t, g, map[string]interface{}{"operation": "package_api", "package": "./app"}); !strings.HasPrefix(got, `package main // import "example.com/demo/app"`) {
+		t.Fatalf("directory lookup failed: %q", got)
+	}
+
+	if got := runGoSymbols(t, g, map[string]interface{}{"operation": "definition", "symbol": "Total"}); got != "shapes/shapes.go:23:6: func Total(shapes ...Shape) float64" {
+		t.Fatalf("unexpected definition %q", got)
+	}
+	if got := runGoSymbols(t, g, map[string]interface{}{"operation": "definition", "symbol": "(*shapes.Circle).Area"}); !strings.HasPrefix(got, "shapes/shapes.go:20:18: func (*Circle).Area()") {
+		t.Fatalf("unexpected method definition %q", got)
+	}
+	if got := runGoSymbols(t, g, map[string]interface{}{"operation": "definition", "symbol": "fmt.Println"}); !strings.Contains(got, "func Println(a ...any)") {
+		t.Fatalf("expected imported package lookup, got %q", got)
+	}
+
+	callers := runGoSymbols(t, g, map[string]interface{}{"operation": "callers", "symbol": "shapes.Total"})
+	want := "app/main.go:10:14: main: fmt.Println(shapes.Total(shapes.Square{Side: 2}))\napp/main.go:15:6: run: _ = shapes.Total(&shapes.Circle{R: 1})"
+	if callers != want {
+		t.Fatalf("unexpected callers:\n%s", callers)
+	}
+	if got := runGoSymbols(t, g, map[string]interface{}{"operation": "callers", "symbol": "Shape.Area"}); got != "shapes/shapes.go:26:10: Total: sum += s.Area()" {
+		t.Fatalf("unexpected interface callers %q", got)
+	}
+
+	if got := runGoSymbols(t, g, map[string]interface{}{"operation": "implementations", "symbol": "Shape"}); got != "shapes/shapes.go:18:6: shapes.*Circle\nshapes/shapes.go:11:6: shapes.Square" {
+		t.Fatalf("unexpected implementations %q", got)
+	}
+}
+
+func TestGoSymbolsIncrementalCache(t *testing.T) {
+	skipIfWindows(t)
+	dir := writeGoSymbolsFixture(t)
+	g := NewGoSymbolsToolWithRoot(dir)
+	runGoSymbols(t, g, map[string]interface{}{"operation": "definition", "symbol": "Total"})
+	shapes := g.index.pkgs["example.com/demo/shapes"].types
+	app := g.index.pkgs["example.com/demo/app"].types
+
+	touch := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		later := time.Now().Add(time.Minute)
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatalf("chtimes: %v", err)
+		}
+	}
+
+	touch("app/extra.go", "package main\n\nfunc Extra() { run() }\n")
+	if got := runGoSymbols(t, g, map[string]interface{}{"operation": "callers", "symbol": "run"}); !strings.Contains(got, "app/extra.go:3:16: Extra: func Extra() { run() }") {
+		t.Fatalf("new file not indexed: %q", got)
+	}
+	if g.index.pkgs["example.com/demo/shapes"].types != shapes {
+		t.Fatal("unchanged package should not be re-checked")
+	}
+	if g.index.pkgs["example.com/demo/app"].types == app {
+		t.Fatal("changed package should be re-checked")
+	}
+
+	app = g.index.pkgs["example.com/demo/app"].types
+	touch("shapes/more.go", "package shapes\n\ntype Triangle struct{}\n\nfunc (Triangle) Area() float64 { return 0 }\n")
+	if got := runGoSymbols(t, g, map[string]interface{}{"operation": "implementations", "symbol": "Shape"}); !strings.Contains(got, "shapes.Triangle") {
+		t.Fatalf("new implementation missing: %q", got)
+	}
+	if g.index.pkgs["example.com/demo/app"].types == app {
+		t.Fatal("dependents of a changed package should be re-checked")
+	}
+
+	if err := os.Remove(filepath.Join(dir, "shapes", "more.go")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	if got := runGoSymbols(t, g, map[string]interface{}{"operation": "implementations", "symbol": "Shape"}); strings.Contains(got, "Triangle") {
+		t.Fatalf("deleted file still indexed: %q", got)
+	}
+}
+
+func TestGoSymbolsErrors(t *testing.T) {
+	skipIfWindows(t)
+	dir := writeGoSymbolsFixture(t)
+	g := NewGoSymbolsToolWithRoot(dir)
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{"missing operation", map[string]interface{}{}, "operation is required"},
+		{"unknown operation", map[string]interface{}{"operation": "rename"}, "unsupported operation"},
+		{"missing package", map[string]interface{}{"operation": "package_api"}, "package is required"},
+		{"missing symbol", map[string]interface{}{"operation": "callers"}, "symbol is required"},
+		{"unknown package", map[string]interface{}{"operation": "package_api", "package": "nope"}, "not found"},
+		{"unknown symbol", map[string]interface{}{"operation": "definition", "symbol": "Nope"}, "not found"},
+		{"not an interface", map[string]interface{}{"operation": "implementations", "symbol": "Square"}, "interface \"Square\" not found"},
+		{"not a function", map[string]interface{}{"operation": "callers", "symbol": "Version"}, "function \"Version\" not found"},
+		{"skipped test package", map[string]interface{}{"operation": "package_api", "package": "skipped"}, "not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := g.Execute(context.Background(), tt.params); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	empty := NewGoSymbolsToolWithRoot(cleanTempDir(t))
+	if _, err := empty.Execute(context.Background(), map[string]interface{}{"operation": "definition", "symbol": "X"}); err == nil || !strings.Contains(err.Error(), "no Go packages") {
+		t.Fatalf("expected empty tree error, got %v", err)
+	}
+}
@@ -113,6 +113,8 @@ type GrepTool struct {
 	maxContext       int
 	respectGitignore bool
 	gitignoreMatcher *gitignore.Matcher
+	files            *FileStateTracker
+	index            *CodeIndex
 }
 
 // NewGrepTool builds a GrepTool rooted at the current directory.
@@ -152,6 +154,21 @@ func (g *GrepTool) SetRespectGitignore(respect bool) {
 	}
 }
 
+// SetFileState attaches the tracker that records files grep has read.
+func (g *GrepTool) SetFileState(files *FileStateTracker) {
+	if g != nil {
+		g.files = files
+	}
+}
+
+// SetCodeIndex lets directory searches skip files the trigram index rules
+// out; matching and output are unchanged.
+func (g *GrepTool) SetCodeIndex(index *CodeIndex) {
+	if g != nil {
+		g.index = index
+	}
+}
+
 func (g *GrepTool) Name() string { return "grep" }
 
 func (g *GrepTool) Description() string { return grepToolDesc }
@@ -260,6 +277,9 @@ func (g *GrepTool) Execute(ctx context.Context, params map[string]interface{}) (
 		multiline:        multiline,
 		gitignoreMatcher: g.gitignoreMatcher,
 	}
+	if info.IsDir() {
+		options.candidate = g.index.Candidates(patternWithFlags)
+	}
 
 	var truncated bool
 	if info.IsDir() {
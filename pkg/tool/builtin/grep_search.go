@@ -21,6 +21,8 @@ type grepSearchOptions struct {
 	root             string
 	multiline        bool
 	gitignoreMatcher *gitignore.Matcher
+	// candidate, when set, reports whether a file may match at all.
+	candidate func(path string) bool
 }
 
 type fileCount struct {
@@ -102,6 +104,9 @@ func (g *GrepTool) searchFile(ctx context.Context, path string, re *regexp.Regex
 	if !allowed {
 		return false, nil
 	}
+	if opts.candidate != nil && !opts.candidate(path) {
+		return false, nil
+	}
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return false, fmt.Errorf("read file: %w", err)
@@ -109,6 +114,15 @@ func (g *GrepTool) searchFile(ctx context.Context, path string, re *regexp.Regex
 	contents := string(data)
 	lines := splitGrepLines(contents)
 	display := displayPath(path, g.root)
+	if g.files != nil {
+		// Files that produced matches count as read for edit purposes.
+		before := len(*matches)
+		defer func() {
+			if len(*matches) > before {
+				g.files.RecordRead(bashSessionID(ctx), path, data)
+			}
+		}()
+	}
 
 	if opts.multiline {
 		cursor := 0
@@ -0,0 +1,481 @@
+package toolbuiltin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const HTTPRequestName = "http_request"
+
+const (
+	httpDefaultTimeout       = 30 * time.Second
+	httpMaxTimeout           = 300 * time.Second
+	httpDefaultMaxResponse   = 512 * 1024
+	httpMaxRedirects         = 10
+	httpRedactedSecretFormat = "[secret:%s]"
+)
+
+// ErrSecretNotFound is returned by a SecretProvider that does not know a name.
+var ErrSecretNotFound = errors.New("secret not found")
+
+// SecretProvider resolves the credentials referenced by {{secret:NAME}}
+// placeholders. Values are only read while a request is being sent.
+type SecretProvider interface {
+	Secret(ctx context.Context, name string) (string, error)
+}
+
+// SecretMap serves secrets from a fixed map such as Settings.Env.
+type SecretMap map[string]string
+
+// Secret implements SecretProvider.
+func (m SecretMap) Secret(_ context.Context, name string) (string, error) {
+	if value, ok := m[name]; ok {
+		return value, nil
+	}
+	return "", ErrSecretNotFound
+}
+
+type multiSecretProvider []SecretProvider
+
+// MultiSecretProvider consults providers in order and returns the first
+// value found. Nil providers are skipped.
+func MultiSecretProvider(providers ...SecretProvider) SecretProvider {
+	var out multiSecretProvider
+	for _, p := range providers {
+		if p != nil {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+func (m multiSecretProvider) Secret(ctx context.Context, name string) (string, error) {
+	for _, p := range m {
+		value, err := p.Secret(ctx, name)
+		if errors.Is(err, ErrSecretNotFound) {
+			continue
+		}
+		return value, err
+	}
+	return "", ErrSecretNotFound
+}
+
+var secretPlaceholder = regexp.MustCompile(`\{\{\s*secret:([A-Za-z_][A-Za-z0-9_.-]*)\s*\}\}`)
+
+const httpRequestDescription = `Sends an HTTP request to an allowed host and returns the status, headers and body.
+Usage:
+- url must be an absolute http or https URL; the host (and every redirect target) must be allowed by the sandbox network policy.
+- method defaults to GET. headers is an object of header names to values.
+- body may be a string, or a JSON object/array which is sent as application/json.
+- Reference credentials as {{secret:NAME}} in the url, headers or body instead of writing them out. They are resolved when the request is sent and masked as [secret:NAME] in the result.
+- Secrets cannot appear in the URL host.
+- JSON responses are pretty-printed; bodies beyond the size cap are truncated.
+- timeout is in seconds (default 30, max 300).`
+
+var httpRequestSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"url": map[string]interface{}{
+			"type":        "string",
+			"description": "Absolute http(s) URL. May contain {{secret:NAME}} placeholders outside the host.",
+		},
+		"method": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"},
+			"description": "HTTP method (default GET).",
+		},
+		"headers": map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+			"description":          "Request headers. Values may contain {{secret:NAME}} placeholders.",
+		},
+		"body": map[string]interface{}{
+			"type":        []string{"string", "object", "array"},
+			"description": "Request body. Objects and arrays are encoded as JSON.",
+		},
+		"timeout": map[string]interface{}{
+			"type":        "number",
+			"description": "Timeout in seconds (default 30, max 300).",
+		},
+	},
+	Required: []string{"url"},
+}
+
+// HTTPRequestTool calls REST endpoints permitted by a network policy and
+// injects named secrets without exposing them to the model.
+type HTTPRequestTool struct {
+	policy      sandbox.NetworkPolicy
+	secrets     SecretProvider
+	maxResponse int
+	transport   http.RoundTripper
+}
+
+// NewHTTPRequestTool builds an HTTPRequestTool guarded by policy. A nil
+// policy allows every host.
+func NewHTTPRequestTool(policy sandbox.NetworkPolicy) *HTTPRequestTool {
+	return &HTTPRequestTool{policy: policy, maxResponse: httpDefaultMaxResponse}
+}
+
+// SetSecretProvider configures where {{secret:NAME}} placeholders resolve.
+func (h *HTTPRequestTool) SetSecretProvider(provider SecretProvider) {
+	if h != nil {
+		h.secrets = provider
+	}
+}
+
+// SetMaxResponseBytes caps how much of a response body is read.
+func (h *HTTPRequestTool) SetMaxResponseBytes(limit int) {
+	if h != nil && limit > 0 {
+		h.maxResponse = limit
+	}
+}
+
+func (h *HTTPRequestTool) Name() string { return HTTPRequestName }
+
+func (h *HTTPRequestTool) Description() string { return httpRequestDescription }
+
+func (h *HTTPRequestTool) Schema() *tool.JSONSchema { return httpRequestSchema }
+
+func (h *HTTPRequestTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsConcurrencySafe: true}
+}
+
+// httpSecrets tracks the secrets resolved for one request so they can be
+// masked in everything returned to the caller.
+type httpSecrets struct {
+	ctx      context.Context
+	provider SecretProvider
+	values   map[string]string
+}
+
+func (s *httpSecrets) expand(text string, encode func(string) string) (string, error) {
+	var firstErr error
+	out := secretPlaceholder.ReplaceAllStringFunc(text, func(match string) string {
+		name := secretPlaceholder.FindStringSubmatch(match)[1]
+		value, err := s.lookup(name)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return match
+		}
+		if encode != nil {
+			return encode(value)
+		}
+		return value
+	})
+	return out, firstErr
+}
+
+func (s *httpSecrets) lookup(name string) (string, error) {
+	if value, ok := s.values[name]; ok {
+		return value, nil
+	}
+	if s.provider == nil {
+		return "", fmt.Errorf("secret %q is not defined", name)
+	}
+	value, err := s.provider.Secret(s.ctx, name)
+	if errors.Is(err, ErrSecretNotFound) {
+		return "", fmt.Errorf("secret %q is not defined", name)
+	}
+	if err != nil {
+		return "", fmt.Errorf("resolve secret %q: %w", name, err)
+	}
+	s.values[name] = value
+	return value, nil
+}
+
+func (s *httpSecrets) redact(text string) string {
+	if len(s.values) == 0 {
+		return text
+	}
+	// Longest values first so a secret containing another is masked whole.
+	names := make([]string, 0, len(s.values))
+	for name := range s.values {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if len(s.values[names[i]]) != len(s.values[names[j]]) {
+			return len(s.values[names[i]]) > len(s.values[names[j]])
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		value := s.values[name]
+		if value == "" {
+			continue
+		}
+		mask := fmt.Sprintf(httpRedactedSecretFormat, name)
+		// Also mask the encodings used when the value was substituted.
+		for _, form := range []string{value, url.QueryEscape(value), url.PathEscape(value), jsonStringContent(value)} {
+			text = strings.ReplaceAll(text, form, mask)
+		}
+	}
+	return text
+}
+
+func (h *HTTPRequestTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if h == nil {
+		return nil, errors.New("http_request tool is not initialised")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if params == nil {
+		return nil, errors.New("params is nil")
+	}
+	secrets := &httpSecrets{ctx: ctx, provider: h.secrets, values: map[string]string{}}
+	req, err := h.buildRequest(ctx, params, secrets)
+	if err != nil {
+		return nil, fmt.Errorf("http_request: %s", secrets.redact(err.Error()))
+	}
+	timeout := httpDefaultTimeout
+	if raw, ok := params["timeout"]; ok && raw != nil {
+		dur, err := durationFromParam(raw)
+		if err != nil {
+			return nil, fmt.Errorf("http_request: invalid timeout: %w", err)
+		}
+		if dur > 0 {
+			timeout = min(dur, httpMaxTimeout)
+		}
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req = req.WithContext(ctx)
+
+	start := time.Now()
+	resp, err := h.client(req, secrets).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http_request: %s", secrets.redact(err.Error()))
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(h.maxResponse)+1))
+	if err != nil {
+		return nil, fmt.Errorf("http_request: read response: %s", secrets.redact(err.Error()))
+	}
+	truncated := len(body) > h.maxResponse
+	if truncated {
+		body = body[:h.maxResponse]
+	}
+	return h.result(req, resp, body, truncated, time.Since(start), secrets), nil
+}
+
+func (h *HTTPRequestTool) buildRequest(ctx context.Context, params map[string]interface{}, secrets *httpSecrets) (*http.Request, error) {
+	raw, ok := params["url"]
+	if !ok || raw == nil {
+		return nil, errors.New("url is required")
+	}
+	rawURL, err := coerceString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("url must be string: %w", err)
+	}
+	if rawURL = strings.TrimSpace(rawURL); rawURL == "" {
+		return nil, errors.New("url is required")
+	}
+	template, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %w", err)
+	}
+	if secretPlaceholder.MatchString(template.Host) {
+		return nil, errors.New("secrets are not allowed in the url host")
+	}
+	// Path secrets are path-escaped; QueryEscape would turn spaces into '+'.
+	path, query, hasQuery := strings.Cut(rawURL, "?")
+	resolvedURL, err := secrets.expand(path, url.PathEscape)
+	if err != nil {
+		return nil, err
+	}
+	if hasQuery {
+		query, err = secrets.expand(query, url.QueryEscape)
+		if err != nil {
+			return nil, err
+		}
+		resolvedURL += "?" + query
+	}
+	target, err := url.Parse(resolvedURL)
+	if err != nil {
+		return nil, errors.New("invalid url after resolving secrets")
+	}
+	if err := h.checkURL(target); err != nil {
+		return nil, err
+	}
+
+	method := http.MethodGet
+	if raw, ok := params["method"]; ok && raw != nil {
+		value, err := coerceString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("method must be string: %w", err)
+		}
+		if value = strings.ToUpper(strings.TrimSpace(value)); value != "" {
+			method = value
+		}
+	}
+	switch method {
+	case http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead, http.MethodOptions:
+	default:
+		return nil, fmt.Errorf("unsupported method %q", method)
+	}
+
+	var body io.Reader
+	isJSON := false
+	if raw, ok := params["body"]; ok && raw != nil {
+		var text string
+		switch v := raw.(type) {
+		case string:
+			text, err = secrets.expand(v, nil)
+		default:
+			encoded, merr := json.Marshal(v)
+			if merr != nil {
+				return nil, fmt.Errorf("encode body: %w", merr)
+			}
+			isJSON = true
+			text, err = secrets.expand(string(encoded), jsonStringContent)
+		}
+		if err != nil {
+			return nil, err
+		}
+		body = strings.NewReader(text)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	if raw, ok := params["headers"]; ok && raw != nil {
+		headers, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("headers must be an object got %T", raw)
+		}
+		for name, rawValue := range headers {
+			value, err := coerceString(rawValue)
+			if err != nil {
+				return nil, fmt.Errorf("header %s: %w", name, err)
+			}
+			if value, err = secrets.expand(value, nil); err != nil {
+				return nil, err
+			}
+			req.Header.Set(name, value)
+		}
+	}
+	if isJSON && req.Header.Get("Content-Type") == "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return req, nil
+}
+
+// jsonStringContent escapes value for use inside an already quoted JSON string.
+func jsonStringContent(value string) string {
+	encoded, _ := json.Marshal(value)
+	return string(encoded[1 : len(encoded)-1])
+}
+
+func (h *HTTPRequestTool) checkURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported url scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return errors.New("url host is required")
+	}
+	if h.policy == nil {
+		return nil
+	}
+	return h.policy.Validate(u.Hostname())
+}
+
+func (h *HTTPRequestTool) client(req *http.Request, secrets *httpSecrets) *http.Client {
+	return &http.Client{
+		Transport: h.transport,
+		CheckRedirect: func(next *http.Request, via []*http.Request) error {
+			if len(via) >= httpMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", httpMaxRedirects)
+			}
+			if err := h.checkURL(next.URL); err != nil {
+				return err
+			}
+			// net/http already drops Authorization and cookies on cross-host
+			// redirects; do the same for any other header carrying a secret.
+			if next.URL.Host != req.URL.Host {
+				for name, values := range next.Header {
+					for _, value := range values {
+						if secrets.redact(value) != value {
+							next.Header.Del(name)
+							break
+						}
+					}
+				}
+			}
+			return nil
+		},
+	}
+}
+
+func (h *HTTPRequestTool) result(req *http.Request, resp *http.Response, body []byte, truncated bool, elapsed time.Duration, secrets *httpSecrets) *tool.ToolResult {
+	text := string(body)
+	if !truncated && len(body) > 0 && isJSONContent(resp.Header.Get("Content-Type"), body) {
+		var pretty bytes.Buffer
+		if err := json.Indent(&pretty, body, "", "  "); err == nil {
+			text = pretty.String()
+		}
+	}
+	text = secrets.redact(text)
+
+	headers := make(map[string]string, len(resp.Header))
+	names := make([]string, 0, len(resp.Header))
+	for name, values := range resp.Header {
+		headers[name] = secrets.redact(strings.Join(values, ", "))
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s %s\n", resp.Proto, resp.Status)
+	for _, name := range names {
+		fmt.Fprintf(&out, "%s: %s\n", name, headers[name])
+	}
+	if text != "" {
+		out.WriteString("\n")
+		out.WriteString(text)
+	}
+	if truncated {
+		fmt.Fprintf(&out, "\n... (response truncated at %d bytes)", h.maxResponse)
+	}
+
+	return &tool.ToolResult{
+		Success: resp.StatusCode < 400,
+		Output:  strings.TrimRight(out.String(), "\n"),
+		Data: map[string]interface{}{
+			"method":      req.Method,
+			"url":         secrets.redact(resp.Request.URL.String()),
+			"status_code": resp.StatusCode,
+			"headers":     headers,
+			"body_bytes":  len(body),
+			"truncated":   truncated,
+			"duration_ms": elapsed.Milliseconds(),
+		},
+	}
+}
+
+func isJSONContent(contentType string, body []byte) bool {
+	contentType = strings.ToLower(contentType)
+	if strings.Contains(contentType, "json") {
+		return true
+	}
+	if contentType != "" && !strings.HasPrefix(contentType, "text/plain") {
+		return false
+	}
+	trimmed := bytes.TrimSpace(body)
+	return len(trimmed) > 0 && (trimmed[0] == '{' || trimmed[0] == '[') && json.Valid(trimmed)
+}
@@ -0,0 +1,147 @@
+package toolbuiltin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+)
+
+const testSecret = "s3cr3t-t0ken+/="
+
+func newHTTPTestTool(t *testing.T, handler http.HandlerFunc) (*HTTPRequestTool, string) {
+	t.Helper()
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+	h := NewHTTPRequestTool(sandbox.NewDomainAllowList("127.0.0.1"))
+	h.SetSecretProvider(MultiSecretProvider(nil, SecretMap{"API_TOKEN": testSecret}))
+	return h, srv.URL
+}
+
+func TestHTTPRequestInjectsAndMasksSecrets(t *testing.T) {
+	var gotAuth, gotQuery, gotBody, gotType string
+	h, base := newHTTPTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+		gotQuery = r.URL.Query().Get("key")
+		body, _ := io.ReadAll(r.Body)
+		gotBody, gotType = string(body), r.Header.Get("Content-Type")
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("X-Echo", gotAuth)
+		_, _ = w.Write([]byte(`{"ok":true,"token":"` + testSecret + `"}`))
+	})
+
+	params := map[string]interface{}{
+		"url":     base + "/items?key={{secret:API_TOKEN}}",
+		"method":  "post",
+		"headers": map[string]interface{}{"Authorization": "Bearer {{ secret:API_TOKEN }}"},
+		"body":    map[string]interface{}{"auth": "{{secret:API_TOKEN}}"},
+	}
+	res, err := h.Execute(context.Background(), params)
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotAuth != "Bearer "+testSecret || gotQuery != testSecret || gotType != "application/json" {
+		t.Fatalf("secret not injected: auth=%q query=%q type=%q", gotAuth, gotQuery, gotType)
+	}
+	var sent map[string]string
+	if err := json.Unmarshal([]byte(gotBody), &sent); err != nil || sent["auth"] != testSecret {
+		t.Fatalf("unexpected body %q: %v", gotBody, err)
+	}
+	if params["url"] != base+"/items?key={{secret:API_TOKEN}}" {
+		t.Fatal("params must not be rewritten")
+	}
+
+	if !res.Success || !strings.Contains(res.Output, "HTTP/1.1 200 OK") {
+		t.Fatalf("unexpected result %v %q", res.Success, res.Output)
+	}
+	if !strings.Contains(res.Output, "{\n  \"ok\": true,\n  \"token\": \"[secret:API_TOKEN]\"\n}") {
+		t.Fatalf("json body not pretty-printed and masked: %q", res.Output)
+	}
+	encoded, _ := json.Marshal(res.Data)
+	for _, text := range []string{res.Output, string(encoded)} {
+		if strings.Contains(text, "s3cr3t") {
+			t.Fatalf("secret leaked: %s", text)
+		}
+	}
+	data := res.Data.(map[string]interface{})
+	if data["status_code"] != 200 || data["headers"].(map[string]string)["X-Echo"] != "Bearer [secret:API_TOKEN]" {
+		t.Fatalf("unexpected data %#v", data)
+	}
+}
+
+func TestHTTPRequestEscapesSecretsByPosition(t *testing.T) {
+	var gotPath, gotQuery string
+	h, base := newHTTPTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotQuery = r.URL.EscapedPath(), r.URL.Query().Get("q")
+	})
+	h.SetSecretProvider(SecretMap{"NAME": "a b/c+d"})
+	if _, err := h.Execute(context.Background(), map[string]interface{}{"url": base + "/users/{{secret:NAME}}?q={{secret:NAME}}"}); err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if gotPath != "/users/a%20b%2Fc+d" || gotQuery != "a b/c+d" {
+		t.Fatalf("path=%q query=%q", gotPath, gotQuery)
+	}
+}
+
+func TestHTTPRequestNetworkPolicy(t *testing.T) {
+	h, base := newHTTPTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/redirect" {
+			http.Redirect(w, r, "http://example.com/", http.StatusFound)
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	if _, err := h.Execute(context.Background(), map[string]interface{}{"url": "http://example.com/"}); err == nil || !strings.Contains(err.Error(), "domain denied") {
+		t.Fatalf("expected denied host, got %v", err)
+	}
+	if _, err := h.Execute(context.Background(), map[string]interface{}{"url": base + "/redirect"}); err == nil || !strings.Contains(err.Error(), "domain denied") {
+		t.Fatalf("expected denied redirect, got %v", err)
+	}
+	if _, err := h.Execute(context.Background(), map[string]interface{}{"url": "http://{{secret:API_TOKEN}}.evil/"}); err == nil {
+		t.Fatal("secrets in the host must be rejected")
+	}
+	if _, err := h.Execute(context.Background(), map[string]interface{}{"url": "file:///etc/passwd"}); err == nil {
+		t.Fatal("expected unsupported scheme")
+	}
+	if _, err := h.Execute(context.Background(), map[string]interface{}{"url": base, "headers": map[string]interface{}{"X": "{{secret:MISSING}}"}}); err == nil || !strings.Contains(err.Error(), `secret "MISSING" is not defined`) {
+		t.Fatalf("expected missing secret error, got %v", err)
+	}
+
+	res, err := h.Execute(context.Background(), map[string]interface{}{"url": base + "/missing"})
+	if err != nil || res.Success || res.Data.(map[string]interface{})["status_code"] != 404 {
+		t.Fatalf("4xx should be reported as an unsuccessful result, got %#v %v", res, err)
+	}
+}
+
+func TestHTTPRequestTruncatesAndRedactsErrors(t *testing.T) {
+	h, base := newHTTPTestTool(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	})
+	h.SetMaxResponseBytes(10)
+	res, err := h.Execute(context.Background(), map[string]interface{}{"url": base})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !strings.Contains(res.Output, "\n\nxxxxxxxxxx\n... (response truncated at 10 bytes)") || res.Data.(map[string]interface{})["truncated"] != true {
+		t.Fatalf("unexpected truncation %q", res.Output)
+	}
+
+	h.transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		return nil, errors.New("dial failed")
+	})
+	_, err = h.Execute(context.Background(), map[string]interface{}{"url": base + "/{{secret:API_TOKEN}}"})
+	if err == nil || strings.Contains(err.Error(), "s3cr3t") || !strings.Contains(err.Error(), "[secret:API_TOKEN]") {
+		t.Fatalf("transport errors must be masked, got %v", err)
+	}
+}
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
@@ -0,0 +1,664 @@
+package toolbuiltin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const LSPName = "lsp"
+
+const (
+	lspMaxLocations   = 200
+	lspMaxDiagnostics = 500
+)
+
+const lspDescription = `Queries a language server (gopls, pyright, typescript-language-server, ...) for precise code intelligence.
+Usage:
+- operation is one of definition, references, hover, document_symbols, diagnostics.
+- definition, references and hover need file_path and line (1-based); give either character (1-based column) or symbol (an identifier on that line).
+- document_symbols lists the symbols declared in file_path.
+- diagnostics reports errors and warnings for file_path, or for the whole workspace when file_path is omitted.
+- Prefer this over grep for navigating code in languages with a configured server.`
+
+var lspSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"operation": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"definition", "references", "hover", "document_symbols", "diagnostics"},
+			"description": "The language server operation to run.",
+		},
+		"file_path": map[string]interface{}{
+			"type":        "string",
+			"description": "File to query (absolute or relative to the sandbox root).",
+		},
+		"line": map[string]interface{}{
+			"type":        "integer",
+			"description": "1-based line number of the position.",
+		},
+		"character": map[string]interface{}{
+			"type":        "integer",
+			"description": "1-based column (in characters) of the position.",
+		},
+		"symbol": map[string]interface{}{
+			"type":        "string",
+			"description": "Identifier on the given line; used to locate the column when character is omitted.",
+		},
+		"include_declaration": map[string]interface{}{
+			"type":        "boolean",
+			"description": "For references: include the declaration itself (default true).",
+		},
+	},
+	Required: []string{"operation"},
+}
+
+// LSPTool exposes language server queries to the model.
+type LSPTool struct {
+	base *fileSandbox
+
+	mu      sync.Mutex
+	manager *LSPManager
+}
+
+// NewLSPTool builds an LSPTool rooted at the current directory.
+func NewLSPTool() *LSPTool {
+	return NewLSPToolWithRoot("")
+}
+
+// NewLSPToolWithRoot builds an LSPTool rooted at the provided directory.
+func NewLSPToolWithRoot(root string) *LSPTool {
+	return &LSPTool{base: newFileSandbox(root)}
+}
+
+// NewLSPToolWithSandbox builds an LSPTool using a custom sandbox.
+func NewLSPToolWithSandbox(root string, policy sandbox.FileSystemPolicy) *LSPTool {
+	return &LSPTool{base: newFileSandboxWithSandbox(root, policy)}
+}
+
+// SetLSPManager shares a manager (and its server processes) with the tool.
+func (l *LSPTool) SetLSPManager(manager *LSPManager) {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	l.manager = manager
+	l.mu.Unlock()
+}
+
+func (l *LSPTool) lspManager() *LSPManager {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.manager == nil {
+		l.manager = NewLSPManager(l.base.root, nil)
+	}
+	return l.manager
+}
+
+func (l *LSPTool) Name() string { return LSPName }
+
+func (l *LSPTool) Description() string { return lspDescription }
+
+func (l *LSPTool) Schema() *tool.JSONSchema { return lspSchema }
+
+func (l *LSPTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsReadOnly: true, IsConcurrencySafe: true}
+}
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange        `json:"range"`
+	Severity int             `json:"severity,omitempty"`
+	Code     json.RawMessage `json:"code,omitempty"`
+	Source   string          `json:"source,omitempty"`
+	Message  string          `json:"message"`
+}
+
+type lspDocumentSymbol struct {
+	Name           string              `json:"name"`
+	Detail         string              `json:"detail,omitempty"`
+	Kind           int                 `json:"kind"`
+	Range          lspRange            `json:"range"`
+	SelectionRange lspRange            `json:"selectionRange"`
+	Children       []lspDocumentSymbol `json:"children,omitempty"`
+	// SymbolInformation fields (flat responses).
+	Location      *lspLocation `json:"location,omitempty"`
+	ContainerName string       `json:"containerName,omitempty"`
+}
+
+func (l *LSPTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if l == nil || l.base == nil {
+		return nil, errors.New("lsp tool is not initialised")
+	}
+	if params == nil {
+		return nil, errors.New("params is nil")
+	}
+	rawOp, ok := params["operation"]
+	if !ok || rawOp == nil {
+		return nil, errors.New("operation is required")
+	}
+	op, err := coerceString(rawOp)
+	if err != nil {
+		return nil, fmt.Errorf("operation must be string: %w", err)
+	}
+	op = strings.ToLower(strings.TrimSpace(op))
+
+	var path string
+	if raw, ok := params["file_path"]; ok && raw != nil {
+		if path, err = l.base.resolvePath(raw); err != nil {
+			return nil, err
+		}
+	}
+
+	switch op {
+	case "definition", "references", "hover":
+		if path == "" {
+			return nil, fmt.Errorf("file_path is required for %s", op)
+		}
+		return l.positional(ctx, op, path, params)
+	case "document_symbols":
+		if path == "" {
+			return nil, errors.New("file_path is required for document_symbols")
+		}
+		return l.documentSymbols(ctx, path)
+	case "diagnostics":
+		return l.diagnostics(ctx, path)
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", op)
+	}
+}
+
+func (l *LSPTool) open(ctx context.Context, path string) (*lspClient, string, string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, "", "", fmt.Errorf("%s is a directory", displayPath(path, l.base.root))
+	}
+	client, err := l.lspManager().client(ctx, path)
+	if err != nil {
+		return nil, "", "", err
+	}
+	uri, text, err := client.syncDocument(path)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return client, uri, text, nil
+}
+
+func (l *LSPTool) positional(ctx context.Context, op, path string, params map[string]interface{}) (*tool.ToolResult, error) {
+	line, err := parseLineNumber(params, "line")
+	if err != nil {
+		return nil, err
+	}
+	if line <= 0 {
+		return nil, fmt.Errorf("line is required for %s and must be >= 1", op)
+	}
+	client, uri, text, err := l.open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(text, "\n")
+	if line > len(lines) {
+		return nil, fmt.Errorf("line %d is past the end of %s (%d lines)", line, displayPath(path, l.base.root), len(lines))
+	}
+	lineText := strings.TrimRight(lines[line-1], "\r")
+	column, err := lspColumn(params, lineText)
+	if err != nil {
+		return nil, err
+	}
+	pos := lspPosition{Line: line - 1, Character: runeToUTF16(lineText, column-1)}
+	request := map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+		"position":     pos,
+	}
+	display := displayPath(path, l.base.root)
+	data := map[string]interface{}{"operation": op, "path": display, "line": line, "character": column}
+
+	switch op {
+	case "hover":
+		var raw json.RawMessage
+		if err := client.call(ctx, "textDocument/hover", request, &raw); err != nil {
+			return nil, err
+		}
+		contents := hoverText(raw)
+		if contents == "" {
+			contents = fmt.Sprintf("no hover information at %s:%d:%d", display, line, column)
+		}
+		return &tool.ToolResult{Success: true, Output: contents, Data: data}, nil
+	case "references":
+		includeDecl := true
+		if raw, ok := params["include_declaration"]; ok && raw != nil {
+			if includeDecl, err = coerceBool(raw); err != nil {
+				return nil, fmt.Errorf("include_declaration must be boolean: %w", err)
+			}
+		}
+		request["context"] = map[string]interface{}{"includeDeclaration": includeDecl}
+	}
+
+	method := "textDocument/definition"
+	if op == "references" {
+		method = "textDocument/references"
+	}
+	var raw json.RawMessage
+	if err := client.call(ctx, method, request, &raw); err != nil {
+		return nil, err
+	}
+	locations := decodeLocations(raw)
+	data["count"] = len(locations)
+	if len(locations) == 0 {
+		return &tool.ToolResult{Success: true, Output: fmt.Sprintf("no %s found for %s:%d:%d", op, display, line, column), Data: data}, nil
+	}
+	return &tool.ToolResult{Success: true, Output: l.formatLocations(locations), Data: data}, nil
+}
+
+func (l *LSPTool) documentSymbols(ctx context.Context, path string) (*tool.ToolResult, error) {
+	client, uri, _, err := l.open(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+	var symbols []lspDocumentSymbol
+	if err := client.call(ctx, "textDocument/documentSymbol", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	}, &symbols); err != nil {
+		return nil, err
+	}
+	display := displayPath(path, l.base.root)
+	if len(symbols) == 0 {
+		return &tool.ToolResult{Success: true, Output: "no symbols found in " + display, Data: map[string]interface{}{"path": display, "count": 0}}, nil
+	}
+	var b strings.Builder
+	count := 0
+	var walk func(items []lspDocumentSymbol, depth int)
+	walk = func(items []lspDocumentSymbol, depth int) {
+		for _, sym := range items {
+			count++
+			start := sym.SelectionRange.Start
+			if sym.Location != nil {
+				start = sym.Location.Range.Start
+			}
+			fmt.Fprintf(&b, "%s%s %s", strings.Repeat("  ", depth), lspSymbolKind(sym.Kind), sym.Name)
+			if sym.Detail != "" {
+				fmt.Fprintf(&b, " %s", sym.Detail)
+			}
+			if sym.ContainerName != "" {
+				fmt.Fprintf(&b, " (in %s)", sym.ContainerName)
+			}
+			fmt.Fprintf(&b, " :%d\n", start.Line+1)
+			walk(sym.Children, depth+1)
+		}
+	}
+	walk(symbols, 0)
+	return &tool.ToolResult{
+		Success: true,
+		Output:  strings.TrimRight(b.String(), "\n"),
+		Data:    map[string]interface{}{"path": display, "count": count},
+	}, nil
+}
+
+func (l *LSPTool) diagnostics(ctx context.Context, path string) (*tool.ToolResult, error) {
+	manager := l.lspManager()
+	results := map[string][]lspDiagnostic{}
+	if path != "" {
+		client, err := manager.client(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		uri := pathToURI(path)
+		since := client.diagnosticVersion(uri)
+		opened := client.documentVersion(uri)
+		if _, _, err := client.syncDocument(path); err != nil {
+			return nil, err
+		}
+		if opened > 0 && opened == client.documentVersion(uri) && since > 0 {
+			// Unchanged since the last publish; no fresh report is coming.
+			since--
+		}
+		var diags []lspDiagnostic
+		if client.supports("diagnosticProvider") {
+			var report struct {
+				Items []lspDiagnostic `json:"items"`
+			}
+			if err := client.call(ctx, "textDocument/diagnostic", map[string]interface{}{
+				"textDocument": map[string]interface{}{"uri": uri},
+			}, &report); err != nil {
+				return nil, err
+			}
+			diags = report.Items
+		} else {
+			diags = client.publishedDiagnostics(ctx, uri, since, manager.diagnosticsWait)
+		}
+		results[uri] = diags
+	} else {
+		clients := manager.running()
+		if len(clients) == 0 {
+			return nil, errors.New("no language servers are running; pass file_path to start the server for that file type")
+		}
+		for _, client := range clients {
+			if client.supportsWorkspaceDiagnostics() {
+				var report struct {
+					Items []struct {
+						URI   string          `json:"uri"`
+						Items []lspDiagnostic `json:"items"`
+					} `json:"items"`
+				}
+				if err := client.call(ctx, "workspace/diagnostic", map[string]interface{}{"previousResultIds": []interface{}{}}, &report); err == nil {
+					for _, item := range report.Items {
+						results[item.URI] = append(results[item.URI], item.Items...)
+					}
+					continue
+				}
+			}
+			for uri, diags := range client.allDiagnostics() {
+				results[uri] = append(results[uri], diags...)
+			}
+		}
+	}
+
+	uris := make([]string, 0, len(results))
+	for uri := range results {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	var b strings.Builder
+	total, errorsCount := 0, 0
+	for _, uri := range uris {
+		diags := append([]lspDiagnostic(nil), results[uri]...)
+		sort.SliceStable(diags, func(i, j int) bool {
+			if diags[i].Range.Start.Line != diags[j].Range.Start.Line {
+				return diags[i].Range.Start.Line < diags[j].Range.Start.Line
+			}
+			return diags[i].Range.Start.Character < diags[j].Range.Start.Character
+		})
+		display := displayPath(uriToPath(uri), l.base.root)
+		for _, d := range diags {
+			total++
+			if d.Severity == 1 {
+				errorsCount++
+			}
+			if total > lspMaxDiagnostics {
+				continue
+			}
+			fmt.Fprintf(&b, "%s:%d:%d: %s: %s", display, d.Range.Start.Line+1, d.Range.Start.Character+1, lspSeverity(d.Severity), strings.TrimSpace(d.Message))
+			if tag := diagnosticTag(d); tag != "" {
+				fmt.Fprintf(&b, " [%s]", tag)
+			}
+			b.WriteByte('\n')
+		}
+	}
+	output := strings.TrimRight(b.String(), "\n")
+	if total > lspMaxDiagnostics {
+		output += fmt.Sprintf("\n... %d more diagnostics omitted", total-lspMaxDiagnostics)
+	}
+	if total == 0 {
+		output = "no diagnostics"
+	}
+	data := map[string]interface{}{"count": total, "errors": errorsCount}
+	if path != "" {
+		data["path"] = displayPath(path, l.base.root)
+	}
+	return &tool.ToolResult{Success: true, Output: output, Data: data}, nil
+}
+
+func (l *LSPTool) formatLocations(locations []lspLocation) string {
+	var b strings.Builder
+	fileLines := map[string][]string{}
+	for i, loc := range locations {
+		if i == lspMaxLocations {
+			fmt.Fprintf(&b, "... %d more locations omitted\n", len(locations)-lspMaxLocations)
+			break
+		}
+		path := uriToPath(loc.URI)
+		line := loc.Range.Start.Line + 1
+		column := loc.Range.Start.Character + 1
+		snippet := ""
+		lines, ok := fileLines[path]
+		if !ok {
+			// Only quote source that the sandbox would let the model read.
+			if l.base.policy == nil || l.base.policy.Validate(path) == nil {
+				if data, err := os.ReadFile(path); err == nil && len(data) <= readMaxNotebookBytes {
+					lines = strings.Split(string(data), "\n")
+				}
+			}
+			fileLines[path] = lines
+		}
+		if loc.Range.Start.Line < len(lines) {
+			text := strings.TrimRight(lines[loc.Range.Start.Line], "\r")
+			column = utf16ToRune(text, loc.Range.Start.Character) + 1
+			snippet = strings.TrimSpace(text)
+		}
+		fmt.Fprintf(&b, "%s:%d:%d", displayPath(path, l.base.root), line, column)
+		if snippet != "" {
+			fmt.Fprintf(&b, ": %s", snippet)
+		}
+		b.WriteByte('\n')
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// lspColumn resolves the 1-based rune column from character or symbol,
+// defaulting to the first non-blank character of the line.
+func lspColumn(params map[string]interface{}, lineText string) (int, error) {
+	character, err := parseLineNumber(params, "character")
+	if err != nil {
+		return 0, err
+	}
+	if character < 0 {
+		return 0, errors.New("character must be >= 1")
+	}
+	if character > 0 {
+		return character, nil
+	}
+	if raw, ok := params["symbol"]; ok && raw != nil {
+		symbol, err := coerceString(raw)
+		if err != nil {
+			return 0, fmt.Errorf("symbol must be string: %w", err)
+		}
+		if symbol = strings.TrimSpace(symbol); symbol != "" {
+			idx := indexIdentifier(lineText, symbol)
+			if idx < 0 {
+				return 0, fmt.Errorf("symbol %q not found on line: %s", symbol, strings.TrimSpace(lineText))
+			}
+			return utf8.RuneCountInString(lineText[:idx]) + 1, nil
+		}
+	}
+	for i, r := range []rune(lineText) {
+		if !unicode.IsSpace(r) {
+			return i + 1, nil
+		}
+	}
+	return 1, nil
+}
+
+// indexIdentifier finds symbol as a whole word, falling back to any match.
+func indexIdentifier(line, symbol string) int {
+	isIdent := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for offset := 0; ; {
+		idx := strings.Index(line[offset:], symbol)
+		if idx < 0 {
+			break
+		}
+		start := offset + idx
+		end := start + len(symbol)
+		before, _ := utf8.DecodeLastRuneInString(line[:start])
+		after, _ := utf8.DecodeRuneInString(line[end:])
+		if (start == 0 || !isIdent(before)) && (end == len(line) || !isIdent(after)) {
+			return start
+		}
+		offset = start + 1
+	}
+	return strings.Index(line, symbol)
+}
+
+func runeToUTF16(line string, runeIdx int) int {
+	units := 0
+	for i, r := range []rune(line) {
+		if i >= runeIdx {
+			return units
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return units + (runeIdx - utf8.RuneCountInString(line))
+}
+
+func utf16ToRune(line string, units int) int {
+	count := 0
+	for i, r := range []rune(line) {
+		if count >= units {
+			return i
+		}
+		count += len(utf16.Encode([]rune{r}))
+	}
+	return utf8.RuneCountInString(line)
+}
+
+// decodeLocations accepts Location, Location[] and LocationLink[] results.
+func decodeLocations(raw json.RawMessage) []lspLocation {
+	trimmed := strings.TrimSpace(string(raw))
+	if trimmed == "" || trimmed == "null" {
+		return nil
+	}
+	type item struct {
+		lspLocation
+		TargetURI            string    `json:"targetUri"`
+		TargetSelectionRange *lspRange `json:"targetSelectionRange"`
+		TargetRange          *lspRange `json:"targetRange"`
+	}
+	var items []item
+	if strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(raw, &items); err != nil {
+			return nil
+		}
+	} else {
+		var single item
+		if err := json.Unmarshal(raw, &single); err != nil {
+			return nil
+		}
+		items = []item{single}
+	}
+	out := make([]lspLocation, 0, len(items))
+	for _, it := range items {
+		if it.TargetURI != "" {
+			loc := lspLocation{URI: it.TargetURI}
+			if it.TargetSelectionRange != nil {
+				loc.Range = *it.TargetSelectionRange
+			} else if it.TargetRange != nil {
+				loc.Range = *it.TargetRange
+			}
+			out = append(out, loc)
+			continue
+		}
+		if it.URI != "" {
+			out = append(out, it.lspLocation)
+		}
+	}
+	return out
+}
+
+// hoverText flattens MarkupContent, MarkedString and MarkedString[] payloads.
+func hoverText(raw json.RawMessage) string {
+	var hover struct {
+		Contents json.RawMessage `json:"contents"`
+	}
+	if err := json.Unmarshal(raw, &hover); err != nil || len(hover.Contents) == 0 {
+		return ""
+	}
+	var flatten func(json.RawMessage) []string
+	flatten = func(value json.RawMessage) []string {
+		var s string
+		if json.Unmarshal(value, &s) == nil {
+			return []string{s}
+		}
+		var list []json.RawMessage
+		if json.Unmarshal(value, &list) == nil {
+			var out []string
+			for _, item := range list {
+				out = append(out, flatten(item)...)
+			}
+			return out
+		}
+		var marked struct {
+			Kind     string `json:"kind"`
+			Language string `json:"language"`
+			Value    string `json:"value"`
+		}
+		if json.Unmarshal(value, &marked) == nil {
+			if marked.Language != "" {
+				return []string{"```" + marked.Language + "\n" + marked.Value + "\n```"}
+			}
+			return []string{marked.Value}
+		}
+		return nil
+	}
+	return strings.TrimSpace(strings.Join(flatten(hover.Contents), "\n\n"))
+}
+
+func lspSeverity(severity int) string {
+	switch severity {
+	case 1:
+		return "error"
+	case 2:
+		return "warning"
+	case 3:
+		return "info"
+	case 4:
+		return "hint"
+	default:
+		return "diagnostic"
+	}
+}
+
+func diagnosticTag(d lspDiagnostic) string {
+	code := strings.Trim(strings.TrimSpace(string(d.Code)), `"`)
+	switch {
+	case d.Source != "" && code != "" && code != "null":
+		return d.Source + " " + code
+	case d.Source != "":
+		return d.Source
+	case code != "" && code != "null":
+		return code
+	}
+	return ""
+}
+
+var lspSymbolKinds = []string{
+	"", "File", "Module", "Namespace", "Package", "Class", "Method", "Property", "Field", "Constructor",
+	"Enum", "Interface", "Function", "Variable", "Constant", "String", "Number", "Boolean", "Array",
+	"Object", "Key", "Null", "EnumMember", "Struct", "Event", "Operator", "TypeParameter",
+}
+
+func lspSymbolKind(kind int) string {
+	if kind > 0 && kind < len(lspSymbolKinds) {
+		return lspSymbolKinds[kind]
+	}
+	return "Symbol"
+}
@@ -0,0 +1,633 @@
+package toolbuiltin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	lspStartTimeout    = 30 * time.Second
+	lspShutdownTimeout = 2 * time.Second
+	lspDiagnosticsWait = 3 * time.Second
+)
+
+// LSPServerConfig describes a language server launched over stdio.
+type LSPServerConfig struct {
+	Name       string
+	Command    string
+	Args       []string
+	Extensions []string
+	LanguageID string
+	Env        map[string]string
+}
+
+// DefaultLSPServers returns the servers used when none are configured:
+// gopls, pyright and typescript-language-server.
+func DefaultLSPServers() []LSPServerConfig {
+	return []LSPServerConfig{
+		{Name: "gopls", Command: "gopls", Extensions: []string{".go"}, LanguageID: "go"},
+		{Name: "pyright", Command: "pyright-langserver", Args: []string{"--stdio"}, Extensions: []string{".py", ".pyi"}, LanguageID: "python"},
+		{Name: "typescript", Command: "typescript-language-server", Args: []string{"--stdio"}, Extensions: []string{".ts", ".tsx", ".js", ".jsx", ".mts", ".cts", ".mjs", ".cjs"}},
+	}
+}
+
+// LSPManager owns the language server processes for one workspace root. Servers
+// start lazily on first use and are shared by every session.
+type LSPManager struct {
+	mu      sync.Mutex
+	root    string
+	servers []LSPServerConfig
+	clients map[string]*lspClient
+	closed  bool
+
+	diagnosticsWait time.Duration
+}
+
+// NewLSPManager builds a manager rooted at root. A nil servers list selects
+// DefaultLSPServers; an empty non-nil list disables every server.
+func NewLSPManager(root string, servers []LSPServerConfig) *LSPManager {
+	if servers == nil {
+		servers = DefaultLSPServers()
+	}
+	return &LSPManager{
+		root:            resolveRoot(root),
+		servers:         append([]LSPServerConfig(nil), servers...),
+		clients:         map[string]*lspClient{},
+		diagnosticsWait: lspDiagnosticsWait,
+	}
+}
+
+func (m *LSPManager) serverFor(path string) (LSPServerConfig, bool) {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, server := range m.servers {
+		for _, candidate := range server.Extensions {
+			if strings.EqualFold(strings.TrimSpace(candidate), ext) {
+				return server, true
+			}
+		}
+	}
+	return LSPServerConfig{}, false
+}
+
+// client returns a running client for path, starting the server if needed.
+func (m *LSPManager) client(ctx context.Context, path string) (*lspClient, error) {
+	if m == nil {
+		return nil, errors.New("lsp manager is nil")
+	}
+	server, ok := m.serverFor(path)
+	if !ok {
+		return nil, fmt.Errorf("no language server configured for %q files", filepath.Ext(path))
+	}
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, errors.New("lsp manager is closed")
+	}
+	if existing := m.clients[server.Name]; existing != nil && existing.alive() {
+		m.mu.Unlock()
+		return existing, nil
+	}
+	m.mu.Unlock()
+
+	startCtx, cancel := context.WithTimeout(ctx, lspStartTimeout)
+	defer cancel()
+	started, err := startLSPClient(startCtx, server, m.root)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		go started.close()
+		return nil, errors.New("lsp manager is closed")
+	}
+	if existing := m.clients[server.Name]; existing != nil && existing.alive() {
+		// Lost a start race; keep the first server.
+		go started.close()
+		return existing, nil
+	}
+	m.clients[server.Name] = started
+	return started, nil
+}
+
+func (m *LSPManager) running() []*lspClient {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	names := make([]string, 0, len(m.clients))
+	for name, c := range m.clients {
+		if c.alive() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	out := make([]*lspClient, 0, len(names))
+	for _, name := range names {
+		out = append(out, m.clients[name])
+	}
+	return out
+}
+
+// Close shuts every server down. It is safe to call more than once.
+func (m *LSPManager) Close() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.closed = true
+	clients := m.clients
+	m.clients = map[string]*lspClient{}
+	m.mu.Unlock()
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func(c *lspClient) {
+			defer wg.Done()
+			c.close()
+		}(c)
+	}
+	wg.Wait()
+}
+
+type lspResponse struct {
+	Result json.RawMessage
+	Error  *lspError
+}
+
+type lspError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *lspError) Error() string { return fmt.Sprintf("lsp error %d: %s", e.Code, e.Message) }
+
+type lspDocument struct {
+	version int
+	text    string
+}
+
+// lspClient speaks JSON-RPC 2.0 with Content-Length framing over the
+// server's stdin/stdout.
+type lspClient struct {
+	name   string
+	config LSPServerConfig
+	root   string
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+
+	writeMu sync.Mutex
+
+	mu           sync.Mutex
+	nextID       int64
+	pending      map[int64]chan lspResponse
+	docs         map[string]*lspDocument
+	diagnostics  map[string][]lspDiagnostic
+	diagVersion  map[string]int
+	diagChanged  chan struct{}
+	capabilities map[string]json.RawMessage
+
+	done chan struct{}
+}
+
+func startLSPClient(ctx context.Context, cfg LSPServerConfig, root string) (*lspClient, error) {
+	if strings.TrimSpace(cfg.Command) == "" {
+		return nil, fmt.Errorf("lsp server %s has no command", cfg.Name)
+	}
+	cmd := exec.Command(cfg.Command, cfg.Args...) //nolint:gosec // configured language server
+	cmd.Dir = root
+	if len(cfg.Env) > 0 {
+		env := os.Environ()
+		for k, v := range cfg.Env {
+			env = append(env, k+"="+v)
+		}
+		cmd.Env = env
+	}
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("lsp %s stdin: %w", cfg.Name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("lsp %s stdout: %w", cfg.Name, err)
+	}
+	cmd.Stderr = io.Discard
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("start language server %s: %w", cfg.Name, err)
+	}
+	c := &lspClient{
+		name:        cfg.Name,
+		config:      cfg,
+		root:        root,
+		cmd:         cmd,
+		stdin:       stdin,
+		pending:     map[int64]chan lspResponse{},
+		docs:        map[string]*lspDocument{},
+		diagnostics: map[string][]lspDiagnostic{},
+		diagVersion: map[string]int{},
+		diagChanged: make(chan struct{}),
+		done:        make(chan struct{}),
+	}
+	readDone := make(chan struct{})
+	go func() {
+		defer close(readDone)
+		c.readLoop(stdout)
+	}()
+	go func() {
+		// Wait closes stdout, so it must not run before the reader is done. A
+		// server whose output stream ended or broke framing is unusable.
+		<-readDone
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		close(c.done)
+	}()
+	if err := c.initialize(ctx); err != nil {
+		c.kill()
+		return nil, fmt.Errorf("initialize language server %s: %w", cfg.Name, err)
+	}
+	return c, nil
+}
+
+func (c *lspClient) alive() bool {
+	select {
+	case <-c.done:
+		return false
+	default:
+		return true
+	}
+}
+
+func (c *lspClient) initialize(ctx context.Context) error {
+	rootURI := pathToURI(c.root)
+	params := map[string]interface{}{
+		"processId": os.Getpid(),
+		"rootUri":   rootURI,
+		"rootPath":  c.root,
+		"workspaceFolders": []map[string]interface{}{
+			{"uri": rootURI, "name": filepath.Base(c.root)},
+		},
+		"clientInfo": map[string]interface{}{"name": "agentsdk-go"},
+		"capabilities": map[string]interface{}{
+			"workspace": map[string]interface{}{
+				"workspaceFolders": true,
+				"configuration":    true,
+				"diagnostics":      map[string]interface{}{},
+			},
+			"textDocument": map[string]interface{}{
+				"synchronization":    map[string]interface{}{"didSave": true},
+				"hover":              map[string]interface{}{"contentFormat": []string{"markdown", "plaintext"}},
+				"definition":         map[string]interface{}{"linkSupport": true},
+				"references":         map[string]interface{}{},
+				"documentSymbol":     map[string]interface{}{"hierarchicalDocumentSymbolSupport": true},
+				"publishDiagnostics": map[string]interface{}{"relatedInformation": false},
+				"diagnostic":         map[string]interface{}{},
+			},
+		},
+	}
+	var result struct {
+		Capabilities map[string]json.RawMessage `json:"capabilities"`
+	}
+	if err := c.call(ctx, "initialize", params, &result); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.capabilities = result.Capabilities
+	c.mu.Unlock()
+	return c.notify("initialized", map[string]interface{}{})
+}
+
+// supports reports whether the server advertised a capability; absent or
+// false values mean unsupported.
+func (c *lspClient) supports(name string) bool {
+	c.mu.Lock()
+	raw, ok := c.capabilities[name]
+	c.mu.Unlock()
+	if !ok {
+		return false
+	}
+	trimmed := strings.TrimSpace(string(raw))
+	return trimmed != "" && trimmed != "false" && trimmed != "null"
+}
+
+func (c *lspClient) supportsWorkspaceDiagnostics() bool {
+	c.mu.Lock()
+	raw := c.capabilities["diagnosticProvider"]
+	c.mu.Unlock()
+	var provider struct {
+		WorkspaceDiagnostics bool `json:"workspaceDiagnostics"`
+	}
+	return len(raw) > 0 && json.Unmarshal(raw, &provider) == nil && provider.WorkspaceDiagnostics
+}
+
+func (c *lspClient) write(msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := fmt.Fprintf(c.stdin, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return fmt.Errorf("lsp %s write: %w", c.name, err)
+	}
+	if _, err := c.stdin.Write(body); err != nil {
+		return fmt.Errorf("lsp %s write: %w", c.name, err)
+	}
+	return nil
+}
+
+func (c *lspClient) notify(method string, params interface{}) error {
+	return c.write(map[string]interface{}{"jsonrpc": "2.0", "method": method, "params": params})
+}
+
+func (c *lspClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	c.mu.Lock()
+	c.nextID++
+	id := c.nextID
+	ch := make(chan lspResponse, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	if err := c.write(map[string]interface{}{"jsonrpc": "2.0", "id": id, "method": method, "params": params}); err != nil {
+		return err
+	}
+	select {
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result == nil || len(resp.Result) == 0 {
+			return nil
+		}
+		return json.Unmarshal(resp.Result, result)
+	case <-c.done:
+		return fmt.Errorf("language server %s exited", c.name)
+	case <-ctx.Done():
+		_ = c.notify("$/cancelRequest", map[string]interface{}{"id": id})
+		return ctx.Err()
+	}
+}
+
+type lspMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  *lspError       `json:"error,omitempty"`
+}
+
+func (c *lspClient) readLoop(r io.Reader) {
+	reader := textproto.NewReader(bufio.NewReader(r))
+	for {
+		header, err := reader.ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		length, err := strconv.Atoi(header.Get("Content-Length"))
+		if err != nil || length < 0 {
+			return
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader.R, body); err != nil {
+			return
+		}
+		var msg lspMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			continue
+		}
+		switch {
+		case msg.Method != "" && len(msg.ID) > 0:
+			c.handleServerRequest(msg)
+		case msg.Method != "":
+			c.handleNotification(msg)
+		case len(msg.ID) > 0:
+			id, err := strconv.ParseInt(strings.Trim(string(msg.ID), `"`), 10, 64)
+			if err != nil {
+				continue
+			}
+			c.mu.Lock()
+			ch := c.pending[id]
+			c.mu.Unlock()
+			if ch != nil {
+				ch <- lspResponse{Result: msg.Result, Error: msg.Error}
+			}
+		}
+	}
+}
+
+// handleServerRequest answers the requests servers commonly send during
+// startup so they do not block waiting for the client.
+func (c *lspClient) handleServerRequest(msg lspMessage) {
+	reply := map[string]interface{}{"jsonrpc": "2.0", "id": msg.ID}
+	switch msg.Method {
+	case "workspace/configuration":
+		var params struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		_ = json.Unmarshal(msg.Params, &params)
+		reply["result"] = make([]interface{}, len(params.Items))
+	case "workspace/workspaceFolders":
+		reply["result"] = []map[string]interface{}{{"uri": pathToURI(c.root), "name": filepath.Base(c.root)}}
+	case "client/registerCapability", "client/unregisterCapability", "window/workDoneProgress/create", "window/showMessageRequest":
+		reply["result"] = nil
+	default:
+		reply["error"] = map[string]interface{}{"code": -32601, "message": "method not found: " + msg.Method}
+	}
+	_ = c.write(reply)
+}
+
+func (c *lspClient) handleNotification(msg lspMessage) {
+	if msg.Method != "textDocument/publishDiagnostics" {
+		return
+	}
+	var params struct {
+		URI         string          `json:"uri"`
+		Version     *int            `json:"version"`
+		Diagnostics []lspDiagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.diagnostics[params.URI] = params.Diagnostics
+	c.diagVersion[params.URI]++
+	close(c.diagChanged)
+	c.diagChanged = make(chan struct{})
+	c.mu.Unlock()
+}
+
+// syncDocument opens path on the server, or sends the full new text when it
+// changed since the last sync. It returns the document URI and text.
+func (c *lspClient) syncDocument(path string) (string, string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("read file: %w", err)
+	}
+	uri := pathToURI(path)
+	text := string(data)
+	c.mu.Lock()
+	doc := c.docs[uri]
+	switch {
+	case doc == nil:
+		c.docs[uri] = &lspDocument{version: 1, text: text}
+		c.mu.Unlock()
+		return uri, text, c.notify("textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{
+				"uri":        uri,
+				"languageId": lspLanguageID(c.config, path),
+				"version":    1,
+				"text":       text,
+			},
+		})
+	case doc.text != text:
+		doc.version++
+		doc.text = text
+		version := doc.version
+		c.mu.Unlock()
+		return uri, text, c.notify("textDocument/didChange", map[string]interface{}{
+			"textDocument":   map[string]interface{}{"uri": uri, "version": version},
+			"contentChanges": []map[string]interface{}{{"text": text}},
+		})
+	default:
+		c.mu.Unlock()
+		return uri, text, nil
+	}
+}
+
+// documentVersion reports the version last sent for uri, or 0 when the
+// document has not been opened.
+func (c *lspClient) documentVersion(uri string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if doc := c.docs[uri]; doc != nil {
+		return doc.version
+	}
+	return 0
+}
+
+// publishedDiagnostics waits up to wait for a publishDiagnostics newer than
+// the snapshot taken before the document was synced.
+func (c *lspClient) publishedDiagnostics(ctx context.Context, uri string, since int, wait time.Duration) []lspDiagnostic {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		c.mu.Lock()
+		version := c.diagVersion[uri]
+		diags := c.diagnostics[uri]
+		changed := c.diagChanged
+		c.mu.Unlock()
+		if version > since {
+			return diags
+		}
+		select {
+		case <-changed:
+		case <-timer.C:
+			return diags
+		case <-ctx.Done():
+			return diags
+		case <-c.done:
+			return diags
+		}
+	}
+}
+
+func (c *lspClient) diagnosticVersion(uri string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.diagVersion[uri]
+}
+
+func (c *lspClient) allDiagnostics() map[string][]lspDiagnostic {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	out := make(map[string][]lspDiagnostic, len(c.diagnostics))
+	for uri, diags := range c.diagnostics {
+		if len(diags) > 0 {
+			out[uri] = append([]lspDiagnostic(nil), diags...)
+		}
+	}
+	return out
+}
+
+func (c *lspClient) close() {
+	if !c.alive() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), lspShutdownTimeout)
+	defer cancel()
+	if err := c.call(ctx, "shutdown", nil, nil); err == nil {
+		_ = c.notify("exit", nil)
+	}
+	_ = c.stdin.Close()
+	select {
+	case <-c.done:
+	case <-time.After(lspShutdownTimeout):
+		c.kill()
+	}
+}
+
+func (c *lspClient) kill() {
+	if c.cmd.Process != nil {
+		_ = c.cmd.Process.Kill()
+	}
+	_ = c.stdin.Close()
+	<-c.done
+}
+
+func lspLanguageID(cfg LSPServerConfig, path string) string {
+	if cfg.LanguageID != "" {
+		return cfg.LanguageID
+	}
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".ts", ".mts", ".cts":
+		return "typescript"
+	case ".tsx":
+		return "typescriptreact"
+	case ".js", ".mjs", ".cjs":
+		return "javascript"
+	case ".jsx":
+		return "javascriptreact"
+	case ".py", ".pyi":
+		return "python"
+	default:
+		return strings.TrimPrefix(ext, ".")
+	}
+}
+
+func pathToURI(path string) string {
+	slashed := filepath.ToSlash(path)
+	if runtime.GOOS == "windows" && !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
+
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path)
+}
@@ -0,0 +1,316 @@
+package toolbuiltin
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const fakeLSPEnv = "AGENTSDK_FAKE_LSP"
+
+// TestFakeLSPServer is not a real test: when re-executed with fakeLSPEnv set
+// it turns the test binary into a scripted language server speaking LSP over
+// stdio. Definitions resolve to "func <word>" lines, references are every
+// whole-word occurrence and lines containing TODO produce a warning.
+func TestFakeLSPServer(t *testing.T) {
+	if os.Getenv(fakeLSPEnv) != "1" {
+		t.Skip("helper process for lsp tests")
+	}
+	runFakeLSPServer(os.Stdin, os.Stdout)
+	os.Exit(0)
+}
+
+func runFakeLSPServer(in io.Reader, out io.Writer) {
+	reader := textproto.NewReader(bufio.NewReader(in))
+	docs := map[string]string{}
+	send := func(msg map[string]interface{}) {
+		msg["jsonrpc"] = "2.0"
+		body, _ := json.Marshal(msg)
+		fmt.Fprintf(out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	}
+	publish := func(uri string) {
+		diags := []map[string]interface{}{}
+		for i, line := range strings.Split(docs[uri], "\n") {
+			if col := strings.Index(line, "TODO"); col >= 0 {
+				diags = append(diags, map[string]interface{}{
+					"range":    fakeRange(i, col, col+4),
+					"severity": 2,
+					"source":   "fake",
+					"message":  "unresolved TODO",
+				})
+			}
+		}
+		send(map[string]interface{}{"method": "textDocument/publishDiagnostics", "params": map[string]interface{}{"uri": uri, "diagnostics": diags}})
+	}
+	for {
+		header, err := reader.ReadMIMEHeader()
+		if err != nil {
+			return
+		}
+		length, _ := strconv.Atoi(header.Get("Content-Length"))
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader.R, body); err != nil {
+			return
+		}
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+			Params struct {
+				TextDocument struct {
+					URI  string `json:"uri"`
+					Text string `json:"text"`
+				} `json:"textDocument"`
+				ContentChanges []struct {
+					Text string `json:"text"`
+				} `json:"contentChanges"`
+				Position lspPosition `json:"position"`
+				Context  struct {
+					IncludeDeclaration bool `json:"includeDeclaration"`
+				} `json:"context"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(body, &msg); err != nil {
+			return
+		}
+		uri := msg.Params.TextDocument.URI
+		lines := strings.Split(docs[uri], "\n")
+		word := ""
+		if p := msg.Params.Position; p.Line < len(lines) {
+			word = fakeWordAt(lines[p.Line], p.Character)
+		}
+		switch msg.Method {
+		case "initialize":
+			send(map[string]interface{}{"id": msg.ID, "result": map[string]interface{}{"capabilities": map[string]interface{}{
+				"definitionProvider": true, "referencesProvider": true, "hoverProvider": true, "documentSymbolProvider": true,
+			}}})
+		case "initialized":
+			// Exercise client handling of server-initiated requests.
+			send(map[string]interface{}{"id": 900, "method": "workspace/configuration", "params": map[string]interface{}{"items": []interface{}{map[string]interface{}{}}}})
+		case "textDocument/didOpen":
+			docs[uri] = msg.Params.TextDocument.Text
+			publish(uri)
+		case "textDocument/didChange":
+			docs[uri] = msg.Params.ContentChanges[len(msg.Params.ContentChanges)-1].Text
+			publish(uri)
+		case "textDocument/definition":
+			var result interface{}
+			for i, line := range lines {
+				if col := strings.Index(line, "func "+word+"("); col >= 0 {
+					result = []map[string]interface{}{{"uri": uri, "range": fakeRange(i, col+5, col+5+len(word))}}
+				}
+			}
+			send(map[string]interface{}{"id": msg.ID, "result": result})
+		case "textDocument/references":
+			refs := []map[string]interface{}{}
+			pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(word) + `\b`)
+			for i, line := range lines {
+				for _, loc := range pattern.FindAllStringIndex(line, -1) {
+					if !msg.Params.Context.IncludeDeclaration && strings.Contains(line, "func "+word) {
+						continue
+					}
+					refs = append(refs, map[string]interface{}{"uri": uri, "range": fakeRange(i, loc[0], loc[1])})
+				}
+			}
+			send(map[string]interface{}{"id": msg.ID, "result": refs})
+		case "textDocument/hover":
+			send(map[string]interface{}{"id": msg.ID, "result": map[string]interface{}{
+				"contents": map[string]interface{}{"kind": "markdown", "value": "```go\nfunc " + word + "()\n```"},
+			}})
+		case "textDocument/documentSymbol":
+			symbols := []map[string]interface{}{}
+			for i, line := range lines {
+				if strings.HasPrefix(line, "func ") {
+					name := strings.TrimPrefix(line, "func ")
+					name = name[:strings.Index(name, "(")]
+					symbols = append(symbols, map[string]interface{}{
+						"name": name, "kind": 12, "range": fakeRange(i, 0, len(line)), "selectionRange": fakeRange(i, 5, 5+len(name)),
+					})
+				}
+			}
+			send(map[string]interface{}{"id": msg.ID, "result": symbols})
+		case "shutdown":
+			send(map[string]interface{}{"id": msg.ID, "result": nil})
+		case "exit":
+			return
+		default:
+			if len(msg.ID) > 0 && msg.Method != "" {
+				send(map[string]interface{}{"id": msg.ID, "error": map[string]interface{}{"code": -32601, "message": "unsupported"}})
+			}
+		}
+	}
+}
+
+func fakeRange(line, start, end int) map[string]interface{} {
+	return map[string]interface{}{
+		"start": map[string]int{"line": line, "character": start},
+		"end":   map[string]int{"line": line, "character": end},
+	}
+}
+
+func fakeWordAt(line string, col int) string {
+	isWord := func(b byte) bool {
+		return b == '_' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z' || b >= '0' && b <= '9'
+	}
+	if col > len(line) {
+		return ""
+	}
+	start, end := col, col
+	for start > 0 && isWord(line[start-1]) {
+		start--
+	}
+	for end < len(line) && isWord(line[end]) {
+		end++
+	}
+	return line[start:end]
+}
+
+func newFakeLSPTool(t *testing.T, dir string) (*LSPTool, *LSPManager) {
+	t.Helper()
+	manager := NewLSPManager(dir, []LSPServerConfig{{
+		Name:       "fake",
+		Command:    os.Args[0],
+		Args:       []string{"-test.run=^TestFakeLSPServer$"},
+		Extensions: []string{".go"},
+		Env:        map[string]string{fakeLSPEnv: "1"},
+	}})
+	manager.diagnosticsWait = 5 * time.Second
+	t.Cleanup(manager.Close)
+	lsp := NewLSPToolWithRoot(dir)
+	lsp.SetLSPManager(manager)
+	return lsp, manager
+}
+
+const fakeLSPSource = `package main
+
+func helper() {}
+
+func main() {
+	helper()
+	helper() // TODO: dedupe
+}
+`
+
+func TestLSPToolOperations(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte(fakeLSPSource), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	lsp, manager := newFakeLSPTool(t, dir)
+	ctx := context.Background()
+	run := func(params map[string]interface{}) string {
+		t.Helper()
+		res, err := lsp.Execute(ctx, params)
+		if err != nil {
+			t.Fatalf("lsp %v: %v", params, err)
+		}
+		return res.Output
+	}
+
+	if got := run(map[string]interface{}{"operation": "definition", "file_path": "main.go", "line": 6, "symbol": "helper"}); got != "main.go:3:6: func helper() {}" {
+		t.Fatalf("unexpected definition %q", got)
+	}
+	refs := run(map[string]interface{}{"operation": "references", "file_path": "main.go", "line": 3, "character": 7})
+	if strings.Count(refs, "\n") != 2 || !strings.Contains(refs, "main.go:7:2: helper() // TODO: dedupe") {
+		t.Fatalf("unexpected references %q", refs)
+	}
+	refs = run(map[string]interface{}{"operation": "references", "file_path": "main.go", "line": 3, "character": 7, "include_declaration": false})
+	if strings.Count(refs, "\n") != 1 {
+		t.Fatalf("declaration should be excluded: %q", refs)
+	}
+	if got := run(map[string]interface{}{"operation": "hover", "file_path": "main.go", "line": 6, "symbol": "helper"}); !strings.Contains(got, "func helper()") {
+		t.Fatalf("unexpected hover %q", got)
+	}
+	if got := run(map[string]interface{}{"operation": "document_symbols", "file_path": "main.go"}); got != "Function helper :3\nFunction main :5" {
+		t.Fatalf("unexpected symbols %q", got)
+	}
+	if got := run(map[string]interface{}{"operation": "diagnostics", "file_path": "main.go"}); got != "main.go:7:14: warning: unresolved TODO [fake]" {
+		t.Fatalf("unexpected diagnostics %q", got)
+	}
+
+	// Edits on disk are synced before the next query.
+	if err := os.WriteFile(path, []byte(strings.ReplaceAll(fakeLSPSource, " // TODO: dedupe", "")), 0o600); err != nil {
+		t.Fatalf("rewrite: %v", err)
+	}
+	if got := run(map[string]interface{}{"operation": "diagnostics", "file_path": "main.go"}); got != "no diagnostics" {
+		t.Fatalf("expected diagnostics to clear, got %q", got)
+	}
+	if got := run(map[string]interface{}{"operation": "diagnostics"}); got != "no diagnostics" {
+		t.Fatalf("unexpected workspace diagnostics %q", got)
+	}
+	if len(manager.running()) != 1 {
+		t.Fatalf("expected one shared server, got %d", len(manager.running()))
+	}
+}
+
+func TestLSPToolErrors(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(fakeLSPSource), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	lsp, manager := newFakeLSPTool(t, dir)
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{"workspace without servers", map[string]interface{}{"operation": "diagnostics"}, "no language servers are running"},
+		{"missing operation", map[string]interface{}{}, "operation is required"},
+		{"unknown operation", map[string]interface{}{"operation": "rename"}, "unsupported operation"},
+		{"missing file", map[string]interface{}{"operation": "hover"}, "file_path is required"},
+		{"missing line", map[string]interface{}{"operation": "hover", "file_path": "main.go"}, "line is required"},
+		{"past end", map[string]interface{}{"operation": "hover", "file_path": "main.go", "line": 99}, "past the end"},
+		{"unknown symbol", map[string]interface{}{"operation": "hover", "file_path": "main.go", "line": 3, "symbol": "nope"}, "not found on line"},
+		{"no server", map[string]interface{}{"operation": "document_symbols", "file_path": "notes.txt"}, "no language server configured"},
+		{"escape", map[string]interface{}{"operation": "document_symbols", "file_path": "../outside.go"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := lsp.Execute(context.Background(), tt.params); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	manager.Close()
+	if _, err := lsp.Execute(context.Background(), map[string]interface{}{"operation": "document_symbols", "file_path": "main.go"}); err == nil || !strings.Contains(err.Error(), "closed") {
+		t.Fatalf("expected closed manager error, got %v", err)
+	}
+}
+
+func TestLSPHelpers(t *testing.T) {
+	if got := runeToUTF16("a😀b", 2); got != 3 {
+		t.Fatalf("runeToUTF16 = %d", got)
+	}
+	if got := utf16ToRune("a😀b", 3); got != 2 {
+		t.Fatalf("utf16ToRune = %d", got)
+	}
+	if got := indexIdentifier("xhelper(helper)", "helper"); got != 8 {
+		t.Fatalf("indexIdentifier = %d", got)
+	}
+	links := decodeLocations(json.RawMessage(`[{"targetUri":"file:///a.go","targetSelectionRange":{"start":{"line":2,"character":1},"end":{"line":2,"character":3}}}]`))
+	if len(links) != 1 || links[0].URI != "file:///a.go" || links[0].Range.Start.Line != 2 {
+		t.Fatalf("unexpected links %#v", links)
+	}
+	if got := hoverText(json.RawMessage(`{"contents":[{"language":"go","value":"x int"},"doc"]}`)); got != "```go\nx int\n```\n\ndoc" {
+		t.Fatalf("unexpected hover %q", got)
+	}
+	if got := uriToPath(pathToURI("/tmp/a b.go")); got != "/tmp/a b.go" {
+		t.Fatalf("uri round trip = %q", got)
+	}
+}
@@ -0,0 +1,223 @@
+package toolbuiltin
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const (
+	ListMCPResourcesName = "list_mcp_resources"
+	ReadMCPResourceName  = "read_mcp_resource"
+
+	listMCPResourcesDescription = `Lists resources (files, records, documents) exposed by connected MCP servers.
+Pass "server" to list a single server. Read a resource with read_mcp_resource.`
+	readMCPResourceDescription = `Reads a resource exposed by a connected MCP server by URI.
+Use list_mcp_resources to discover URIs. "server" is required when several servers expose resources.`
+)
+
+var listMCPResourcesSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"server": map[string]interface{}{
+			"type":        "string",
+			"description": "Only list resources of this MCP server.",
+		},
+	},
+}
+
+var readMCPResourceSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"server": map[string]interface{}{
+			"type":        "string",
+			"description": "MCP server that owns the resource.",
+		},
+		"uri": map[string]interface{}{
+			"type":        "string",
+			"description": "Resource URI as returned by list_mcp_resources.",
+		},
+	},
+	Required: []string{"uri"},
+}
+
+// MCPResourceSource is the part of tool.Registry the resource tools use.
+type MCPResourceSource interface {
+	ListMCPResources(ctx context.Context, server string) ([]tool.MCPResource, error)
+	ReadMCPResource(ctx context.Context, server, uri string) (*mcp.ReadResourceResult, error)
+}
+
+// ListMCPResourcesTool lists resources of connected MCP servers.
+type ListMCPResourcesTool struct {
+	source MCPResourceSource
+}
+
+// NewListMCPResourcesTool builds the tool on top of source, usually the
+// runtime's tool.Registry.
+func NewListMCPResourcesTool(source MCPResourceSource) *ListMCPResourcesTool {
+	return &ListMCPResourcesTool{source: source}
+}
+
+func (t *ListMCPResourcesTool) Name() string { return ListMCPResourcesName }
+
+func (t *ListMCPResourcesTool) Description() string { return listMCPResourcesDescription }
+
+func (t *ListMCPResourcesTool) Schema() *tool.JSONSchema { return listMCPResourcesSchema }
+
+func (t *ListMCPResourcesTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsReadOnly: true, IsConcurrencySafe: true}
+}
+
+// Cacheable is false: servers change their resource lists without touching
+// the local filesystem.
+func (t *ListMCPResourcesTool) Cacheable() bool { return false }
+
+func (t *ListMCPResourcesTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if t == nil || t.source == nil {
+		return nil, errors.New("list_mcp_resources tool is not initialised")
+	}
+	server, err := optionalMCPParam(params, "server")
+	if err != nil {
+		return nil, err
+	}
+	resources, err := t.source.ListMCPResources(ctx, server)
+	if err != nil && len(resources) == 0 {
+		return nil, err
+	}
+	var b strings.Builder
+	for _, res := range resources {
+		fmt.Fprintf(&b, "%s: %s", res.Server, res.URI)
+		if res.Name != "" && res.Name != res.URI {
+			fmt.Fprintf(&b, " (%s)", res.Name)
+		}
+		if res.MIMEType != "" {
+			fmt.Fprintf(&b, " [%s]", res.MIMEType)
+		}
+		if res.Description != "" {
+			fmt.Fprintf(&b, " - %s", res.Description)
+		}
+		b.WriteByte('\n')
+	}
+	if err != nil {
+		// Partial listing: keep what succeeded and surface the failures.
+		fmt.Fprintf(&b, "warning: %v\n", err)
+	}
+	output := strings.TrimSpace(b.String())
+	if output == "" {
+		output = "No MCP resources found."
+	}
+	return &tool.ToolResult{
+		Success: true,
+		Output:  output,
+		Data:    map[string]interface{}{"resources": resources},
+	}, nil
+}
+
+// ReadMCPResourceTool reads one resource from a connected MCP server.
+type ReadMCPResourceTool struct {
+	source MCPResourceSource
+}
+
+// NewReadMCPResourceTool builds the tool on top of source, usually the
+// runtime's tool.Registry.
+func NewReadMCPResourceTool(source MCPResourceSource) *ReadMCPResourceTool {
+	return &ReadMCPResourceTool{source: source}
+}
+
+func (t *ReadMCPResourceTool) Name() string { return ReadMCPResourceName }
+
+func (t *ReadMCPResourceTool) Description() string { return readMCPResourceDescription }
+
+func (t *ReadMCPResourceTool) Schema() *tool.JSONSchema { return readMCPResourceSchema }
+
+func (t *ReadMCPResourceTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsReadOnly: true, IsConcurrencySafe: true}
+}
+
+// Cacheable is false since remote resource contents change independently of
+// the files the cache watches.
+func (t *ReadMCPResourceTool) Cacheable() bool { return false }
+
+func (t *ReadMCPResourceTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if t == nil || t.source == nil {
+		return nil, errors.New("read_mcp_resource tool is not initialised")
+	}
+	server, err := optionalMCPParam(params, "server")
+	if err != nil {
+		return nil, err
+	}
+	uri, err := optionalMCPParam(params, "uri")
+	if err != nil {
+		return nil, err
+	}
+	if uri == "" {
+		return nil, errors.New("uri is required")
+	}
+	res, err := t.source.ReadMCPResource(ctx, server, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		parts  []string
+		blocks []model.ContentBlock
+	)
+	if res != nil {
+		for _, content := range res.Contents {
+			if content == nil {
+				continue
+			}
+			switch {
+			case content.Blob == nil:
+				parts = append(parts, content.Text)
+			case strings.HasPrefix(content.MIMEType, "image/"):
+				blocks = append(blocks, model.ContentBlock{
+					Type:      model.ContentBlockImage,
+					MediaType: content.MIMEType,
+					Data:      base64.StdEncoding.EncodeToString(content.Blob),
+				})
+				parts = append(parts, fmt.Sprintf("[image %s, %d bytes]", content.MIMEType, len(content.Blob)))
+			default:
+				parts = append(parts, fmt.Sprintf("[binary %s, %d bytes]", content.MIMEType, len(content.Blob)))
+			}
+		}
+	}
+	output := strings.Join(parts, "\n")
+	if strings.TrimSpace(output) == "" {
+		output = "Resource is empty."
+	}
+	var contents []*mcp.ResourceContents
+	if res != nil {
+		contents = res.Contents
+	}
+	return &tool.ToolResult{
+		Success:       true,
+		Output:        output,
+		ContentBlocks: blocks,
+		Data:          map[string]interface{}{"server": server, "uri": uri, "contents": contents},
+	}, nil
+}
+
+func optionalMCPParam(params map[string]interface{}, key string) (string, error) {
+	raw, ok := params[key]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	value, err := coerceString(raw)
+	if err != nil {
+		return "", fmt.Errorf("%s must be string: %w", key, err)
+	}
+	return strings.TrimSpace(value), nil
+}
@@ -0,0 +1,85 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+type fakeResourceSource struct {
+	resources []tool.MCPResource
+	listErr   error
+	server    string
+	uri       string
+}
+
+func (f *fakeResourceSource) ListMCPResources(_ context.Context, server string) ([]tool.MCPResource, error) {
+	f.server = server
+	return f.resources, f.listErr
+}
+
+func (f *fakeResourceSource) ReadMCPResource(_ context.Context, server, uri string) (*mcp.ReadResourceResult, error) {
+	f.server, f.uri = server, uri
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{
+		{URI: uri, MIMEType: "text/plain", Text: "hello"},
+		{URI: uri, MIMEType: "image/png", Blob: []byte{1, 2, 3}},
+		{URI: uri, MIMEType: "application/zip", Blob: []byte{4}},
+	}}, nil
+}
+
+func TestListMCPResourcesTool(t *testing.T) {
+	source := &fakeResourceSource{resources: []tool.MCPResource{
+		{Server: "docs", URI: "file:///a.md", Name: "a", MIMEType: "text/markdown", Description: "Doc A"},
+	}}
+	impl := NewListMCPResourcesTool(source)
+	if !impl.Metadata().IsReadOnly || tool.IsCacheable(impl) {
+		t.Fatal("listing resources is read-only but must not be cached")
+	}
+	res, err := impl.Execute(context.Background(), map[string]interface{}{"server": " docs "})
+	if err != nil || res.Output != "docs: file:///a.md (a) [text/markdown] - Doc A" || source.server != "docs" {
+		t.Fatalf("unexpected result %+v %v", res, err)
+	}
+
+	source.listErr = errors.New("other: offline")
+	res, err = impl.Execute(context.Background(), nil)
+	if err != nil || !strings.Contains(res.Output, "warning: other: offline") {
+		t.Fatalf("partial failures should be reported: %+v %v", res, err)
+	}
+	source.resources = nil
+	if _, err := impl.Execute(context.Background(), nil); err == nil {
+		t.Fatal("total failure should be an error")
+	}
+	source.listErr = nil
+	if res, _ := impl.Execute(context.Background(), nil); res.Output != "No MCP resources found." {
+		t.Fatalf("unexpected empty output %q", res.Output)
+	}
+}
+
+func TestReadMCPResourceTool(t *testing.T) {
+	source := &fakeResourceSource{}
+	impl := NewReadMCPResourceTool(source)
+	if tool.IsCacheable(impl) {
+		t.Fatal("remote resource contents must not be cached")
+	}
+	if _, err := impl.Execute(context.Background(), map[string]interface{}{}); err == nil {
+		t.Fatal("uri is required")
+	}
+	res, err := impl.Execute(context.Background(), map[string]interface{}{"server": "docs", "uri": "file:///a"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if source.server != "docs" || source.uri != "file:///a" {
+		t.Fatalf("unexpected request %+v", source)
+	}
+	if res.Output != "hello\n[image image/png, 3 bytes]\n[binary application/zip, 1 bytes]" {
+		t.Fatalf("unexpected output %q", res.Output)
+	}
+	if len(res.ContentBlocks) != 1 || res.ContentBlocks[0].Type != model.ContentBlockImage || res.ContentBlocks[0].Data != "AQID" {
+		t.Fatalf("images should become content blocks: %+v", res.ContentBlocks)
+	}
+}
@@ -0,0 +1,239 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const MultiEditName = "multi_edit"
+
+const multiEditDescription = `Applies several exact string replacements to a single file in one atomic operation.
+Usage:
+- edits are applied in order; each edit sees the result of the previous ones.
+- Every old_string must be unique in the intermediate content unless replace_all is set.
+- If any edit fails, the file is left untouched. Prefer this over repeated edit calls on the same file.
+- The file must have been read in this session and must not have changed since.`
+
+var multiEditSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"file_path": map[string]interface{}{
+			"type":        "string",
+			"description": "Path to the file to modify (absolute or relative to the sandbox root).",
+		},
+		"edits": map[string]interface{}{
+			"type":        "array",
+			"minItems":    1,
+			"description": "Ordered list of replacements to apply.",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"old_string": map[string]interface{}{
+						"type":        "string",
+						"description": "The text to replace",
+					},
+					"new_string": map[string]interface{}{
+						"type":        "string",
+						"description": "The text to replace it with (must be different from old_string)",
+					},
+					"replace_all": map[string]interface{}{
+						"type":        "boolean",
+						"default":     false,
+						"description": "Replace all occurrences of old_string (default false)",
+					},
+				},
+				"required": []string{"old_string", "new_string"},
+			},
+		},
+	},
+	Required: []string{"file_path", "edits"},
+}
+
+// MultiEditTool applies an ordered batch of replacements to one file.
+type MultiEditTool struct {
+	base *fileSandbox
+}
+
+// NewMultiEditTool builds a MultiEditTool rooted at the current directory.
+func NewMultiEditTool() *MultiEditTool {
+	return NewMultiEditToolWithRoot("")
+}
+
+// NewMultiEditToolWithRoot builds a MultiEditTool rooted at the provided directory.
+func NewMultiEditToolWithRoot(root string) *MultiEditTool {
+	return &MultiEditTool{base: newFileSandbox(root)}
+}
+
+// NewMultiEditToolWithSandbox builds a MultiEditTool using a custom sandbox.
+func NewMultiEditToolWithSandbox(root string, policy sandbox.FileSystemPolicy) *MultiEditTool {
+	return &MultiEditTool{base: newFileSandboxWithSandbox(root, policy)}
+}
+
+func (m *MultiEditTool) Name() string { return MultiEditName }
+
+func (m *MultiEditTool) Description() string { return multiEditDescription }
+
+func (m *MultiEditTool) Schema() *tool.JSONSchema { return multiEditSchema }
+
+func (m *MultiEditTool) Metadata() tool.Metadata {
+	return tool.Metadata{}
+}
+
+// SetFileState attaches the tracker used to enforce read-before-write.
+func (m *MultiEditTool) SetFileState(files *FileStateTracker) {
+	if m != nil && m.base != nil {
+		m.base.files = files
+	}
+}
+
+type stringEdit struct {
+	oldString  string
+	newString  string
+	replaceAll bool
+}
+
+func (m *MultiEditTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if m == nil || m.base == nil {
+		return nil, errors.New("multi_edit tool is not initialised")
+	}
+	if params == nil {
+		return nil, errors.New("params is nil")
+	}
+	raw, ok := params["file_path"]
+	if !ok {
+		return nil, errors.New("file_path is required")
+	}
+	path, err := m.base.resolvePath(raw)
+	if err != nil {
+		return nil, err
+	}
+	edits, err := parseStringEdits(params["edits"])
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	if err := m.base.checkWrite(ctx, path); err != nil {
+		return nil, err
+	}
+	content, err := m.base.readFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := content
+	total := 0
+	results := make([]map[string]interface{}, 0, len(edits))
+	for i, edit := range edits {
+		next, matches, replaced, err := replaceExact(updated, edit.oldString, edit.newString, edit.replaceAll)
+		if err != nil {
+			if errors.Is(err, errOldStringNotFound) {
+				return nil, fmt.Errorf("edit %d: old_string not found in %s (after applying previous edits)", i+1, displayPath(path, m.base.root))
+			}
+			return nil, fmt.Errorf("edit %d: %w", i+1, err)
+		}
+		updated = next
+		total += replaced
+		results = append(results, map[string]interface{}{
+			"matches":  matches,
+			"replaced": replaced,
+		})
+	}
+
+	if m.base.maxBytes > 0 && int64(len(updated)) > m.base.maxBytes {
+		return nil, fmt.Errorf("edited content exceeds %d bytes limit", m.base.maxBytes)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path, []byte(updated), info.Mode()); err != nil {
+		return nil, err
+	}
+	m.base.recordRead(ctx, path, []byte(updated))
+
+	return &tool.ToolResult{
+		Success: true,
+		Output:  fmt.Sprintf("applied %d edit(s) with %d replacement(s) to %s", len(edits), total, displayPath(path, m.base.root)),
+		Data: map[string]interface{}{
+			"path":     displayPath(path, m.base.root),
+			"edits":    results,
+			"replaced": total,
+		},
+	}, nil
+}
+
+func parseStringEdits(raw interface{}) ([]stringEdit, error) {
+	if raw == nil {
+		return nil, errors.New("edits is required")
+	}
+	var items []interface{}
+	switch v := raw.(type) {
+	case []interface{}:
+		items = v
+	case []map[string]interface{}:
+		items = make([]interface{}, len(v))
+		for i := range v {
+			items[i] = v[i]
+		}
+	default:
+		return nil, fmt.Errorf("edits must be an array, got %T", raw)
+	}
+	if len(items) == 0 {
+		return nil, errors.New("edits cannot be empty")
+	}
+	edits := make([]stringEdit, 0, len(items))
+	for i, item := range items {
+		obj, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("edit %d must be an object, got %T", i+1, item)
+		}
+		var edit stringEdit
+		for _, key := range []string{"old_string", "new_string"} {
+			value, ok := obj[key]
+			if !ok || value == nil {
+				return nil, fmt.Errorf("edit %d: %s is required", i+1, key)
+			}
+			str, err := coerceString(value)
+			if err != nil {
+				return nil, fmt.Errorf("edit %d: %s must be string: %w", i+1, key, err)
+			}
+			if key == "old_string" {
+				edit.oldString = str
+			} else {
+				edit.newString = str
+			}
+		}
+		if edit.oldString == "" {
+			return nil, fmt.Errorf("edit %d: old_string cannot be empty", i+1)
+		}
+		if edit.oldString == edit.newString {
+			return nil, fmt.Errorf("edit %d: new_string must differ from old_string", i+1)
+		}
+		if value, ok := obj["replace_all"]; ok && value != nil {
+			replaceAll, err := coerceBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("edit %d: replace_all must be boolean: %w", i+1, err)
+			}
+			edit.replaceAll = replaceAll
+		}
+		edits = append(edits, edit)
+	}
+	return edits, nil
+}
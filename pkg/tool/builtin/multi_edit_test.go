@@ -0,0 +1,114 @@
+package toolbuiltin
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMultiEditAppliesEditsInOrder(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := filepath.Join(dir, "main.go")
+	if err := os.WriteFile(path, []byte("func oldName() {}\n\nfunc caller() { oldName(); oldName() }\n"), 0o640); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+
+	res, err := NewMultiEditToolWithRoot(dir).Execute(context.Background(), map[string]interface{}{
+		"file_path": "main.go",
+		"edits": []interface{}{
+			map[string]interface{}{"old_string": "func oldName()", "new_string": "func newName()"},
+			// Unique only once the first edit has been applied.
+			map[string]interface{}{"old_string": "oldName()", "new_string": "newName()", "replace_all": true},
+			map[string]interface{}{"old_string": "func caller()", "new_string": "func Caller()"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("multi_edit: %v", err)
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read back: %v", err)
+	}
+	if want := "func newName() {}\n\nfunc Caller() { newName(); newName() }\n"; string(got) != want {
+		t.Fatalf("unexpected content %q", got)
+	}
+	data := res.Data.(map[string]interface{})
+	if data["replaced"] != 4 || len(data["edits"].([]map[string]interface{})) != 3 {
+		t.Fatalf("unexpected data %#v", data)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Fatalf("file mode not preserved: %v", info.Mode())
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("temp files left behind: %v", entries)
+	}
+}
+
+func TestMultiEditLeavesFileUntouchedOnFailure(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := filepath.Join(dir, "notes.txt")
+	original := "alpha beta alpha\n"
+	if err := os.WriteFile(path, []byte(original), 0o600); err != nil {
+		t.Fatalf("write fixture: %v", err)
+	}
+	tool := NewMultiEditToolWithRoot(dir)
+
+	tests := []struct {
+		name  string
+		edits []interface{}
+		want  string
+	}{
+		{
+			name: "later edit missing",
+			edits: []interface{}{
+				map[string]interface{}{"old_string": "beta", "new_string": "gamma"},
+				map[string]interface{}{"old_string": "beta", "new_string": "delta"},
+			},
+			want: "edit 2: old_string not found",
+		},
+		{
+			name:  "ambiguous",
+			edits: []interface{}{map[string]interface{}{"old_string": "alpha", "new_string": "omega"}},
+			want:  "edit 1: old_string must be unique",
+		},
+		{
+			name:  "identical strings",
+			edits: []interface{}{map[string]interface{}{"old_string": "beta", "new_string": "beta"}},
+			want:  "edit 1: new_string must differ",
+		},
+		{
+			name:  "missing new_string",
+			edits: []interface{}{map[string]interface{}{"old_string": "beta"}},
+			want:  "edit 1: new_string is required",
+		},
+		{name: "empty", edits: []interface{}{}, want: "edits cannot be empty"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), map[string]interface{}{"file_path": path, "edits": tt.edits})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+			got, _ := os.ReadFile(path)
+			if string(got) != original {
+				t.Fatalf("file modified on failure: %q", got)
+			}
+		})
+	}
+
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"file_path": path, "edits": "nope"}); err == nil {
+		t.Fatal("expected type error for edits")
+	}
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"file_path": filepath.Join(dir, "missing.txt"), "edits": []interface{}{map[string]interface{}{"old_string": "a", "new_string": "b"}}}); err == nil {
+		t.Fatal("expected error for missing file")
+	}
+}
@@ -0,0 +1,349 @@
+package toolbuiltin
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const NotebookEditName = "notebook_edit"
+
+const notebookEditDescription = `Edits a Jupyter notebook (.ipynb) cell by id.
+Usage:
+- cell_id is the id shown by the read tool (nbformat id or "cell-N" for older notebooks).
+- edit_mode=replace (default) swaps the cell source; replacing a code cell clears its outputs.
+- edit_mode=insert adds a new cell after cell_id, or at the start when cell_id is omitted; cell_type is required.
+- edit_mode=delete removes the cell.
+- All other notebook content and metadata is preserved.
+- The notebook must have been read in this session and must not have changed since.`
+
+var notebookEditSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"notebook_path": map[string]interface{}{
+			"type":        "string",
+			"description": "Path to the .ipynb file (absolute or relative to the sandbox root).",
+		},
+		"cell_id": map[string]interface{}{
+			"type":        "string",
+			"description": "Id of the cell to edit. For insert, the new cell goes after this cell (omit to insert at the start).",
+		},
+		"new_source": map[string]interface{}{
+			"type":        "string",
+			"description": "The new source for the cell.",
+		},
+		"cell_type": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"code", "markdown"},
+			"description": "The cell type. Required for insert; defaults to the current type for replace.",
+		},
+		"edit_mode": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"replace", "insert", "delete"},
+			"description": "replace (default), insert or delete.",
+		},
+	},
+	Required: []string{"notebook_path"},
+}
+
+// NotebookEditTool replaces, inserts and deletes notebook cells.
+type NotebookEditTool struct {
+	base *fileSandbox
+}
+
+// NewNotebookEditTool builds a NotebookEditTool rooted at the current directory.
+func NewNotebookEditTool() *NotebookEditTool {
+	return NewNotebookEditToolWithRoot("")
+}
+
+// NewNotebookEditToolWithRoot builds a NotebookEditTool rooted at the provided directory.
+func NewNotebookEditToolWithRoot(root string) *NotebookEditTool {
+	return &NotebookEditTool{base: newFileSandbox(root)}
+}
+
+// NewNotebookEditToolWithSandbox builds a NotebookEditTool using a custom sandbox.
+func NewNotebookEditToolWithSandbox(root string, policy sandbox.FileSystemPolicy) *NotebookEditTool {
+	return &NotebookEditTool{base: newFileSandboxWithSandbox(root, policy)}
+}
+
+func (n *NotebookEditTool) Name() string { return NotebookEditName }
+
+func (n *NotebookEditTool) Description() string { return notebookEditDescription }
+
+func (n *NotebookEditTool) Schema() *tool.JSONSchema { return notebookEditSchema }
+
+func (n *NotebookEditTool) Metadata() tool.Metadata {
+	return tool.Metadata{}
+}
+
+// SetFileState attaches the tracker used to enforce read-before-write.
+func (n *NotebookEditTool) SetFileState(files *FileStateTracker) {
+	if n != nil && n.base != nil {
+		n.base.files = files
+	}
+}
+
+type notebookEditRequest struct {
+	cellID    string
+	source    string
+	hasSource bool
+	cellType  string
+	mode      string
+}
+
+func (n *NotebookEditTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if n == nil || n.base == nil {
+		return nil, errors.New("notebook_edit tool is not initialised")
+	}
+	if params == nil {
+		return nil, errors.New("params is nil")
+	}
+	raw, ok := params["notebook_path"]
+	if !ok {
+		return nil, errors.New("notebook_path is required")
+	}
+	path, err := n.base.resolvePath(raw)
+	if err != nil {
+		return nil, err
+	}
+	if detectReadKind(path) != readKindNotebook {
+		return nil, fmt.Errorf("%s is not a .ipynb notebook", displayPath(path, n.base.root))
+	}
+	req, err := parseNotebookEditRequest(params)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat file: %w", err)
+	}
+	if err := n.base.checkWrite(ctx, path); err != nil {
+		return nil, err
+	}
+	data, err := readBoundedFile(path, readMaxNotebookBytes)
+	if err != nil {
+		return nil, err
+	}
+	nb, cells, err := decodeNotebook(data)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	if req.cellID != "" {
+		for i, rawCell := range cells {
+			if cell, ok := rawCell.(map[string]interface{}); ok && notebookCellID(cell, i) == req.cellID {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			return nil, fmt.Errorf("cell %q not found in %s", req.cellID, displayPath(path, n.base.root))
+		}
+	} else if req.mode != "insert" {
+		return nil, errors.New("cell_id is required")
+	}
+
+	var affected string
+	switch req.mode {
+	case "insert":
+		cell := newNotebookCell(req.cellType, req.source)
+		if notebookHasCellIDs(nb) {
+			cell["id"] = newNotebookCellID(cells)
+		}
+		pos := index + 1
+		cells = append(cells, nil)
+		copy(cells[pos+1:], cells[pos:])
+		cells[pos] = cell
+		affected = notebookCellID(cell, pos)
+	case "delete":
+		affected = req.cellID
+		cells = append(cells[:index], cells[index+1:]...)
+	default:
+		cell, ok := cells[index].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("cell %q is malformed", req.cellID)
+		}
+		replaceNotebookCell(cell, req.cellType, req.source)
+		affected = req.cellID
+	}
+	nb["cells"] = cells
+
+	encoded, err := encodeNotebook(nb)
+	if err != nil {
+		return nil, err
+	}
+	if n.base.maxBytes > 0 && int64(len(encoded)) > n.base.maxBytes {
+		return nil, fmt.Errorf("edited notebook exceeds %d bytes limit", n.base.maxBytes)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(path, encoded, info.Mode()); err != nil {
+		return nil, err
+	}
+	n.base.recordRead(ctx, path, encoded)
+
+	verb := map[string]string{"replace": "replaced", "insert": "inserted", "delete": "deleted"}[req.mode]
+	return &tool.ToolResult{
+		Success: true,
+		Output:  fmt.Sprintf("%s cell %s in %s", verb, affected, displayPath(path, n.base.root)),
+		Data: map[string]interface{}{
+			"path":      displayPath(path, n.base.root),
+			"edit_mode": req.mode,
+			"cell_id":   affected,
+			"cells":     len(cells),
+		},
+	}, nil
+}
+
+func parseNotebookEditRequest(params map[string]interface{}) (notebookEditRequest, error) {
+	req := notebookEditRequest{mode: "replace"}
+	str := func(key string) (string, bool, error) {
+		value, ok := params[key]
+		if !ok || value == nil {
+			return "", false, nil
+		}
+		s, err := coerceString(value)
+		if err != nil {
+			return "", false, fmt.Errorf("%s must be string: %w", key, err)
+		}
+		return s, true, nil
+	}
+	var err error
+	if req.cellID, _, err = str("cell_id"); err != nil {
+		return req, err
+	}
+	req.cellID = strings.TrimSpace(req.cellID)
+	if req.source, req.hasSource, err = str("new_source"); err != nil {
+		return req, err
+	}
+	if req.cellType, _, err = str("cell_type"); err != nil {
+		return req, err
+	}
+	mode, ok, err := str("edit_mode")
+	if err != nil {
+		return req, err
+	}
+	if ok && strings.TrimSpace(mode) != "" {
+		req.mode = strings.ToLower(strings.TrimSpace(mode))
+	}
+	switch req.mode {
+	case "replace", "insert", "delete":
+	default:
+		return req, fmt.Errorf("edit_mode must be replace, insert or delete, got %q", mode)
+	}
+	switch req.cellType {
+	case "", "code", "markdown":
+	default:
+		return req, fmt.Errorf("cell_type must be code or markdown, got %q", req.cellType)
+	}
+	if req.mode != "delete" && !req.hasSource {
+		return req, errors.New("new_source is required")
+	}
+	if req.mode == "insert" && req.cellType == "" {
+		return req, errors.New("cell_type is required for insert")
+	}
+	return req, nil
+}
+
+// notebookSource splits text into the nbformat line list, keeping newlines.
+func notebookSource(text string) []interface{} {
+	lines := strings.SplitAfter(text, "\n")
+	out := make([]interface{}, 0, len(lines))
+	for _, line := range lines {
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func newNotebookCell(cellType, source string) map[string]interface{} {
+	cell := map[string]interface{}{
+		"cell_type": cellType,
+		"metadata":  map[string]interface{}{},
+		"source":    notebookSource(source),
+	}
+	if cellType == "code" {
+		cell["execution_count"] = nil
+		cell["outputs"] = []interface{}{}
+	}
+	return cell
+}
+
+func replaceNotebookCell(cell map[string]interface{}, cellType, source string) {
+	current, _ := cell["cell_type"].(string)
+	if cellType == "" {
+		cellType = current
+	}
+	cell["source"] = notebookSource(source)
+	cell["cell_type"] = cellType
+	if cellType == "code" {
+		// Stale outputs would no longer match the new source.
+		cell["execution_count"] = nil
+		cell["outputs"] = []interface{}{}
+		return
+	}
+	delete(cell, "execution_count")
+	delete(cell, "outputs")
+}
+
+// notebookHasCellIDs reports whether the notebook format (>= 4.5) requires
+// cell ids.
+func notebookHasCellIDs(nb map[string]interface{}) bool {
+	version := func(key string) int64 {
+		num, _ := nb[key].(json.Number)
+		v, _ := num.Int64()
+		return v
+	}
+	major, minor := version("nbformat"), version("nbformat_minor")
+	return major > 4 || (major == 4 && minor >= 5)
+}
+
+func newNotebookCellID(cells []interface{}) string {
+	used := make(map[string]bool, len(cells))
+	for i, raw := range cells {
+		if cell, ok := raw.(map[string]interface{}); ok {
+			used[notebookCellID(cell, i)] = true
+		}
+	}
+	buf := make([]byte, 4)
+	for {
+		if _, err := rand.Read(buf); err != nil {
+			return fmt.Sprintf("cell-%d", len(cells))
+		}
+		if id := hex.EncodeToString(buf); !used[id] {
+			return id
+		}
+	}
+}
+
+// encodeNotebook mirrors nbformat's writer: sorted keys, one-space indent,
+// no HTML escaping and a trailing newline.
+func encodeNotebook(nb map[string]interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", " ")
+	if err := enc.Encode(nb); err != nil {
+		return nil, fmt.Errorf("encode notebook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
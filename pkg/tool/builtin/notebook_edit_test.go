@@ -0,0 +1,122 @@
+package toolbuiltin
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadNotebookCells(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read notebook: %v", err)
+	}
+	var nb struct {
+		Cells []map[string]interface{} `json:"cells"`
+	}
+	if err := json.Unmarshal(data, &nb); err != nil {
+		t.Fatalf("parse notebook: %v", err)
+	}
+	return nb.Cells
+}
+
+func TestNotebookEditTool(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := filepath.Join(dir, "nb.ipynb")
+	if err := os.WriteFile(path, []byte(testNotebook), 0o600); err != nil {
+		t.Fatalf("write notebook: %v", err)
+	}
+	tool := NewNotebookEditToolWithRoot(dir)
+	run := func(params map[string]interface{}) {
+		t.Helper()
+		params["notebook_path"] = "nb.ipynb"
+		if _, err := tool.Execute(context.Background(), params); err != nil {
+			t.Fatalf("notebook_edit %v: %v", params, err)
+		}
+	}
+
+	run(map[string]interface{}{"cell_id": "calc", "new_source": "x = 1\nprint(x)"})
+	cells := loadNotebookCells(t, path)
+	if got := cells[1]["source"]; len(got.([]interface{})) != 2 || got.([]interface{})[0] != "x = 1\n" {
+		t.Fatalf("unexpected source %#v", got)
+	}
+	if cells[1]["execution_count"] != nil || len(cells[1]["outputs"].([]interface{})) != 0 {
+		t.Fatalf("outputs should be cleared: %#v", cells[1])
+	}
+
+	run(map[string]interface{}{"edit_mode": "insert", "cell_type": "markdown", "new_source": "top"})
+	run(map[string]interface{}{"edit_mode": "insert", "cell_id": "intro", "cell_type": "code", "new_source": "pass"})
+	cells = loadNotebookCells(t, path)
+	if len(cells) != 4 || cells[0]["cell_type"] != "markdown" || cells[2]["cell_type"] != "code" {
+		t.Fatalf("unexpected cells after insert: %#v", cells)
+	}
+	if id, _ := cells[2]["id"].(string); len(id) != 8 {
+		t.Fatalf("expected generated id, got %#v", cells[2]["id"])
+	}
+	if _, ok := cells[2]["outputs"]; !ok {
+		t.Fatalf("inserted code cell should have outputs: %#v", cells[2])
+	}
+
+	run(map[string]interface{}{"cell_id": "calc", "cell_type": "markdown", "new_source": "now prose"})
+	run(map[string]interface{}{"edit_mode": "delete", "cell_id": "intro"})
+	cells = loadNotebookCells(t, path)
+	if len(cells) != 3 || cells[2]["cell_type"] != "markdown" {
+		t.Fatalf("unexpected cells after delete: %#v", cells)
+	}
+	if _, ok := cells[2]["outputs"]; ok {
+		t.Fatalf("markdown cell must not keep outputs: %#v", cells[2])
+	}
+
+	data, _ := os.ReadFile(path)
+	text := string(data)
+	if !strings.Contains(text, "\"language_info\": {\n   \"name\": \"python\"") || !strings.HasSuffix(text, "}\n") {
+		t.Fatalf("notebook formatting/metadata not preserved:\n%s", text)
+	}
+	if !strings.Contains(text, "\"nbformat_minor\": 5") {
+		t.Fatalf("nbformat version lost:\n%s", text)
+	}
+}
+
+func TestNotebookEditToolErrors(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	path := filepath.Join(dir, "nb.ipynb")
+	if err := os.WriteFile(path, []byte(testNotebook), 0o600); err != nil {
+		t.Fatalf("write notebook: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "plain.txt"), []byte("x"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	tool := NewNotebookEditToolWithRoot(dir)
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{"missing path", map[string]interface{}{"new_source": "x"}, "notebook_path is required"},
+		{"not notebook", map[string]interface{}{"notebook_path": "plain.txt", "cell_id": "a", "new_source": "x"}, "not a .ipynb"},
+		{"unknown cell", map[string]interface{}{"notebook_path": "nb.ipynb", "cell_id": "nope", "new_source": "x"}, "not found"},
+		{"missing cell id", map[string]interface{}{"notebook_path": "nb.ipynb", "new_source": "x"}, "cell_id is required"},
+		{"missing source", map[string]interface{}{"notebook_path": "nb.ipynb", "cell_id": "calc"}, "new_source is required"},
+		{"insert without type", map[string]interface{}{"notebook_path": "nb.ipynb", "edit_mode": "insert", "new_source": "x"}, "cell_type is required"},
+		{"bad mode", map[string]interface{}{"notebook_path": "nb.ipynb", "cell_id": "calc", "edit_mode": "append", "new_source": "x"}, "edit_mode must be"},
+		{"bad type", map[string]interface{}{"notebook_path": "nb.ipynb", "cell_id": "calc", "cell_type": "raw", "new_source": "x"}, "cell_type must be"},
+		{"escape", map[string]interface{}{"notebook_path": "../x.ipynb", "cell_id": "calc", "new_source": "x"}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+	if data, _ := os.ReadFile(path); string(data) != testNotebook {
+		t.Fatal("notebook changed despite failures")
+	}
+}
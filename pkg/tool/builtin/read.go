@@ -22,7 +22,10 @@ Usage:
 - By default, reads up to 2000 lines from the beginning of the file.
 - offset/limit can be used for large files.
 - Lines longer than 2000 characters are truncated.
-- Text files only (no images/PDFs/notebooks); binary files error.
+- Images (png, jpg, gif, webp) are returned as image content, downscaled when larger than 2000px.
+- PDFs return extracted text per page; use pages (e.g. "1-5") to select at most 20 pages.
+- Jupyter notebooks (.ipynb) render every cell with its outputs; edit them with notebook_edit.
+- Other binary files error.
 - Directories are rejected.`
 )
 
@@ -43,6 +46,10 @@ var readSchema = &tool.JSONSchema{
 			"type":        "number",
 			"description": "The number of lines to read. Only provide if the file is too large to read at once.",
 		},
+		"pages": map[string]interface{}{
+			"type":        "string",
+			"description": "Page range for PDF files, e.g. \"1-5\", \"3\" or \"1,4-6\". At most 20 pages per request.",
+		},
 	},
 	Required: []string{"file_path"},
 }
@@ -87,6 +94,13 @@ func (r *ReadTool) Metadata() tool.Metadata {
 	return tool.Metadata{IsReadOnly: true, IsConcurrencySafe: true}
 }
 
+// SetFileState attaches the tracker used to enforce read-before-write.
+func (r *ReadTool) SetFileState(files *FileStateTracker) {
+	if r != nil && r.base != nil {
+		r.base.files = files
+	}
+}
+
 func (r *ReadTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
 	if ctx == nil {
 		return nil, errors.New("context is nil")
@@ -98,6 +112,23 @@ func (r *ReadTool) Execute(ctx context.Context, params map[string]interface{}) (
 	if err != nil {
 		return nil, err
 	}
+	if kind := detectReadKind(path); kind != readKindText {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		switch kind {
+		case readKindImage:
+			return r.readImage(path)
+		case readKindPDF:
+			pages, err := parsePagesParam(params)
+			if err != nil {
+				return nil, err
+			}
+			return r.readPDF(path, pages)
+		default:
+			return r.readNotebook(ctx, path)
+		}
+	}
 	offset, err := r.parseOffset(params)
 	if err != nil {
 		return nil, err
@@ -114,6 +145,7 @@ func (r *ReadTool) Execute(ctx context.Context, params map[string]interface{}) (
 	if err != nil {
 		return nil, err
 	}
+	r.base.recordRead(ctx, path, []byte(content))
 
 	lines := splitFileLines(content)
 	totalLines := len(lines)
@@ -162,6 +194,21 @@ func (r *ReadTool) resolveFilePath(params map[string]interface{}) (string, error
 	return r.base.resolvePath(raw)
 }
 
+func parsePagesParam(params map[string]interface{}) (string, error) {
+	raw, ok := params["pages"]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	if value, err := coerceString(raw); err == nil {
+		return value, nil
+	}
+	page, err := coerceInt(raw)
+	if err != nil {
+		return "", fmt.Errorf("pages must be a string: %w", err)
+	}
+	return strconv.Itoa(page), nil
+}
+
 func (r *ReadTool) parseOffset(params map[string]interface{}) (int, error) {
 	value, err := parseLineNumber(params, "offset")
 	if err != nil {
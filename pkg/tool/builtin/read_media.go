@@ -0,0 +1,386 @@
+package toolbuiltin
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const (
+	readMaxImageFileBytes    = 20 << 20
+	readMaxImageDimension    = 2000
+	readMaxImageEncodedBytes = 3_750_000 // ~5 MB once base64 encoded
+	// readMaxImagePixels bounds decoding: a small compressed file can claim
+	// dimensions whose decoded pixels would not fit in memory.
+	readMaxImagePixels       = 40_000_000
+	readMaxNotebookBytes     = 10 << 20
+	readMaxNotebookOutputLen = 10000
+)
+
+type readKind int
+
+const (
+	readKindText readKind = iota
+	readKindImage
+	readKindPDF
+	readKindNotebook
+)
+
+func detectReadKind(path string) readKind {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp":
+		return readKindImage
+	case ".pdf":
+		return readKindPDF
+	case ".ipynb":
+		return readKindNotebook
+	default:
+		return readKindText
+	}
+}
+
+func readBoundedFile(path string, limit int64) ([]byte, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("stat file: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+	if limit > 0 && info.Size() > limit {
+		return nil, fmt.Errorf("file exceeds %d bytes limit", limit)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read file: %w", err)
+	}
+	return data, nil
+}
+
+func (r *ReadTool) readImage(path string) (*tool.ToolResult, error) {
+	data, err := readBoundedFile(path, readMaxImageFileBytes)
+	if err != nil {
+		return nil, err
+	}
+	mediaType := http.DetectContentType(data)
+	switch mediaType {
+	case "image/png", "image/jpeg", "image/gif", "image/webp":
+	default:
+		return nil, fmt.Errorf("%s is not a supported image (detected %s)", displayPath(path, r.base.root), mediaType)
+	}
+
+	display := displayPath(path, r.base.root)
+	meta := map[string]interface{}{
+		"path":       display,
+		"media_type": mediaType,
+	}
+	encoded := data
+	summary := ""
+	if mediaType == "image/webp" {
+		// The standard library cannot decode WebP, so it is passed through.
+		if len(data) > readMaxImageEncodedBytes {
+			return nil, fmt.Errorf("webp image %s exceeds %d bytes and cannot be resized", display, readMaxImageEncodedBytes)
+		}
+		summary = fmt.Sprintf("Image %s (%s, %d bytes)", display, mediaType, len(data))
+	} else {
+		cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("decode image: %w", err)
+		}
+		if int64(cfg.Width)*int64(cfg.Height) > readMaxImagePixels {
+			return nil, fmt.Errorf("image %s is %dx%d, more than %d pixels", display, cfg.Width, cfg.Height, readMaxImagePixels)
+		}
+		meta["original_width"], meta["original_height"] = cfg.Width, cfg.Height
+		width, height := cfg.Width, cfg.Height
+		if width > readMaxImageDimension || height > readMaxImageDimension || len(data) > readMaxImageEncodedBytes {
+			encoded, mediaType, width, height, err = downscaleImage(data, mediaType)
+			if err != nil {
+				return nil, err
+			}
+			meta["media_type"] = mediaType
+			meta["resized"] = true
+		}
+		meta["width"], meta["height"] = width, height
+		summary = fmt.Sprintf("Image %s (%dx%d, %s, %d bytes)", display, width, height, mediaType, len(encoded))
+		if width != cfg.Width || height != cfg.Height {
+			summary += fmt.Sprintf(", resized from %dx%d", cfg.Width, cfg.Height)
+		}
+	}
+
+	return &tool.ToolResult{
+		Success: true,
+		Output:  summary,
+		ContentBlocks: []model.ContentBlock{{
+			Type:      model.ContentBlockImage,
+			MediaType: mediaType,
+			Data:      base64.StdEncoding.EncodeToString(encoded),
+		}},
+		Data: meta,
+	}, nil
+}
+
+// downscaleImage fits the image within readMaxImageDimension and the encoded
+// size budget, shrinking further until the budget is met.
+func downscaleImage(data []byte, mediaType string) ([]byte, string, int, int, error) {
+	var (
+		src image.Image
+		err error
+	)
+	switch mediaType {
+	case "image/png":
+		src, err = png.Decode(bytes.NewReader(data))
+	case "image/gif":
+		src, err = gif.Decode(bytes.NewReader(data))
+	default:
+		src, err = jpeg.Decode(bytes.NewReader(data))
+	}
+	if err != nil {
+		return nil, "", 0, 0, fmt.Errorf("decode image: %w", err)
+	}
+	bounds := src.Bounds()
+	scale := 1.0
+	if longest := max(bounds.Dx(), bounds.Dy()); longest > readMaxImageDimension {
+		scale = float64(readMaxImageDimension) / float64(longest)
+	}
+	for attempt := 0; attempt < 6; attempt++ {
+		width := max(1, int(float64(bounds.Dx())*scale))
+		height := max(1, int(float64(bounds.Dy())*scale))
+		resized := resizeImage(src, width, height)
+		var buf bytes.Buffer
+		outType := "image/jpeg"
+		if mediaType != "image/jpeg" && attempt == 0 {
+			outType = "image/png"
+			err = png.Encode(&buf, resized)
+		} else {
+			err = jpeg.Encode(&buf, resized, &jpeg.Options{Quality: 85})
+		}
+		if err != nil {
+			return nil, "", 0, 0, fmt.Errorf("encode image: %w", err)
+		}
+		if buf.Len() <= readMaxImageEncodedBytes {
+			return buf.Bytes(), outType, width, height, nil
+		}
+		if outType == "image/jpeg" {
+			scale *= 0.75
+		}
+	}
+	return nil, "", 0, 0, errors.New("image is too large to downscale within the size limit")
+}
+
+// resizeImage downsamples src with an area average, which is adequate for
+// shrinking screenshots and photos without an external imaging library.
+func resizeImage(src image.Image, width, height int) *image.NRGBA {
+	bounds := src.Bounds()
+	in := image.NewNRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(in, in.Bounds(), src, bounds.Min, draw.Src)
+	if width == bounds.Dx() && height == bounds.Dy() {
+		return in
+	}
+	out := image.NewNRGBA(image.Rect(0, 0, width, height))
+	srcW, srcH := bounds.Dx(), bounds.Dy()
+	for y := 0; y < height; y++ {
+		y0 := y * srcH / height
+		y1 := max(y0+1, (y+1)*srcH/height)
+		for x := 0; x < width; x++ {
+			x0 := x * srcW / width
+			x1 := max(x0+1, (x+1)*srcW/width)
+			var r, g, b, a, n int
+			for sy := y0; sy < y1; sy++ {
+				off := sy*in.Stride + x0*4
+				for sx := x0; sx < x1; sx++ {
+					r += int(in.Pix[off])
+					g += int(in.Pix[off+1])
+					b += int(in.Pix[off+2])
+					a += int(in.Pix[off+3])
+					off += 4
+					n++
+				}
+			}
+			o := y*out.Stride + x*4
+			out.Pix[o] = uint8(r / n)
+			out.Pix[o+1] = uint8(g / n)
+			out.Pix[o+2] = uint8(b / n)
+			out.Pix[o+3] = uint8(a / n)
+		}
+	}
+	return out
+}
+
+// notebookCellID returns the nbformat 4.5 cell id, falling back to a
+// positional "cell-N" identifier for older notebooks.
+func notebookCellID(cell map[string]interface{}, index int) string {
+	if id, ok := cell["id"].(string); ok && strings.TrimSpace(id) != "" {
+		return id
+	}
+	return fmt.Sprintf("cell-%d", index)
+}
+
+// notebookText joins nbformat multiline strings, which may be a string or a
+// list of strings.
+func notebookText(raw interface{}) string {
+	switch v := raw.(type) {
+	case string:
+		return v
+	case []interface{}:
+		var b strings.Builder
+		for _, part := range v {
+			if s, ok := part.(string); ok {
+				b.WriteString(s)
+			}
+		}
+		return b.String()
+	default:
+		return ""
+	}
+}
+
+func decodeNotebook(data []byte) (map[string]interface{}, []interface{}, error) {
+	var nb map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keeps numeric fields byte-identical when re-encoded
+	if err := dec.Decode(&nb); err != nil {
+		return nil, nil, fmt.Errorf("parse notebook: %w", err)
+	}
+	cells, ok := nb["cells"].([]interface{})
+	if !ok {
+		return nil, nil, errors.New("notebook has no cells array")
+	}
+	return nb, cells, nil
+}
+
+var ansiEscape = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+
+func (r *ReadTool) readNotebook(ctx context.Context, path string) (*tool.ToolResult, error) {
+	data, err := readBoundedFile(path, readMaxNotebookBytes)
+	if err != nil {
+		return nil, err
+	}
+	nb, cells, err := decodeNotebook(data)
+	if err != nil {
+		return nil, err
+	}
+	r.base.recordRead(ctx, path, data)
+	language := ""
+	if meta, ok := nb["metadata"].(map[string]interface{}); ok {
+		if info, ok := meta["language_info"].(map[string]interface{}); ok {
+			language, _ = info["name"].(string)
+		}
+	}
+
+	display := displayPath(path, r.base.root)
+	var b strings.Builder
+	fmt.Fprintf(&b, "Notebook %s (%d cells", display, len(cells))
+	if language != "" {
+		fmt.Fprintf(&b, ", language: %s", language)
+	}
+	b.WriteString(")\n")
+
+	var blocks []model.ContentBlock
+	for i, raw := range cells {
+		cell, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		id := notebookCellID(cell, i)
+		cellType, _ := cell["cell_type"].(string)
+		fmt.Fprintf(&b, "\n<cell id=\"%s\" index=\"%d\" type=\"%s\"", escapeXML(id), i, escapeXML(cellType))
+		if count, ok := cell["execution_count"].(json.Number); ok {
+			fmt.Fprintf(&b, " execution_count=\"%s\"", count)
+		}
+		b.WriteString(">\n")
+		b.WriteString(strings.TrimRight(notebookText(cell["source"]), "\n"))
+		b.WriteString("\n</cell>\n")
+
+		outputs, _ := cell["outputs"].([]interface{})
+		for _, rawOut := range outputs {
+			out, ok := rawOut.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			text, images := renderNotebookOutput(out)
+			blocks = append(blocks, images...)
+			if text == "" && len(images) == 0 {
+				continue
+			}
+			if len(text) > readMaxNotebookOutputLen {
+				text = text[:readMaxNotebookOutputLen] + "\n...(output truncated)"
+			}
+			fmt.Fprintf(&b, "<output cell_id=\"%s\">\n", escapeXML(id))
+			if text != "" {
+				b.WriteString(text)
+				b.WriteString("\n")
+			}
+			for range images {
+				b.WriteString("[image attached]\n")
+			}
+			b.WriteString("</output>\n")
+		}
+	}
+
+	return &tool.ToolResult{
+		Success:       true,
+		Output:        strings.TrimRight(b.String(), "\n"),
+		ContentBlocks: blocks,
+		Data: map[string]interface{}{
+			"path":     display,
+			"cells":    len(cells),
+			"language": language,
+			"images":   len(blocks),
+		},
+	}, nil
+}
+
+func renderNotebookOutput(out map[string]interface{}) (string, []model.ContentBlock) {
+	switch out["output_type"] {
+	case "stream":
+		return strings.TrimRight(notebookText(out["text"]), "\n"), nil
+	case "error":
+		ename, _ := out["ename"].(string)
+		evalue, _ := out["evalue"].(string)
+		text := ename + ": " + evalue
+		// Traceback entries are separate lines without trailing newlines.
+		if lines, ok := out["traceback"].([]interface{}); ok {
+			for _, line := range lines {
+				if s, ok := line.(string); ok {
+					text += "\n" + s
+				}
+			}
+		}
+		return strings.TrimRight(ansiEscape.ReplaceAllString(text, ""), "\n"), nil
+	case "execute_result", "display_data":
+		bundle, _ := out["data"].(map[string]interface{})
+		var blocks []model.ContentBlock
+		for _, mediaType := range []string{"image/png", "image/jpeg"} {
+			if raw := strings.TrimSpace(notebookText(bundle[mediaType])); raw != "" {
+				blocks = append(blocks, model.ContentBlock{
+					Type:      model.ContentBlockImage,
+					MediaType: mediaType,
+					Data:      strings.ReplaceAll(raw, "\n", ""),
+				})
+			}
+		}
+		text := notebookText(bundle["text/plain"])
+		if text == "" && len(blocks) == 0 {
+			text = notebookText(bundle["text/markdown"])
+		}
+		return strings.TrimRight(text, "\n"), blocks
+	default:
+		return "", nil
+	}
+}
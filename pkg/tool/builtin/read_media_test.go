@@ -0,0 +1,270 @@
+package toolbuiltin
+
+import (
+	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+)
+
+func TestReadToolImageDownscales(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	img := image.NewNRGBA(image.Rect(0, 0, 3000, 1000))
+	for x := 0; x < 3000; x++ {
+		img.Set(x, 0, color.NRGBA{R: uint8(x), A: 255})
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	path := filepath.Join(dir, "wide.png")
+	if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+		t.Fatalf("write png: %v", err)
+	}
+
+	res, err := NewReadToolWithRoot(dir).Execute(context.Background(), map[string]interface{}{"file_path": "wide.png"})
+	if err != nil {
+		t.Fatalf("read image: %v", err)
+	}
+	if len(res.ContentBlocks) != 1 || res.ContentBlocks[0].Type != model.ContentBlockImage {
+		t.Fatalf("expected one image block, got %#v", res.ContentBlocks)
+	}
+	raw, err := base64.StdEncoding.DecodeString(res.ContentBlocks[0].Data)
+	if err != nil {
+		t.Fatalf("decode base64: %v", err)
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("decode image: %v", err)
+	}
+	if cfg.Width != 2000 || cfg.Height != 666 {
+		t.Fatalf("unexpected resized dimensions %dx%d", cfg.Width, cfg.Height)
+	}
+	data := res.Data.(map[string]interface{})
+	if data["resized"] != true || data["original_width"] != 3000 {
+		t.Fatalf("unexpected metadata %#v", data)
+	}
+}
+
+func TestReadToolImageRejectsNonImage(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	if err := os.WriteFile(filepath.Join(dir, "fake.png"), []byte("not really an image"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := NewReadToolWithRoot(dir).Execute(context.Background(), map[string]interface{}{"file_path": "fake.png"}); err == nil {
+		t.Fatal("expected error for invalid image")
+	}
+}
+
+func TestReadToolImageRejectsHugeDimensions(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1, 1))); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	// Rewrite the IHDR chunk to claim 100000x100000 pixels.
+	data := buf.Bytes()
+	binary.BigEndian.PutUint32(data[16:20], 100000)
+	binary.BigEndian.PutUint32(data[20:24], 100000)
+	binary.BigEndian.PutUint32(data[29:33], crc32.ChecksumIEEE(data[12:29]))
+	if err := os.WriteFile(filepath.Join(dir, "bomb.png"), data, 0o600); err != nil {
+		t.Fatalf("write png: %v", err)
+	}
+	_, err := NewReadToolWithRoot(dir).Execute(context.Background(), map[string]interface{}{"file_path": "bomb.png"})
+	if err == nil || !strings.Contains(err.Error(), "pixels") {
+		t.Fatalf("expected pixel budget error, got %v", err)
+	}
+}
+
+// buildTestPDF assembles a minimal PDF whose pages show the given strings.
+// The first page uses a compressed content stream and a ToUnicode CMap.
+func buildTestPDF(t *testing.T, pages []string) []byte {
+	t.Helper()
+	var objects []string
+	add := func(body string) int {
+		objects = append(objects, body)
+		return len(objects)
+	}
+	cmap := "/CIDInit /ProcSet findresource begin\nbegincmap\n1 begincodespacerange <00> <FF> endcodespacerange\n" +
+		"1 beginbfrange <41> <5A> <0061> endbfrange\n1 beginbfchar <20> <0020> endbfchar\nendcmap\n"
+	cmapObj := add(fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(cmap), cmap))
+	plainFont := add("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>")
+	mappedFont := add(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /ToUnicode %d 0 R >>", cmapObj))
+	pagesObj := len(objects) + 1 + 2*len(pages)
+	var kids []string
+	for i, text := range pages {
+		var content string
+		if i == 0 {
+			// Uppercase codes map to lowercase through the CMap.
+			var z bytes.Buffer
+			w := zlib.NewWriter(&z)
+			fmt.Fprintf(w, "BT /F2 12 Tf 72 720 Td (%s) Tj 0 -14 Td [(SEC) -300 (OND)] TJ ET", strings.ToUpper(text))
+			w.Close()
+			content = fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", z.Len(), z.String())
+		} else {
+			stream := fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+			content = fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream)
+		}
+		contentObj := add(content)
+		page := add(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /Contents %d 0 R >>", pagesObj, contentObj))
+		kids = append(kids, fmt.Sprintf("%d 0 R", page))
+	}
+	add(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /Resources << /Font << /F1 %d 0 R /F2 %d 0 R >> >> >>",
+		strings.Join(kids, " "), len(pages), plainFont, mappedFont))
+	catalog := add(fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pagesObj))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	for i, body := range objects {
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, body)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root %d 0 R >>\n%%%%EOF\n", len(objects)+1, catalog)
+	return out.Bytes()
+}
+
+func TestReadToolPDF(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	pdf := buildTestPDF(t, []string{"hello world", "Page (two) text", "third"})
+	if err := os.WriteFile(filepath.Join(dir, "doc.pdf"), pdf, 0o600); err != nil {
+		t.Fatalf("write pdf: %v", err)
+	}
+	tool := NewReadToolWithRoot(dir)
+
+	res, err := tool.Execute(context.Background(), map[string]interface{}{"file_path": "doc.pdf"})
+	if err != nil {
+		t.Fatalf("read pdf: %v", err)
+	}
+	for _, want := range []string{"(3 pages)", "--- Page 1 ---\nhello world\nsec ond", "--- Page 2 ---\nPage (two) text", "--- Page 3 ---\nthird"} {
+		if !strings.Contains(res.Output, want) {
+			t.Fatalf("output missing %q:\n%s", want, res.Output)
+		}
+	}
+
+	res, err = tool.Execute(context.Background(), map[string]interface{}{"file_path": "doc.pdf", "pages": "2-3"})
+	if err != nil {
+		t.Fatalf("read pdf pages: %v", err)
+	}
+	if strings.Contains(res.Output, "Page 1 ---") || !strings.Contains(res.Output, "--- Page 3 ---") {
+		t.Fatalf("unexpected page selection:\n%s", res.Output)
+	}
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"file_path": "doc.pdf", "pages": "5"}); err == nil {
+		t.Fatal("expected out of range error")
+	}
+}
+
+func TestPDFDecodeStreamLimits(t *testing.T) {
+	var z bytes.Buffer
+	w := zlib.NewWriter(&z)
+	if _, err := w.Write(make([]byte, readMaxPDFStreamBytes+1)); err != nil {
+		t.Fatalf("compress: %v", err)
+	}
+	w.Close()
+	bomb := &pdfObject{value: pdfDict{"Filter": pdfName("FlateDecode")}, stream: z.Bytes()}
+	doc := &pdfDocument{objects: map[int]*pdfObject{}}
+	if _, err := doc.decodeStream(bomb); err == nil || doc.limitErr == nil {
+		t.Fatalf("expected per-stream limit error, got %v", err)
+	}
+
+	z.Reset()
+	w = zlib.NewWriter(&z)
+	_, _ = w.Write([]byte("BT (hi) Tj ET"))
+	w.Close()
+	small := &pdfObject{value: pdfDict{"Filter": pdfName("FlateDecode")}, stream: z.Bytes()}
+	doc = &pdfDocument{objects: map[int]*pdfObject{}}
+	if data, err := doc.decodeStream(small); err != nil || string(data) != "BT (hi) Tj ET" {
+		t.Fatalf("decode small stream: %q, %v", data, err)
+	}
+	doc.inflated = readMaxPDFInflatedBytes - 4
+	if _, err := doc.decodeStream(small); err == nil {
+		t.Fatal("expected total limit error")
+	}
+}
+
+func TestParsePageSelection(t *testing.T) {
+	got, err := parsePageSelection("3, 1-2,2", 10)
+	if err != nil || fmt.Sprint(got) != "[1 2 3]" {
+		t.Fatalf("unexpected pages %v err=%v", got, err)
+	}
+	got, err = parsePageSelection("8-30", 10)
+	if err != nil || fmt.Sprint(got) != "[8 9 10]" {
+		t.Fatalf("unexpected clamped pages %v err=%v", got, err)
+	}
+	for _, bad := range []string{"0", "3-1", "x", "", "11"} {
+		if _, err := parsePageSelection(bad, 10); err == nil {
+			t.Fatalf("expected error for %q", bad)
+		}
+	}
+}
+
+const testNotebook = `{
+ "cells": [
+  {
+   "cell_type": "markdown",
+   "id": "intro",
+   "metadata": {},
+   "source": ["# Title\n", "Some text"]
+  },
+  {
+   "cell_type": "code",
+   "execution_count": 3,
+   "id": "calc",
+   "metadata": {},
+   "outputs": [
+    {"name": "stdout", "output_type": "stream", "text": ["42\n"]},
+    {"data": {"image/png": "iVBORw0KGgo=", "text/plain": ["<Figure>"]}, "metadata": {}, "output_type": "display_data"},
+    {"ename": "ValueError", "evalue": "bad", "output_type": "error", "traceback": ["\u001b[0;31mValueError\u001b[0m: bad"]}
+   ],
+   "source": "print(6 * 7)"
+  }
+ ],
+ "metadata": {"language_info": {"name": "python"}},
+ "nbformat": 4,
+ "nbformat_minor": 5
+}
+`
+
+func TestReadToolNotebook(t *testing.T) {
+	skipIfWindows(t)
+	dir := cleanTempDir(t)
+	if err := os.WriteFile(filepath.Join(dir, "nb.ipynb"), []byte(testNotebook), 0o600); err != nil {
+		t.Fatalf("write notebook: %v", err)
+	}
+	res, err := NewReadToolWithRoot(dir).Execute(context.Background(), map[string]interface{}{"file_path": "nb.ipynb"})
+	if err != nil {
+		t.Fatalf("read notebook: %v", err)
+	}
+	for _, want := range []string{
+		"(2 cells, language: python)",
+		"<cell id=\"intro\" index=\"0\" type=\"markdown\">\n# Title\nSome text\n</cell>",
+		"<cell id=\"calc\" index=\"1\" type=\"code\" execution_count=\"3\">\nprint(6 * 7)\n</cell>",
+		"42",
+		"[image attached]",
+		"ValueError: bad",
+	} {
+		if !strings.Contains(res.Output, want) {
+			t.Fatalf("output missing %q:\n%s", want, res.Output)
+		}
+	}
+	if strings.Contains(res.Output, "\x1b[") {
+		t.Fatalf("ANSI escapes should be stripped:\n%s", res.Output)
+	}
+	if len(res.ContentBlocks) != 1 || res.ContentBlocks[0].MediaType != "image/png" {
+		t.Fatalf("expected png output block, got %#v", res.ContentBlocks)
+	}
+}
@@ -0,0 +1,937 @@
+package toolbuiltin
+
+import (
+	"bytes"
+	"compress/zlib"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+// This file implements a deliberately small PDF text extractor: it resolves
+// the page tree, inflates FlateDecode content streams (including compressed
+// object streams) and decodes text-showing operators through ToUnicode CMaps
+// when fonts provide them. Layout is approximated from text positioning
+// operators; encrypted or image-only PDFs yield no text.
+
+const (
+	readMaxPDFBytes = 32 << 20
+	readMaxPDFPages = 20
+	// Decompressed stream budgets; a small FlateDecode stream can inflate to
+	// gigabytes.
+	readMaxPDFStreamBytes   = 16 << 20
+	readMaxPDFInflatedBytes = 128 << 20
+)
+
+func (r *ReadTool) readPDF(path, pageSpec string) (*tool.ToolResult, error) {
+	data, err := readBoundedFile(path, readMaxPDFBytes)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := parsePDF(data)
+	if err == nil {
+		err = doc.limitErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse pdf: %w", err)
+	}
+	pages := doc.pages()
+	total := len(pages)
+	if total == 0 {
+		return nil, errors.New("pdf has no pages")
+	}
+
+	var selected []int
+	note := ""
+	if strings.TrimSpace(pageSpec) == "" {
+		for page := 1; page <= min(total, readMaxPDFPages); page++ {
+			selected = append(selected, page)
+		}
+		if total > readMaxPDFPages {
+			note = fmt.Sprintf("\n[showing pages 1-%d of %d; use pages to read more]", readMaxPDFPages, total)
+		}
+	} else {
+		if selected, err = parsePageSelection(pageSpec, total); err != nil {
+			return nil, err
+		}
+		if len(selected) > readMaxPDFPages {
+			return nil, fmt.Errorf("pages selects %d pages; at most %d pages can be read per request", len(selected), readMaxPDFPages)
+		}
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "PDF %s (%d pages)\n", displayPath(path, r.base.root), total)
+	for _, num := range selected {
+		page := pages[num-1]
+		text := extractPDFPageText(doc.pageContent(page), doc.pageFonts(page))
+		if text == "" {
+			text = "[no extractable text]"
+		}
+		fmt.Fprintf(&b, "\n--- Page %d ---\n%s\n", num, text)
+	}
+	if doc.limitErr != nil {
+		return nil, fmt.Errorf("parse pdf: %w", doc.limitErr)
+	}
+	b.WriteString(note)
+
+	return &tool.ToolResult{
+		Success: true,
+		Output:  strings.TrimRight(b.String(), "\n"),
+		Data: map[string]interface{}{
+			"path":        displayPath(path, r.base.root),
+			"total_pages": total,
+			"pages":       selected,
+		},
+	}, nil
+}
+
+type pdfRef struct{ num, gen int }
+
+type pdfName string
+
+type pdfDict map[string]interface{}
+
+type pdfObject struct {
+	value  interface{}
+	stream []byte // raw (still encoded) stream data
+}
+
+type pdfDocument struct {
+	objects map[int]*pdfObject
+	trailer pdfDict
+	cmaps   map[int]map[string]string
+	// inflated counts decompressed stream bytes against
+	// readMaxPDFInflatedBytes; limitErr records the first budget overrun.
+	inflated int64
+	limitErr error
+}
+
+var pdfObjHeader = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+
+func parsePDF(data []byte) (*pdfDocument, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\r\n "), []byte("%PDF-")) {
+		return nil, errors.New("not a PDF file")
+	}
+	doc := &pdfDocument{objects: map[int]*pdfObject{}, trailer: pdfDict{}, cmaps: map[int]map[string]string{}}
+	for _, loc := range pdfObjHeader.FindAllSubmatchIndex(data, -1) {
+		num, _ := strconv.Atoi(string(data[loc[2]:loc[3]]))
+		p := &pdfParser{data: data, pos: loc[1]}
+		value, err := p.parseValue()
+		if err != nil {
+			continue
+		}
+		obj := &pdfObject{value: value}
+		if dict, ok := value.(pdfDict); ok {
+			p.skipSpace()
+			if bytes.HasPrefix(data[p.pos:], []byte("stream")) {
+				obj.stream = readPDFStream(data, p.pos+len("stream"), dict, doc)
+			}
+		}
+		// Later definitions (incremental updates) win.
+		doc.objects[num] = obj
+	}
+	for _, obj := range doc.objects {
+		if dict, ok := obj.value.(pdfDict); ok && dict["Type"] == pdfName("XRef") {
+			mergePDFTrailer(doc.trailer, dict)
+		}
+	}
+	for _, loc := range regexp.MustCompile(`trailer\s*<<`).FindAllIndex(data, -1) {
+		p := &pdfParser{data: data, pos: loc[1] - 2}
+		if value, err := p.parseValue(); err == nil {
+			if dict, ok := value.(pdfDict); ok {
+				mergePDFTrailer(doc.trailer, dict)
+			}
+		}
+	}
+	if _, encrypted := doc.trailer["Encrypt"]; encrypted {
+		return nil, errors.New("encrypted PDFs are not supported")
+	}
+	doc.expandObjectStreams()
+	return doc, nil
+}
+
+func mergePDFTrailer(dst, src pdfDict) {
+	for k, v := range src {
+		dst[k] = v
+	}
+}
+
+func readPDFStream(data []byte, start int, dict pdfDict, doc *pdfDocument) []byte {
+	if start < len(data) && data[start] == '\r' {
+		start++
+	}
+	if start < len(data) && data[start] == '\n' {
+		start++
+	}
+	if length, ok := dict["Length"].(int); ok && length >= 0 && start+length <= len(data) {
+		end := start + length
+		if bytes.HasPrefix(bytes.TrimLeft(data[end:], "\r\n "), []byte("endstream")) {
+			return data[start:end]
+		}
+	}
+	end := bytes.Index(data[start:], []byte("endstream"))
+	if end < 0 {
+		return nil
+	}
+	return bytes.TrimRight(data[start:start+end], "\r\n")
+}
+
+func (d *pdfDocument) expandObjectStreams() {
+	for _, obj := range d.objects {
+		dict, ok := obj.value.(pdfDict)
+		if !ok || dict["Type"] != pdfName("ObjStm") {
+			continue
+		}
+		raw, err := d.decodeStream(obj)
+		if err != nil {
+			continue
+		}
+		n, _ := d.resolve(dict["N"]).(int)
+		first, _ := d.resolve(dict["First"]).(int)
+		if first <= 0 || first > len(raw) {
+			continue
+		}
+		header := strings.Fields(string(raw[:first]))
+		for i := 0; i+1 < len(header) && i/2 < n; i += 2 {
+			num, err1 := strconv.Atoi(header[i])
+			off, err2 := strconv.Atoi(header[i+1])
+			if err1 != nil || err2 != nil || first+off >= len(raw) {
+				continue
+			}
+			if _, exists := d.objects[num]; exists {
+				continue
+			}
+			p := &pdfParser{data: raw, pos: first + off}
+			if value, err := p.parseValue(); err == nil {
+				d.objects[num] = &pdfObject{value: value}
+			}
+		}
+	}
+}
+
+func (d *pdfDocument) resolve(v interface{}) interface{} {
+	for i := 0; i < 32; i++ {
+		ref, ok := v.(pdfRef)
+		if !ok {
+			return v
+		}
+		obj := d.objects[ref.num]
+		if obj == nil {
+			return nil
+		}
+		v = obj.value
+	}
+	return nil
+}
+
+func (d *pdfDocument) dict(v interface{}) pdfDict {
+	dict, _ := d.resolve(v).(pdfDict)
+	return dict
+}
+
+func (d *pdfDocument) decodeStream(obj *pdfObject) ([]byte, error) {
+	if obj == nil || obj.stream == nil {
+		return nil, errors.New("missing stream")
+	}
+	dict, _ := obj.value.(pdfDict)
+	var filters []interface{}
+	switch f := d.resolve(dict["Filter"]).(type) {
+	case pdfName:
+		filters = []interface{}{f}
+	case []interface{}:
+		filters = f
+	}
+	data := obj.stream
+	for _, f := range filters {
+		switch d.resolve(f) {
+		case pdfName("FlateDecode"), pdfName("Fl"):
+			r, err := zlib.NewReader(bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			limit := min(int64(readMaxPDFStreamBytes), readMaxPDFInflatedBytes-d.inflated)
+			out, err := io.ReadAll(io.LimitReader(r, limit+1))
+			if int64(len(out)) > limit {
+				if d.limitErr == nil {
+					d.limitErr = fmt.Errorf("decompressed streams exceed %d bytes per stream or %d bytes in total", readMaxPDFStreamBytes, readMaxPDFInflatedBytes)
+				}
+				return nil, d.limitErr
+			}
+			if err != nil && len(out) == 0 {
+				return nil, err
+			}
+			d.inflated += int64(len(out))
+			data = out
+		default:
+			return nil, fmt.Errorf("unsupported stream filter %v", f)
+		}
+	}
+	return data, nil
+}
+
+// pages walks the page tree from the catalog, falling back to every Page
+// object in object-number order for damaged files.
+func (d *pdfDocument) pages() []pdfDict {
+	var pages []pdfDict
+	seen := map[int]bool{}
+	var walk func(v interface{}, depth int)
+	walk = func(v interface{}, depth int) {
+		if depth > 64 {
+			return
+		}
+		if ref, ok := v.(pdfRef); ok {
+			if seen[ref.num] {
+				return
+			}
+			seen[ref.num] = true
+		}
+		node := d.dict(v)
+		if node == nil {
+			return
+		}
+		if kids, ok := d.resolve(node["Kids"]).([]interface{}); ok {
+			for _, kid := range kids {
+				walk(kid, depth+1)
+			}
+			return
+		}
+		if node["Type"] == pdfName("Page") || node["Contents"] != nil {
+			pages = append(pages, node)
+		}
+	}
+	if root := d.dict(d.trailer["Root"]); root != nil {
+		walk(root["Pages"], 0)
+	}
+	if len(pages) > 0 {
+		return pages
+	}
+	nums := make([]int, 0, len(d.objects))
+	for num := range d.objects {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+	for _, num := range nums {
+		if dict, ok := d.objects[num].value.(pdfDict); ok && dict["Type"] == pdfName("Page") {
+			pages = append(pages, dict)
+		}
+	}
+	return pages
+}
+
+func (d *pdfDocument) pageContent(page pdfDict) []byte {
+	var refs []interface{}
+	switch c := page["Contents"].(type) {
+	case []interface{}:
+		refs = c
+	case pdfRef:
+		if arr, ok := d.resolve(c).([]interface{}); ok {
+			refs = arr
+		} else {
+			refs = []interface{}{c}
+		}
+	}
+	var buf bytes.Buffer
+	for _, ref := range refs {
+		r, ok := ref.(pdfRef)
+		if !ok {
+			continue
+		}
+		data, err := d.decodeStream(d.objects[r.num])
+		if err != nil {
+			continue
+		}
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// pageFonts maps resource font names to ToUnicode tables, honouring
+// resources inherited from parent page-tree nodes.
+func (d *pdfDocument) pageFonts(page pdfDict) map[string]map[string]string {
+	fonts := map[string]map[string]string{}
+	node := page
+	for depth := 0; node != nil && depth < 64; depth++ {
+		if res := d.dict(node["Resources"]); res != nil {
+			for name, ref := range d.dict(res["Font"]) {
+				if _, ok := fonts[name]; ok {
+					continue
+				}
+				fonts[name] = d.toUnicode(ref)
+			}
+			break
+		}
+		node = d.dict(node["Parent"])
+	}
+	return fonts
+}
+
+func (d *pdfDocument) toUnicode(fontRef interface{}) map[string]string {
+	font := d.dict(fontRef)
+	if font == nil {
+		return nil
+	}
+	ref, ok := font["ToUnicode"].(pdfRef)
+	if !ok {
+		return nil
+	}
+	if cmap, ok := d.cmaps[ref.num]; ok {
+		return cmap
+	}
+	data, err := d.decodeStream(d.objects[ref.num])
+	var cmap map[string]string
+	if err == nil {
+		cmap = parseToUnicodeCMap(data)
+	}
+	d.cmaps[ref.num] = cmap
+	return cmap
+}
+
+// parseToUnicodeCMap reads bfchar/bfrange sections into a map keyed by the
+// raw source code bytes.
+func parseToUnicodeCMap(data []byte) map[string]string {
+	cmap := map[string]string{}
+	p := &pdfParser{data: data}
+	var operands []interface{}
+	for {
+		tok, err := p.next()
+		if err != nil {
+			break
+		}
+		op, isOp := tok.(pdfOperator)
+		if !isOp {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				src, ok1 := operands[i].(pdfString)
+				dst, ok2 := operands[i+1].(pdfString)
+				if ok1 && ok2 {
+					cmap[string(src)] = utf16BEString(dst)
+				}
+			}
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, ok1 := operands[i].(pdfString)
+				hi, ok2 := operands[i+1].(pdfString)
+				if !ok1 || !ok2 || len(lo) != len(hi) || len(lo) == 0 || len(lo) > 4 {
+					continue
+				}
+				start, end := pdfCode(lo), pdfCode(hi)
+				if end < start || end-start > 0xFFFF {
+					continue
+				}
+				switch dst := operands[i+2].(type) {
+				case pdfString:
+					base := []rune(utf16BEString(dst))
+					if len(base) == 0 {
+						continue
+					}
+					for code := start; code <= end; code++ {
+						out := append([]rune(nil), base...)
+						out[len(out)-1] += rune(code - start)
+						cmap[string(pdfCodeBytes(code, len(lo)))] = string(out)
+					}
+				case []interface{}:
+					for j, item := range dst {
+						if s, ok := item.(pdfString); ok && start+j <= end {
+							cmap[string(pdfCodeBytes(start+j, len(lo)))] = utf16BEString(s)
+						}
+					}
+				}
+			}
+		}
+		operands = operands[:0]
+	}
+	return cmap
+}
+
+func pdfCode(b pdfString) int {
+	code := 0
+	for _, c := range []byte(b) {
+		code = code<<8 | int(c)
+	}
+	return code
+}
+
+func pdfCodeBytes(code, width int) []byte {
+	out := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		out[i] = byte(code)
+		code >>= 8
+	}
+	return out
+}
+
+func utf16BEString(b pdfString) string {
+	if len(b)%2 != 0 {
+		return string(b)
+	}
+	units := make([]uint16, len(b)/2)
+	for i := range units {
+		units[i] = uint16(b[2*i])<<8 | uint16(b[2*i+1])
+	}
+	return string(utf16.Decode(units))
+}
+
+// decodePDFText maps a shown string through the active font's CMap, falling
+// back to PDFDocEncoding-ish Latin-1 when no map is available.
+func decodePDFText(s pdfString, cmap map[string]string) string {
+	if len(cmap) == 0 {
+		if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+			return utf16BEString(s[2:])
+		}
+		runes := make([]rune, len(s))
+		for i, c := range []byte(s) {
+			runes[i] = rune(c)
+		}
+		return string(runes)
+	}
+	var b strings.Builder
+	raw := []byte(s)
+	for i := 0; i < len(raw); {
+		matched := false
+		for width := 1; width <= 4 && i+width <= len(raw); width++ {
+			if text, ok := cmap[string(raw[i:i+width])]; ok {
+				b.WriteString(text)
+				i += width
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			i++
+		}
+	}
+	return b.String()
+}
+
+// extractPDFPageText interprets text operators in a content stream.
+func extractPDFPageText(content []byte, fonts map[string]map[string]string) string {
+	var b strings.Builder
+	p := &pdfParser{data: content}
+	var operands []interface{}
+	var cmap map[string]string
+	lastY, haveY := 0.0, false
+	newline := func() {
+		if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") {
+			b.WriteByte('\n')
+		}
+	}
+	moveTo := func(y float64) {
+		if haveY && absFloat(y-lastY) > 0.1 {
+			newline()
+		}
+		lastY, haveY = y, true
+	}
+	for {
+		tok, err := p.next()
+		if err != nil {
+			break
+		}
+		op, isOp := tok.(pdfOperator)
+		if !isOp {
+			operands = append(operands, tok)
+			continue
+		}
+		switch op {
+		case "Tf":
+			if len(operands) >= 2 {
+				if name, ok := operands[len(operands)-2].(pdfName); ok {
+					cmap = fonts[string(name)]
+				}
+			}
+		case "Tj", "'", `"`:
+			if op != "Tj" {
+				newline()
+			}
+			if len(operands) > 0 {
+				if s, ok := operands[len(operands)-1].(pdfString); ok {
+					b.WriteString(decodePDFText(s, cmap))
+				}
+			}
+		case "TJ":
+			if len(operands) > 0 {
+				if arr, ok := operands[len(operands)-1].([]interface{}); ok {
+					for _, item := range arr {
+						switch v := item.(type) {
+						case pdfString:
+							b.WriteString(decodePDFText(v, cmap))
+						case int:
+							if v < -200 {
+								b.WriteByte(' ')
+							}
+						case float64:
+							if v < -200 {
+								b.WriteByte(' ')
+							}
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 {
+				if ty := pdfNumber(operands[len(operands)-1]); ty != 0 {
+					newline()
+					lastY += ty
+				} else if b.Len() > 0 && !strings.HasSuffix(b.String(), " ") && !strings.HasSuffix(b.String(), "\n") {
+					b.WriteByte(' ')
+				}
+			}
+		case "Tm":
+			if len(operands) >= 6 {
+				moveTo(pdfNumber(operands[len(operands)-1]))
+			}
+		case "T*":
+			newline()
+		case "ET":
+			if b.Len() > 0 && !strings.HasSuffix(b.String(), "\n") && !strings.HasSuffix(b.String(), " ") {
+				b.WriteByte(' ')
+			}
+		}
+		operands = operands[:0]
+	}
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+func pdfNumber(v interface{}) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case float64:
+		return n
+	default:
+		return 0
+	}
+}
+
+func absFloat(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+type pdfString string
+
+type pdfOperator string
+
+type pdfParser struct {
+	data []byte
+	pos  int
+}
+
+var errPDFEOF = errors.New("eof")
+
+func isPDFSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0:
+		return true
+	}
+	return false
+}
+
+func isPDFDelimiter(c byte) bool {
+	switch c {
+	case '(', ')', '<', '>', '[', ']', '{', '}', '/', '%':
+		return true
+	}
+	return false
+}
+
+func (p *pdfParser) skipSpace() {
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		if isPDFSpace(c) {
+			p.pos++
+			continue
+		}
+		if c == '%' {
+			for p.pos < len(p.data) && p.data[p.pos] != '\n' && p.data[p.pos] != '\r' {
+				p.pos++
+			}
+			continue
+		}
+		return
+	}
+}
+
+// parseValue reads one object, folding "num gen R" into a pdfRef.
+func (p *pdfParser) parseValue() (interface{}, error) {
+	tok, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if num, ok := tok.(int); ok {
+		save := p.pos
+		if gen, err := p.next(); err == nil {
+			if g, ok := gen.(int); ok {
+				if r, err := p.next(); err == nil && r == pdfOperator("R") {
+					return pdfRef{num: num, gen: g}, nil
+				}
+			}
+		}
+		p.pos = save
+	}
+	return tok, nil
+}
+
+func (p *pdfParser) next() (interface{}, error) {
+	p.skipSpace()
+	if p.pos >= len(p.data) {
+		return nil, errPDFEOF
+	}
+	c := p.data[p.pos]
+	switch {
+	case c == '/':
+		p.pos++
+		start := p.pos
+		for p.pos < len(p.data) && !isPDFSpace(p.data[p.pos]) && !isPDFDelimiter(p.data[p.pos]) {
+			p.pos++
+		}
+		return pdfName(decodePDFName(p.data[start:p.pos])), nil
+	case c == '(':
+		return p.literalString(), nil
+	case c == '<' && p.pos+1 < len(p.data) && p.data[p.pos+1] == '<':
+		p.pos += 2
+		dict := pdfDict{}
+		for {
+			p.skipSpace()
+			if p.pos+1 < len(p.data) && p.data[p.pos] == '>' && p.data[p.pos+1] == '>' {
+				p.pos += 2
+				return dict, nil
+			}
+			key, err := p.next()
+			if err != nil {
+				return nil, err
+			}
+			name, ok := key.(pdfName)
+			if !ok {
+				continue
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			dict[string(name)] = value
+		}
+	case c == '<':
+		p.pos++
+		end := bytes.IndexByte(p.data[p.pos:], '>')
+		if end < 0 {
+			return nil, errPDFEOF
+		}
+		hex := bytes.Map(func(r rune) rune {
+			if isPDFSpace(byte(r)) {
+				return -1
+			}
+			return r
+		}, p.data[p.pos:p.pos+end])
+		p.pos += end + 1
+		if len(hex)%2 == 1 {
+			hex = append(hex, '0')
+		}
+		out := make([]byte, len(hex)/2)
+		for i := range out {
+			v, _ := strconv.ParseUint(string(hex[2*i:2*i+2]), 16, 8)
+			out[i] = byte(v)
+		}
+		return pdfString(out), nil
+	case c == '[':
+		p.pos++
+		var arr []interface{}
+		for {
+			p.skipSpace()
+			if p.pos >= len(p.data) {
+				return nil, errPDFEOF
+			}
+			if p.data[p.pos] == ']' {
+				p.pos++
+				return arr, nil
+			}
+			value, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			arr = append(arr, value)
+		}
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		p.pos++
+		return pdfOperator(string(c)), nil
+	}
+	start := p.pos
+	for p.pos < len(p.data) && !isPDFSpace(p.data[p.pos]) && !isPDFDelimiter(p.data[p.pos]) {
+		p.pos++
+	}
+	word := string(p.data[start:p.pos])
+	if i, err := strconv.Atoi(word); err == nil {
+		return i, nil
+	}
+	if f, err := strconv.ParseFloat(word, 64); err == nil {
+		return f, nil
+	}
+	switch word {
+	case "true":
+		return true, nil
+	case "false":
+		return false, nil
+	case "null":
+		return nil, nil
+	}
+	if word == "BI" {
+		p.skipInlineImage()
+	}
+	return pdfOperator(word), nil
+}
+
+// skipInlineImage jumps past BI ... ID <binary> EI so image bytes are not
+// tokenised as operators.
+func (p *pdfParser) skipInlineImage() {
+	idx := bytes.Index(p.data[p.pos:], []byte("ID"))
+	if idx < 0 {
+		p.pos = len(p.data)
+		return
+	}
+	p.pos += idx + 2
+	for p.pos < len(p.data) {
+		end := bytes.Index(p.data[p.pos:], []byte("EI"))
+		if end < 0 {
+			p.pos = len(p.data)
+			return
+		}
+		p.pos += end + 2
+		if p.pos >= len(p.data) || isPDFSpace(p.data[p.pos]) {
+			return
+		}
+	}
+}
+
+func (p *pdfParser) literalString() pdfString {
+	p.pos++ // opening paren
+	var out []byte
+	depth := 1
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		p.pos++
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return pdfString(out)
+			}
+			out = append(out, c)
+		case '\\':
+			if p.pos >= len(p.data) {
+				return pdfString(out)
+			}
+			e := p.data[p.pos]
+			p.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+					p.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					v := int(e - '0')
+					for i := 0; i < 2 && p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '7'; i++ {
+						v = v*8 + int(p.data[p.pos]-'0')
+						p.pos++
+					}
+					out = append(out, byte(v))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return pdfString(out)
+}
+
+func decodePDFName(raw []byte) string {
+	if bytes.IndexByte(raw, '#') < 0 {
+		return string(raw)
+	}
+	var out []byte
+	for i := 0; i < len(raw); i++ {
+		if raw[i] == '#' && i+2 < len(raw) {
+			if v, err := strconv.ParseUint(string(raw[i+1:i+3]), 16, 8); err == nil {
+				out = append(out, byte(v))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, raw[i])
+	}
+	return string(out)
+}
+
+// parsePageSelection parses "3", "1-5" or "1,3,5-7" into sorted, de-duplicated
+// 1-based page numbers bounded by total.
+func parsePageSelection(spec string, total int) ([]int, error) {
+	seen := map[int]bool{}
+	var pages []int
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		loRaw, hiRaw, isRange := strings.Cut(part, "-")
+		lo, err := strconv.Atoi(strings.TrimSpace(loRaw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid page %q", part)
+		}
+		hi := lo
+		if isRange {
+			if hi, err = strconv.Atoi(strings.TrimSpace(hiRaw)); err != nil {
+				return nil, fmt.Errorf("invalid page range %q", part)
+			}
+		}
+		if lo < 1 || hi < lo {
+			return nil, fmt.Errorf("invalid page range %q", part)
+		}
+		if lo > total {
+			return nil, fmt.Errorf("page %d out of range (document has %d pages)", lo, total)
+		}
+		for page := lo; page <= min(hi, total); page++ {
+			if !seen[page] {
+				seen[page] = true
+				pages = append(pages, page)
+			}
+		}
+	}
+	if len(pages) == 0 {
+		return nil, errors.New("pages selects no pages")
+	}
+	sort.Ints(pages)
+	return pages, nil
+}
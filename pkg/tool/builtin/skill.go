@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"strings"
 
 	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/skills"
@@ -14,7 +15,7 @@ import (
 const skillToolDescriptionHeader = `Execute a skill.
 
 <skills_instructions>
-Call this tool with {"command":"<skill-name>"} (no arguments).
+Call this tool with {"command":"<skill-name>"}. Add "args" only for skills whose description lists arguments.
 Only use skills listed in <available_skills>. Do not invoke a skill that is already running.
 </skills_instructions>
 
@@ -28,6 +29,11 @@ var skillSchema = &tool.JSONSchema{
 			"type":        "string",
 			"description": "The skill name (no arguments). E.g., \"pdf\" or \"xlsx\"",
 		},
+		"args": map[string]interface{}{
+			"type":                 "object",
+			"description":          "Named arguments for skills that declare them.",
+			"additionalProperties": map[string]interface{}{"type": "string"},
+		},
 	},
 	Required: []string{"command"},
 }
@@ -117,12 +123,23 @@ func (s *SkillTool) Execute(ctx context.Context, params map[string]interface{})
 	if err != nil {
 		return nil, err
 	}
+	args, err := parseSkillArgs(params)
+	if err != nil {
+		return nil, err
+	}
 	act := s.provider(ctx)
+	if len(args) > 0 {
+		act.Metadata = maps.Clone(act.Metadata)
+		if act.Metadata == nil {
+			act.Metadata = map[string]any{}
+		}
+		act.Metadata[skills.ArgumentsMetadataKey] = args
+	}
 	result, err := s.registry.Execute(ctx, name, act)
 	if err != nil {
 		return nil, err
 	}
-	output := formatSkillOutput(result)
+	output := FormatSkillOutput(result)
 	data := map[string]interface{}{
 		"skill":    result.Skill,
 		"output":   result.Output,
@@ -135,6 +152,26 @@ func (s *SkillTool) Execute(ctx context.Context, params map[string]interface{})
 	}, nil
 }
 
+func parseSkillArgs(params map[string]interface{}) (map[string]string, error) {
+	raw, ok := params["args"]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	obj, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("args must be an object, got %T", raw)
+	}
+	args := make(map[string]string, len(obj))
+	for key, value := range obj {
+		text, err := coerceString(value)
+		if err != nil {
+			return nil, fmt.Errorf("args.%s must be string: %w", key, err)
+		}
+		args[key] = text
+	}
+	return args, nil
+}
+
 func parseSkillName(params map[string]interface{}) (string, error) {
 	if params == nil {
 		return "", errors.New("params is nil")
@@ -154,7 +191,8 @@ func parseSkillName(params map[string]interface{}) (string, error) {
 	return name, nil
 }
 
-func formatSkillOutput(result skills.Result) string {
+// FormatSkillOutput renders a skill result as the text shown to the model.
+func FormatSkillOutput(result skills.Result) string {
 	switch v := result.Output.(type) {
 	case string:
 		if strings.TrimSpace(v) != "" {
@@ -54,14 +54,14 @@ func TestSkillLocation_ReturnsEmptyWhenNoKnownKeyPresent(t *testing.T) {
 }
 
 func TestFormatSkillOutput_MarshalFailureFallsBack(t *testing.T) {
-	got := formatSkillOutput(skills.Result{Skill: "a", Output: func() {}})
+	got := FormatSkillOutput(skills.Result{Skill: "a", Output: func() {}})
 	if !strings.Contains(got, "skill a executed") {
 		t.Fatalf("unexpected output %q", got)
 	}
 }
 
 func TestFormatSkillOutput_EmptySkillFallsBackToGenericMessage(t *testing.T) {
-	got := formatSkillOutput(skills.Result{})
+	got := FormatSkillOutput(skills.Result{})
 	if got != "skill executed" {
 		t.Fatalf("unexpected output %q", got)
 	}
@@ -94,6 +94,28 @@ func TestSkillToolDefaultActivationProviderUsesContext(t *testing.T) {
 	}
 }
 
+func TestSkillToolPassesArgs(t *testing.T) {
+	reg := skills.NewRegistry()
+	err := reg.Register(skills.Definition{Name: "greet"}, skills.HandlerFunc(func(ctx context.Context, ac skills.ActivationContext) (skills.Result, error) {
+		args, _ := ac.Metadata[skills.ArgumentsMetadataKey].(map[string]string)
+		return skills.Result{Skill: "greet", Output: "hello " + args["name"]}, nil
+	}))
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	tool := NewSkillTool(reg, nil)
+	res, err := tool.Execute(context.Background(), map[string]interface{}{"command": "greet", "args": map[string]interface{}{"name": "ada"}})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Output != "hello ada" {
+		t.Fatalf("args not forwarded, got %q", res.Output)
+	}
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"command": "greet", "args": "name=ada"}); err == nil {
+		t.Fatalf("expected error for non-object args")
+	}
+}
+
 type skillStringer struct{}
 
 func (skillStringer) String() string { return "stringer-output" }
@@ -111,7 +133,7 @@ func TestFormatSkillOutputVariants(t *testing.T) {
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
-			if got := formatSkillOutput(tc.result); !strings.Contains(got, tc.want) {
+			if got := FormatSkillOutput(tc.result); !strings.Contains(got, tc.want) {
 				t.Fatalf("expected %q in %q", tc.want, got)
 			}
 		})
@@ -0,0 +1,250 @@
+package toolbuiltin
+
+import (
+	"errors"
+	"strings"
+)
+
+// sqlReadOnlyLeaders are the statement keywords that can start a read-only
+// statement. The remaining words are still checked against sqlWriteWords.
+var sqlReadOnlyLeaders = map[string]bool{
+	"SELECT": true, "WITH": true, "VALUES": true, "TABLE": true, "SHOW": true,
+	"DESCRIBE": true, "DESC": true, "EXPLAIN": true, "PRAGMA": true,
+}
+
+// sqlWriteWords mark a statement as modifying data or state wherever they
+// appear, which also catches data-modifying CTEs, SELECT ... INTO and
+// EXPLAIN ANALYZE of a write. False positives only cost a refusal.
+var sqlWriteWords = map[string]bool{
+	"INSERT": true, "UPDATE": true, "DELETE": true, "MERGE": true, "UPSERT": true,
+	"INTO": true, "CREATE": true, "DROP": true, "ALTER": true, "TRUNCATE": true,
+	"GRANT": true, "REVOKE": true, "COPY": true, "CALL": true, "EXEC": true,
+	"EXECUTE": true, "LOCK": true, "VACUUM": true, "ATTACH": true, "DETACH": true,
+	"REINDEX": true, "REFRESH": true, "CLUSTER": true, "COMMENT": true,
+}
+
+// sqlReadOnlyPragmas are the SQLite pragmas that may take an argument
+// without changing anything.
+var sqlReadOnlyPragmas = map[string]bool{
+	"TABLE_INFO": true, "TABLE_XINFO": true, "TABLE_LIST": true, "INDEX_LIST": true,
+	"INDEX_INFO": true, "INDEX_XINFO": true, "FOREIGN_KEY_LIST": true,
+}
+
+// sqlDialect describes the lexical rules that decide where literals and
+// comments end, which is what a keyword scan must get right.
+type sqlDialect struct {
+	backslashEscapes bool // backslash escapes quotes in every string (MySQL)
+	escapeStrings    bool // backslash escapes inside E'...' strings (PostgreSQL)
+	hashComments     bool // # starts a line comment and /*! ... */ is code (MySQL)
+	dashNeedsSpace   bool // -- only starts a comment when followed by whitespace (MySQL)
+	dollarQuotes     bool // $tag$...$tag$ strings (PostgreSQL)
+}
+
+var (
+	sqlDialectStandard = sqlDialect{}
+	sqlDialectPostgres = sqlDialect{escapeStrings: true, dollarQuotes: true}
+	sqlDialectMySQL    = sqlDialect{backslashEscapes: true, hashComments: true, dashNeedsSpace: true}
+)
+
+// sqlDialectsFor picks the lexical rules for a database/sql driver name.
+// Unknown drivers are read under every dialect.
+func sqlDialectsFor(driver string) []sqlDialect {
+	switch strings.ToLower(driver) {
+	case "postgres", "postgresql", "pgx", "pq", "cloudsqlpostgres":
+		return []sqlDialect{sqlDialectPostgres}
+	case "mysql", "mariadb":
+		return []sqlDialect{sqlDialectMySQL}
+	case "sqlite", "sqlite3":
+		return []sqlDialect{sqlDialectStandard}
+	default:
+		return []sqlDialect{sqlDialectStandard, sqlDialectPostgres, sqlDialectMySQL}
+	}
+}
+
+// sqlStatement is the classification of one SQL statement.
+type sqlStatement struct {
+	readOnly  bool
+	returning bool
+}
+
+// classifySQL checks that query holds exactly one statement and reports
+// whether it only reads. With several dialects the statement must be a single
+// read-only statement under every one of them.
+func classifySQL(query string, dialects []sqlDialect) (sqlStatement, error) {
+	var out sqlStatement
+	for i, dialect := range dialects {
+		words, err := sqlWords(query, dialect)
+		if err != nil {
+			return sqlStatement{}, err
+		}
+		stmt := classifySQLWords(words)
+		if i == 0 {
+			out = stmt
+			continue
+		}
+		out.readOnly = out.readOnly && stmt.readOnly
+		out.returning = out.returning || stmt.returning
+	}
+	return out, nil
+}
+
+func classifySQLWords(words []string) sqlStatement {
+	stmt := sqlStatement{}
+	for _, w := range words {
+		if w == "RETURNING" {
+			stmt.returning = true
+		}
+	}
+	if len(words) == 0 || !sqlReadOnlyLeaders[words[0]] {
+		return stmt
+	}
+	for _, w := range words {
+		if sqlWriteWords[w] {
+			return stmt
+		}
+	}
+	if words[0] == "PRAGMA" && !readOnlyPragma(words[1:]) {
+		return stmt
+	}
+	stmt.readOnly = true
+	return stmt
+}
+
+// readOnlyPragma accepts bare pragma queries and the introspection pragmas;
+// any other "PRAGMA x = y" or "PRAGMA x(y)" form may change settings.
+func readOnlyPragma(rest []string) bool {
+	if len(rest) >= 2 && rest[1] == "." {
+		rest = rest[2:] // schema-qualified: PRAGMA main.table_info(t)
+	}
+	if len(rest) == 0 {
+		return false
+	}
+	if sqlReadOnlyPragmas[rest[0]] {
+		return true
+	}
+	for _, w := range rest[1:] {
+		if w == "=" || w == "(" {
+			return false
+		}
+	}
+	return true
+}
+
+// sqlWords upper-cases the keywords and identifiers of query and returns them
+// with the "=", "(" and "." punctuation, skipping literals, quoted identifiers
+// and comments. It fails when query holds more than one statement. Bracketed
+// identifiers are scanned as words since [ is an operator in PostgreSQL.
+func sqlWords(query string, dialect sqlDialect) ([]string, error) {
+	var words []string
+	ended := false
+	for i := 0; i < len(query); {
+		c := query[i]
+		switch {
+		case c == '-' && strings.HasPrefix(query[i:], "--") && (!dialect.dashNeedsSpace || i+2 >= len(query) || isSQLSpace(query[i+2])),
+			c == '#' && dialect.hashComments:
+			end := strings.IndexByte(query[i:], '\n')
+			if end < 0 {
+				return words, nil
+			}
+			i += end + 1
+			continue
+		case c == '/' && dialect.hashComments && strings.HasPrefix(query[i:], "/*!"):
+			// MySQL runs the body of /*! ... */ comments, so scan it as code.
+			i += 3
+			continue
+		case c == '/' && strings.HasPrefix(query[i:], "/*"):
+			end := strings.Index(query[i+2:], "*/")
+			if end < 0 {
+				return nil, errors.New("unterminated comment")
+			}
+			i += end + 4
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			escapes := dialect.backslashEscapes
+			if dialect.escapeStrings && c == '\'' && i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && (i == 1 || !isSQLWordByte(query[i-2])) {
+				escapes = true
+			}
+			end, err := skipSQLQuoted(query, i, escapes)
+			if err != nil {
+				return nil, err
+			}
+			i = end
+		case c == '$' && dialect.dollarQuotes && dollarQuoteTag(query[i:]) != "":
+			tag := dollarQuoteTag(query[i:])
+			end := strings.Index(query[i+len(tag):], tag)
+			if end < 0 {
+				return nil, errors.New("unterminated dollar-quoted string")
+			}
+			i += len(tag) + end + len(tag)
+		case c == ';':
+			ended = len(words) > 0 || ended
+			i++
+			continue
+		case isSQLWordByte(c):
+			start := i
+			for i < len(query) && isSQLWordByte(query[i]) {
+				i++
+			}
+			if ended {
+				return nil, errors.New("only one SQL statement may be run per call")
+			}
+			words = append(words, strings.ToUpper(query[start:i]))
+			continue
+		case c == '=' || c == '(' || c == '.':
+			if ended {
+				return nil, errors.New("only one SQL statement may be run per call")
+			}
+			words = append(words, string(c))
+			i++
+			continue
+		default:
+			i++
+			continue
+		}
+		if ended {
+			return nil, errors.New("only one SQL statement may be run per call")
+		}
+	}
+	return words, nil
+}
+
+func skipSQLQuoted(query string, start int, backslash bool) (int, error) {
+	quote := query[start]
+	for i := start + 1; i < len(query); i++ {
+		switch query[i] {
+		case '\\':
+			if backslash && quote != '`' {
+				i++
+			}
+		case quote:
+			if i+1 < len(query) && query[i+1] == quote {
+				i++
+				continue
+			}
+			return i + 1, nil
+		}
+	}
+	return 0, errors.New("unterminated quoted string")
+}
+
+// dollarQuoteTag returns the PostgreSQL $tag$ opening s, if any.
+func dollarQuoteTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch {
+		case s[i] == '$':
+			return s[:i+1]
+		case s[i] == '_' || ('a' <= s[i] && s[i] <= 'z') || ('A' <= s[i] && s[i] <= 'Z') || (i > 1 && '0' <= s[i] && s[i] <= '9'):
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func isSQLSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\f' || c == '\v'
+}
+
+func isSQLWordByte(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || c >= 0x80
+}
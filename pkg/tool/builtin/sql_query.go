@@ -0,0 +1,604 @@
+package toolbuiltin
+
+import (
+	"context"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const SQLQueryName = "sql_query"
+
+const (
+	sqlDefaultTimeout  = 30 * time.Second
+	sqlDefaultRows     = 100
+	sqlDefaultMaxRows  = 1000
+	sqlDefaultMaxBytes = 64 * 1024
+	sqlMaxSchemaRows   = 5000
+)
+
+// SQLConnectionConfig describes one named database reachable through a
+// database/sql driver registered by the host program.
+type SQLConnectionConfig struct {
+	Name        string
+	Driver      string
+	DSN         string
+	AllowWrites bool
+	MaxRows     int           // Upper bound on returned rows (default 1000).
+	MaxBytes    int           // Upper bound on rendered result size (default 64 KiB).
+	Timeout     time.Duration // Per-statement timeout (default 30s).
+}
+
+func (c SQLConnectionConfig) maxRows() int {
+	if c.MaxRows > 0 {
+		return c.MaxRows
+	}
+	return sqlDefaultMaxRows
+}
+
+func (c SQLConnectionConfig) maxBytes() int {
+	if c.MaxBytes > 0 {
+		return c.MaxBytes
+	}
+	return sqlDefaultMaxBytes
+}
+
+func (c SQLConnectionConfig) timeout() time.Duration {
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return sqlDefaultTimeout
+}
+
+// SQLManager owns the connection pools for the configured databases. Pools
+// open lazily on first use and are shared by every session.
+type SQLManager struct {
+	mu     sync.Mutex
+	conns  map[string]SQLConnectionConfig
+	dbs    map[string]*sql.DB
+	closed bool
+}
+
+// NewSQLManager builds a manager for conns.
+func NewSQLManager(conns []SQLConnectionConfig) *SQLManager {
+	m := &SQLManager{conns: make(map[string]SQLConnectionConfig, len(conns)), dbs: map[string]*sql.DB{}}
+	for _, c := range conns {
+		m.conns[c.Name] = c
+	}
+	return m
+}
+
+// Names lists the configured connections in sorted order.
+func (m *SQLManager) Names() []string {
+	if m == nil {
+		return nil
+	}
+	names := make([]string, 0, len(m.conns))
+	for name := range m.conns {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m *SQLManager) open(name string) (*sql.DB, SQLConnectionConfig, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return nil, SQLConnectionConfig{}, errors.New("sql manager is closed")
+	}
+	cfg, ok := m.conns[name]
+	if !ok {
+		return nil, SQLConnectionConfig{}, fmt.Errorf("unknown connection %q (configured: %s)", name, strings.Join(m.Names(), ", "))
+	}
+	if db := m.dbs[name]; db != nil {
+		return db, cfg, nil
+	}
+	db, err := sql.Open(cfg.Driver, cfg.DSN)
+	if err != nil {
+		// Driver errors may quote the DSN, which can hold credentials.
+		return nil, cfg, fmt.Errorf("open connection %q with driver %q failed", name, cfg.Driver)
+	}
+	db.SetMaxOpenConns(4)
+	db.SetConnMaxIdleTime(5 * time.Minute)
+	m.dbs[name] = db
+	return db, cfg, nil
+}
+
+// Close closes every open pool.
+func (m *SQLManager) Close() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	m.closed = true
+	dbs := m.dbs
+	m.dbs = map[string]*sql.DB{}
+	m.mu.Unlock()
+	for _, db := range dbs {
+		_ = db.Close()
+	}
+}
+
+const sqlQueryDescription = `Runs SQL against a configured database connection and returns the rows.
+Usage:
+- operation is query (default) or describe_schema.
+- query runs one statement from sql, with optional positional args bound to the driver's placeholders ($1, ? ...).
+- Only read-only statements (SELECT, WITH, VALUES, SHOW, EXPLAIN, ...) run unless the connection allows writes; writes report rows_affected.
+- Results are limited to max_rows (default 100) and a byte budget; format is markdown (default) or json.
+- describe_schema lists tables and columns, optionally only those whose name matches table.
+- connection may be omitted when only one is configured.`
+
+var sqlQuerySchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"operation": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"query", "describe_schema"},
+			"description": "query (default) or describe_schema.",
+		},
+		"connection": map[string]interface{}{
+			"type":        "string",
+			"description": "Name of the configured connection.",
+		},
+		"sql": map[string]interface{}{
+			"type":        "string",
+			"description": "query: the statement to run.",
+		},
+		"args": map[string]interface{}{
+			"type":        "array",
+			"description": "query: positional arguments for the statement's placeholders.",
+		},
+		"format": map[string]interface{}{
+			"type":        "string",
+			"enum":        []string{"markdown", "json"},
+			"description": "Result format (default markdown).",
+		},
+		"max_rows": map[string]interface{}{
+			"type":        "integer",
+			"description": "query: maximum rows to return (default 100, capped by the connection).",
+		},
+		"table": map[string]interface{}{
+			"type":        "string",
+			"description": "describe_schema: only describe tables with this name (case-insensitive).",
+		},
+	},
+}
+
+// SQLQueryTool runs guarded SQL against the connections of an SQLManager.
+type SQLQueryTool struct {
+	manager *SQLManager
+}
+
+// NewSQLQueryTool builds an SQLQueryTool over manager.
+func NewSQLQueryTool(manager *SQLManager) *SQLQueryTool {
+	return &SQLQueryTool{manager: manager}
+}
+
+func (s *SQLQueryTool) Name() string { return SQLQueryName }
+
+func (s *SQLQueryTool) Description() string {
+	desc := sqlQueryDescription
+	if s != nil && s.manager != nil {
+		var names []string
+		for _, name := range s.manager.Names() {
+			cfg := s.manager.conns[name]
+			mode := "read-only"
+			if cfg.AllowWrites {
+				mode = "read-write"
+			}
+			names = append(names, fmt.Sprintf("%s (%s, %s)", name, cfg.Driver, mode))
+		}
+		if len(names) > 0 {
+			desc += "\nConnections: " + strings.Join(names, "; ")
+		}
+	}
+	return desc
+}
+
+func (s *SQLQueryTool) Schema() *tool.JSONSchema { return sqlQuerySchema }
+
+func (s *SQLQueryTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsConcurrencySafe: true}
+}
+
+// sqlResult holds rows collected within the row and byte limits.
+type sqlResult struct {
+	columns   []string
+	rows      [][]interface{}
+	truncated bool
+}
+
+func (s *SQLQueryTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if s == nil || s.manager == nil {
+		return nil, errors.New("sql_query tool is not initialised")
+	}
+	if params == nil {
+		return nil, errors.New("params is nil")
+	}
+	operation := "query"
+	if raw, ok := params["operation"]; ok && raw != nil {
+		value, err := coerceString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("operation must be string: %w", err)
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			operation = value
+		}
+	}
+	name, err := s.connectionName(params)
+	if err != nil {
+		return nil, err
+	}
+	format := "markdown"
+	if raw, ok := params["format"]; ok && raw != nil {
+		value, err := coerceString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("format must be string: %w", err)
+		}
+		switch value = strings.ToLower(strings.TrimSpace(value)); value {
+		case "", "markdown":
+		case "json":
+			format = value
+		default:
+			return nil, fmt.Errorf("unsupported format %q", value)
+		}
+	}
+	db, cfg, err := s.manager.open(name)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, cfg.timeout())
+	defer cancel()
+
+	switch operation {
+	case "query":
+		return s.query(ctx, db, cfg, params, format)
+	case "describe_schema":
+		return s.describeSchema(ctx, db, cfg, params, format)
+	default:
+		return nil, fmt.Errorf("unsupported operation %q", operation)
+	}
+}
+
+func (s *SQLQueryTool) connectionName(params map[string]interface{}) (string, error) {
+	if raw, ok := params["connection"]; ok && raw != nil {
+		value, err := coerceString(raw)
+		if err != nil {
+			return "", fmt.Errorf("connection must be string: %w", err)
+		}
+		if value = strings.TrimSpace(value); value != "" {
+			return value, nil
+		}
+	}
+	names := s.manager.Names()
+	switch len(names) {
+	case 0:
+		return "", errors.New("no sql connections are configured")
+	case 1:
+		return names[0], nil
+	default:
+		return "", fmt.Errorf("connection is required (configured: %s)", strings.Join(names, ", "))
+	}
+}
+
+func (s *SQLQueryTool) query(ctx context.Context, db *sql.DB, cfg SQLConnectionConfig, params map[string]interface{}, format string) (*tool.ToolResult, error) {
+	raw, ok := params["sql"]
+	if !ok || raw == nil {
+		return nil, errors.New("sql is required")
+	}
+	statement, err := coerceString(raw)
+	if err != nil {
+		return nil, fmt.Errorf("sql must be string: %w", err)
+	}
+	if strings.TrimSpace(statement) == "" {
+		return nil, errors.New("sql is required")
+	}
+	stmt, err := classifySQL(statement, sqlDialectsFor(cfg.Driver))
+	if err != nil {
+		return nil, err
+	}
+	if !stmt.readOnly && !cfg.AllowWrites {
+		return nil, fmt.Errorf("statement is not read-only and connection %q does not allow writes", cfg.Name)
+	}
+	var args []interface{}
+	if raw, ok := params["args"]; ok && raw != nil {
+		list, ok := raw.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("args must be an array got %T", raw)
+		}
+		args = list
+	}
+	limit := min(sqlDefaultRows, cfg.maxRows())
+	n, err := parseLineNumber(params, "max_rows")
+	if err != nil {
+		return nil, err
+	}
+	if n > 0 {
+		limit = min(n, cfg.maxRows())
+	}
+
+	start := time.Now()
+	data := map[string]interface{}{"connection": cfg.Name, "read_only": stmt.readOnly}
+	if !stmt.readOnly && !stmt.returning {
+		res, err := db.ExecContext(ctx, statement, args...)
+		if err != nil {
+			return nil, fmt.Errorf("sql_query: %w", err)
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			affected = -1
+		}
+		data["rows_affected"] = affected
+		data["duration_ms"] = time.Since(start).Milliseconds()
+		return &tool.ToolResult{Success: true, Output: fmt.Sprintf("%d rows affected", affected), Data: data}, nil
+	}
+
+	var rows *sql.Rows
+	if stmt.readOnly {
+		// A read-only transaction backs up the keyword check against side
+		// effects it cannot see; drivers without them fall back to a plain
+		// query, still guarded by the classification.
+		tx, txErr := db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+		if txErr == nil {
+			defer func() { _ = tx.Rollback() }()
+			rows, err = tx.QueryContext(ctx, statement, args...)
+		} else {
+			rows, err = db.QueryContext(ctx, statement, args...)
+		}
+	} else {
+		rows, err = db.QueryContext(ctx, statement, args...)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sql_query: %w", err)
+	}
+	defer rows.Close()
+	result, err := collectSQLRows(rows, limit, cfg.maxBytes())
+	if err != nil {
+		return nil, fmt.Errorf("sql_query: %w", err)
+	}
+	data["columns"] = result.columns
+	data["rows"] = result.rows
+	data["row_count"] = len(result.rows)
+	data["truncated"] = result.truncated
+	data["duration_ms"] = time.Since(start).Milliseconds()
+	return &tool.ToolResult{Success: true, Output: renderSQLResult(result, format), Data: data}, nil
+}
+
+func collectSQLRows(rows *sql.Rows, limit, maxBytes int) (sqlResult, error) {
+	columns, err := rows.Columns()
+	if err != nil {
+		return sqlResult{}, err
+	}
+	result := sqlResult{columns: columns, rows: [][]interface{}{}}
+	size := 0
+	for _, c := range columns {
+		size += len(c) + 3
+	}
+	for rows.Next() {
+		if len(result.rows) >= limit {
+			result.truncated = true
+			break
+		}
+		values := make([]interface{}, len(columns))
+		ptrs := make([]interface{}, len(columns))
+		for i := range values {
+			ptrs[i] = &values[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return sqlResult{}, err
+		}
+		rowSize := 0
+		for i, v := range values {
+			values[i] = sqlValue(v)
+			rowSize += len(sqlCellText(values[i])) + 3
+		}
+		if size+rowSize > maxBytes && len(result.rows) > 0 {
+			result.truncated = true
+			break
+		}
+		size += rowSize
+		result.rows = append(result.rows, values)
+	}
+	return result, rows.Err()
+}
+
+// sqlValue converts driver values into JSON-friendly ones.
+func sqlValue(v interface{}) interface{} {
+	switch x := v.(type) {
+	case []byte:
+		if utf8.Valid(x) {
+			return string(x)
+		}
+		return "0x" + hex.EncodeToString(x)
+	case time.Time:
+		return x.Format(time.RFC3339Nano)
+	default:
+		return x
+	}
+}
+
+var sqlCellReplacer = strings.NewReplacer("|", "\\|", "\r\n", " ", "\n", " ", "\r", " ")
+
+func sqlCellText(v interface{}) string {
+	if v == nil {
+		return "NULL"
+	}
+	return fmt.Sprint(v)
+}
+
+func renderSQLResult(result sqlResult, format string) string {
+	var out strings.Builder
+	if format == "json" {
+		encoded, _ := json.MarshalIndent(map[string]interface{}{"columns": result.columns, "rows": result.rows}, "", "  ")
+		out.Write(encoded)
+	} else {
+		if len(result.columns) == 0 {
+			return "statement returned no columns"
+		}
+		writeRow := func(cells []string) {
+			out.WriteString("|")
+			for _, c := range cells {
+				out.WriteString(" ")
+				out.WriteString(sqlCellReplacer.Replace(c))
+				out.WriteString(" |")
+			}
+			out.WriteString("\n")
+		}
+		writeRow(result.columns)
+		sep := make([]string, len(result.columns))
+		for i := range sep {
+			sep[i] = "---"
+		}
+		writeRow(sep)
+		for _, row := range result.rows {
+			cells := make([]string, len(row))
+			for i, v := range row {
+				cells[i] = sqlCellText(v)
+			}
+			writeRow(cells)
+		}
+		fmt.Fprintf(&out, "(%d rows)", len(result.rows))
+	}
+	if result.truncated {
+		fmt.Fprintf(&out, "\n... (truncated after %d rows)", len(result.rows))
+	}
+	return out.String()
+}
+
+// sqlTable is one table in a describe_schema result.
+type sqlTable struct {
+	Schema  string      `json:"schema,omitempty"`
+	Name    string      `json:"name"`
+	Columns []sqlColumn `json:"columns"`
+}
+
+type sqlColumn struct {
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Nullable   bool   `json:"nullable"`
+	PrimaryKey bool   `json:"primary_key,omitempty"`
+}
+
+const sqliteSchemaQuery = `SELECT m.name, p.name, p.type, p."notnull", p.pk
+FROM sqlite_master m JOIN pragma_table_info(m.name) p
+WHERE m.type IN ('table', 'view') AND m.name NOT LIKE 'sqlite_%'
+ORDER BY m.name, p.cid`
+
+const informationSchemaQuery = `SELECT table_schema, table_name, column_name, data_type, is_nullable
+FROM information_schema.columns
+WHERE table_schema NOT IN ('information_schema', 'pg_catalog', 'mysql', 'performance_schema', 'sys')
+ORDER BY table_schema, table_name, ordinal_position`
+
+func (s *SQLQueryTool) describeSchema(ctx context.Context, db *sql.DB, cfg SQLConnectionConfig, params map[string]interface{}, format string) (*tool.ToolResult, error) {
+	filter := ""
+	if raw, ok := params["table"]; ok && raw != nil {
+		value, err := coerceString(raw)
+		if err != nil {
+			return nil, fmt.Errorf("table must be string: %w", err)
+		}
+		filter = strings.TrimSpace(value)
+	}
+	sqlite := strings.HasPrefix(strings.ToLower(cfg.Driver), "sqlite")
+	query := informationSchemaQuery
+	if sqlite {
+		query = sqliteSchemaQuery
+	}
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("sql_query: describe schema: %w", err)
+	}
+	defer rows.Close()
+
+	var tables []sqlTable
+	count := 0
+	truncated := false
+	for rows.Next() {
+		if count >= sqlMaxSchemaRows {
+			truncated = true
+			break
+		}
+		var schema, table string
+		var col sqlColumn
+		if sqlite {
+			var notNull, pk int
+			err = rows.Scan(&table, &col.Name, &col.Type, &notNull, &pk)
+			col.Nullable, col.PrimaryKey = notNull == 0 && pk == 0, pk > 0
+		} else {
+			var nullable string
+			err = rows.Scan(&schema, &table, &col.Name, &col.Type, &nullable)
+			col.Nullable = strings.EqualFold(nullable, "YES")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("sql_query: describe schema: %w", err)
+		}
+		if filter != "" && !strings.EqualFold(table, filter) {
+			continue
+		}
+		count++
+		if n := len(tables); n == 0 || tables[n-1].Schema != schema || tables[n-1].Name != table {
+			tables = append(tables, sqlTable{Schema: schema, Name: table})
+		}
+		last := &tables[len(tables)-1]
+		last.Columns = append(last.Columns, col)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("sql_query: describe schema: %w", err)
+	}
+
+	var out strings.Builder
+	if format == "json" {
+		encoded, _ := json.MarshalIndent(tables, "", "  ")
+		out.Write(encoded)
+	} else {
+		for i, t := range tables {
+			if i > 0 {
+				out.WriteString("\n")
+			}
+			name := t.Name
+			if t.Schema != "" {
+				name = t.Schema + "." + t.Name
+			}
+			fmt.Fprintf(&out, "%s\n", name)
+			for _, c := range t.Columns {
+				fmt.Fprintf(&out, "  %s %s", c.Name, c.Type)
+				if c.PrimaryKey {
+					out.WriteString(" PRIMARY KEY")
+				} else if !c.Nullable {
+					out.WriteString(" NOT NULL")
+				}
+				out.WriteString("\n")
+			}
+		}
+		if len(tables) == 0 {
+			out.WriteString("no tables found")
+		}
+	}
+	if truncated {
+		fmt.Fprintf(&out, "\n... (truncated after %d columns)", sqlMaxSchemaRows)
+	}
+	if tables == nil {
+		tables = []sqlTable{}
+	}
+	return &tool.ToolResult{
+		Success: true,
+		Output:  strings.TrimRight(out.String(), "\n"),
+		Data: map[string]interface{}{
+			"connection": cfg.Name,
+			"tables":     tables,
+			"truncated":  truncated,
+		},
+	}, nil
+}
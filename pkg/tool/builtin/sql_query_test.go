@@ -0,0 +1,257 @@
+package toolbuiltin
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSQLDriver serves canned results so the tool can be tested without a
+// database. The DSN selects the fakeSQLServer registered under that name.
+type fakeSQLDriver struct{}
+
+type fakeSQLServer struct {
+	mu       sync.Mutex
+	queries  []string
+	readOnly []bool
+	handle   func(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error)
+}
+
+var (
+	fakeSQLOnce    sync.Once
+	fakeSQLServers sync.Map
+)
+
+func newFakeSQL(t *testing.T, handle func(string, []driver.NamedValue) ([]string, [][]driver.Value, error)) (*fakeSQLServer, string) {
+	t.Helper()
+	fakeSQLOnce.Do(func() { sql.Register("toolbuiltin-fake", fakeSQLDriver{}) })
+	srv := &fakeSQLServer{handle: handle}
+	fakeSQLServers.Store(t.Name(), srv)
+	t.Cleanup(func() { fakeSQLServers.Delete(t.Name()) })
+	return srv, t.Name()
+}
+
+func (fakeSQLDriver) Open(dsn string) (driver.Conn, error) {
+	srv, ok := fakeSQLServers.Load(dsn)
+	if !ok {
+		return nil, fmt.Errorf("no fake server %q", dsn)
+	}
+	return &fakeSQLConn{srv: srv.(*fakeSQLServer)}, nil
+}
+
+type fakeSQLConn struct {
+	srv      *fakeSQLServer
+	readOnly bool
+}
+
+func (c *fakeSQLConn) Prepare(string) (driver.Stmt, error) { return nil, errors.New("not supported") }
+func (c *fakeSQLConn) Close() error                        { return nil }
+func (c *fakeSQLConn) Begin() (driver.Tx, error)           { return c, nil }
+func (c *fakeSQLConn) Commit() error                       { c.readOnly = false; return nil }
+func (c *fakeSQLConn) Rollback() error                     { c.readOnly = false; return nil }
+
+func (c *fakeSQLConn) BeginTx(_ context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	c.readOnly = opts.ReadOnly
+	return c, nil
+}
+
+func (c *fakeSQLConn) record(query string) {
+	c.srv.mu.Lock()
+	defer c.srv.mu.Unlock()
+	c.srv.queries = append(c.srv.queries, query)
+	c.srv.readOnly = append(c.srv.readOnly, c.readOnly)
+}
+
+func (c *fakeSQLConn) QueryContext(_ context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.record(query)
+	cols, rows, err := c.srv.handle(query, args)
+	if err != nil {
+		return nil, err
+	}
+	return &fakeSQLRows{cols: cols, rows: rows}, nil
+}
+
+func (c *fakeSQLConn) ExecContext(_ context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	c.record(query)
+	if _, _, err := c.srv.handle(query, args); err != nil {
+		return nil, err
+	}
+	return driver.RowsAffected(3), nil
+}
+
+type fakeSQLRows struct {
+	cols []string
+	rows [][]driver.Value
+}
+
+func (r *fakeSQLRows) Columns() []string { return r.cols }
+func (r *fakeSQLRows) Close() error      { return nil }
+
+func (r *fakeSQLRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+func newSQLTool(conns ...SQLConnectionConfig) *SQLQueryTool {
+	return NewSQLQueryTool(NewSQLManager(conns))
+}
+
+func TestClassifySQL(t *testing.T) {
+	tests := []struct {
+		query    string
+		dialects []sqlDialect
+		readOnly bool
+		err      bool
+	}{
+		{"SELECT * FROM users WHERE name = 'drop table'", nil, true, false},
+		{"  with t as (select 1) select * from t;  ", nil, true, false},
+		{"EXPLAIN SELECT 1", nil, true, false},
+		{"-- note\nSELECT 1 /* delete */", nil, true, false},
+		{"PRAGMA table_info(users)", nil, true, false},
+		{"PRAGMA journal_mode = WAL", nil, false, false},
+		{"WITH d AS (DELETE FROM t RETURNING *) SELECT * FROM d", nil, false, false},
+		{"SELECT * INTO backup FROM t", nil, false, false},
+		{"EXPLAIN ANALYZE UPDATE t SET x = 1", nil, false, false},
+		{"SELECT 1; DROP TABLE users", nil, false, true},
+		{"SELECT 'unterminated", nil, false, true},
+		// Under MySQL rules the quote is escaped and DROP sits in a string; under
+		// standard rules it is a second statement. Unknown drivers need both.
+		{`SELECT 'a\'; DROP TABLE t; --'`, []sqlDialect{sqlDialectMySQL}, true, false},
+		{`SELECT 'a\'; DROP TABLE t; --'`, nil, false, true},
+		{"SELECT 1 # 2; DROP TABLE t", []sqlDialect{sqlDialectPostgres}, false, true},
+		{"SELECT 1 /*! ; DROP TABLE t */", []sqlDialect{sqlDialectMySQL}, false, true},
+		{"SELECT $$; DELETE FROM t; $$", []sqlDialect{sqlDialectPostgres}, true, false},
+		{"SELECT $$; DELETE FROM t; $$", []sqlDialect{sqlDialectStandard}, false, true},
+		{`SELECT E'\'; DROP TABLE t; --'`, []sqlDialect{sqlDialectPostgres}, true, false},
+	}
+	for _, tt := range tests {
+		dialects := tt.dialects
+		if dialects == nil {
+			dialects = sqlDialectsFor("unknown")
+		}
+		stmt, err := classifySQL(tt.query, dialects)
+		if (err != nil) != tt.err {
+			t.Fatalf("%q: err = %v, want error %v", tt.query, err, tt.err)
+		}
+		if err == nil && stmt.readOnly != tt.readOnly {
+			t.Fatalf("%q: readOnly = %v, want %v", tt.query, stmt.readOnly, tt.readOnly)
+		}
+	}
+}
+
+func TestSQLQueryReadOnlyResults(t *testing.T) {
+	srv, dsn := newFakeSQL(t, func(query string, args []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		if len(args) != 1 || args[0].Value != int64(7) {
+			return nil, nil, fmt.Errorf("unexpected args %v", args)
+		}
+		return []string{"id", "name", "note"}, [][]driver.Value{
+			{int64(1), []byte("alice"), nil},
+			{int64(2), "b|ob", "multi\nline"},
+			{int64(3), "carol", []byte{0xff, 0x00}},
+		}, nil
+	})
+	s := newSQLTool(SQLConnectionConfig{Name: "analytics", Driver: "toolbuiltin-fake", DSN: dsn})
+	t.Cleanup(s.manager.Close)
+	ctx := context.Background()
+
+	res, err := s.Execute(ctx, map[string]interface{}{"sql": "SELECT * FROM users WHERE org = $1", "args": []interface{}{int64(7)}})
+	if err != nil {
+		t.Fatalf("query: %v", err)
+	}
+	want := "| id | name | note |\n| --- | --- | --- |\n| 1 | alice | NULL |\n| 2 | b\\|ob | multi line |\n| 3 | carol | 0xff00 |\n(3 rows)"
+	if res.Output != want {
+		t.Fatalf("markdown output:\n%s\nwant:\n%s", res.Output, want)
+	}
+	if len(srv.readOnly) != 1 || !srv.readOnly[0] {
+		t.Fatalf("read-only statements should run in a read-only transaction: %v", srv.readOnly)
+	}
+
+	res, err = s.Execute(ctx, map[string]interface{}{"sql": "SELECT * FROM users", "args": []interface{}{int64(7)}, "format": "json", "max_rows": 2})
+	if err != nil {
+		t.Fatalf("json query: %v", err)
+	}
+	if !strings.Contains(res.Output, `"alice"`) || strings.Contains(res.Output, "carol") || !strings.HasSuffix(res.Output, "... (truncated after 2 rows)") {
+		t.Fatalf("unexpected json output %s", res.Output)
+	}
+	data := res.Data.(map[string]interface{})
+	if data["row_count"] != 2 || data["truncated"] != true {
+		t.Fatalf("unexpected data %#v", data)
+	}
+
+	s.manager.conns["analytics"] = SQLConnectionConfig{Name: "analytics", Driver: "toolbuiltin-fake", DSN: dsn, MaxBytes: 40}
+	res, err = s.Execute(ctx, map[string]interface{}{"sql": "SELECT * FROM users", "args": []interface{}{int64(7)}})
+	if err != nil || res.Data.(map[string]interface{})["row_count"] != 1 {
+		t.Fatalf("byte limit not applied: %v %#v", err, res)
+	}
+}
+
+func TestSQLQueryWriteGuard(t *testing.T) {
+	srv, dsn := newFakeSQL(t, func(string, []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		return []string{"id"}, [][]driver.Value{{int64(1)}}, nil
+	})
+	s := newSQLTool(
+		SQLConnectionConfig{Name: "ro", Driver: "toolbuiltin-fake", DSN: dsn},
+		SQLConnectionConfig{Name: "rw", Driver: "toolbuiltin-fake", DSN: dsn, AllowWrites: true},
+	)
+	t.Cleanup(s.manager.Close)
+	ctx := context.Background()
+
+	if _, err := s.Execute(ctx, map[string]interface{}{"sql": "SELECT 1"}); err == nil || !strings.Contains(err.Error(), "connection is required") {
+		t.Fatalf("expected connection error, got %v", err)
+	}
+	if _, err := s.Execute(ctx, map[string]interface{}{"connection": "ro", "sql": "DELETE FROM users"}); err == nil || !strings.Contains(err.Error(), "does not allow writes") {
+		t.Fatalf("expected write refusal, got %v", err)
+	}
+	if len(srv.queries) != 0 {
+		t.Fatalf("refused statements must not reach the database: %v", srv.queries)
+	}
+	res, err := s.Execute(ctx, map[string]interface{}{"connection": "rw", "sql": "DELETE FROM users"})
+	if err != nil || res.Output != "3 rows affected" {
+		t.Fatalf("expected exec, got %v %v", res, err)
+	}
+	res, err = s.Execute(ctx, map[string]interface{}{"connection": "rw", "sql": "INSERT INTO users DEFAULT VALUES RETURNING id"})
+	if err != nil || res.Data.(map[string]interface{})["row_count"] != 1 {
+		t.Fatalf("RETURNING should return rows, got %v %v", res, err)
+	}
+	if !strings.Contains(s.Description(), "ro (toolbuiltin-fake, read-only); rw (toolbuiltin-fake, read-write)") {
+		t.Fatalf("description should list connections: %s", s.Description())
+	}
+}
+
+func TestSQLQueryDescribeSchema(t *testing.T) {
+	_, dsn := newFakeSQL(t, func(query string, _ []driver.NamedValue) ([]string, [][]driver.Value, error) {
+		if !strings.Contains(query, "information_schema.columns") {
+			return nil, nil, fmt.Errorf("unexpected query %q", query)
+		}
+		return []string{"table_schema", "table_name", "column_name", "data_type", "is_nullable"}, [][]driver.Value{
+			{"public", "orders", "id", "bigint", "NO"},
+			{"public", "orders", "total", "numeric", "YES"},
+			{"public", "users", "id", "bigint", "NO"},
+		}, nil
+	})
+	s := newSQLTool(SQLConnectionConfig{Name: "db", Driver: "toolbuiltin-fake", DSN: dsn})
+	t.Cleanup(s.manager.Close)
+
+	res, err := s.Execute(context.Background(), map[string]interface{}{"operation": "describe_schema"})
+	if err != nil {
+		t.Fatalf("describe: %v", err)
+	}
+	want := "public.orders\n  id bigint NOT NULL\n  total numeric\n\npublic.users\n  id bigint NOT NULL"
+	if res.Output != want {
+		t.Fatalf("schema output:\n%s\nwant:\n%s", res.Output, want)
+	}
+	res, err = s.Execute(context.Background(), map[string]interface{}{"operation": "describe_schema", "table": "USERS"})
+	if err != nil || len(res.Data.(map[string]interface{})["tables"].([]sqlTable)) != 1 {
+		t.Fatalf("table filter not applied: %v %v", res, err)
+	}
+}
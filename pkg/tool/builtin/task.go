@@ -0,0 +1,490 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/subagents"
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const (
+	TaskName       = "task"
+	TaskStatusName = "task_status"
+	TaskOutputName = "task_output"
+
+	taskToolDescriptionHeader = `Launch a subagent to handle a complex, multi-step task autonomously.
+
+<task_instructions>
+- Pick subagent_type from <available_subagents>; the subagent starts with a fresh context and only sees your prompt.
+- Write a self-contained prompt: state the goal, relevant paths, and exactly what the subagent should report back.
+- The subagent's final answer is returned as the tool result and is not shown to the user; summarise it yourself.
+- Set run_in_background to true for long-running or independent work that can proceed in parallel, then poll with task_status and collect the result with task_output.
+- Background results are also delivered to the conversation when the task finishes.
+</task_instructions>
+
+<available_subagents>
+`
+	taskStatusDescription = `Reports the state (queued, running, success, error) of a background task started by the task tool.`
+	taskOutputDescription = `Returns the result of a background task started by the task tool.
+Usage:
+- By default waits until the task finishes or timeout seconds elapse (default 30, max 600).
+- Set block to false to return immediately with the current state.`
+
+	defaultTaskOutputWait = 30 * time.Second
+	maxTaskOutputWait     = 10 * time.Minute
+	taskPollInterval      = 50 * time.Millisecond
+)
+
+var taskSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"subagent_type": map[string]interface{}{
+			"type":        "string",
+			"description": "Name of the subagent to run, as listed in <available_subagents>.",
+		},
+		"description": map[string]interface{}{
+			"type":        "string",
+			"description": "Short (3-5 word) label for the task.",
+		},
+		"prompt": map[string]interface{}{
+			"type":        "string",
+			"description": "Full instructions for the subagent.",
+		},
+		"run_in_background": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Start the task asynchronously and return its task_id immediately.",
+		},
+	},
+	Required: []string{"subagent_type", "prompt"},
+}
+
+var taskIDSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"task_id": map[string]interface{}{
+			"type":        "string",
+			"description": "Identifier returned by the task tool.",
+		},
+	},
+	Required: []string{"task_id"},
+}
+
+var taskOutputSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"task_id": map[string]interface{}{
+			"type":        "string",
+			"description": "Identifier returned by the task tool.",
+		},
+		"block": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Wait for the task to finish (default true).",
+		},
+		"timeout": map[string]interface{}{
+			"type":        "number",
+			"description": "Maximum seconds to wait when block is true (default 30, max 600).",
+		},
+	},
+	Required: []string{"task_id"},
+}
+
+// TaskRequest describes a single delegated subagent run.
+type TaskRequest struct {
+	SubagentType string
+	Description  string
+	Prompt       string
+	SessionID    string
+}
+
+// TaskRunner executes subagent tasks on behalf of the task tools. The runtime
+// provides the implementation so the tools stay independent of the agent loop.
+type TaskRunner interface {
+	// Definitions lists the subagents the model may delegate to.
+	Definitions() []subagents.Definition
+	// Run executes the task synchronously and returns the subagent result.
+	Run(ctx context.Context, req TaskRequest) (subagents.Result, error)
+	// Start queues the task in the background and returns its identifier.
+	Start(ctx context.Context, req TaskRequest) (string, error)
+	// Status reports the state of a background task. Status.SessionID must
+	// be the SessionID of the request that started it; the task tools only
+	// answer lookups from that session.
+	Status(taskID string) (subagents.Status, error)
+}
+
+// TaskTool lets the model spawn subagents itself.
+type TaskTool struct {
+	runner TaskRunner
+}
+
+// NewTaskTool wires the tool to runner.
+func NewTaskTool(runner TaskRunner) *TaskTool {
+	return &TaskTool{runner: runner}
+}
+
+func (t *TaskTool) Name() string { return TaskName }
+
+func (t *TaskTool) Description() string {
+	var defs []subagents.Definition
+	if t != nil && t.runner != nil {
+		defs = t.runner.Definitions()
+	}
+	return buildTaskDescription(defs)
+}
+
+func (t *TaskTool) Schema() *tool.JSONSchema { return taskSchema }
+
+func (t *TaskTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if t == nil || t.runner == nil {
+		return nil, errors.New("task runner is not initialised")
+	}
+	req, background, err := parseTaskParams(params)
+	if err != nil {
+		return nil, err
+	}
+	if !t.knownSubagent(req.SubagentType) {
+		return nil, fmt.Errorf("unknown subagent_type %q (available: %s)", req.SubagentType, strings.Join(t.subagentNames(), ", "))
+	}
+	req.SessionID = bashSessionID(ctx)
+
+	if background {
+		taskID, err := t.runner.Start(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		return &tool.ToolResult{
+			Success: true,
+			Output:  fmt.Sprintf("Started background task %s (%s). Use task_status or task_output with this task_id to follow up.", taskID, req.SubagentType),
+			Data: map[string]interface{}{
+				"task_id":       taskID,
+				"subagent_type": req.SubagentType,
+				"state":         string(subagents.StatusQueued),
+			},
+		}, nil
+	}
+
+	res, err := t.runner.Run(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	output := taskResultText(res.Output)
+	if output == "" {
+		output = "(subagent returned no output)"
+	}
+	data := map[string]interface{}{
+		"subagent_type": req.SubagentType,
+	}
+	if res.Subagent != "" {
+		data["subagent"] = res.Subagent
+	}
+	for k, v := range res.Metadata {
+		if _, exists := data[k]; !exists {
+			data[k] = v
+		}
+	}
+	return &tool.ToolResult{Success: true, Output: output, Data: data}, nil
+}
+
+func (t *TaskTool) knownSubagent(name string) bool {
+	for _, def := range t.runner.Definitions() {
+		if strings.EqualFold(strings.TrimSpace(def.Name), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func (t *TaskTool) subagentNames() []string {
+	defs := t.runner.Definitions()
+	names := make([]string, 0, len(defs))
+	for _, def := range defs {
+		if name := strings.TrimSpace(def.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func buildTaskDescription(defs []subagents.Definition) string {
+	var b strings.Builder
+	b.WriteString(taskToolDescriptionHeader)
+	for _, def := range defs {
+		name := strings.TrimSpace(def.Name)
+		if name == "" {
+			continue
+		}
+		description := strings.TrimSpace(def.Description)
+		if description == "" {
+			description = "No description provided."
+		}
+		fmt.Fprintf(&b, "- %s: %s", escapeXML(name), escapeXML(description))
+		if tools := def.BaseContext.ToolWhitelist; len(tools) > 0 {
+			fmt.Fprintf(&b, " (tools: %s)", escapeXML(strings.Join(tools, ", ")))
+		}
+		b.WriteByte('\n')
+	}
+	b.WriteString("</available_subagents>\n")
+	return b.String()
+}
+
+func parseTaskParams(params map[string]interface{}) (TaskRequest, bool, error) {
+	if params == nil {
+		return TaskRequest{}, false, errors.New("params is nil")
+	}
+	var req TaskRequest
+	rawType, ok := params["subagent_type"]
+	if !ok || rawType == nil {
+		return TaskRequest{}, false, errors.New("subagent_type is required")
+	}
+	name, err := coerceString(rawType)
+	if err != nil {
+		return TaskRequest{}, false, fmt.Errorf("subagent_type must be string: %w", err)
+	}
+	req.SubagentType = strings.ToLower(strings.TrimSpace(name))
+	if req.SubagentType == "" {
+		return TaskRequest{}, false, errors.New("subagent_type cannot be empty")
+	}
+
+	rawPrompt, ok := params["prompt"]
+	if !ok || rawPrompt == nil {
+		return TaskRequest{}, false, errors.New("prompt is required")
+	}
+	prompt, err := coerceString(rawPrompt)
+	if err != nil {
+		return TaskRequest{}, false, fmt.Errorf("prompt must be string: %w", err)
+	}
+	req.Prompt = strings.TrimSpace(prompt)
+	if req.Prompt == "" {
+		return TaskRequest{}, false, errors.New("prompt cannot be empty")
+	}
+
+	if raw, ok := params["description"]; ok && raw != nil {
+		desc, err := coerceString(raw)
+		if err != nil {
+			return TaskRequest{}, false, fmt.Errorf("description must be string: %w", err)
+		}
+		req.Description = strings.TrimSpace(desc)
+	}
+
+	background := false
+	if raw, ok := params["run_in_background"]; ok && raw != nil {
+		background, err = coerceBool(raw)
+		if err != nil {
+			return TaskRequest{}, false, fmt.Errorf("run_in_background must be boolean: %w", err)
+		}
+	}
+	return req, background, nil
+}
+
+// TaskStatusTool reports the state of background tasks.
+type TaskStatusTool struct {
+	runner TaskRunner
+}
+
+// NewTaskStatusTool wires the tool to runner.
+func NewTaskStatusTool(runner TaskRunner) *TaskStatusTool {
+	return &TaskStatusTool{runner: runner}
+}
+
+func (t *TaskStatusTool) Name() string { return TaskStatusName }
+
+func (t *TaskStatusTool) Description() string { return taskStatusDescription }
+
+func (t *TaskStatusTool) Schema() *tool.JSONSchema { return taskIDSchema }
+
+func (t *TaskStatusTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsReadOnly: true, IsConcurrencySafe: true}
+}
+
+// Cacheable is false: task state changes without touching the filesystem.
+func (t *TaskStatusTool) Cacheable() bool { return false }
+
+func (t *TaskStatusTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if t == nil || t.runner == nil {
+		return nil, errors.New("task runner is not initialised")
+	}
+	taskID, err := parseTaskID(params)
+	if err != nil {
+		return nil, err
+	}
+	status, err := sessionTaskStatus(ctx, t.runner, taskID)
+	if err != nil {
+		return nil, err
+	}
+	output := fmt.Sprintf("Task %s (%s): %s", status.TaskID, status.Name, status.State)
+	if status.Error != "" {
+		output += "\nError: " + status.Error
+	}
+	return &tool.ToolResult{Success: true, Output: output, Data: taskStatusData(status, false)}, nil
+}
+
+// TaskOutputTool returns the result of background tasks, optionally waiting
+// for completion.
+type TaskOutputTool struct {
+	runner TaskRunner
+}
+
+// NewTaskOutputTool wires the tool to runner.
+func NewTaskOutputTool(runner TaskRunner) *TaskOutputTool {
+	return &TaskOutputTool{runner: runner}
+}
+
+func (t *TaskOutputTool) Name() string { return TaskOutputName }
+
+func (t *TaskOutputTool) Description() string { return taskOutputDescription }
+
+func (t *TaskOutputTool) Schema() *tool.JSONSchema { return taskOutputSchema }
+
+func (t *TaskOutputTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsReadOnly: true, IsConcurrencySafe: true}
+}
+
+// Cacheable is false since the output grows while the task runs.
+func (t *TaskOutputTool) Cacheable() bool { return false }
+
+func (t *TaskOutputTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if t == nil || t.runner == nil {
+		return nil, errors.New("task runner is not initialised")
+	}
+	taskID, err := parseTaskID(params)
+	if err != nil {
+		return nil, err
+	}
+	block := true
+	if raw, ok := params["block"]; ok && raw != nil {
+		if block, err = coerceBool(raw); err != nil {
+			return nil, fmt.Errorf("block must be boolean: %w", err)
+		}
+	}
+	wait := defaultTaskOutputWait
+	if raw, ok := params["timeout"]; ok && raw != nil {
+		if wait, err = durationFromParam(raw); err != nil {
+			return nil, fmt.Errorf("invalid timeout: %w", err)
+		}
+		if wait > maxTaskOutputWait {
+			wait = maxTaskOutputWait
+		}
+	}
+
+	status, err := sessionTaskStatus(ctx, t.runner, taskID)
+	if err != nil {
+		return nil, err
+	}
+	if block && !taskFinished(status) {
+		status, err = t.waitForTask(ctx, taskID, wait)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var output string
+	switch status.State {
+	case subagents.StatusSuccess:
+		output = status.Output
+		if output == "" {
+			output = "(subagent returned no output)"
+		}
+	case subagents.StatusError:
+		output = fmt.Sprintf("Task %s failed: %s", status.TaskID, status.Error)
+	default:
+		output = fmt.Sprintf("Task %s is still %s.", status.TaskID, status.State)
+	}
+	return &tool.ToolResult{
+		Success: status.State != subagents.StatusError,
+		Output:  output,
+		Data:    taskStatusData(status, true),
+	}, nil
+}
+
+func (t *TaskOutputTool) waitForTask(ctx context.Context, taskID string, wait time.Duration) (subagents.Status, error) {
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	ticker := time.NewTicker(taskPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return subagents.Status{}, ctx.Err()
+		case <-timer.C:
+			return sessionTaskStatus(ctx, t.runner, taskID)
+		case <-ticker.C:
+			status, err := sessionTaskStatus(ctx, t.runner, taskID)
+			if err != nil || taskFinished(status) {
+				return status, err
+			}
+		}
+	}
+}
+
+// sessionTaskStatus looks up taskID for the calling session. Tasks started by
+// other sessions are reported as unknown, like background shells.
+func sessionTaskStatus(ctx context.Context, runner TaskRunner, taskID string) (subagents.Status, error) {
+	status, err := runner.Status(taskID)
+	if err != nil {
+		return status, err
+	}
+	if strings.TrimSpace(status.SessionID) != bashSessionID(ctx) {
+		return subagents.Status{}, fmt.Errorf("%w: %s", subagents.ErrUnknownTask, taskID)
+	}
+	return status, nil
+}
+
+func taskFinished(status subagents.Status) bool {
+	return status.State == subagents.StatusSuccess || status.State == subagents.StatusError
+}
+
+func taskStatusData(status subagents.Status, withOutput bool) map[string]interface{} {
+	data := map[string]interface{}{
+		"task_id":       status.TaskID,
+		"subagent_type": status.Name,
+		"state":         string(status.State),
+	}
+	if status.Error != "" {
+		data["error"] = status.Error
+	}
+	if withOutput && status.Output != "" {
+		data["output"] = status.Output
+	}
+	return data
+}
+
+func parseTaskID(params map[string]interface{}) (string, error) {
+	if params == nil {
+		return "", errors.New("params is nil")
+	}
+	raw, ok := params["task_id"]
+	if !ok || raw == nil {
+		return "", errors.New("task_id is required")
+	}
+	id, err := coerceString(raw)
+	if err != nil {
+		return "", fmt.Errorf("task_id must be string: %w", err)
+	}
+	id = strings.TrimSpace(id)
+	if id == "" {
+		return "", errors.New("task_id cannot be empty")
+	}
+	return id, nil
+}
+
+func taskResultText(output any) string {
+	if output == nil {
+		return ""
+	}
+	if text, ok := output.(string); ok {
+		return strings.TrimSpace(text)
+	}
+	return strings.TrimSpace(fmt.Sprint(output))
+}
@@ -0,0 +1,230 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/middleware"
+	"github.com/stellarlinkco/agentsdk-go/pkg/runtime/subagents"
+)
+
+type fakeTaskRunner struct {
+	mu       sync.Mutex
+	defs     []subagents.Definition
+	runs     []TaskRequest
+	started  []TaskRequest
+	statuses map[string][]subagents.Status
+	runErr   error
+}
+
+func (f *fakeTaskRunner) Definitions() []subagents.Definition { return f.defs }
+
+func (f *fakeTaskRunner) Run(_ context.Context, req TaskRequest) (subagents.Result, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.runs = append(f.runs, req)
+	if f.runErr != nil {
+		return subagents.Result{}, f.runErr
+	}
+	return subagents.Result{Subagent: req.SubagentType, Output: "report: " + req.Prompt, Metadata: map[string]any{"session_id": "child"}}, nil
+}
+
+func (f *fakeTaskRunner) Start(_ context.Context, req TaskRequest) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started = append(f.started, req)
+	return "task-1", nil
+}
+
+// Status pops queued snapshots so tests can simulate progress; the last
+// snapshot sticks.
+func (f *fakeTaskRunner) Status(taskID string) (subagents.Status, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	queue := f.statuses[taskID]
+	if len(queue) == 0 {
+		return subagents.Status{}, subagents.ErrUnknownTask
+	}
+	status := queue[0]
+	if len(queue) > 1 {
+		f.statuses[taskID] = queue[1:]
+	}
+	return status, nil
+}
+
+func newFakeTaskRunner() *fakeTaskRunner {
+	return &fakeTaskRunner{
+		defs: []subagents.Definition{
+			{Name: "explore", Description: "Read-only <explorer>", BaseContext: subagents.Context{ToolWhitelist: []string{"glob", "read"}}},
+			{Name: "plan"},
+		},
+		statuses: map[string][]subagents.Status{},
+	}
+}
+
+func TestTaskToolDescriptionListsSubagents(t *testing.T) {
+	desc := NewTaskTool(newFakeTaskRunner()).Description()
+	for _, want := range []string{
+		"- explore: Read-only &lt;explorer&gt; (tools: glob, read)",
+		"- plan: No description provided.",
+		"</available_subagents>",
+	} {
+		if !strings.Contains(desc, want) {
+			t.Fatalf("description missing %q:\n%s", want, desc)
+		}
+	}
+	if got := NewTaskTool(nil).Description(); !strings.Contains(got, "<available_subagents>\n</available_subagents>") {
+		t.Fatalf("nil runner description = %q", got)
+	}
+}
+
+func TestTaskToolRunsSynchronously(t *testing.T) {
+	runner := newFakeTaskRunner()
+	ctx := context.WithValue(context.Background(), middleware.SessionIDContextKey, "parent")
+	res, err := NewTaskTool(runner).Execute(ctx, map[string]interface{}{
+		"subagent_type": "Explore",
+		"description":   "find callers",
+		"prompt":        "list callers of Foo",
+	})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Output != "report: list callers of Foo" {
+		t.Fatalf("unexpected output %q", res.Output)
+	}
+	data := res.Data.(map[string]interface{})
+	if data["subagent"] != "explore" || data["session_id"] != "child" {
+		t.Fatalf("unexpected data %#v", data)
+	}
+	if len(runner.runs) != 1 || runner.runs[0].SessionID != "parent" || runner.runs[0].Description != "find callers" {
+		t.Fatalf("unexpected run request %+v", runner.runs)
+	}
+}
+
+func TestTaskToolStartsInBackground(t *testing.T) {
+	runner := newFakeTaskRunner()
+	res, err := NewTaskTool(runner).Execute(context.Background(), map[string]interface{}{
+		"subagent_type":     "plan",
+		"prompt":            "outline migration",
+		"run_in_background": true,
+	})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !strings.Contains(res.Output, "task-1") || len(runner.started) != 1 || len(runner.runs) != 0 {
+		t.Fatalf("expected background start, got output %q runs=%d started=%d", res.Output, len(runner.runs), len(runner.started))
+	}
+	if res.Data.(map[string]interface{})["task_id"] != "task-1" {
+		t.Fatalf("missing task id: %#v", res.Data)
+	}
+}
+
+func TestTaskToolValidation(t *testing.T) {
+	runner := newFakeTaskRunner()
+	tool := NewTaskTool(runner)
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{name: "missing type", params: map[string]interface{}{"prompt": "x"}, want: "subagent_type is required"},
+		{name: "missing prompt", params: map[string]interface{}{"subagent_type": "plan"}, want: "prompt is required"},
+		{name: "blank prompt", params: map[string]interface{}{"subagent_type": "plan", "prompt": "  "}, want: "prompt cannot be empty"},
+		{name: "unknown type", params: map[string]interface{}{"subagent_type": "ghost", "prompt": "x"}, want: "available: explore, plan"},
+		{name: "bad background", params: map[string]interface{}{"subagent_type": "plan", "prompt": "x", "run_in_background": []int{1}}, want: "run_in_background must be boolean"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tool.Execute(context.Background(), tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+
+	runner.runErr = errors.New("boom")
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"subagent_type": "plan", "prompt": "x"}); err == nil || err.Error() != "boom" {
+		t.Fatalf("expected runner error, got %v", err)
+	}
+	if _, err := NewTaskTool(nil).Execute(context.Background(), map[string]interface{}{}); err == nil {
+		t.Fatal("expected error for nil runner")
+	}
+}
+
+func TestTaskStatusTool(t *testing.T) {
+	runner := newFakeTaskRunner()
+	runner.statuses["task-1"] = []subagents.Status{{TaskID: "task-1", SessionID: "default", Name: "plan", State: subagents.StatusError, Error: "model refused"}}
+	res, err := NewTaskStatusTool(runner).Execute(context.Background(), map[string]interface{}{"task_id": "task-1"})
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if res.Output != "Task task-1 (plan): error\nError: model refused" {
+		t.Fatalf("unexpected output %q", res.Output)
+	}
+	if _, err := NewTaskStatusTool(runner).Execute(context.Background(), map[string]interface{}{"task_id": "missing"}); !errors.Is(err, subagents.ErrUnknownTask) {
+		t.Fatalf("expected ErrUnknownTask, got %v", err)
+	}
+	// Task ids are only visible to the session that started the task.
+	other := context.WithValue(context.Background(), middleware.SessionIDContextKey, "other")
+	if _, err := NewTaskStatusTool(runner).Execute(other, map[string]interface{}{"task_id": "task-1"}); !errors.Is(err, subagents.ErrUnknownTask) {
+		t.Fatalf("expected other sessions to be rejected, got %v", err)
+	}
+	if _, err := NewTaskOutputTool(runner).Execute(other, map[string]interface{}{"task_id": "task-1"}); !errors.Is(err, subagents.ErrUnknownTask) {
+		t.Fatalf("expected other sessions to be rejected, got %v", err)
+	}
+	if _, err := NewTaskStatusTool(runner).Execute(context.Background(), map[string]interface{}{}); err == nil {
+		t.Fatal("expected missing task_id error")
+	}
+	if !NewTaskStatusTool(runner).Metadata().IsReadOnly {
+		t.Fatal("task_status should be read-only")
+	}
+}
+
+func TestTaskOutputToolWaitsForCompletion(t *testing.T) {
+	runner := newFakeTaskRunner()
+	runner.statuses["task-1"] = []subagents.Status{
+		{TaskID: "task-1", SessionID: "default", Name: "plan", State: subagents.StatusQueued},
+		{TaskID: "task-1", SessionID: "default", Name: "plan", State: subagents.StatusRunning},
+		{TaskID: "task-1", SessionID: "default", Name: "plan", State: subagents.StatusSuccess, Output: "final plan"},
+	}
+	res, err := NewTaskOutputTool(runner).Execute(context.Background(), map[string]interface{}{"task_id": "task-1", "timeout": 5})
+	if err != nil {
+		t.Fatalf("output: %v", err)
+	}
+	if !res.Success || res.Output != "final plan" {
+		t.Fatalf("unexpected result %+v", res)
+	}
+}
+
+func TestTaskOutputToolNonBlockingAndErrors(t *testing.T) {
+	runner := newFakeTaskRunner()
+	runner.statuses["task-1"] = []subagents.Status{{TaskID: "task-1", SessionID: "default", State: subagents.StatusRunning}}
+	res, err := NewTaskOutputTool(runner).Execute(context.Background(), map[string]interface{}{"task_id": "task-1", "block": false})
+	if err != nil {
+		t.Fatalf("output: %v", err)
+	}
+	if res.Output != "Task task-1 is still running." {
+		t.Fatalf("unexpected output %q", res.Output)
+	}
+
+	runner.statuses["task-2"] = []subagents.Status{{TaskID: "task-2", SessionID: "default", State: subagents.StatusError, Error: "boom"}}
+	res, err = NewTaskOutputTool(runner).Execute(context.Background(), map[string]interface{}{"task_id": "task-2"})
+	if err != nil {
+		t.Fatalf("output: %v", err)
+	}
+	if res.Success || res.Output != "Task task-2 failed: boom" {
+		t.Fatalf("unexpected failed result %+v", res)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := NewTaskOutputTool(runner).Execute(ctx, map[string]interface{}{"task_id": "task-1"}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context cancellation, got %v", err)
+	}
+	if _, err := NewTaskOutputTool(runner).Execute(context.Background(), map[string]interface{}{"task_id": "task-1", "block": "maybe"}); err == nil {
+		t.Fatal("expected invalid block error")
+	}
+}
@@ -0,0 +1,336 @@
+package toolbuiltin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
+)
+
+const (
+	TodoWriteName = "todo_write"
+	TodoReadName  = "todo_read"
+
+	todoWriteDescription = `Creates or replaces the structured task list for the current session.
+Usage:
+- Use it to plan and track multi-step work; send the full list every time.
+- Each item needs content (imperative, e.g. "Run tests"), activeForm (present continuous, e.g. "Running tests") and status.
+- status is one of pending, in_progress, completed; keep at most one item in_progress.
+- Mark items completed as soon as they are done instead of batching updates.`
+	todoReadDescription = `Returns the current session task list written by todo_write.`
+)
+
+// TodoStatus enumerates the lifecycle states of a todo item.
+type TodoStatus string
+
+const (
+	TodoPending    TodoStatus = "pending"
+	TodoInProgress TodoStatus = "in_progress"
+	TodoCompleted  TodoStatus = "completed"
+)
+
+// TodoItem is a single entry of the session task list.
+type TodoItem struct {
+	Content    string     `json:"content"`
+	Status     TodoStatus `json:"status"`
+	ActiveForm string     `json:"activeForm"`
+}
+
+var todoWriteSchema = &tool.JSONSchema{
+	Type: "object",
+	Properties: map[string]interface{}{
+		"todos": map[string]interface{}{
+			"type":        "array",
+			"description": "The complete, updated task list.",
+			"items": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"content": map[string]interface{}{
+						"type":        "string",
+						"description": "Imperative description of the task.",
+					},
+					"status": map[string]interface{}{
+						"type":        "string",
+						"enum":        []string{string(TodoPending), string(TodoInProgress), string(TodoCompleted)},
+						"description": "Current task status.",
+					},
+					"activeForm": map[string]interface{}{
+						"type":        "string",
+						"description": "Present continuous form shown while the task is in progress.",
+					},
+				},
+				"required": []string{"content", "status", "activeForm"},
+			},
+		},
+	},
+	Required: []string{"todos"},
+}
+
+var todoReadSchema = &tool.JSONSchema{
+	Type:       "object",
+	Properties: map[string]interface{}{},
+}
+
+// TodoStore keeps one task list per session. It is safe for concurrent use.
+type TodoStore struct {
+	mu       sync.RWMutex
+	sessions map[string][]TodoItem
+}
+
+// NewTodoStore builds an empty store.
+func NewTodoStore() *TodoStore {
+	return &TodoStore{sessions: map[string][]TodoItem{}}
+}
+
+// Get returns a copy of the task list for sessionID.
+func (s *TodoStore) Get(sessionID string) []TodoItem {
+	if s == nil {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return cloneTodos(s.sessions[strings.TrimSpace(sessionID)])
+}
+
+// Set replaces the task list for sessionID. An empty list clears the session.
+func (s *TodoStore) Set(sessionID string, items []TodoItem) {
+	if s == nil {
+		return
+	}
+	key := strings.TrimSpace(sessionID)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(items) == 0 {
+		delete(s.sessions, key)
+		return
+	}
+	s.sessions[key] = cloneTodos(items)
+}
+
+// Delete drops all state tracked for sessionID.
+func (s *TodoStore) Delete(sessionID string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, strings.TrimSpace(sessionID))
+}
+
+// TodoWriteTool replaces the session task list.
+type TodoWriteTool struct {
+	store *TodoStore
+}
+
+// NewTodoWriteTool builds a TodoWriteTool backed by store. A nil store gets a
+// private one so the tool stays usable standalone.
+func NewTodoWriteTool(store *TodoStore) *TodoWriteTool {
+	if store == nil {
+		store = NewTodoStore()
+	}
+	return &TodoWriteTool{store: store}
+}
+
+func (t *TodoWriteTool) Name() string { return TodoWriteName }
+
+func (t *TodoWriteTool) Description() string { return todoWriteDescription }
+
+func (t *TodoWriteTool) Schema() *tool.JSONSchema { return todoWriteSchema }
+
+func (t *TodoWriteTool) Metadata() tool.Metadata {
+	return tool.Metadata{}
+}
+
+func (t *TodoWriteTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if t == nil || t.store == nil {
+		return nil, errors.New("todo_write tool is not initialised")
+	}
+	items, err := parseTodoItems(params)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	sessionID := bashSessionID(ctx)
+	t.store.Set(sessionID, items)
+	return &tool.ToolResult{
+		Success: true,
+		Output:  "Todos updated.\n" + FormatTodos(items),
+		Data:    todoResultData(sessionID, items),
+	}, nil
+}
+
+// TodoReadTool returns the session task list.
+type TodoReadTool struct {
+	store *TodoStore
+}
+
+// NewTodoReadTool builds a TodoReadTool backed by store.
+func NewTodoReadTool(store *TodoStore) *TodoReadTool {
+	if store == nil {
+		store = NewTodoStore()
+	}
+	return &TodoReadTool{store: store}
+}
+
+func (t *TodoReadTool) Name() string { return TodoReadName }
+
+func (t *TodoReadTool) Description() string { return todoReadDescription }
+
+func (t *TodoReadTool) Schema() *tool.JSONSchema { return todoReadSchema }
+
+func (t *TodoReadTool) Metadata() tool.Metadata {
+	return tool.Metadata{IsReadOnly: true, IsConcurrencySafe: true}
+}
+
+func (t *TodoReadTool) Execute(ctx context.Context, _ map[string]interface{}) (*tool.ToolResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context is nil")
+	}
+	if t == nil || t.store == nil {
+		return nil, errors.New("todo_read tool is not initialised")
+	}
+	sessionID := bashSessionID(ctx)
+	items := t.store.Get(sessionID)
+	output := FormatTodos(items)
+	if output == "" {
+		output = "No todos for this session."
+	}
+	return &tool.ToolResult{
+		Success: true,
+		Output:  output,
+		Data:    todoResultData(sessionID, items),
+	}, nil
+}
+
+// FormatTodos renders items as a compact checklist. Empty input yields "".
+func FormatTodos(items []TodoItem) string {
+	if len(items) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	for i, item := range items {
+		marker := "[ ]"
+		text := item.Content
+		switch item.Status {
+		case TodoInProgress:
+			marker = "[>]"
+			if strings.TrimSpace(item.ActiveForm) != "" {
+				text = item.ActiveForm
+			}
+		case TodoCompleted:
+			marker = "[x]"
+		}
+		if i > 0 {
+			b.WriteByte('\n')
+		}
+		fmt.Fprintf(&b, "%d. %s %s", i+1, marker, text)
+	}
+	return b.String()
+}
+
+func todoResultData(sessionID string, items []TodoItem) map[string]interface{} {
+	counts := map[TodoStatus]int{}
+	for _, item := range items {
+		counts[item.Status]++
+	}
+	return map[string]interface{}{
+		"session_id":  sessionID,
+		"todos":       cloneTodos(items),
+		"pending":     counts[TodoPending],
+		"in_progress": counts[TodoInProgress],
+		"completed":   counts[TodoCompleted],
+	}
+}
+
+func parseTodoItems(params map[string]interface{}) ([]TodoItem, error) {
+	if params == nil {
+		return nil, errors.New("params is nil")
+	}
+	raw, ok := params["todos"]
+	if !ok || raw == nil {
+		return nil, errors.New("todos is required")
+	}
+	list, ok := raw.([]interface{})
+	if !ok {
+		if typed, ok := raw.([]map[string]interface{}); ok {
+			list = make([]interface{}, len(typed))
+			for i, entry := range typed {
+				list[i] = entry
+			}
+		} else {
+			return nil, fmt.Errorf("todos must be an array, got %T", raw)
+		}
+	}
+	items := make([]TodoItem, 0, len(list))
+	inProgress := 0
+	for idx, entry := range list {
+		obj, ok := entry.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("todos[%d] must be an object", idx)
+		}
+		item, err := parseTodoItem(obj)
+		if err != nil {
+			return nil, fmt.Errorf("todos[%d]: %w", idx, err)
+		}
+		if item.Status == TodoInProgress {
+			inProgress++
+		}
+		items = append(items, item)
+	}
+	if inProgress > 1 {
+		return nil, fmt.Errorf("only one todo may be in_progress at a time (got %d)", inProgress)
+	}
+	return items, nil
+}
+
+func parseTodoItem(obj map[string]interface{}) (TodoItem, error) {
+	content, err := requiredTodoString(obj, "content")
+	if err != nil {
+		return TodoItem{}, err
+	}
+	status, err := requiredTodoString(obj, "status")
+	if err != nil {
+		return TodoItem{}, err
+	}
+	activeForm, err := requiredTodoString(obj, "activeForm")
+	if err != nil {
+		return TodoItem{}, err
+	}
+	switch TodoStatus(status) {
+	case TodoPending, TodoInProgress, TodoCompleted:
+	default:
+		return TodoItem{}, fmt.Errorf("status %q must be one of pending, in_progress, completed", status)
+	}
+	return TodoItem{Content: content, Status: TodoStatus(status), ActiveForm: activeForm}, nil
+}
+
+func requiredTodoString(obj map[string]interface{}, key string) (string, error) {
+	raw, ok := obj[key]
+	if !ok || raw == nil {
+		return "", fmt.Errorf("%s is required", key)
+	}
+	value, err := coerceString(raw)
+	if err != nil {
+		return "", fmt.Errorf("%s must be string: %w", key, err)
+	}
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", fmt.Errorf("%s cannot be empty", key)
+	}
+	return value, nil
+}
+
+func cloneTodos(items []TodoItem) []TodoItem {
+	if len(items) == 0 {
+		return nil
+	}
+	return append([]TodoItem(nil), items...)
+}
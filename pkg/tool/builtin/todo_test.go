@@ -0,0 +1,148 @@
+package toolbuiltin
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/middleware"
+)
+
+func todoParams(items ...map[string]interface{}) map[string]interface{} {
+	list := make([]interface{}, len(items))
+	for i, item := range items {
+		list[i] = item
+	}
+	return map[string]interface{}{"todos": list}
+}
+
+func todoEntry(content, status, active string) map[string]interface{} {
+	return map[string]interface{}{"content": content, "status": status, "activeForm": active}
+}
+
+func TestTodoWriteAndReadRoundTrip(t *testing.T) {
+	store := NewTodoStore()
+	writer := NewTodoWriteTool(store)
+	reader := NewTodoReadTool(store)
+	ctx := context.WithValue(context.Background(), middleware.SessionIDContextKey, "sess-a")
+
+	res, err := writer.Execute(ctx, todoParams(
+		todoEntry("Run tests", "completed", "Running tests"),
+		todoEntry("Fix lint", "in_progress", "Fixing lint"),
+		todoEntry("Open PR", "pending", "Opening PR"),
+	))
+	if err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if !res.Success || !strings.Contains(res.Output, "[>] Fixing lint") {
+		t.Fatalf("unexpected write output: %q", res.Output)
+	}
+	data, ok := res.Data.(map[string]interface{})
+	if !ok || data["in_progress"] != 1 || data["completed"] != 1 || data["pending"] != 1 {
+		t.Fatalf("unexpected write data: %#v", res.Data)
+	}
+
+	read, err := reader.Execute(ctx, nil)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	want := "1. [x] Run tests\n2. [>] Fixing lint\n3. [ ] Open PR"
+	if read.Output != want {
+		t.Fatalf("read output = %q, want %q", read.Output, want)
+	}
+
+	other := context.WithValue(context.Background(), middleware.SessionIDContextKey, "sess-b")
+	empty, err := reader.Execute(other, nil)
+	if err != nil {
+		t.Fatalf("read other: %v", err)
+	}
+	if empty.Output != "No todos for this session." {
+		t.Fatalf("sessions should be isolated, got %q", empty.Output)
+	}
+}
+
+func TestTodoWriteReplacesAndClears(t *testing.T) {
+	store := NewTodoStore()
+	writer := NewTodoWriteTool(store)
+	ctx := context.Background()
+
+	if _, err := writer.Execute(ctx, todoParams(todoEntry("One", "pending", "Doing one"))); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if got := store.Get("default"); len(got) != 1 {
+		t.Fatalf("expected default session list, got %+v", got)
+	}
+	if _, err := writer.Execute(ctx, map[string]interface{}{"todos": []interface{}{}}); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+	if got := store.Get("default"); len(got) != 0 {
+		t.Fatalf("expected cleared list, got %+v", got)
+	}
+}
+
+func TestTodoWriteValidation(t *testing.T) {
+	writer := NewTodoWriteTool(nil)
+	tests := []struct {
+		name   string
+		params map[string]interface{}
+		want   string
+	}{
+		{name: "missing todos", params: map[string]interface{}{}, want: "todos is required"},
+		{name: "not array", params: map[string]interface{}{"todos": "x"}, want: "must be an array"},
+		{name: "not object", params: map[string]interface{}{"todos": []interface{}{"x"}}, want: "todos[0] must be an object"},
+		{name: "missing content", params: todoParams(map[string]interface{}{"status": "pending", "activeForm": "x"}), want: "content is required"},
+		{name: "empty active form", params: todoParams(todoEntry("a", "pending", " ")), want: "activeForm cannot be empty"},
+		{name: "bad status", params: todoParams(todoEntry("a", "done", "Doing a")), want: "must be one of"},
+		{
+			name:   "two in progress",
+			params: todoParams(todoEntry("a", "in_progress", "A"), todoEntry("b", "in_progress", "B")),
+			want:   "only one todo may be in_progress",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := writer.Execute(context.Background(), tt.params)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("expected error containing %q, got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestTodoStoreDeleteAndCopies(t *testing.T) {
+	store := NewTodoStore()
+	items := []TodoItem{{Content: "a", Status: TodoPending, ActiveForm: "A"}}
+	store.Set("s", items)
+	items[0].Content = "mutated"
+	got := store.Get("s")
+	if got[0].Content != "a" {
+		t.Fatalf("store should keep its own copy, got %+v", got)
+	}
+	got[0].Content = "mutated"
+	if store.Get("s")[0].Content != "a" {
+		t.Fatal("Get should return a copy")
+	}
+	store.Delete("s")
+	if len(store.Get("s")) != 0 {
+		t.Fatal("expected session deleted")
+	}
+
+	var nilStore *TodoStore
+	nilStore.Set("s", items)
+	nilStore.Delete("s")
+	if nilStore.Get("s") != nil {
+		t.Fatal("nil store should be inert")
+	}
+}
+
+func TestTodoToolsMetadata(t *testing.T) {
+	if !NewTodoReadTool(nil).Metadata().IsReadOnly {
+		t.Fatal("todo_read should be read-only")
+	}
+	if NewTodoWriteTool(nil).Metadata().IsReadOnly {
+		t.Fatal("todo_write should not be read-only")
+	}
+	if FormatTodos(nil) != "" {
+		t.Fatal("empty list should format to empty string")
+	}
+}
@@ -62,7 +62,7 @@ func (t *ToolSearchTool) Execute(_ context.Context, params map[string]any) (*too
 		payload = append(payload, map[string]any{
 			"name":        impl.Name(),
 			"description": strings.TrimSpace(impl.Description()),
-			"parameters":  toolSearchSchemaMap(impl.Schema()),
+			"parameters":  impl.Schema().Map(),
 		})
 		activated = append(activated, impl.Name())
 	}
@@ -116,20 +116,3 @@ func (t *ToolSearchTool) matches(query string) []tool.Tool {
 	})
 	return matches
 }
-
-func toolSearchSchemaMap(schema *tool.JSONSchema) map[string]any {
-	if schema == nil {
-		return nil
-	}
-	out := map[string]any{}
-	if schema.Type != "" {
-		out["type"] = schema.Type
-	}
-	if len(schema.Properties) > 0 {
-		out["properties"] = schema.Properties
-	}
-	if len(schema.Required) > 0 {
-		out["required"] = append([]string(nil), schema.Required...)
-	}
-	return out
-}
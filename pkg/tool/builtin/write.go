@@ -9,7 +9,8 @@ import (
 	"github.com/stellarlinkco/agentsdk-go/pkg/tool"
 )
 
-const writeDescription = `Writes a file within the configured sandbox (overwrites if it exists).`
+const writeDescription = `Writes a file within the configured sandbox (overwrites if it exists).
+Existing files must be read first; writes are atomic.`
 
 var writeSchema = &tool.JSONSchema{
 	Type: "object",
@@ -56,6 +57,13 @@ func (w *WriteTool) Metadata() tool.Metadata {
 	return tool.Metadata{}
 }
 
+// SetFileState attaches the tracker used to enforce read-before-write.
+func (w *WriteTool) SetFileState(files *FileStateTracker) {
+	if w != nil && w.base != nil {
+		w.base.files = files
+	}
+}
+
 func (w *WriteTool) Execute(ctx context.Context, params map[string]interface{}) (*tool.ToolResult, error) {
 	if ctx == nil {
 		return nil, errors.New("context is nil")
@@ -75,9 +83,13 @@ func (w *WriteTool) Execute(ctx context.Context, params map[string]interface{})
 		return nil, err
 	}
 
+	if err := w.base.checkWrite(ctx, path); err != nil {
+		return nil, err
+	}
 	if err := w.base.writeFile(path, content); err != nil {
 		return nil, err
 	}
+	w.base.recordRead(ctx, path, []byte(content))
 
 	return &tool.ToolResult{
 		Success: true,
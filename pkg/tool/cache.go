@@ -0,0 +1,292 @@
+package tool
+
+import (
+	"container/list"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/model"
+)
+
+const defaultResultCacheEntries = 256
+
+// ResultCache memoises results of cacheable (read-only) tools for an
+// Executor. Entries are keyed by session, tool name and canonical params, and
+// are only valid for the filesystem generation they were produced in. The
+// generation advances whenever a non-read-only tool runs and, once Watch is
+// called, whenever fsnotify reports a change under the watched root. If part
+// of the tree cannot be watched the cache disables itself rather than serve
+// results it cannot invalidate.
+type ResultCache struct {
+	generation atomic.Uint64
+	disabled   atomic.Bool
+
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]*list.Element
+	order      *list.List
+
+	tree        *TreeWatcher
+	ownsTree    bool
+	unsubscribe func()
+}
+
+type resultCacheEntry struct {
+	key        string
+	generation uint64
+	result     ToolResult
+}
+
+// NewResultCache creates a cache holding up to maxEntries results (LRU).
+// Values <= 0 select a default of 256.
+func NewResultCache(maxEntries int) *ResultCache {
+	if maxEntries <= 0 {
+		maxEntries = defaultResultCacheEntries
+	}
+	return &ResultCache{maxEntries: maxEntries, entries: map[string]*list.Element{}, order: list.New()}
+}
+
+// Generation returns the current filesystem generation.
+func (c *ResultCache) Generation() uint64 {
+	if c == nil {
+		return 0
+	}
+	return c.generation.Load()
+}
+
+// Invalidate advances the generation so every cached result becomes stale.
+func (c *ResultCache) Invalidate() {
+	if c != nil {
+		c.generation.Add(1)
+	}
+}
+
+// resultCacheKey canonicalises a call; json.Marshal sorts map keys. The
+// second return is false when params cannot be encoded.
+func resultCacheKey(call Call) (string, bool) {
+	params := call.Params
+	if params == nil {
+		params = map[string]any{}
+	}
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return "", false
+	}
+	key, err := json.Marshal([]string{call.SessionID, call.Name, string(encoded)})
+	if err != nil {
+		return "", false
+	}
+	return string(key), true
+}
+
+// Enabled reports whether the cache serves results. It turns false once a
+// directory below the watched root cannot be watched.
+func (c *ResultCache) Enabled() bool {
+	return c != nil && !c.disabled.Load()
+}
+
+func (c *ResultCache) get(key string, generation uint64) (*ToolResult, bool) {
+	if c.disabled.Load() {
+		return nil, false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*resultCacheEntry)
+	if entry.generation != generation {
+		c.order.Remove(elem)
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return cloneToolResult(&entry.result), true
+}
+
+func (c *ResultCache) put(key string, generation uint64, res *ToolResult) {
+	if res == nil || c.disabled.Load() {
+		return
+	}
+	// The caller keeps res; later changes to it must not reach the cache.
+	stored := cloneToolResult(res)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if generation != c.generation.Load() {
+		return // produced against a filesystem that has since changed
+	}
+	if elem, ok := c.entries[key]; ok {
+		elem.Value = &resultCacheEntry{key: key, generation: generation, result: *stored}
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&resultCacheEntry{key: key, generation: generation, result: *stored})
+	for c.order.Len() > c.maxEntries {
+		oldest := c.order.Back()
+		c.order.Remove(oldest)
+		delete(c.entries, oldest.Value.(*resultCacheEntry).key)
+	}
+}
+
+// Len reports the number of stored entries, including stale ones not yet
+// evicted.
+func (c *ResultCache) Len() int {
+	if c == nil {
+		return 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Watch invalidates the cache whenever a change is reported below root,
+// catching edits made outside the agent's tools. See TreeWatcher for the
+// directories that are skipped. Call Close to stop watching.
+func (c *ResultCache) Watch(root string) error {
+	if c == nil {
+		return errors.New("result cache is nil")
+	}
+	tree, err := NewTreeWatcher(root)
+	if err != nil {
+		return err
+	}
+	if err := c.follow(tree, true); err != nil {
+		if errors.Is(err, ErrTreeUnwatched) {
+			return err // the cache stays attached but disabled
+		}
+		_ = tree.Close()
+		return err
+	}
+	return nil
+}
+
+// WatchTree is Watch on a TreeWatcher shared with other consumers; Close
+// detaches from it without closing it.
+func (c *ResultCache) WatchTree(tree *TreeWatcher) error {
+	if c == nil {
+		return errors.New("result cache is nil")
+	}
+	if tree == nil {
+		return errors.New("tree watcher is nil")
+	}
+	return c.follow(tree, false)
+}
+
+func (c *ResultCache) follow(tree *TreeWatcher, owns bool) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tree != nil {
+		return errors.New("result cache is already watching")
+	}
+	c.tree, c.ownsTree = tree, owns
+	c.unsubscribe = tree.Subscribe(func(event TreeEvent) {
+		if errors.Is(event.Err, ErrTreeUnwatched) {
+			c.disabled.Store(true)
+		}
+		// Dropped events (e.g. queue overflow) may hide changes.
+		c.Invalidate()
+	})
+	if err := tree.Err(); err != nil {
+		c.disabled.Store(true)
+		return fmt.Errorf("result cache disabled: %w", err)
+	}
+	return nil
+}
+
+// Close stops watching, closing the watcher if Watch created it.
+func (c *ResultCache) Close() error {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	tree, owns, unsubscribe := c.tree, c.ownsTree, c.unsubscribe
+	c.tree, c.ownsTree, c.unsubscribe = nil, false, nil
+	c.mu.Unlock()
+	if tree == nil {
+		return nil
+	}
+	unsubscribe()
+	if owns {
+		return tree.Close()
+	}
+	return nil
+}
+
+// cloneToolResult copies res deeply enough that neither copy can change the
+// other through Data, ContentBlocks or OutputRef.
+func cloneToolResult(res *ToolResult) *ToolResult {
+	out := *res
+	if res.OutputRef != nil {
+		ref := *res.OutputRef
+		out.OutputRef = &ref
+	}
+	if res.ContentBlocks != nil {
+		out.ContentBlocks = append([]model.ContentBlock(nil), res.ContentBlocks...)
+	}
+	if res.Data != nil {
+		out.Data = deepCopy(reflect.ValueOf(res.Data)).Interface()
+	}
+	return &out
+}
+
+// deepCopy copies maps, slices, arrays, pointers and the exported fields of
+// structs reachable from v. Other values are shared.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(deepCopy(v.Elem()))
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(deepCopy(v.Elem()))
+		return out
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,216 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// readFileTool returns a file's contents and reports itself read-only.
+type readFileTool struct {
+	name  string
+	calls int32
+}
+
+func (r *readFileTool) Name() string        { return r.name }
+func (r *readFileTool) Description() string { return "read" }
+func (r *readFileTool) Schema() *JSONSchema { return nil }
+func (r *readFileTool) Metadata() Metadata  { return Metadata{IsReadOnly: true} }
+func (r *readFileTool) Execute(_ context.Context, params map[string]interface{}) (*ToolResult, error) {
+	atomic.AddInt32(&r.calls, 1)
+	data, err := os.ReadFile(params["path"].(string))
+	if err != nil {
+		return nil, err
+	}
+	return &ToolResult{Success: true, Output: string(data)}, nil
+}
+
+type uncacheableReadTool struct{ readFileTool }
+
+func (u *uncacheableReadTool) Cacheable() bool { return false }
+
+func newCachingExecutor(t *testing.T, tools ...Tool) (*Executor, *ResultCache) {
+	t.Helper()
+	reg := NewRegistry()
+	for _, impl := range tools {
+		if err := reg.Register(impl); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	cache := NewResultCache(0)
+	t.Cleanup(func() { _ = cache.Close() })
+	return NewExecutor(reg, nil).WithResultCache(cache), cache
+}
+
+func TestExecutorResultCache(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("v1"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	reader := &readFileTool{name: "read"}
+	writer := &stubTool{name: "write"}
+	exec, _ := newCachingExecutor(t, reader, writer)
+	ctx := context.Background()
+
+	call := Call{Name: "read", Params: map[string]any{"path": path, "opts": map[string]any{"b": 1, "a": 2}}, SessionID: "s"}
+	first, err := exec.Execute(ctx, call)
+	if err != nil || first.Cached {
+		t.Fatalf("first call should run the tool: %v %+v", err, first)
+	}
+	// Same params in a different map order hit the cache.
+	second, err := exec.Execute(ctx, Call{Name: "read", Params: map[string]any{"opts": map[string]any{"a": 2, "b": 1}, "path": path}, SessionID: "s"})
+	if err != nil || !second.Cached || second.Result.Output != "v1" || atomic.LoadInt32(&reader.calls) != 1 {
+		t.Fatalf("expected cache hit, got %+v (calls=%d)", second, reader.calls)
+	}
+	if res, _ := exec.Execute(ctx, Call{Name: "read", Params: call.Params, SessionID: "other"}); res.Cached {
+		t.Fatal("sessions must not share cached results")
+	}
+
+	// A non-read-only tool invalidates everything.
+	if err := os.WriteFile(path, []byte("v2"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := exec.Execute(ctx, Call{Name: "write"}); err != nil {
+		t.Fatalf("write tool: %v", err)
+	}
+	third, err := exec.Execute(ctx, call)
+	if err != nil || third.Cached || third.Result.Output != "v2" {
+		t.Fatalf("expected fresh result after write, got %+v", third)
+	}
+
+	// Errors are not cached.
+	missing := Call{Name: "read", Params: map[string]any{"path": filepath.Join(dir, "missing")}}
+	for i := 0; i < 2; i++ {
+		if res, err := exec.Execute(ctx, missing); err == nil || res.Cached {
+			t.Fatalf("expected uncached error, got %+v %v", res, err)
+		}
+	}
+}
+
+func TestExecutorResultCacheSkipsUncacheableTools(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("x"), 0o600); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	status := &uncacheableReadTool{readFileTool{name: "task_status"}}
+	plain := &stubTool{name: "plain"}
+	exec, cache := newCachingExecutor(t, status, plain)
+	for i := 0; i < 2; i++ {
+		if res, err := exec.Execute(context.Background(), Call{Name: "task_status", Params: map[string]any{"path": path}}); err != nil || res.Cached {
+			t.Fatalf("opted-out tool must not be cached: %+v %v", res, err)
+		}
+	}
+	if cache.Len() != 0 {
+		t.Fatalf("expected no cache entries, got %d", cache.Len())
+	}
+	gen := cache.Generation()
+	if _, err := exec.Execute(context.Background(), Call{Name: "plain"}); err != nil {
+		t.Fatalf("plain: %v", err)
+	}
+	if cache.Generation() == gen {
+		t.Fatal("tools without read-only metadata should invalidate the cache")
+	}
+}
+
+func TestResultCacheWatchInvalidates(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	cache := NewResultCache(2)
+	if err := cache.Watch(dir); err != nil {
+		t.Fatalf("watch: %v", err)
+	}
+	t.Cleanup(func() { _ = cache.Close() })
+
+	waitForBump := func(change func()) {
+		t.Helper()
+		gen := cache.Generation()
+		change()
+		deadline := time.Now().Add(5 * time.Second)
+		for cache.Generation() == gen {
+			if time.Now().After(deadline) {
+				t.Fatal("watcher did not invalidate the cache")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	waitForBump(func() { _ = os.WriteFile(filepath.Join(dir, "sub", "f.txt"), []byte("x"), 0o600) })
+	waitForBump(func() { _ = os.Mkdir(filepath.Join(dir, "new"), 0o755) })
+	waitForBump(func() { _ = os.WriteFile(filepath.Join(dir, "new", "g.txt"), []byte("x"), 0o600) })
+
+	// LRU bound.
+	gen := cache.Generation()
+	for _, key := range []string{"a", "b", "c"} {
+		cache.put(key, gen, &ToolResult{Output: key})
+	}
+	if _, ok := cache.get("a", gen); ok || cache.Len() != 2 {
+		t.Fatalf("oldest entry should be evicted, len=%d", cache.Len())
+	}
+
+	if err := cache.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+}
+
+func TestResultCacheCopiesData(t *testing.T) {
+	cache := NewResultCache(0)
+	gen := cache.Generation()
+	original := &ToolResult{Output: "x", Data: map[string]interface{}{"items": []string{"a"}, "meta": map[string]interface{}{"n": 1}}}
+	cache.put("k", gen, original)
+	original.Data.(map[string]interface{})["items"].([]string)[0] = "changed"
+
+	hit, ok := cache.get("k", gen)
+	if !ok {
+		t.Fatal("expected cache hit")
+	}
+	data := hit.Data.(map[string]interface{})
+	if data["items"].([]string)[0] != "a" {
+		t.Fatal("mutating the stored result changed the cache")
+	}
+	data["meta"].(map[string]interface{})["n"] = 2
+	again, _ := cache.get("k", gen)
+	if again.Data.(map[string]interface{})["meta"].(map[string]interface{})["n"] != 1 {
+		t.Fatal("mutating a cache hit changed the cache")
+	}
+}
+
+func TestResultCacheDisabledByUnwatchedDirectory(t *testing.T) {
+	tree, err := NewTreeWatcher(t.TempDir())
+	if err != nil {
+		t.Fatalf("tree watcher: %v", err)
+	}
+	t.Cleanup(func() { _ = tree.Close() })
+	cache := NewResultCache(0)
+	if err := cache.WatchTree(tree); err != nil {
+		t.Fatalf("watch tree: %v", err)
+	}
+	gen := cache.Generation()
+	cache.put("k", gen, &ToolResult{Output: "x"})
+	if _, ok := cache.get("k", gen); !ok {
+		t.Fatal("expected cache hit")
+	}
+
+	tree.fail(fmt.Errorf("%w: %s", ErrTreeUnwatched, "big/dir"))
+	if cache.Enabled() {
+		t.Fatal("an unwatched directory must disable the cache")
+	}
+	gen = cache.Generation()
+	cache.put("k", gen, &ToolResult{Output: "x"})
+	if _, ok := cache.get("k", gen); ok {
+		t.Fatal("a disabled cache must not serve results")
+	}
+	if err := cache.Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+	if err := tree.Err(); !errors.Is(err, ErrTreeUnwatched) {
+		t.Fatalf("expected the shared watcher to stay open and report the failure, got %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package tool
 import (
 	"context"
 	"errors"
+	"maps"
 	"strconv"
 	"strings"
 	"sync"
@@ -17,7 +18,10 @@ type Executor struct {
 	registry      *Registry
 	sandbox       *sandbox.Manager
 	persister     *OutputPersister
+	cache         *ResultCache
+	policies      map[string]PolicyOverride
 	maxOutputSize int
+	repairParams  bool
 }
 
 const defaultMaxToolOutputSize = 100 * 1024
@@ -64,16 +68,54 @@ func (e *Executor) Execute(ctx context.Context, call Call) (*CallResult, error)
 		return nil, err
 	}
 
-	params := call.cloneParams()
 	started := time.Now()
+	params := call.cloneParams()
+	if e.repairParams {
+		var invalid *ToolResult
+		if params, invalid = e.registry.prepareParams(tool, params); invalid != nil {
+			return &CallResult{Call: call, Result: invalid, StartedAt: started, CompletedAt: time.Now()}, nil
+		}
+	}
+	streamingTool, streaming := tool.(StreamingTool)
+	streaming = streaming && call.StreamSink != nil
+
 	var (
-		res     *ToolResult
-		execErr error
+		cacheKey   string
+		generation uint64
+		cacheable  = e.cache != nil && !streaming && IsCacheable(tool)
 	)
-	if streamingTool, ok := tool.(StreamingTool); ok && call.StreamSink != nil {
-		res, execErr = streamingTool.StreamExecute(ctx, params, call.StreamSink)
-	} else {
-		res, execErr = tool.Execute(ctx, params)
+	if cacheable {
+		cacheKey, cacheable = resultCacheKey(Call{Name: tool.Name(), Params: params, SessionID: call.SessionID})
+		generation = e.cache.Generation()
+	}
+	if cacheable {
+		if res, ok := e.cache.get(cacheKey, generation); ok {
+			return &CallResult{Call: call, Result: res, Cached: true, StartedAt: started, CompletedAt: time.Now()}, nil
+		}
+	}
+
+	policy := e.policyFor(tool)
+	run := func(ctx context.Context) (*ToolResult, error) {
+		if streaming {
+			return streamingTool.StreamExecute(ctx, params, call.StreamSink)
+		}
+		// Each attempt gets fresh params in case the tool mutated them.
+		return tool.Execute(ctx, cloneValue(params).(map[string]any))
+	}
+	var (
+		res      *ToolResult
+		execErr  error
+		attempts int
+	)
+	for {
+		attempts++
+		res, execErr = runAttempt(ctx, tool.Name(), policy.Timeout, run)
+		if execErr == nil || attempts > policy.MaxRetries || !e.shouldRetry(tool, policy, streaming, execErr) {
+			break
+		}
+		if err := sleepContext(ctx, policy.retryDelay(attempts)); err != nil {
+			break
+		}
 	}
 	if e.persister != nil && res != nil {
 		// MaybePersist errors are logged internally; ignore return value
@@ -82,16 +124,44 @@ func (e *Executor) Execute(ctx context.Context, call Call) (*CallResult, error)
 	if res != nil {
 		applyOutputLimit(tool, res, e.maxOutputSize)
 	}
+	switch {
+	case cacheable && execErr == nil:
+		e.cache.put(cacheKey, generation, res)
+	case e.cache != nil && !MetadataOf(tool).IsReadOnly:
+		// Anything that is not read-only may have changed the files that
+		// cached results were computed from.
+		e.cache.Invalidate()
+	}
 	cr := &CallResult{
 		Call:        call,
 		Result:      res,
 		Err:         execErr,
+		Attempts:    attempts,
 		StartedAt:   started,
 		CompletedAt: time.Now(),
 	}
 	return cr, execErr
 }
 
+// policyFor returns the tool's declared Policy with any override configured
+// for its name applied.
+func (e *Executor) policyFor(tool Tool) Policy {
+	policy := PolicyOf(tool)
+	if override, ok := e.policies[tool.Name()]; ok {
+		policy = override.Apply(policy)
+	}
+	return policy
+}
+
+// shouldRetry reports whether a failed attempt may be repeated. Streaming
+// calls are never retried because their output has already been emitted.
+func (e *Executor) shouldRetry(tool Tool, policy Policy, streaming bool, err error) bool {
+	if streaming || !IsRetryable(err) {
+		return false
+	}
+	return policy.Idempotent || MetadataOf(tool).IsReadOnly
+}
+
 // ExecuteAll runs the provided calls concurrently and preserves ordering in the
 // returned slice. Each call is isolated with its own parameter copy. Execution
 // stops early when the context is cancelled; tools observe ctx directly.
@@ -144,6 +214,47 @@ func (e *Executor) WithOutputPersister(persister *OutputPersister) *Executor {
 	return &clone
 }
 
+// WithResultCache returns a shallow copy that serves repeated calls to
+// cacheable tools from cache. A nil cache disables caching.
+func (e *Executor) WithResultCache(cache *ResultCache) *Executor {
+	if e == nil {
+		exec := NewExecutor(nil, nil)
+		exec.cache = cache
+		return exec
+	}
+	clone := *e
+	clone.cache = cache
+	return &clone
+}
+
+// WithPolicies returns a shallow copy that applies the given overrides, keyed
+// by tool name, on top of each tool's declared Policy.
+func (e *Executor) WithPolicies(overrides map[string]PolicyOverride) *Executor {
+	if e == nil {
+		exec := NewExecutor(nil, nil)
+		exec.policies = maps.Clone(overrides)
+		return exec
+	}
+	clone := *e
+	clone.policies = maps.Clone(overrides)
+	return &clone
+}
+
+// WithParamRepair returns a shallow copy that repairs and validates params
+// against the tool schema before running it, as Registry.Execute does.
+// Invalid params yield a failed result with an invalid_arguments payload
+// instead of running the tool.
+func (e *Executor) WithParamRepair(enabled bool) *Executor {
+	if e == nil {
+		exec := NewExecutor(nil, nil)
+		exec.repairParams = enabled
+		return exec
+	}
+	clone := *e
+	clone.repairParams = enabled
+	return &clone
+}
+
 func (e *Executor) WithMaxOutputSize(limit int) *Executor {
 	if e == nil {
 		exec := NewExecutor(nil, nil)
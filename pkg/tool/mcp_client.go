@@ -0,0 +1,135 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+)
+
+// MCPClientHandlers answers requests that MCP servers send to the client.
+// Nil handlers leave the matching capability unadvertised. The server
+// argument is the name the server was registered under (its spec when
+// unnamed). When the request arrives while a tool of that server is running,
+// ctx is that tool call's context, so values such as stream emitters are
+// available to the handler.
+type MCPClientHandlers struct {
+	CreateMessage func(ctx context.Context, server string, params *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error)
+	Elicit        func(ctx context.Context, server string, params *mcp.ElicitParams) (*mcp.ElicitResult, error)
+	// Roots are advertised to servers through roots/list.
+	Roots []*mcp.Root
+}
+
+// SetMCPClientHandlers configures the handlers used by MCP servers connected
+// afterwards. Servers that are already connected keep their capabilities.
+func (r *Registry) SetMCPClientHandlers(h MCPClientHandlers) {
+	if r == nil {
+		return
+	}
+	h.Roots = append([]*mcp.Root(nil), h.Roots...)
+	r.mu.Lock()
+	r.clientHandlers = h
+	r.mu.Unlock()
+}
+
+type mcpClientHooksKey struct{}
+
+type mcpClientHooks struct {
+	registry *Registry
+	server   string
+}
+
+// withMCPClientHooks lets connectMCPClientWithOptions wire the registry's
+// notification and request handlers into the new client without widening
+// the newMCPClient hooks.
+func withMCPClientHooks(ctx context.Context, r *Registry, server string) context.Context {
+	return context.WithValue(ctx, mcpClientHooksKey{}, mcpClientHooks{registry: r, server: server})
+}
+
+// applyMCPClientHooks fills opts from the registry carried by ctx and returns
+// the roots the client should advertise.
+func applyMCPClientHooks(ctx context.Context, opts *mcp.ClientOptions) []*mcp.Root {
+	hooks, ok := ctx.Value(mcpClientHooksKey{}).(mcpClientHooks)
+	if !ok || hooks.registry == nil {
+		return nil
+	}
+	r, server := hooks.registry, hooks.server
+	opts.ResourceListChangedHandler = func(context.Context, *mcp.ResourceListChangedRequest) {
+		r.notifyMCPResourceChange(server, "")
+	}
+	opts.ResourceUpdatedHandler = func(_ context.Context, req *mcp.ResourceUpdatedRequest) {
+		if req != nil && req.Params != nil {
+			r.notifyMCPResourceChange(server, req.Params.URI)
+		}
+	}
+
+	r.mu.RLock()
+	handlers := r.clientHandlers
+	r.mu.RUnlock()
+	if handlers.CreateMessage != nil {
+		opts.CreateMessageHandler = func(ctx context.Context, req *mcp.CreateMessageRequest) (*mcp.CreateMessageResult, error) {
+			if req == nil || req.Params == nil {
+				return nil, errors.New("sampling request is empty")
+			}
+			return handlers.CreateMessage(mcpCallContext(ctx, req.Session), server, req.Params)
+		}
+	}
+	if handlers.Elicit != nil {
+		opts.ElicitationHandler = func(ctx context.Context, req *mcp.ElicitRequest) (*mcp.ElicitResult, error) {
+			if req == nil || req.Params == nil {
+				return nil, errors.New("elicitation request is empty")
+			}
+			return handlers.Elicit(mcpCallContext(ctx, req.Session), server, req.Params)
+		}
+	}
+	return handlers.Roots
+}
+
+type mcpCall struct {
+	ctx context.Context
+}
+
+// mcpCalls records the tool calls in flight per session so requests a server
+// sends while serving a call can run under that call's context.
+var mcpCalls = struct {
+	sync.Mutex
+	bySession map[*mcp.ClientSession][]*mcpCall
+}{bySession: map[*mcp.ClientSession][]*mcpCall{}}
+
+func trackMCPCall(session *mcp.ClientSession, ctx context.Context) func() {
+	if session == nil {
+		return func() {}
+	}
+	call := &mcpCall{ctx: ctx}
+	mcpCalls.Lock()
+	mcpCalls.bySession[session] = append(mcpCalls.bySession[session], call)
+	mcpCalls.Unlock()
+	return func() {
+		mcpCalls.Lock()
+		defer mcpCalls.Unlock()
+		calls := mcpCalls.bySession[session]
+		for i, c := range calls {
+			if c == call {
+				calls = append(calls[:i], calls[i+1:]...)
+				break
+			}
+		}
+		if len(calls) == 0 {
+			delete(mcpCalls.bySession, session)
+			return
+		}
+		mcpCalls.bySession[session] = calls
+	}
+}
+
+// mcpCallContext returns the context of the most recent tool call in flight on
+// session, or fallback when there is none.
+func mcpCallContext(fallback context.Context, session *mcp.ClientSession) context.Context {
+	mcpCalls.Lock()
+	defer mcpCalls.Unlock()
+	if calls := mcpCalls.bySession[session]; len(calls) > 0 {
+		return calls[len(calls)-1].ctx
+	}
+	return fallback
+}
@@ -0,0 +1,87 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+)
+
+type callMarkerKey struct{}
+
+func TestRegistryMCPClientHandlers(t *testing.T) {
+	server := mcpsdk.NewServer(&mcpsdk.Implementation{Name: "asker", Version: "1"}, nil)
+	server.AddTool(&mcpsdk.Tool{Name: "ask", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcpsdk.CallToolRequest) (*mcpsdk.CallToolResult, error) {
+		roots, err := req.Session.ListRoots(ctx, nil)
+		if err != nil {
+			return nil, err
+		}
+		msg, err := req.Session.CreateMessage(ctx, &mcpsdk.CreateMessageParams{
+			MaxTokens: 10,
+			Messages:  []*mcpsdk.SamplingMessage{{Role: "user", Content: &mcpsdk.TextContent{Text: "hi"}}},
+		})
+		if err != nil {
+			return nil, err
+		}
+		answer, err := req.Session.Elicit(ctx, &mcpsdk.ElicitParams{Message: "name?", RequestedSchema: map[string]any{"type": "object"}})
+		if err != nil {
+			return nil, err
+		}
+		text := roots.Roots[0].URI + " " + msg.Content.(*mcpsdk.TextContent).Text + " " + answer.Action
+		return &mcpsdk.CallToolResult{Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: text}}}, nil
+	})
+
+	r := NewRegistry()
+	defer r.Close()
+	var servers []string
+	r.SetMCPClientHandlers(MCPClientHandlers{
+		CreateMessage: func(ctx context.Context, server string, params *mcp.CreateMessageParams) (*mcp.CreateMessageResult, error) {
+			servers = append(servers, server)
+			if ctx.Value(callMarkerKey{}) != "call" {
+				return nil, errors.New("sampling should run under the tool call context")
+			}
+			return &mcp.CreateMessageResult{Role: "assistant", Model: "m", Content: &mcp.TextContent{Text: "sampled"}}, nil
+		},
+		Elicit: func(_ context.Context, server string, params *mcp.ElicitParams) (*mcp.ElicitResult, error) {
+			servers = append(servers, server)
+			return &mcp.ElicitResult{Action: "decline"}, nil
+		},
+		Roots: []*mcp.Root{{URI: "file:///work", Name: "work"}},
+	})
+	registerResourceServer(t, r, server)
+
+	res, err := r.Execute(context.WithValue(context.Background(), callMarkerKey{}, "call"), "docs__ask", map[string]interface{}{})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if res.Output != "file:///work sampled decline" {
+		t.Fatalf("unexpected output %q", res.Output)
+	}
+	if len(servers) != 2 || servers[0] != "docs" || servers[1] != "docs" {
+		t.Fatalf("handlers should see the server name, got %v", servers)
+	}
+	mcpCalls.Lock()
+	pending := len(mcpCalls.bySession)
+	mcpCalls.Unlock()
+	if pending != 0 {
+		t.Fatalf("finished calls should be released, %d sessions tracked", pending)
+	}
+}
+
+func TestRegistryMCPClientWithoutHandlers(t *testing.T) {
+	server := mcpsdk.NewServer(&mcpsdk.Implementation{Name: "asker", Version: "1"}, nil)
+	server.AddTool(&mcpsdk.Tool{Name: "ask", InputSchema: map[string]any{"type": "object"}}, func(ctx context.Context, req *mcpsdk.CallToolRequest) (*mcpsdk.CallToolResult, error) {
+		if caps := req.Session.InitializeParams().Capabilities; caps.Sampling != nil || caps.Elicitation != nil {
+			return nil, errors.New("capabilities advertised without handlers")
+		}
+		return &mcpsdk.CallToolResult{Content: []mcpsdk.Content{&mcpsdk.TextContent{Text: "ok"}}}, nil
+	})
+	r := NewRegistry()
+	defer r.Close()
+	registerResourceServer(t, r, server)
+	if res, err := r.Execute(context.Background(), "docs__ask", map[string]interface{}{}); err != nil || res.Output != "ok" {
+		t.Fatalf("unexpected result %+v %v", res, err)
+	}
+}
@@ -0,0 +1,246 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+)
+
+// MCPResource describes a resource advertised by a connected MCP server.
+type MCPResource struct {
+	Server      string `json:"server"`
+	URI         string `json:"uri"`
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	MIMEType    string `json:"mimeType,omitempty"`
+}
+
+// MCPResourceEvent reports a resource change pushed by an MCP server. URI is
+// empty when the server's resource list changed and set when a subscribed
+// resource was updated.
+type MCPResourceEvent struct {
+	Server string
+	URI    string
+}
+
+// MCPPrompt describes a prompt template advertised by a connected MCP server.
+type MCPPrompt struct {
+	Server      string
+	Name        string
+	Description string
+	Arguments   []*mcp.PromptArgument
+}
+
+// OnMCPResourceChange registers fn for resource notifications from every
+// MCP server connected to the registry, including ones connected later.
+func (r *Registry) OnMCPResourceChange(fn func(MCPResourceEvent)) {
+	if r == nil || fn == nil {
+		return
+	}
+	r.mu.Lock()
+	r.resourceListeners = append(r.resourceListeners, fn)
+	r.mu.Unlock()
+}
+
+// ListMCPResources lists the resources of server, or of every connected server
+// that supports resources when server is empty.
+func (r *Registry) ListMCPResources(ctx context.Context, server string) ([]MCPResource, error) {
+	infos, err := r.mcpSessionsFor(server, func(caps *mcp.ServerCapabilities) bool { return caps.Resources != nil }, "resources")
+	if err != nil {
+		return nil, err
+	}
+	ctx = nonNilContext(ctx)
+	var (
+		out  []MCPResource
+		errs []error
+	)
+	for _, info := range infos {
+		label := info.label()
+		for res, iterErr := range info.session.Resources(ctx, nil) {
+			if iterErr != nil {
+				errs = append(errs, fmt.Errorf("list MCP resources of %s: %w", label, iterErr))
+				break
+			}
+			if res == nil {
+				continue
+			}
+			out = append(out, MCPResource{
+				Server:      label,
+				URI:         res.URI,
+				Name:        res.Name,
+				Description: res.Description,
+				MIMEType:    res.MIMEType,
+			})
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// ReadMCPResource reads uri from server. server may be empty when exactly one
+// connected server supports resources. When the server supports
+// subscriptions the resource is subscribed so later updates reach
+// OnMCPResourceChange listeners.
+func (r *Registry) ReadMCPResource(ctx context.Context, server, uri string) (*mcp.ReadResourceResult, error) {
+	uri = strings.TrimSpace(uri)
+	if uri == "" {
+		return nil, errors.New("resource uri is empty")
+	}
+	infos, err := r.mcpSessionsFor(server, func(caps *mcp.ServerCapabilities) bool { return caps.Resources != nil }, "resources")
+	if err != nil {
+		return nil, err
+	}
+	switch len(infos) {
+	case 0:
+		return nil, errors.New("no connected MCP server supports resources")
+	case 1:
+	default:
+		return nil, fmt.Errorf("%d MCP servers expose resources; specify the server", len(infos))
+	}
+	info := infos[0]
+	ctx = nonNilContext(ctx)
+	res, err := info.session.ReadResource(ctx, &mcp.ReadResourceParams{URI: uri})
+	if err != nil {
+		return nil, fmt.Errorf("read MCP resource %s from %s: %w", uri, info.label(), err)
+	}
+	r.subscribeMCPResource(ctx, info, uri)
+	return res, nil
+}
+
+// ListMCPPrompts lists the prompts of every connected server that supports
+// them. Servers that fail to list are reported in the joined error while the
+// remaining prompts are still returned.
+func (r *Registry) ListMCPPrompts(ctx context.Context) ([]MCPPrompt, error) {
+	infos, err := r.mcpSessionsFor("", func(caps *mcp.ServerCapabilities) bool { return caps.Prompts != nil }, "prompts")
+	if err != nil {
+		return nil, err
+	}
+	ctx = nonNilContext(ctx)
+	var (
+		out  []MCPPrompt
+		errs []error
+	)
+	for _, info := range infos {
+		label := info.label()
+		for prompt, iterErr := range info.session.Prompts(ctx, nil) {
+			if iterErr != nil {
+				errs = append(errs, fmt.Errorf("list MCP prompts of %s: %w", label, iterErr))
+				break
+			}
+			if prompt == nil || strings.TrimSpace(prompt.Name) == "" {
+				continue
+			}
+			out = append(out, MCPPrompt{
+				Server:      label,
+				Name:        prompt.Name,
+				Description: prompt.Description,
+				Arguments:   prompt.Arguments,
+			})
+		}
+	}
+	return out, errors.Join(errs...)
+}
+
+// GetMCPPrompt renders prompt name from server with args.
+func (r *Registry) GetMCPPrompt(ctx context.Context, server, name string, args map[string]string) (*mcp.GetPromptResult, error) {
+	if strings.TrimSpace(server) == "" {
+		return nil, errors.New("mcp server is empty")
+	}
+	infos, err := r.mcpSessionsFor(server, func(caps *mcp.ServerCapabilities) bool { return caps.Prompts != nil }, "prompts")
+	if err != nil {
+		return nil, err
+	}
+	res, err := infos[0].session.GetPrompt(nonNilContext(ctx), &mcp.GetPromptParams{Name: name, Arguments: args})
+	if err != nil {
+		return nil, fmt.Errorf("get MCP prompt %s from %s: %w", name, server, err)
+	}
+	return res, nil
+}
+
+// mcpSessionsFor returns the sessions whose capabilities satisfy supports.
+// A named server must exist and support the feature; an empty name selects
+// every supporting session, possibly none.
+func (r *Registry) mcpSessionsFor(server string, supports func(*mcp.ServerCapabilities) bool, feature string) ([]*mcpSessionInfo, error) {
+	if r == nil {
+		return nil, errors.New("registry is nil")
+	}
+	server = strings.TrimSpace(server)
+	r.mu.RLock()
+	sessions := append([]*mcpSessionInfo(nil), r.mcpSessions...)
+	r.mu.RUnlock()
+
+	var out []*mcpSessionInfo
+	for _, info := range sessions {
+		if info == nil || info.session == nil {
+			continue
+		}
+		if server != "" && info.label() != server {
+			continue
+		}
+		initRes := info.session.InitializeResult()
+		if initRes == nil || initRes.Capabilities == nil || !supports(initRes.Capabilities) {
+			if server != "" {
+				return nil, fmt.Errorf("MCP server %s does not support %s", server, feature)
+			}
+			continue
+		}
+		out = append(out, info)
+	}
+	if server != "" && len(out) == 0 {
+		return nil, fmt.Errorf("MCP server %s not connected", server)
+	}
+	return out, nil
+}
+
+func (r *Registry) subscribeMCPResource(ctx context.Context, info *mcpSessionInfo, uri string) {
+	initRes := info.session.InitializeResult()
+	if initRes == nil || initRes.Capabilities == nil || initRes.Capabilities.Resources == nil || !initRes.Capabilities.Resources.Subscribe {
+		return
+	}
+	r.mu.Lock()
+	if _, ok := info.subscribed[uri]; ok {
+		r.mu.Unlock()
+		return
+	}
+	if info.subscribed == nil {
+		info.subscribed = map[string]struct{}{}
+	}
+	info.subscribed[uri] = struct{}{}
+	r.mu.Unlock()
+
+	if err := info.session.Subscribe(ctx, &mcp.SubscribeParams{URI: uri}); err != nil {
+		log.Printf("tool registry: subscribe MCP resource %s: %v", uri, err)
+		r.mu.Lock()
+		delete(info.subscribed, uri)
+		r.mu.Unlock()
+	}
+}
+
+func (r *Registry) notifyMCPResourceChange(server, uri string) {
+	if r == nil {
+		return
+	}
+	r.mu.RLock()
+	listeners := append([](func(MCPResourceEvent))(nil), r.resourceListeners...)
+	r.mu.RUnlock()
+	evt := MCPResourceEvent{Server: server, URI: uri}
+	for _, fn := range listeners {
+		fn(evt)
+	}
+}
+
+func (info *mcpSessionInfo) label() string {
+	return mcpServerLabel(info.serverID, info.serverName)
+}
+
+// mcpServerLabel names a server in events and errors: its registered name,
+// or its spec when unnamed.
+func mcpServerLabel(serverID, serverName string) string {
+	if name := strings.TrimSpace(serverName); name != "" {
+		return name
+	}
+	return strings.TrimSpace(serverID)
+}
@@ -0,0 +1,118 @@
+package tool
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	mcpsdk "github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stellarlinkco/agentsdk-go/pkg/mcp"
+)
+
+func newResourceServer() *mcpsdk.Server {
+	server := mcpsdk.NewServer(&mcpsdk.Implementation{Name: "docs", Version: "1"}, &mcpsdk.ServerOptions{
+		SubscribeHandler:   func(context.Context, *mcpsdk.SubscribeRequest) error { return nil },
+		UnsubscribeHandler: func(context.Context, *mcpsdk.UnsubscribeRequest) error { return nil },
+	})
+	server.AddTool(&mcpsdk.Tool{Name: "noop", InputSchema: map[string]any{"type": "object"}}, func(context.Context, *mcpsdk.CallToolRequest) (*mcpsdk.CallToolResult, error) {
+		return &mcpsdk.CallToolResult{}, nil
+	})
+	server.AddResource(&mcpsdk.Resource{URI: "file:///readme.md", Name: "readme", MIMEType: "text/markdown"}, func(_ context.Context, req *mcpsdk.ReadResourceRequest) (*mcpsdk.ReadResourceResult, error) {
+		return &mcpsdk.ReadResourceResult{Contents: []*mcpsdk.ResourceContents{{URI: req.Params.URI, MIMEType: "text/markdown", Text: "# Docs"}}}, nil
+	})
+	server.AddPrompt(&mcpsdk.Prompt{
+		Name:        "summarize",
+		Description: "Summarize a topic",
+		Arguments:   []*mcpsdk.PromptArgument{{Name: "topic", Required: true}},
+	}, func(_ context.Context, req *mcpsdk.GetPromptRequest) (*mcpsdk.GetPromptResult, error) {
+		return &mcpsdk.GetPromptResult{Messages: []*mcpsdk.PromptMessage{{Role: "user", Content: &mcpsdk.TextContent{Text: "Summarize " + req.Params.Arguments["topic"]}}}}, nil
+	})
+	return server
+}
+
+func registerResourceServer(t *testing.T, r *Registry, server *mcpsdk.Server) {
+	t.Helper()
+	restore := withStubMCPTransport(t, func(context.Context, string) (mcp.Transport, error) {
+		serverTransport, clientTransport := mcpsdk.NewInMemoryTransports()
+		if _, err := server.Connect(context.Background(), serverTransport, nil); err != nil {
+			return nil, err
+		}
+		return clientTransport, nil
+	})
+	defer restore()
+	if err := r.RegisterMCPServer(context.Background(), "stdio://docs", "docs"); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+}
+
+func TestRegistryMCPResources(t *testing.T) {
+	server := newResourceServer()
+	r := NewRegistry()
+	defer r.Close()
+	events := make(chan MCPResourceEvent, 4)
+	r.OnMCPResourceChange(func(evt MCPResourceEvent) { events <- evt })
+	registerResourceServer(t, r, server)
+
+	resources, err := r.ListMCPResources(context.Background(), "")
+	if err != nil || len(resources) != 1 {
+		t.Fatalf("list: %+v %v", resources, err)
+	}
+	if got := resources[0]; got.Server != "docs" || got.URI != "file:///readme.md" || got.MIMEType != "text/markdown" {
+		t.Fatalf("unexpected resource %+v", got)
+	}
+	if _, err := r.ListMCPResources(context.Background(), "other"); err == nil || !strings.Contains(err.Error(), "not connected") {
+		t.Fatalf("unknown server should fail, got %v", err)
+	}
+
+	res, err := r.ReadMCPResource(context.Background(), "", "file:///readme.md")
+	if err != nil || len(res.Contents) != 1 || res.Contents[0].Text != "# Docs" {
+		t.Fatalf("read: %+v %v", res, err)
+	}
+
+	// Reading subscribed the resource, so updates reach the listener.
+	if err := server.ResourceUpdated(context.Background(), &mcpsdk.ResourceUpdatedNotificationParams{URI: "file:///readme.md"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case evt := <-events:
+		if evt.Server != "docs" || evt.URI != "file:///readme.md" {
+			t.Fatalf("unexpected event %+v", evt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("resource update not delivered")
+	}
+
+	server.AddResource(&mcpsdk.Resource{URI: "file:///new.md", Name: "new"}, func(context.Context, *mcpsdk.ReadResourceRequest) (*mcpsdk.ReadResourceResult, error) {
+		return &mcpsdk.ReadResourceResult{}, nil
+	})
+	select {
+	case evt := <-events:
+		if evt.Server != "docs" || evt.URI != "" {
+			t.Fatalf("unexpected list change event %+v", evt)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("resource list change not delivered")
+	}
+}
+
+func TestRegistryMCPPrompts(t *testing.T) {
+	r := NewRegistry()
+	defer r.Close()
+	if prompts, err := r.ListMCPPrompts(context.Background()); err != nil || prompts != nil {
+		t.Fatalf("no servers should list nothing: %v %v", prompts, err)
+	}
+	registerResourceServer(t, r, newResourceServer())
+
+	prompts, err := r.ListMCPPrompts(context.Background())
+	if err != nil || len(prompts) != 1 || prompts[0].Server != "docs" || prompts[0].Name != "summarize" || !prompts[0].Arguments[0].Required {
+		t.Fatalf("list prompts: %+v %v", prompts, err)
+	}
+	res, err := r.GetMCPPrompt(context.Background(), "docs", "summarize", map[string]string{"topic": "MCP"})
+	if err != nil || res.Messages[0].Content.(*mcpsdk.TextContent).Text != "Summarize MCP" {
+		t.Fatalf("get prompt: %+v %v", res, err)
+	}
+	if _, err := r.GetMCPPrompt(context.Background(), "", "summarize", nil); err == nil {
+		t.Fatal("server is required")
+	}
+}
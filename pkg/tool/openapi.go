@@ -0,0 +1,749 @@
+package tool
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	openAPIDefaultTimeout     = 30 * time.Second
+	openAPIDefaultMaxResponse = 512 * 1024
+	openAPIMaxRedirects       = 10
+	openAPISchemaRefPrefix    = "#/components/schemas/"
+)
+
+var (
+	openAPIMethods      = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+	openAPIUnsafeName   = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+	openAPIServerVarRef = regexp.MustCompile(`\{([^{}]+)\}`)
+)
+
+// OpenAPIOptions configures FromOpenAPI.
+type OpenAPIOptions struct {
+	// Prefix is prepended to every tool name, e.g. "billing__".
+	Prefix string
+	// BaseURL overrides the first server URL declared by the document.
+	BaseURL string
+	// Headers are added to every request after the call's own header
+	// parameters, so credentials configured here cannot be overridden by the
+	// model. They are dropped when a redirect leaves the original host.
+	Headers map[string]string
+	// Network validates the host of every request and redirect. A nil policy
+	// allows all hosts.
+	Network sandbox.NetworkPolicy
+	// Operations limits the result to these operationIds or "METHOD /path"
+	// entries. Empty selects every operation.
+	Operations []string
+	// Client sends requests. Nil uses a client bounded by Timeout.
+	Client *http.Client
+	// Timeout bounds each request when Client is nil (default 30s).
+	Timeout time.Duration
+	// MaxResponseBytes caps how much of a response body is returned
+	// (default 512 KiB).
+	MaxResponseBytes int
+}
+
+// FromOpenAPI turns every operation of an OpenAPI 3 document (JSON or YAML)
+// into a deferred tool. The input schema holds one property per path, query
+// and header parameter plus "body" for the request body; component schemas
+// referenced by the operation are carried along as $defs.
+func FromOpenAPI(spec []byte, opts OpenAPIOptions) ([]Tool, error) {
+	doc, err := decodeOpenAPIDocument(spec)
+	if err != nil {
+		return nil, err
+	}
+	version, _ := doc["openapi"].(string)
+	if !strings.HasPrefix(version, "3.") {
+		return nil, fmt.Errorf("openapi: unsupported document version %q", version)
+	}
+	base, err := openAPIBaseURL(doc, opts.BaseURL)
+	if err != nil {
+		return nil, err
+	}
+
+	var selected map[string]bool
+	if len(opts.Operations) > 0 {
+		selected = make(map[string]bool, len(opts.Operations))
+		for _, op := range opts.Operations {
+			selected[strings.TrimSpace(op)] = false
+		}
+	}
+
+	paths, _ := doc["paths"].(map[string]interface{})
+	pathNames := make([]string, 0, len(paths))
+	for path := range paths {
+		pathNames = append(pathNames, path)
+	}
+	sort.Strings(pathNames)
+
+	b := &openAPIBuilder{doc: doc, opts: opts, base: base, names: map[string]int{}}
+	var tools []Tool
+	for _, path := range pathNames {
+		item, ok := b.resolve(paths[path])
+		if !ok {
+			continue
+		}
+		for _, method := range openAPIMethods {
+			op, ok := item[method].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			opID, _ := op["operationId"].(string)
+			route := strings.ToUpper(method) + " " + path
+			if selected != nil {
+				_, byID := selected[opID]
+				_, byRoute := selected[route]
+				if !byID && !byRoute {
+					continue
+				}
+				selected[opID], selected[route] = true, true
+			}
+			t, err := b.operation(path, method, item, op)
+			if err != nil {
+				return nil, fmt.Errorf("openapi: %s: %w", route, err)
+			}
+			tools = append(tools, t)
+		}
+	}
+	for op, found := range selected {
+		if !found {
+			return nil, fmt.Errorf("openapi: operation %q not found", op)
+		}
+	}
+	return tools, nil
+}
+
+// decodeOpenAPIDocument parses JSON or YAML into JSON-compatible values.
+func decodeOpenAPIDocument(spec []byte) (map[string]interface{}, error) {
+	var raw interface{}
+	if trimmed := bytes.TrimSpace(spec); len(trimmed) > 0 && trimmed[0] == '{' {
+		if err := json.Unmarshal(trimmed, &raw); err != nil {
+			return nil, fmt.Errorf("openapi: parse document: %w", err)
+		}
+	} else {
+		if err := yaml.Unmarshal(spec, &raw); err != nil {
+			return nil, fmt.Errorf("openapi: parse document: %w", err)
+		}
+		raw = normalizeYAMLValue(raw)
+	}
+	doc, ok := raw.(map[string]interface{})
+	if !ok {
+		return nil, errors.New("openapi: document must be an object")
+	}
+	return doc, nil
+}
+
+// normalizeYAMLValue converts YAML decoding results to the shapes
+// encoding/json produces: string-keyed maps and float64 numbers.
+func normalizeYAMLValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, item := range v {
+			v[key] = normalizeYAMLValue(item)
+		}
+		return v
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out[fmt.Sprint(key)] = normalizeYAMLValue(item)
+		}
+		return out
+	case []interface{}:
+		for i, item := range v {
+			v[i] = normalizeYAMLValue(item)
+		}
+		return v
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	default:
+		return v
+	}
+}
+
+func openAPIBaseURL(doc map[string]interface{}, override string) (*url.URL, error) {
+	raw := strings.TrimSpace(override)
+	if raw == "" {
+		servers, _ := doc["servers"].([]interface{})
+		if len(servers) > 0 {
+			if server, ok := servers[0].(map[string]interface{}); ok {
+				raw, _ = server["url"].(string)
+				vars, _ := server["variables"].(map[string]interface{})
+				raw = openAPIServerVarRef.ReplaceAllStringFunc(raw, func(match string) string {
+					if v, ok := vars[match[1:len(match)-1]].(map[string]interface{}); ok {
+						if def, ok := v["default"].(string); ok {
+							return def
+						}
+					}
+					return match
+				})
+			}
+		}
+	}
+	if raw == "" {
+		return nil, errors.New("openapi: document declares no server; set a base URL")
+	}
+	base, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("openapi: invalid base URL %q: %w", raw, err)
+	}
+	if (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return nil, fmt.Errorf("openapi: base URL %q must be an absolute http(s) URL", raw)
+	}
+	return base, nil
+}
+
+type openAPIBuilder struct {
+	doc   map[string]interface{}
+	opts  OpenAPIOptions
+	base  *url.URL
+	names map[string]int
+}
+
+// resolve follows local $ref pointers until it reaches an object.
+func (b *openAPIBuilder) resolve(value interface{}) (map[string]interface{}, bool) {
+	for hops := 0; hops < maxRefHops; hops++ {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		ref, _ := obj["$ref"].(string)
+		if ref == "" {
+			return obj, true
+		}
+		target, err := resolveJSONPointer(b.doc, ref)
+		if err != nil {
+			return nil, false
+		}
+		value = target
+	}
+	return nil, false
+}
+
+// openAPIParam is one non-body input of an operation.
+type openAPIParam struct {
+	name string // Name on the wire.
+	in   string // path, query or header.
+	key  string // Property name in the tool schema.
+}
+
+func (b *openAPIBuilder) operation(path, method string, item, op map[string]interface{}) (*OpenAPITool, error) {
+	properties := map[string]interface{}{}
+	var required []string
+	var params []openAPIParam
+
+	// Operation parameters override path-level ones with the same name and location.
+	merged := map[string]map[string]interface{}{}
+	var order []string
+	for _, list := range []interface{}{item["parameters"], op["parameters"]} {
+		entries, _ := list.([]interface{})
+		for _, entry := range entries {
+			param, ok := b.resolve(entry)
+			if !ok {
+				return nil, errors.New("unresolvable parameter")
+			}
+			name, _ := param["name"].(string)
+			in, _ := param["in"].(string)
+			if name == "" || (in != "path" && in != "query" && in != "header") {
+				continue
+			}
+			id := in + ":" + name
+			if _, seen := merged[id]; !seen {
+				order = append(order, id)
+			}
+			merged[id] = param
+		}
+	}
+	for _, id := range order {
+		param := merged[id]
+		name, _ := param["name"].(string)
+		in, _ := param["in"].(string)
+		key := name
+		if _, taken := properties[key]; taken {
+			key = in + "_" + name
+		}
+		schema := openAPIParamSchema(param)
+		properties[key] = schema
+		if req, _ := param["required"].(bool); req || in == "path" {
+			required = append(required, key)
+		}
+		params = append(params, openAPIParam{name: name, in: in, key: key})
+	}
+
+	var bodyKey, bodyType string
+	if rawBody, ok := op["requestBody"]; ok {
+		body, ok := b.resolve(rawBody)
+		if !ok {
+			return nil, errors.New("unresolvable requestBody")
+		}
+		content, _ := body["content"].(map[string]interface{})
+		bodyType = pickOpenAPIMediaType(content)
+		if bodyType != "" {
+			bodyKey = "body"
+			if _, taken := properties[bodyKey]; taken {
+				bodyKey = "request_body"
+			}
+			media, _ := content[bodyType].(map[string]interface{})
+			schema, _ := media["schema"].(map[string]interface{})
+			schema, _ = cloneValue(schema).(map[string]interface{})
+			if schema == nil {
+				schema = map[string]interface{}{}
+			}
+			if desc, ok := body["description"].(string); ok && schema["description"] == nil {
+				schema["description"] = desc
+			}
+			properties[bodyKey] = schema
+			if req, _ := body["required"].(bool); req {
+				required = append(required, bodyKey)
+			}
+		}
+	}
+
+	defs := b.collectDefs(properties)
+	schemaMap := map[string]interface{}{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schemaMap["required"] = required
+	}
+	if len(defs) > 0 {
+		schemaMap["$defs"] = defs
+	}
+	rewriteOpenAPIRefs(schemaMap)
+	raw, err := json.Marshal(schemaMap)
+	if err != nil {
+		return nil, fmt.Errorf("encode schema: %w", err)
+	}
+	var schema JSONSchema
+	if err := json.Unmarshal(raw, &schema); err != nil {
+		return nil, fmt.Errorf("decode schema: %w", err)
+	}
+
+	return &OpenAPITool{
+		name:        b.toolName(path, method, op),
+		description: openAPIDescription(path, method, op),
+		schema:      &schema,
+		method:      strings.ToUpper(method),
+		base:        b.base,
+		path:        path,
+		params:      params,
+		bodyKey:     bodyKey,
+		bodyType:    bodyType,
+		opts:        b.opts,
+	}, nil
+}
+
+func openAPIParamSchema(param map[string]interface{}) map[string]interface{} {
+	schema, _ := param["schema"].(map[string]interface{})
+	if schema == nil {
+		if content, ok := param["content"].(map[string]interface{}); ok {
+			if media, ok := content[pickOpenAPIMediaType(content)].(map[string]interface{}); ok {
+				schema, _ = media["schema"].(map[string]interface{})
+			}
+		}
+	}
+	out, _ := cloneValue(schema).(map[string]interface{})
+	if out == nil {
+		out = map[string]interface{}{"type": "string"}
+	}
+	if desc, ok := param["description"].(string); ok && out["description"] == nil {
+		out["description"] = desc
+	}
+	return out
+}
+
+// pickOpenAPIMediaType prefers JSON, then form encoding, then the first
+// declared media type.
+func pickOpenAPIMediaType(content map[string]interface{}) string {
+	if len(content) == 0 {
+		return ""
+	}
+	types := make([]string, 0, len(content))
+	for mediaType := range content {
+		types = append(types, mediaType)
+	}
+	sort.Strings(types)
+	for _, mediaType := range types {
+		if mediaType == "application/json" {
+			return mediaType
+		}
+	}
+	for _, mediaType := range types {
+		if strings.Contains(mediaType, "json") {
+			return mediaType
+		}
+	}
+	for _, mediaType := range types {
+		if mediaType == "application/x-www-form-urlencoded" {
+			return mediaType
+		}
+	}
+	return types[0]
+}
+
+// collectDefs gathers the component schemas reachable from value.
+func (b *openAPIBuilder) collectDefs(value interface{}) map[string]interface{} {
+	components, _ := b.doc["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+	defs := map[string]interface{}{}
+	var walk func(interface{})
+	walk = func(v interface{}) {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			if ref, ok := node["$ref"].(string); ok && strings.HasPrefix(ref, openAPISchemaRefPrefix) {
+				name := strings.TrimPrefix(ref, openAPISchemaRefPrefix)
+				if _, done := defs[name]; !done {
+					if target, ok := schemas[name]; ok {
+						defs[name] = cloneValue(target)
+						walk(target)
+					}
+				}
+			}
+			for _, child := range node {
+				walk(child)
+			}
+		case []interface{}:
+			for _, child := range node {
+				walk(child)
+			}
+		}
+	}
+	walk(value)
+	return defs
+}
+
+// rewriteOpenAPIRefs points component schema references at $defs.
+func rewriteOpenAPIRefs(value interface{}) {
+	switch node := value.(type) {
+	case map[string]interface{}:
+		if ref, ok := node["$ref"].(string); ok && strings.HasPrefix(ref, openAPISchemaRefPrefix) {
+			node["$ref"] = "#/$defs/" + strings.TrimPrefix(ref, openAPISchemaRefPrefix)
+		}
+		for _, child := range node {
+			rewriteOpenAPIRefs(child)
+		}
+	case []interface{}:
+		for _, child := range node {
+			rewriteOpenAPIRefs(child)
+		}
+	}
+}
+
+func (b *openAPIBuilder) toolName(path, method string, op map[string]interface{}) string {
+	base, _ := op["operationId"].(string)
+	if strings.TrimSpace(base) == "" {
+		base = method + " " + path
+	}
+	base = strings.Trim(openAPIUnsafeName.ReplaceAllString(base, "_"), "_")
+	name := b.opts.Prefix + base
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	b.names[name]++
+	if n := b.names[name]; n > 1 {
+		suffix := "_" + strconv.Itoa(n)
+		if len(name)+len(suffix) > 64 {
+			name = name[:64-len(suffix)]
+		}
+		name += suffix
+	}
+	return name
+}
+
+func openAPIDescription(path, method string, op map[string]interface{}) string {
+	var parts []string
+	for _, key := range []string{"summary", "description"} {
+		if text, ok := op[key].(string); ok && strings.TrimSpace(text) != "" {
+			parts = append(parts, strings.TrimSpace(text))
+		}
+	}
+	if deprecated, _ := op["deprecated"].(bool); deprecated {
+		parts = append(parts, "Deprecated.")
+	}
+	parts = append(parts, fmt.Sprintf("Calls %s %s.", strings.ToUpper(method), path))
+	return strings.Join(parts, "\n\n")
+}
+
+// OpenAPITool calls one operation of an OpenAPI document. It is deferred, so
+// models discover it through tool_search rather than the initial tool list.
+type OpenAPITool struct {
+	name        string
+	description string
+	schema      *JSONSchema
+	method      string
+	base        *url.URL
+	path        string
+	params      []openAPIParam
+	bodyKey     string
+	bodyType    string
+	opts        OpenAPIOptions
+}
+
+func (t *OpenAPITool) Name() string { return t.name }
+
+func (t *OpenAPITool) Description() string { return t.description }
+
+func (t *OpenAPITool) Schema() *JSONSchema { return t.schema }
+
+// ShouldDefer implements DeferrableProvider.
+func (t *OpenAPITool) ShouldDefer() bool { return true }
+
+// Metadata marks GET, HEAD and OPTIONS operations read-only.
+func (t *OpenAPITool) Metadata() Metadata {
+	switch t.method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return Metadata{IsReadOnly: true, IsConcurrencySafe: true}
+	}
+	return Metadata{}
+}
+
+// Cacheable opts out of result caching; remote state changes without any
+// local file event to invalidate it.
+func (t *OpenAPITool) Cacheable() bool { return false }
+
+// Policy declares the idempotent HTTP methods so transient failures retry.
+func (t *OpenAPITool) Policy() Policy {
+	switch t.method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return Policy{Idempotent: true}
+	}
+	return Policy{}
+}
+
+func (t *OpenAPITool) Execute(ctx context.Context, params map[string]interface{}) (*ToolResult, error) {
+	ctx = nonNilContext(ctx)
+	req, err := t.buildRequest(ctx, params)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.name, err)
+	}
+	start := time.Now()
+	resp, err := t.client(req).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t.name, err)
+	}
+	defer resp.Body.Close()
+	limit := t.opts.MaxResponseBytes
+	if limit <= 0 {
+		limit = openAPIDefaultMaxResponse
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, int64(limit)+1))
+	if err != nil {
+		return nil, fmt.Errorf("%s: read response: %w", t.name, err)
+	}
+	truncated := len(body) > limit
+	if truncated {
+		body = body[:limit]
+	}
+
+	text := string(body)
+	var decoded interface{}
+	if !truncated && strings.Contains(strings.ToLower(resp.Header.Get("Content-Type")), "json") && json.Unmarshal(body, &decoded) == nil {
+		var pretty bytes.Buffer
+		if json.Indent(&pretty, body, "", "  ") == nil {
+			text = pretty.String()
+		}
+	}
+	var out strings.Builder
+	fmt.Fprintf(&out, "%s %s", resp.Proto, resp.Status)
+	if text != "" {
+		out.WriteString("\n\n")
+		out.WriteString(text)
+	}
+	if truncated {
+		fmt.Fprintf(&out, "\n... (response truncated at %d bytes)", limit)
+	}
+	data := map[string]interface{}{
+		"method":      req.Method,
+		"url":         resp.Request.URL.String(),
+		"status_code": resp.StatusCode,
+		"truncated":   truncated,
+		"duration_ms": time.Since(start).Milliseconds(),
+	}
+	if decoded != nil {
+		data["body"] = decoded
+	}
+	return &ToolResult{Success: resp.StatusCode < 400, Output: out.String(), Data: data}, nil
+}
+
+func (t *OpenAPITool) buildRequest(ctx context.Context, params map[string]interface{}) (*http.Request, error) {
+	path := t.path
+	query := url.Values{}
+	headers := http.Header{}
+	for _, p := range t.params {
+		value, ok := params[p.key]
+		if !ok || value == nil {
+			if p.in == "path" {
+				return nil, fmt.Errorf("path parameter %s is required", p.key)
+			}
+			continue
+		}
+		switch p.in {
+		case "path":
+			path = strings.ReplaceAll(path, "{"+p.name+"}", url.PathEscape(openAPIParamString(value)))
+		case "query":
+			addOpenAPIQuery(query, p.name, value)
+		case "header":
+			headers.Set(p.name, openAPIParamString(value))
+		}
+	}
+
+	target := *t.base
+	rawPath := strings.TrimRight(target.EscapedPath(), "/") + path
+	unescaped, err := url.PathUnescape(rawPath)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path: %w", err)
+	}
+	target.Path, target.RawPath = unescaped, rawPath
+	if encoded := query.Encode(); encoded != "" {
+		if target.RawQuery != "" {
+			target.RawQuery += "&" + encoded
+		} else {
+			target.RawQuery = encoded
+		}
+	}
+	if err := t.checkHost(&target); err != nil {
+		return nil, err
+	}
+
+	var body io.Reader
+	if t.bodyKey != "" {
+		if value, ok := params[t.bodyKey]; ok && value != nil {
+			encoded, err := encodeOpenAPIBody(t.bodyType, value)
+			if err != nil {
+				return nil, err
+			}
+			body = bytes.NewReader(encoded)
+			headers.Set("Content-Type", t.bodyType)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, t.method, target.String(), body)
+	if err != nil {
+		return nil, fmt.Errorf("build request: %w", err)
+	}
+	req.Header = headers
+	if req.Header.Get("Accept") == "" {
+		req.Header.Set("Accept", "application/json")
+	}
+	for name, value := range t.opts.Headers {
+		req.Header.Set(name, value)
+	}
+	return req, nil
+}
+
+func (t *OpenAPITool) checkHost(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("unsupported url scheme %q", u.Scheme)
+	}
+	if t.opts.Network == nil {
+		return nil
+	}
+	return t.opts.Network.Validate(u.Hostname())
+}
+
+func (t *OpenAPITool) client(req *http.Request) *http.Client {
+	client := &http.Client{Timeout: openAPIDefaultTimeout}
+	if t.opts.Timeout > 0 {
+		client.Timeout = t.opts.Timeout
+	}
+	if t.opts.Client != nil {
+		copied := *t.opts.Client
+		client = &copied
+	}
+	next := client.CheckRedirect
+	client.CheckRedirect = func(redirect *http.Request, via []*http.Request) error {
+		if len(via) >= openAPIMaxRedirects {
+			return fmt.Errorf("stopped after %d redirects", openAPIMaxRedirects)
+		}
+		if err := t.checkHost(redirect.URL); err != nil {
+			return err
+		}
+		if redirect.URL.Host != req.URL.Host {
+			for name := range t.opts.Headers {
+				redirect.Header.Del(name)
+			}
+		}
+		if next != nil {
+			return next(redirect, via)
+		}
+		return nil
+	}
+	return client
+}
+
+func openAPIParamString(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case json.Number:
+		return v.String()
+	default:
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(encoded)
+	}
+}
+
+// addOpenAPIQuery applies the default form style with explode: arrays repeat
+// the key and objects contribute one pair per property.
+func addOpenAPIQuery(query url.Values, name string, value interface{}) {
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			query.Add(name, openAPIParamString(item))
+		}
+	case map[string]interface{}:
+		for key, item := range v {
+			query.Add(key, openAPIParamString(item))
+		}
+	default:
+		query.Add(name, openAPIParamString(value))
+	}
+}
+
+func encodeOpenAPIBody(mediaType string, value interface{}) ([]byte, error) {
+	switch {
+	case strings.Contains(mediaType, "json"):
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("encode body: %w", err)
+		}
+		return encoded, nil
+	case mediaType == "application/x-www-form-urlencoded":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("body must be an object for %s", mediaType)
+		}
+		form := url.Values{}
+		for key, item := range obj {
+			addOpenAPIQuery(form, key, item)
+		}
+		return []byte(form.Encode()), nil
+	default:
+		if text, ok := value.(string); ok {
+			return []byte(text), nil
+		}
+		return nil, fmt.Errorf("body must be a string for %s", mediaType)
+	}
+}
@@ -0,0 +1,256 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stellarlinkco/agentsdk-go/pkg/sandbox"
+)
+
+const petstoreSpec = `
+openapi: 3.0.3
+info: {title: Pets, version: "1"}
+servers:
+  - url: https://{region}.pets.example/v1
+    variables:
+      region: {default: eu}
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      summary: List pets
+      parameters:
+        - {name: limit, in: query, schema: {type: integer, maximum: 100}}
+        - {name: tag, in: query, schema: {type: array, items: {type: string}}}
+      responses:
+        200: {description: ok}
+    post:
+      operationId: createPet
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: "#/components/schemas/NewPet"}
+      responses:
+        201: {description: created}
+  /pets/{petId}:
+    parameters:
+      - $ref: "#/components/parameters/PetId"
+    delete:
+      summary: Delete a pet
+      deprecated: true
+      parameters:
+        - {name: X-Reason, in: header, schema: {type: string}}
+      responses:
+        204: {description: deleted}
+components:
+  parameters:
+    PetId: {name: petId, in: path, description: Pet identifier, schema: {type: string}}
+  schemas:
+    NewPet:
+      type: object
+      required: [name]
+      properties:
+        name: {type: string}
+        owner: {$ref: "#/components/schemas/Owner"}
+    Owner:
+      type: object
+      properties:
+        email: {type: string, format: email}
+    Unused: {type: string}
+`
+
+func openAPIToolsByName(t *testing.T, spec string, opts OpenAPIOptions) map[string]*OpenAPITool {
+	t.Helper()
+	tools, err := FromOpenAPI([]byte(spec), opts)
+	if err != nil {
+		t.Fatalf("FromOpenAPI: %v", err)
+	}
+	out := make(map[string]*OpenAPITool, len(tools))
+	for _, impl := range tools {
+		out[impl.Name()] = impl.(*OpenAPITool)
+	}
+	return out
+}
+
+func TestFromOpenAPIBuildsTools(t *testing.T) {
+	tools := openAPIToolsByName(t, petstoreSpec, OpenAPIOptions{Prefix: "pets__"})
+	if len(tools) != 3 {
+		t.Fatalf("expected 3 tools, got %v", tools)
+	}
+	list, create, del := tools["pets__listPets"], tools["pets__createPet"], tools["pets__delete_pets_petId"]
+	if list == nil || create == nil || del == nil {
+		t.Fatalf("unexpected tool names: %v", tools)
+	}
+	if list.base.String() != "https://eu.pets.example/v1" {
+		t.Fatalf("server variables not substituted: %s", list.base)
+	}
+	for _, impl := range []Tool{list, create, del} {
+		if !ShouldDefer(impl) || IsCacheable(impl) {
+			t.Fatalf("%s should be deferred and uncached", impl.Name())
+		}
+	}
+	if !MetadataOf(list).IsReadOnly || MetadataOf(create).IsReadOnly {
+		t.Fatal("only GET operations are read-only")
+	}
+	if !PolicyOf(del).Idempotent || PolicyOf(create).Idempotent {
+		t.Fatal("DELETE is idempotent, POST is not")
+	}
+	if !strings.Contains(del.Description(), "Deprecated.") || !strings.Contains(del.Description(), "Calls DELETE /pets/{petId}.") {
+		t.Fatalf("unexpected description %q", del.Description())
+	}
+
+	schema := create.Schema()
+	if len(schema.Required) != 1 || schema.Required[0] != "body" {
+		t.Fatalf("body should be required: %v", schema.Required)
+	}
+	if _, ok := schema.Defs["NewPet"]; !ok {
+		t.Fatalf("referenced schemas should be carried as $defs: %v", schema.Defs)
+	}
+	if _, ok := schema.Defs["Owner"]; !ok {
+		t.Fatal("transitively referenced schemas should be included")
+	}
+	if _, ok := schema.Defs["Unused"]; ok {
+		t.Fatal("unreferenced schemas should be left out")
+	}
+	if ref := schema.Properties["body"].(map[string]interface{})["$ref"]; ref != "#/$defs/NewPet" {
+		t.Fatalf("ref not rewritten: %v", ref)
+	}
+
+	validator := DefaultValidator{}
+	if err := validator.Validate(map[string]interface{}{"body": map[string]interface{}{"owner": map[string]interface{}{"email": "x"}}}, schema); err == nil {
+		t.Fatal("expected validation through $defs to fail")
+	}
+	if err := validator.Validate(map[string]interface{}{"petId": "7"}, del.Schema()); err != nil {
+		t.Fatalf("path params: %v", err)
+	}
+	if err := validator.Validate(map[string]interface{}{}, del.Schema()); err == nil {
+		t.Fatal("path params are always required")
+	}
+	if desc := del.Schema().Properties["petId"].(map[string]interface{})["description"]; desc != "Pet identifier" {
+		t.Fatalf("parameter description not kept: %v", desc)
+	}
+}
+
+func TestFromOpenAPIOptionsAndErrors(t *testing.T) {
+	tools := openAPIToolsByName(t, petstoreSpec, OpenAPIOptions{Operations: []string{"listPets", "DELETE /pets/{petId}"}})
+	if len(tools) != 2 || tools["listPets"] == nil || tools["delete_pets_petId"] == nil {
+		t.Fatalf("operation filter not applied: %v", tools)
+	}
+	if _, err := FromOpenAPI([]byte(petstoreSpec), OpenAPIOptions{Operations: []string{"nope"}}); err == nil || !strings.Contains(err.Error(), `"nope" not found`) {
+		t.Fatalf("expected unknown operation error, got %v", err)
+	}
+	if _, err := FromOpenAPI([]byte(`{"swagger": "2.0"}`), OpenAPIOptions{}); err == nil || !strings.Contains(err.Error(), "unsupported document version") {
+		t.Fatalf("expected version error, got %v", err)
+	}
+	if _, err := FromOpenAPI([]byte(`{"openapi": "3.1.0", "paths": {}}`), OpenAPIOptions{}); err == nil || !strings.Contains(err.Error(), "no server") {
+		t.Fatalf("expected missing server error, got %v", err)
+	}
+	if _, err := FromOpenAPI([]byte(`{"openapi": "3.1.0", "servers": [{"url": "/api"}]}`), OpenAPIOptions{}); err == nil || !strings.Contains(err.Error(), "absolute") {
+		t.Fatalf("expected relative server error, got %v", err)
+	}
+}
+
+func TestOpenAPIToolExecute(t *testing.T) {
+	var got *http.Request
+	var gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodDelete {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		_, _ = w.Write([]byte(`{"id":1,"name":"rex"}`))
+	}))
+	defer srv.Close()
+
+	tools := openAPIToolsByName(t, petstoreSpec, OpenAPIOptions{
+		BaseURL: srv.URL + "/v1/",
+		Headers: map[string]string{"Authorization": "Bearer token", "X-Reason": "configured"},
+	})
+
+	res, err := tools["listPets"].Execute(context.Background(), map[string]interface{}{"limit": 5.0, "tag": []interface{}{"a", "b c"}})
+	if err != nil || !res.Success {
+		t.Fatalf("list: %+v %v", res, err)
+	}
+	if got.URL.Path != "/v1/pets" || got.URL.Query().Get("limit") != "5" || strings.Join(got.URL.Query()["tag"], ",") != "a,b c" {
+		t.Fatalf("unexpected request %s", got.URL)
+	}
+	if got.Header.Get("Authorization") != "Bearer token" {
+		t.Fatal("configured headers should be sent")
+	}
+	if !strings.HasSuffix(res.Output, "{\n  \"id\": 1,\n  \"name\": \"rex\"\n}") || res.Data.(map[string]interface{})["status_code"] != 200 {
+		t.Fatalf("unexpected result %+v", res)
+	}
+
+	if _, err := tools["createPet"].Execute(context.Background(), map[string]interface{}{"body": map[string]interface{}{"name": "rex"}}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if got.Method != http.MethodPost || got.Header.Get("Content-Type") != "application/json" || gotBody != `{"name":"rex"}` {
+		t.Fatalf("unexpected create request %s %v %q", got.Method, got.Header, gotBody)
+	}
+
+	res, err = tools["delete_pets_petId"].Execute(context.Background(), map[string]interface{}{"petId": "a/b", "X-Reason": "model"})
+	if err != nil || res.Success {
+		t.Fatalf("4xx should be a failed result without error: %+v %v", res, err)
+	}
+	if got.URL.EscapedPath() != "/v1/pets/a%2Fb" {
+		t.Fatalf("path param not escaped: %s", got.URL.EscapedPath())
+	}
+	if got.Header.Get("X-Reason") != "configured" {
+		t.Fatal("configured headers must win over header parameters")
+	}
+	if _, err := tools["delete_pets_petId"].Execute(context.Background(), map[string]interface{}{}); err == nil {
+		t.Fatal("missing path parameter should fail")
+	}
+}
+
+func TestOpenAPIToolNetworkPolicy(t *testing.T) {
+	var hits int
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hits++
+		if r.Header.Get("X-Api-Key") != "" {
+			t.Error("credentials leaked across hosts")
+		}
+	}))
+	defer other.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/elsewhere", http.StatusFound)
+	}))
+	defer srv.Close()
+
+	spec := `{"openapi": "3.0.0", "paths": {"/go": {"get": {"operationId": "go"}}}}`
+	denied := openAPIToolsByName(t, spec, OpenAPIOptions{BaseURL: srv.URL, Network: sandbox.NewDomainAllowList("api.example.com")})
+	if _, err := denied["go"].Execute(context.Background(), nil); !errors.Is(err, sandbox.ErrDomainDenied) {
+		t.Fatalf("expected domain denied, got %v", err)
+	}
+
+	// Both test servers listen on 127.0.0.1; the redirect changes the port and
+	// therefore the host, so configured headers are dropped.
+	allowed := openAPIToolsByName(t, spec, OpenAPIOptions{BaseURL: srv.URL, Headers: map[string]string{"X-Api-Key": "secret"}, Network: sandbox.NewDomainAllowList("127.0.0.1")})
+	res, err := allowed["go"].Execute(context.Background(), nil)
+	if err != nil || !res.Success || hits != 1 {
+		t.Fatalf("redirect: %+v %v (hits %d)", res, err, hits)
+	}
+	u, _ := url.Parse(res.Data.(map[string]interface{})["url"].(string))
+	if u.Path != "/elsewhere" {
+		t.Fatalf("unexpected final url %s", u)
+	}
+}
+
+func TestOpenAPIToolRegistersWithToolSearchSchema(t *testing.T) {
+	tools := openAPIToolsByName(t, petstoreSpec, OpenAPIOptions{})
+	data, err := json.Marshal(tools["createPet"].Schema())
+	if err != nil || !strings.Contains(string(data), `"$defs"`) {
+		t.Fatalf("schema should serialise with $defs: %s %v", data, err)
+	}
+}
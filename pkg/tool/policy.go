@@ -0,0 +1,169 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	defaultRetryBackoff = 500 * time.Millisecond
+	maxRetryBackoff     = 30 * time.Second
+)
+
+// Policy controls how the Executor runs a tool. The zero value runs each call
+// once with no executor-imposed timeout.
+type Policy struct {
+	// Timeout bounds each attempt. Zero leaves the call to the context.
+	Timeout time.Duration
+	// MaxRetries is the number of extra attempts made after a retryable
+	// error. Retries only happen for idempotent tools.
+	MaxRetries int
+	// Backoff is the delay before the first retry; it doubles on every
+	// further retry. Zero selects 500ms.
+	Backoff time.Duration
+	// Idempotent declares that repeating a call has no additional effect.
+	// Read-only tools are always treated as idempotent.
+	Idempotent bool
+}
+
+// PolicyProvider lets a tool declare its own execution policy.
+type PolicyProvider interface {
+	Policy() Policy
+}
+
+// PolicyOverride replaces the fields of a tool's Policy that are set. It is
+// how host configuration tightens or relaxes policies for tools it does not
+// own, such as MCP tools.
+type PolicyOverride struct {
+	Timeout    *time.Duration
+	MaxRetries *int
+	Backoff    *time.Duration
+	Idempotent *bool
+}
+
+// Apply returns p with the fields set in o replaced.
+func (o PolicyOverride) Apply(p Policy) Policy {
+	if o.Timeout != nil {
+		p.Timeout = *o.Timeout
+	}
+	if o.MaxRetries != nil {
+		p.MaxRetries = *o.MaxRetries
+	}
+	if o.Backoff != nil {
+		p.Backoff = *o.Backoff
+	}
+	if o.Idempotent != nil {
+		p.Idempotent = *o.Idempotent
+	}
+	return p
+}
+
+// PolicyOf returns the policy declared by tool, or the zero Policy.
+func PolicyOf(tool Tool) Policy {
+	if provider, ok := tool.(PolicyProvider); ok {
+		return provider.Policy()
+	}
+	return Policy{}
+}
+
+// retryDelay is the backoff before retry number n (1-based).
+func (p Policy) retryDelay(n int) time.Duration {
+	delay := p.Backoff
+	if delay <= 0 {
+		delay = defaultRetryBackoff
+	}
+	for i := 1; i < n && delay < maxRetryBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxRetryBackoff)
+}
+
+// ErrToolTimeout is wrapped by errors returned when an attempt exceeds the
+// tool's Policy.Timeout.
+var ErrToolTimeout = errors.New("tool call timed out")
+
+type retryableError struct{ err error }
+
+func (e *retryableError) Error() string   { return e.err.Error() }
+func (e *retryableError) Unwrap() error   { return e.err }
+func (e *retryableError) Retryable() bool { return true }
+
+// Retryable marks err as transient so the Executor may retry the call under
+// the tool's Policy. A nil err stays nil.
+func Retryable(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &retryableError{err: err}
+}
+
+// IsRetryable reports whether err is worth retrying: errors marked with
+// Retryable (or any error exposing Retryable() bool), policy timeouts, and
+// network errors reporting Timeout() or Temporary().
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrToolTimeout) {
+		return true
+	}
+	var marked interface{ Retryable() bool }
+	if errors.As(err, &marked) {
+		return marked.Retryable()
+	}
+	var timeout interface{ Timeout() bool }
+	if errors.As(err, &timeout) && timeout.Timeout() {
+		return true
+	}
+	var temporary interface{ Temporary() bool }
+	return errors.As(err, &temporary) && temporary.Temporary()
+}
+
+type attemptResult struct {
+	res *ToolResult
+	err error
+}
+
+// runAttempt executes run once under policy.Timeout. The attempt is abandoned
+// at the deadline even when the tool ignores its context.
+func runAttempt(ctx context.Context, name string, timeout time.Duration, run func(context.Context) (*ToolResult, error)) (*ToolResult, error) {
+	if timeout <= 0 {
+		return run(ctx)
+	}
+	ctx = nonNilContext(ctx)
+	attemptCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan attemptResult, 1)
+	go func() {
+		res, err := run(attemptCtx)
+		done <- attemptResult{res: res, err: err}
+	}()
+	select {
+	case out := <-done:
+		if out.err != nil && ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+			// The cause is flattened so callers treating context.DeadlineExceeded
+			// as a cancelled run do not mistake a tool timeout for one.
+			return out.res, fmt.Errorf("%w: %s exceeded %s: %v", ErrToolTimeout, name, timeout, out.err)
+		}
+		return out.res, out.err
+	case <-attemptCtx.Done():
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("%w: %s exceeded %s", ErrToolTimeout, name, timeout)
+	}
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	ctx = nonNilContext(ctx)
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
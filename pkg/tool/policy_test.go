@@ -0,0 +1,113 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// flakyTool fails with err until it has been called failures times.
+type flakyTool struct {
+	name     string
+	failures int32
+	err      error
+	policy   Policy
+	calls    int32
+}
+
+func (f *flakyTool) Name() string        { return f.name }
+func (f *flakyTool) Description() string { return "flaky" }
+func (f *flakyTool) Schema() *JSONSchema { return nil }
+func (f *flakyTool) Policy() Policy      { return f.policy }
+func (f *flakyTool) Execute(context.Context, map[string]interface{}) (*ToolResult, error) {
+	if atomic.AddInt32(&f.calls, 1) <= f.failures {
+		return nil, f.err
+	}
+	return &ToolResult{Success: true, Output: "ok"}, nil
+}
+
+// hangingTool ignores its context and blocks until released.
+type hangingTool struct{ release chan struct{} }
+
+func (h *hangingTool) Name() string        { return "hang" }
+func (h *hangingTool) Description() string { return "hang" }
+func (h *hangingTool) Schema() *JSONSchema { return nil }
+func (h *hangingTool) Execute(context.Context, map[string]interface{}) (*ToolResult, error) {
+	<-h.release
+	return &ToolResult{Success: true}, nil
+}
+
+func newPolicyExecutor(t *testing.T, tools ...Tool) *Executor {
+	t.Helper()
+	reg := NewRegistry()
+	for _, impl := range tools {
+		if err := reg.Register(impl); err != nil {
+			t.Fatalf("register: %v", err)
+		}
+	}
+	return NewExecutor(reg, nil)
+}
+
+func TestExecutorRetriesIdempotentTools(t *testing.T) {
+	transient := Retryable(errors.New("503 from upstream"))
+	idempotent := &flakyTool{name: "fetch", failures: 2, err: transient, policy: Policy{MaxRetries: 3, Backoff: time.Millisecond, Idempotent: true}}
+	sideEffect := &flakyTool{name: "deploy", failures: 2, err: transient, policy: Policy{MaxRetries: 3, Backoff: time.Millisecond}}
+	permanent := &flakyTool{name: "parse", failures: 5, err: errors.New("bad input"), policy: Policy{MaxRetries: 3, Backoff: time.Millisecond, Idempotent: true}}
+	exec := newPolicyExecutor(t, idempotent, sideEffect, permanent)
+	ctx := context.Background()
+
+	res, err := exec.Execute(ctx, Call{Name: "fetch"})
+	if err != nil || res.Attempts != 3 || res.Result.Output != "ok" {
+		t.Fatalf("expected success on third attempt, got %+v %v", res, err)
+	}
+	res, err = exec.Execute(ctx, Call{Name: "deploy"})
+	if err == nil || res.Attempts != 1 {
+		t.Fatalf("non-idempotent tools must not be retried: %+v %v", res, err)
+	}
+	res, err = exec.Execute(ctx, Call{Name: "parse"})
+	if err == nil || res.Attempts != 1 {
+		t.Fatalf("non-retryable errors must not be retried: %+v %v", res, err)
+	}
+
+	// Settings overrides apply on top of the declared policy.
+	exec = exec.WithPolicies(map[string]PolicyOverride{"deploy": {Idempotent: ptr(true)}})
+	sideEffect.calls = 0
+	if res, err = exec.Execute(ctx, Call{Name: "deploy"}); err != nil || res.Attempts != 3 {
+		t.Fatalf("override should enable retries: %+v %v", res, err)
+	}
+}
+
+func TestExecutorPolicyTimeout(t *testing.T) {
+	hang := &hangingTool{release: make(chan struct{})}
+	defer close(hang.release)
+	exec := newPolicyExecutor(t, hang).WithPolicies(map[string]PolicyOverride{"hang": {Timeout: ptr(20 * time.Millisecond)}})
+
+	started := time.Now()
+	res, err := exec.Execute(context.Background(), Call{Name: "hang"})
+	if !errors.Is(err, ErrToolTimeout) || errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected tool timeout error, got %v", err)
+	}
+	if res.Attempts != 1 || time.Since(started) > 2*time.Second {
+		t.Fatalf("timeout not enforced: %+v after %s", res, time.Since(started))
+	}
+	if !IsRetryable(err) {
+		t.Fatal("policy timeouts should be retryable")
+	}
+}
+
+func TestPolicyRetryDelay(t *testing.T) {
+	p := Policy{Backoff: 100 * time.Millisecond}
+	if got := p.retryDelay(1); got != 100*time.Millisecond {
+		t.Fatalf("first delay = %s", got)
+	}
+	if got := p.retryDelay(3); got != 400*time.Millisecond {
+		t.Fatalf("third delay = %s", got)
+	}
+	if got := p.retryDelay(50); got != maxRetryBackoff {
+		t.Fatalf("delay should be capped, got %s", got)
+	}
+}
+
+func ptr[T any](v T) *T { return &v }
@@ -22,6 +22,10 @@ type Registry struct {
 	tools       map[string]Tool
 	mcpSessions []*mcpSessionInfo
 	validator   Validator
+	onRepair    func(string, ParamRepair)
+
+	resourceListeners []func(MCPResourceEvent)
+	clientHandlers    MCPClientHandlers
 }
 
 type mcpListChangedHandler = func(context.Context, *mcp.ClientSession)
@@ -39,6 +43,9 @@ type MCPServerOptions struct {
 	EnabledTools  []string
 	DisabledTools []string
 	ToolTimeout   time.Duration
+	// WrapTransport decorates the HTTP transport of http/sse servers, e.g.
+	// to authorize requests with OAuth tokens.
+	WrapTransport func(http.RoundTripper) http.RoundTripper
 }
 
 var newMCPClientWithOptions = func(ctx context.Context, spec string, opts MCPServerOptions, handler mcpListChangedHandler) (*mcp.ClientSession, error) {
@@ -105,7 +112,25 @@ func (r *Registry) SetValidator(v Validator) {
 	r.validator = v
 }
 
-// Execute runs a registered tool after optional schema validation.
+// SetRepairHook installs fn to observe each argument repair made before a
+// tool runs, e.g. to log it at debug level. Repairs are silent by default.
+func (r *Registry) SetRepairHook(fn func(tool string, repair ParamRepair)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onRepair = fn
+}
+
+func (r *Registry) prepareParams(tool Tool, params map[string]interface{}) (map[string]interface{}, *ToolResult) {
+	r.mu.RLock()
+	validator, onRepair := r.validator, r.onRepair
+	r.mu.RUnlock()
+	return prepareParams(tool, params, validator, onRepair)
+}
+
+// Execute runs a registered tool after repairing its params against the
+// schema (see RepairParams) and validating them. Invalid params produce a
+// failed ToolResult describing the problem instead of an error, so callers
+// can hand it back to the model.
 
 func (r *Registry) Execute(ctx context.Context, name string, params map[string]interface{}) (_ *ToolResult, err error) {
 	tool, err := r.Get(name)
@@ -113,16 +138,9 @@ func (r *Registry) Execute(ctx context.Context, name string, params map[string]i
 		return nil, err
 	}
 
-	if schema := tool.Schema(); schema != nil {
-		r.mu.RLock()
-		validator := r.validator
-		r.mu.RUnlock()
-
-		if validator != nil {
-			if err := validator.Validate(params, schema); err != nil {
-				return nil, fmt.Errorf("tool %s validation failed: %w", name, err)
-			}
-		}
+	params, invalid := r.prepareParams(tool, params)
+	if invalid != nil {
+		return invalid, nil
 	}
 
 	result, execErr := tool.Execute(ctx, params)
@@ -137,7 +155,7 @@ func (r *Registry) RegisterMCPServer(ctx context.Context, serverPath, serverName
 		return fmt.Errorf("server path is empty")
 	}
 	serverName = strings.TrimSpace(serverName)
-	connectCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	connectCtx, cancel := context.WithTimeout(withMCPClientHooks(ctx, r, mcpServerLabel(serverPath, serverName)), 10*time.Second)
 	defer cancel()
 
 	session, err := newMCPClient(connectCtx, serverPath, r.mcpToolsChangedHandler(serverPath))
@@ -202,7 +220,7 @@ func (r *Registry) RegisterMCPServerWithOptions(ctx context.Context, serverPath,
 		timeout = 10 * time.Second
 	}
 
-	connectCtx, cancel := context.WithTimeout(ctx, timeout)
+	connectCtx, cancel := context.WithTimeout(withMCPClientHooks(ctx, r, mcpServerLabel(serverPath, serverName)), timeout)
 	defer cancel()
 
 	session, err := newMCPClientWithOptions(connectCtx, serverPath, opts, r.mcpToolsChangedHandler(serverPath))
@@ -282,13 +300,15 @@ func connectMCPClientWithOptions(ctx context.Context, spec string, opts MCPServe
 		return nil, err
 	}
 
-	var clientOpts *mcp.ClientOptions
+	clientOpts := &mcp.ClientOptions{}
 	if handler != nil {
-		clientOpts = &mcp.ClientOptions{
-			ToolListChangedHandler: toolListChangedHandler(handler),
-		}
+		clientOpts.ToolListChangedHandler = toolListChangedHandler(handler)
 	}
+	roots := applyMCPClientHooks(ctx, clientOpts)
 	client := mcp.NewClient(&mcp.Implementation{Name: "agentsdk-go", Version: "dev"}, clientOpts)
+	if len(roots) > 0 {
+		client.AddRoots(roots...)
+	}
 
 	dialCtx, cancel := context.WithCancel(context.Background())
 	done := make(chan struct{})
@@ -325,6 +345,8 @@ type mcpSessionInfo struct {
 	session    *mcp.ClientSession
 	toolNames  map[string]struct{}
 	opts       MCPServerOptions
+	// subscribed holds resource URIs subscribed through ReadMCPResource.
+	subscribed map[string]struct{}
 }
 
 func (r *Registry) registerMCPSession(serverID, serverName string, session *mcp.ClientSession, wrappers []Tool, names []string, opts MCPServerOptions) error {
@@ -388,6 +410,7 @@ func buildRemoteToolWrappers(session *mcp.ClientSession, serverName string, tool
 			schema:      schema,
 			session:     session,
 			timeout:     opts.ToolTimeout,
+			idempotent:  desc.Annotations != nil && (desc.Annotations.IdempotentHint || desc.Annotations.ReadOnlyHint),
 		})
 		names = append(names, toolName)
 	}
@@ -620,7 +643,7 @@ func applyMCPTransportOptions(transport mcp.Transport, opts MCPServerOptions) er
 	if transport == nil {
 		return errors.New("mcp transport is nil")
 	}
-	if len(opts.Headers) == 0 && len(opts.Env) == 0 {
+	if len(opts.Headers) == 0 && len(opts.Env) == 0 && opts.WrapTransport == nil {
 		return nil
 	}
 
@@ -634,15 +657,9 @@ func applyMCPTransportOptions(transport mcp.Transport, opts MCPServerOptions) er
 		}
 		impl.Command.Env = mergeEnv(impl.Command.Env, opts.Env)
 	case *mcp.SSEClientTransport:
-		if len(opts.Headers) == 0 {
-			return nil
-		}
-		impl.HTTPClient = withInjectedHeaders(impl.HTTPClient, opts.Headers)
+		impl.HTTPClient = withWrappedTransport(withInjectedHeaders(impl.HTTPClient, opts.Headers), opts.WrapTransport)
 	case *mcp.StreamableClientTransport:
-		if len(opts.Headers) == 0 {
-			return nil
-		}
-		impl.HTTPClient = withInjectedHeaders(impl.HTTPClient, opts.Headers)
+		impl.HTTPClient = withWrappedTransport(withInjectedHeaders(impl.HTTPClient, opts.Headers), opts.WrapTransport)
 	}
 	return nil
 }
@@ -666,6 +683,21 @@ func withInjectedHeaders(client *http.Client, headers map[string]string) *http.C
 	return client
 }
 
+func withWrappedTransport(client *http.Client, wrap func(http.RoundTripper) http.RoundTripper) *http.Client {
+	if wrap == nil {
+		return client
+	}
+	if client == nil {
+		client = &http.Client{}
+	}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = wrap(base)
+	return client
+}
+
 func normalizeHeaders(headers map[string]string) http.Header {
 	if len(headers) == 0 {
 		return nil
@@ -786,16 +818,15 @@ func convertMCPSchema(raw any) (*JSONSchema, error) {
 		}
 	}
 	var schema JSONSchema
-	if err := json.Unmarshal(data, &schema); err == nil && schema.Type != "" {
+	if err := json.Unmarshal(data, &schema); err == nil {
 		return &schema, nil
 	}
 	var generic map[string]interface{}
 	if err := json.Unmarshal(data, &generic); err != nil {
 		return nil, err
 	}
-	if t, ok := generic["type"].(string); ok {
-		schema.Type = t
-	}
+	schema.Type, schema.Types = "", nil
+	schema.setTypes(generic["type"])
 	if props, ok := generic["properties"].(map[string]interface{}); ok {
 		schema.Properties = props
 	}
@@ -823,12 +854,17 @@ type remoteTool struct {
 	schema      *JSONSchema
 	session     *mcp.ClientSession
 	timeout     time.Duration
+	idempotent  bool
 }
 
 func (r *remoteTool) Name() string        { return r.name }
 func (r *remoteTool) Description() string { return r.description }
 func (r *remoteTool) Schema() *JSONSchema { return r.schema }
 
+// Policy marks tools the server annotates as idempotent or read-only so the
+// executor may retry them; timeouts stay with MCPServerOptions.ToolTimeout.
+func (r *remoteTool) Policy() Policy { return Policy{Idempotent: r.idempotent} }
+
 func (r *remoteTool) Execute(ctx context.Context, params map[string]interface{}) (*ToolResult, error) {
 	if r.session == nil {
 		return nil, fmt.Errorf("mcp session is nil")
@@ -837,6 +873,7 @@ func (r *remoteTool) Execute(ctx context.Context, params map[string]interface{})
 		params = map[string]interface{}{}
 	}
 	callCtx := nonNilContext(ctx)
+	defer trackMCPCall(r.session, callCtx)()
 	if r.timeout > 0 {
 		var cancel context.CancelFunc
 		callCtx, cancel = context.WithTimeout(callCtx, r.timeout)
@@ -109,6 +109,37 @@ func TestWithInjectedHeaders(t *testing.T) {
 	}
 }
 
+type wrappedRoundTripper struct{ base http.RoundTripper }
+
+func (w wrappedRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	return w.base.RoundTrip(req)
+}
+
+func TestApplyMCPTransportOptionsWrapsTransport(t *testing.T) {
+	t.Parallel()
+
+	wrap := func(base http.RoundTripper) http.RoundTripper { return wrappedRoundTripper{base: base} }
+	streamable := &mcp.StreamableClientTransport{}
+	if err := applyMCPTransportOptions(streamable, MCPServerOptions{Headers: map[string]string{"X": "1"}, WrapTransport: wrap}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	outer, ok := streamable.HTTPClient.Transport.(wrappedRoundTripper)
+	if !ok {
+		t.Fatalf("expected wrapped transport, got %T", streamable.HTTPClient.Transport)
+	}
+	if _, ok := outer.base.(*headerRoundTripper); !ok {
+		t.Fatalf("expected headers below the wrapper, got %T", outer.base)
+	}
+
+	sse := &mcp.SSEClientTransport{}
+	if err := applyMCPTransportOptions(sse, MCPServerOptions{WrapTransport: wrap}); err != nil {
+		t.Fatalf("unexpected error %v", err)
+	}
+	if outer, ok := sse.HTTPClient.Transport.(wrappedRoundTripper); !ok || outer.base != http.DefaultTransport {
+		t.Fatalf("expected default transport to be wrapped, got %T", sse.HTTPClient.Transport)
+	}
+}
+
 func TestMCPToolListChangedHandlerNilSession(t *testing.T) {
 	t.Parallel()
 
@@ -82,6 +82,7 @@ func TestRegistryExecute(t *testing.T) {
 		params     map[string]interface{}
 		validator  Validator
 		wantErr    string
+		wantResult string
 		wantCalls  int
 		wantParams map[string]interface{}
 	}{
@@ -92,11 +93,11 @@ func TestRegistryExecute(t *testing.T) {
 			wantCalls: 1,
 		},
 		{
-			name:      "validation failure prevents execution",
-			tool:      &spyTool{name: "calc", schema: &JSONSchema{Type: "object"}},
-			validator: &spyValidator{err: errors.New("boom")},
-			wantErr:   "validation failed",
-			wantCalls: 0,
+			name:       "validation failure prevents execution",
+			tool:       &spyTool{name: "calc", schema: &JSONSchema{Type: "object"}},
+			validator:  &spyValidator{err: errors.New("boom")},
+			wantResult: "tool calc validation failed: boom",
+			wantCalls:  0,
 		},
 		{
 			name:       "validation success forwards params to tool",
@@ -130,6 +131,9 @@ func TestRegistryExecute(t *testing.T) {
 			if err != nil {
 				t.Fatalf("execute failed: %v", err)
 			}
+			if tt.wantResult != "" && (res == nil || res.Success || !strings.Contains(res.Output, tt.wantResult)) {
+				t.Fatalf("expected failed result containing %q, got %+v", tt.wantResult, res)
+			}
 			if tt.tool.calls != tt.wantCalls {
 				t.Fatalf("tool calls = %d want %d", tt.tool.calls, tt.wantCalls)
 			}
@@ -0,0 +1,225 @@
+package tool
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParamRepair records one change RepairParams made to tool params.
+type ParamRepair struct {
+	// Pointer is the JSON pointer of the repaired value.
+	Pointer string `json:"pointer"`
+	// Reason describes the change, e.g. "string to integer".
+	Reason string `json:"reason"`
+}
+
+// RepairParams coerces params toward schema before validation: numeric and
+// boolean strings become numbers and booleans, JSON-encoded strings become
+// objects or arrays where the schema expects them, and missing properties
+// with a declared default are filled in. The input map is not modified.
+// Values that cannot be repaired are left for the validator to report.
+func RepairParams(params map[string]interface{}, schema *JSONSchema) (map[string]interface{}, []ParamRepair) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	if schema == nil {
+		return params, nil
+	}
+	out, ok := cloneValue(params).(map[string]interface{})
+	if !ok {
+		return params, nil
+	}
+	r := &paramRepairer{run: &schemaValidation{root: schema}}
+	repaired := r.repair(out, schema, location{}, 0)
+	if obj, ok := repaired.(map[string]interface{}); ok {
+		out = obj
+	}
+	return out, r.repairs
+}
+
+type paramRepairer struct {
+	run     *schemaValidation
+	repairs []ParamRepair
+}
+
+func (r *paramRepairer) record(loc location, reason string) {
+	r.repairs = append(r.repairs, ParamRepair{Pointer: loc.pointer, Reason: reason})
+}
+
+func (r *paramRepairer) repairDefinition(value any, definition interface{}, loc location, hops int) any {
+	schema, ok := schemaFromDefinition(definition)
+	if !ok {
+		return value
+	}
+	return r.repair(value, schema, loc, hops)
+}
+
+func (r *paramRepairer) repair(value any, schema *JSONSchema, loc location, hops int) any {
+	if schema == nil {
+		return value
+	}
+	if schema.Ref != "" && hops < maxRefHops {
+		if target, err := r.run.resolveRef(schema.Ref); err == nil {
+			value = r.repairDefinition(value, target, loc, hops+1)
+		}
+	}
+	for _, def := range schema.AllOf {
+		value = r.repairDefinition(value, def, loc, hops)
+	}
+
+	value = r.coerce(value, schema, loc)
+
+	switch val := value.(type) {
+	case map[string]interface{}:
+		names := make([]string, 0, len(schema.Properties))
+		for name := range schema.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			propSchema, ok := schemaFromDefinition(schema.Properties[name])
+			if !ok {
+				continue
+			}
+			child, present := val[name]
+			if !present {
+				if propSchema.Default == nil {
+					continue
+				}
+				val[name] = cloneValue(propSchema.Default)
+				r.record(loc.key(name), "filled default")
+				continue
+			}
+			val[name] = r.repair(child, propSchema, loc.key(name), 0)
+		}
+		if extra, ok := schemaFromDefinition(schema.AdditionalProperties); ok {
+			for name, child := range val {
+				if _, declared := schema.Properties[name]; !declared {
+					val[name] = r.repair(child, extra, loc.key(name), 0)
+				}
+			}
+		}
+	case []interface{}:
+		if schema.Items != nil {
+			for i, item := range val {
+				val[i] = r.repair(item, schema.Items, loc.index(i), 0)
+			}
+		}
+	}
+	return value
+}
+
+// coerce converts a string to the first declared type it can represent when
+// the value does not already match one of the schema's types.
+func (r *paramRepairer) coerce(value any, schema *JSONSchema, loc location) any {
+	str, ok := value.(string)
+	if !ok {
+		return value
+	}
+	types := schema.Types
+	if len(types) == 0 && schema.Type != "" {
+		types = []string{schema.Type}
+	}
+	for _, typ := range types {
+		if typ == "string" {
+			return value
+		}
+	}
+	for _, typ := range types {
+		if converted, ok := coerceString(str, typ); ok {
+			r.record(loc, "string to "+typ)
+			return converted
+		}
+	}
+	return value
+}
+
+func coerceString(str, typ string) (any, bool) {
+	trimmed := strings.TrimSpace(str)
+	switch typ {
+	case "integer":
+		num, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil || math.IsInf(num, 0) || math.IsNaN(num) || math.Trunc(num) != num {
+			return nil, false
+		}
+		return num, true
+	case "number":
+		num, err := strconv.ParseFloat(trimmed, 64)
+		if err != nil || math.IsInf(num, 0) || math.IsNaN(num) {
+			return nil, false
+		}
+		return num, true
+	case "boolean":
+		switch strings.ToLower(trimmed) {
+		case "true":
+			return true, true
+		case "false":
+			return false, true
+		}
+	case "object":
+		var obj map[string]interface{}
+		if strings.HasPrefix(trimmed, "{") && json.Unmarshal([]byte(trimmed), &obj) == nil {
+			return obj, true
+		}
+	case "array":
+		var arr []interface{}
+		if strings.HasPrefix(trimmed, "[") && json.Unmarshal([]byte(trimmed), &arr) == nil {
+			return arr, true
+		}
+	case "null":
+		if trimmed == "null" {
+			return nil, true
+		}
+	}
+	return nil, false
+}
+
+// prepareParams repairs params against the tool schema, reports each repair
+// to onRepair and runs the validator. A validation failure is returned as a
+// failed ToolResult carrying a structured error for the model to act on.
+func prepareParams(tool Tool, params map[string]interface{}, validator Validator, onRepair func(string, ParamRepair)) (map[string]interface{}, *ToolResult) {
+	schema := tool.Schema()
+	if schema == nil {
+		return params, nil
+	}
+	params, repairs := RepairParams(params, schema)
+	if onRepair != nil {
+		for _, repair := range repairs {
+			onRepair(tool.Name(), repair)
+		}
+	}
+	if validator == nil {
+		return params, nil
+	}
+	if err := validator.Validate(params, schema); err != nil {
+		return params, invalidArgumentsResult(tool.Name(), err, repairs)
+	}
+	return params, nil
+}
+
+func invalidArgumentsResult(name string, err error, repairs []ParamRepair) *ToolResult {
+	err = fmt.Errorf("tool %s validation failed: %w", name, err)
+	payload := map[string]interface{}{
+		"error":   "invalid_arguments",
+		"tool":    name,
+		"message": err.Error(),
+		"hint":    "fix the arguments to match the tool's input schema and call the tool again",
+	}
+	var verr *ValidationError
+	if errors.As(err, &verr) {
+		payload["pointer"] = verr.Pointer
+	}
+	if len(repairs) > 0 {
+		payload["repairs"] = repairs
+	}
+	output, marshalErr := json.Marshal(payload)
+	if marshalErr != nil {
+		output = []byte(strconv.Quote(err.Error()))
+	}
+	return &ToolResult{Success: false, Output: string(output), Data: payload, Error: err}
+}
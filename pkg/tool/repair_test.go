@@ -0,0 +1,118 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRepairParams(t *testing.T) {
+	schema, err := convertMCPSchema(json.RawMessage(`{
+		"type": "object",
+		"$defs": {"opts": {"type": "object", "properties": {"depth": {"type": "integer", "default": 2}}}},
+		"properties": {
+			"limit":   {"type": "integer"},
+			"ratio":   {"type": ["number", "null"]},
+			"dry_run": {"type": "boolean", "default": false},
+			"filter":  {"type": "object", "properties": {"max": {"type": "integer"}}},
+			"ids":     {"type": "array", "items": {"type": "integer"}},
+			"name":    {"type": "string"},
+			"opts":    {"$ref": "#/$defs/opts"},
+			"mode":    {"type": "string", "default": "fast"}
+		}
+	}`))
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	params := map[string]interface{}{
+		"limit":  " 5 ",
+		"ratio":  "0.5",
+		"filter": `{"max": "10"}`,
+		"ids":    `["1", 2]`,
+		"name":   "42",
+		"opts":   map[string]interface{}{},
+		"mode":   "exact",
+	}
+	got, repairs := RepairParams(params, schema)
+	want := map[string]interface{}{
+		"limit":   5.0,
+		"ratio":   0.5,
+		"dry_run": false,
+		"filter":  map[string]interface{}{"max": 10.0},
+		"ids":     []interface{}{1.0, 2.0},
+		"name":    "42",
+		"opts":    map[string]interface{}{"depth": 2.0},
+		"mode":    "exact",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("repaired params:\n%#v\nwant:\n%#v", got, want)
+	}
+	if params["limit"] != " 5 " || len(params) != 7 {
+		t.Fatal("input params must not be modified")
+	}
+	pointers := make([]string, 0, len(repairs))
+	for _, r := range repairs {
+		pointers = append(pointers, r.Pointer+" "+r.Reason)
+	}
+	wantRepairs := []string{
+		"/dry_run filled default", "/filter string to object", "/filter/max string to integer",
+		"/ids string to array", "/ids/0 string to integer", "/limit string to integer",
+		"/opts/depth filled default", "/ratio string to number",
+	}
+	if !reflect.DeepEqual(pointers, wantRepairs) {
+		t.Fatalf("repairs = %v, want %v", pointers, wantRepairs)
+	}
+
+	// Values that cannot be coerced are left for the validator.
+	got, repairs = RepairParams(map[string]interface{}{"limit": "5.5", "dry_run": "maybe"}, schema)
+	if got["limit"] != "5.5" || got["dry_run"] != "maybe" || len(repairs) != 1 {
+		t.Fatalf("unexpected repair of invalid values: %v %v", got, repairs)
+	}
+}
+
+func TestExecutorReturnsInvalidArgumentsResult(t *testing.T) {
+	spy := &spyTool{name: "calc", result: &ToolResult{Success: true, Output: "ok"}, schema: &JSONSchema{
+		Type:       "object",
+		Required:   []string{"n"},
+		Properties: map[string]interface{}{"n": map[string]interface{}{"type": "integer", "minimum": 1}},
+	}}
+	reg := NewRegistry()
+	if err := reg.Register(spy); err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	var observed []string
+	reg.SetRepairHook(func(name string, repair ParamRepair) {
+		observed = append(observed, name+" "+repair.Pointer)
+	})
+	exec := NewExecutor(reg, nil)
+	if res, err := exec.Execute(context.Background(), Call{Name: "calc", Params: map[string]any{"n": "0"}}); err != nil || spy.calls != 1 || spy.params["n"] != "0" || len(observed) != 0 {
+		t.Fatalf("executors only repair params when enabled: %+v %v (params %v)", res, err, spy.params)
+	}
+	spy.calls = 0
+	exec = exec.WithParamRepair(true)
+
+	res, err := exec.Execute(context.Background(), Call{Name: "calc", Params: map[string]any{"n": "3"}})
+	if err != nil || res.Result.Output != "ok" || spy.params["n"] != 3.0 {
+		t.Fatalf("repaired call should run: %+v %v (params %v)", res, err, spy.params)
+	}
+	if len(observed) != 1 || observed[0] != "calc /n" {
+		t.Fatalf("repair hook saw %v", observed)
+	}
+
+	res, err = exec.Execute(context.Background(), Call{Name: "calc", Params: map[string]any{"n": "0"}})
+	if err != nil || res.Result.Success || spy.calls != 1 {
+		t.Fatalf("invalid call should be reported as a failed result without running: %+v %v", res, err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(res.Result.Output), &payload); err != nil {
+		t.Fatalf("output should be JSON: %v", err)
+	}
+	if payload["error"] != "invalid_arguments" || payload["pointer"] != "/n" || !strings.Contains(payload["message"].(string), "less than minimum") {
+		t.Fatalf("unexpected payload %v", payload)
+	}
+	if repairs, _ := payload["repairs"].([]interface{}); len(repairs) != 1 {
+		t.Fatalf("payload should list the repairs tried: %v", payload)
+	}
+}
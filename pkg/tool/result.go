@@ -1,5 +1,7 @@
 package tool
 
+import "github.com/stellarlinkco/agentsdk-go/pkg/model"
+
 // OutputRef describes where tool output has been persisted when it is too large
 // (or otherwise undesirable) to embed directly in ToolResult.Output.
 type OutputRef struct {
@@ -9,10 +11,13 @@ type OutputRef struct {
 }
 
 // ToolResult captures the outcome of a tool invocation.
+// ContentBlocks carries multimodal output (images, documents) that is
+// forwarded to the model alongside the textual Output.
 type ToolResult struct {
-	Success   bool
-	Output    string
-	OutputRef *OutputRef
-	Data      interface{}
-	Error     error
+	Success       bool
+	Output        string
+	OutputRef     *OutputRef
+	ContentBlocks []model.ContentBlock
+	Data          interface{}
+	Error         error
 }
@@ -1,6 +1,10 @@
 package tool
 
-// JSONSchema captures the subset of JSON Schema we require for tool validation.
+import "encoding/json"
+
+// JSONSchema captures the JSON Schema keywords used for tool validation.
+// Nested schemas (Properties values, combinators, $defs) may be either
+// *JSONSchema or the decoded map[string]interface{} form.
 type JSONSchema struct {
 	Type       string                 `json:"type"`
 	Properties map[string]interface{} `json:"properties"`
@@ -10,6 +14,140 @@ type JSONSchema struct {
 	Minimum    *float64               `json:"minimum,omitempty"`
 	Maximum    *float64               `json:"maximum,omitempty"`
 	Items      *JSONSchema            `json:"items,omitempty"`
+
+	// Types lists every allowed type when "type" is an array; Type then
+	// holds the first non-null entry. A "null" entry sets Nullable instead.
+	Types []string `json:"-"`
+	// Nullable also accepts null, as in OpenAPI's "nullable": true.
+	Nullable bool `json:"nullable,omitempty"`
+
+	Const   interface{} `json:"const,omitempty"`
+	Default interface{} `json:"default,omitempty"`
+	Format  string      `json:"format,omitempty"`
+
+	MinLength   *int `json:"minLength,omitempty"`
+	MaxLength   *int `json:"maxLength,omitempty"`
+	MinItems    *int `json:"minItems,omitempty"`
+	MaxItems    *int `json:"maxItems,omitempty"`
+	UniqueItems bool `json:"uniqueItems,omitempty"`
+
+	// AdditionalProperties is false, true or a schema for properties not
+	// listed in Properties. Nil allows anything.
+	AdditionalProperties interface{} `json:"additionalProperties,omitempty"`
+
+	AllOf []interface{} `json:"allOf,omitempty"`
+	AnyOf []interface{} `json:"anyOf,omitempty"`
+	OneOf []interface{} `json:"oneOf,omitempty"`
+
+	// Ref points at another schema within the same document, e.g.
+	// "#/$defs/Address". Remote references are not supported.
+	Ref         string                 `json:"$ref,omitempty"`
+	Defs        map[string]interface{} `json:"$defs,omitempty"`
+	Definitions map[string]interface{} `json:"definitions,omitempty"`
+}
+
+type jsonSchemaFields JSONSchema
+
+// UnmarshalJSON accepts "type" as a string or an array of strings.
+func (s *JSONSchema) UnmarshalJSON(data []byte) error {
+	aux := struct {
+		*jsonSchemaFields
+		Type json.RawMessage `json:"type"`
+	}{jsonSchemaFields: (*jsonSchemaFields)(s)}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	var raw interface{}
+	if len(aux.Type) > 0 {
+		if err := json.Unmarshal(aux.Type, &raw); err != nil {
+			return err
+		}
+	}
+	s.Type, s.Types = "", nil
+	s.setTypes(raw)
+	return nil
+}
+
+// MarshalJSON writes Types back as a type array when more than one type, or
+// a nullable type, was declared that way.
+func (s JSONSchema) MarshalJSON() ([]byte, error) {
+	fields := jsonSchemaFields(s)
+	var typ interface{} = s.Type
+	if len(s.Types) > 0 {
+		types := append([]string(nil), s.Types...)
+		if s.Nullable {
+			types = append(types, "null")
+			fields.Nullable = false
+		}
+		typ = types
+	}
+	return json.Marshal(struct {
+		jsonSchemaFields
+		Type interface{} `json:"type"`
+	}{jsonSchemaFields: fields, Type: typ})
+}
+
+// Map returns the top-level keywords model providers read from a tool's input
+// schema: type, properties, required, the definitions nested properties may
+// $ref, additionalProperties and the combinators. A nil schema yields nil.
+func (s *JSONSchema) Map() map[string]any {
+	if s == nil {
+		return nil
+	}
+	out := map[string]any{}
+	if s.Type != "" {
+		out["type"] = s.Type
+	}
+	if len(s.Properties) > 0 {
+		out["properties"] = s.Properties
+	}
+	if len(s.Required) > 0 {
+		out["required"] = append([]string(nil), s.Required...)
+	}
+	if len(s.Defs) > 0 {
+		out["$defs"] = s.Defs
+	}
+	if len(s.Definitions) > 0 {
+		out["definitions"] = s.Definitions
+	}
+	if s.AdditionalProperties != nil {
+		out["additionalProperties"] = s.AdditionalProperties
+	}
+	if len(s.AnyOf) > 0 {
+		out["anyOf"] = s.AnyOf
+	}
+	if len(s.OneOf) > 0 {
+		out["oneOf"] = s.OneOf
+	}
+	if len(s.AllOf) > 0 {
+		out["allOf"] = s.AllOf
+	}
+	return out
+}
+
+// setTypes applies a decoded "type" value.
+func (s *JSONSchema) setTypes(raw interface{}) {
+	switch v := raw.(type) {
+	case string:
+		s.Type = v
+	case []interface{}:
+		for _, item := range v {
+			name, ok := item.(string)
+			if !ok {
+				continue
+			}
+			if name == "null" {
+				s.Nullable = true
+				continue
+			}
+			s.Types = append(s.Types, name)
+		}
+		if len(s.Types) > 0 {
+			s.Type = s.Types[0]
+		} else if s.Nullable {
+			s.Type = "null"
+		}
+	}
 }
 
 // ToolSchema defines the structure for tool definitions passed to LLM.
@@ -20,6 +20,12 @@ type OutputLimiter interface {
 	MaxOutputSize() int
 }
 
+// CacheableProvider lets a read-only tool opt out of result caching when its
+// output depends on state other than the filesystem, such as running tasks.
+type CacheableProvider interface {
+	Cacheable() bool
+}
+
 // Tool represents an executable capability exposed to the agent runtime.
 type Tool interface {
 	// Name returns the unique identifier of the tool.
@@ -49,6 +55,18 @@ func ShouldDefer(tool Tool) bool {
 	return false
 }
 
+// IsCacheable reports whether results of tool may be served from a
+// ResultCache: it must be read-only and not opt out via CacheableProvider.
+func IsCacheable(tool Tool) bool {
+	if !MetadataOf(tool).IsReadOnly {
+		return false
+	}
+	if provider, ok := tool.(CacheableProvider); ok {
+		return provider.Cacheable()
+	}
+	return true
+}
+
 func MaxOutputSizeOf(tool Tool, fallback int) int {
 	if provider, ok := tool.(OutputLimiter); ok {
 		if limit := provider.MaxOutputSize(); limit > 0 {
@@ -0,0 +1,361 @@
+package tool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TypedTool adapts a Go function with struct input to the Tool interface.
+// Build one with NewTyped.
+type TypedTool[In, Out any] struct {
+	name        string
+	description string
+	schema      *JSONSchema
+	metadata    Metadata
+	fn          func(context.Context, In) (Out, error)
+}
+
+// NewTyped builds a Tool whose parameter schema is reflected from In, which
+// must be a struct (or pointer to one). Fields are named after their json tag
+// and are required unless tagged omitempty. The jsonschema tag adds
+// constraints as comma-separated entries:
+//
+//	description=text   (escape commas as \,)
+//	enum=value         (repeat for every allowed value)
+//	minimum=n, maximum=n, pattern=regexp
+//	required, optional (override the omitempty rule)
+//
+// Params are decoded into In through encoding/json. A string Out becomes the
+// tool output verbatim, a *ToolResult is returned as is, and any other Out is
+// rendered as indented JSON and also stored in ToolResult.Data.
+//
+// NewTyped panics when In cannot be described as a JSON object, since that is
+// a programming error rather than a runtime condition.
+func NewTyped[In, Out any](name, description string, fn func(context.Context, In) (Out, error)) *TypedTool[In, Out] {
+	if strings.TrimSpace(name) == "" {
+		panic("tool: NewTyped requires a name")
+	}
+	if fn == nil {
+		panic("tool: NewTyped requires a function")
+	}
+	schema, err := SchemaFor(reflect.TypeFor[In]())
+	if err != nil {
+		panic(fmt.Sprintf("tool: NewTyped %s: %v", name, err))
+	}
+	return &TypedTool[In, Out]{name: name, description: description, schema: schema, fn: fn}
+}
+
+// WithMetadata sets the metadata reported by the tool, e.g. to mark it
+// read-only so it runs concurrently and is eligible for result caching.
+func (t *TypedTool[In, Out]) WithMetadata(meta Metadata) *TypedTool[In, Out] {
+	t.metadata = meta
+	return t
+}
+
+func (t *TypedTool[In, Out]) Name() string        { return t.name }
+func (t *TypedTool[In, Out]) Description() string { return t.description }
+func (t *TypedTool[In, Out]) Schema() *JSONSchema { return t.schema }
+func (t *TypedTool[In, Out]) Metadata() Metadata  { return t.metadata }
+
+// Execute decodes params into In, calls the function and encodes its result.
+func (t *TypedTool[In, Out]) Execute(ctx context.Context, params map[string]interface{}) (*ToolResult, error) {
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("%s: encode params: %w", t.name, err)
+	}
+	var in In
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return nil, fmt.Errorf("%s: decode params: %w", t.name, err)
+	}
+	out, err := t.fn(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	switch v := any(out).(type) {
+	case *ToolResult:
+		if v == nil {
+			return &ToolResult{Success: true}, nil
+		}
+		return v, nil
+	case string:
+		return &ToolResult{Success: true, Output: v}, nil
+	}
+	encoded, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("%s: encode result: %w", t.name, err)
+	}
+	return &ToolResult{Success: true, Output: string(encoded), Data: out}, nil
+}
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	rawMessageType    = reflect.TypeFor[json.RawMessage]()
+	textMarshalerType = reflect.TypeFor[interface{ MarshalText() ([]byte, error) }]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	durationType      = reflect.TypeFor[time.Duration]()
+)
+
+// SchemaFor reflects a tool parameter schema from a struct type using the
+// same rules as NewTyped.
+func SchemaFor(typ reflect.Type) (*JSONSchema, error) {
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("input type %v is not a struct", typ)
+	}
+	r := schemaReflector{seen: map[reflect.Type]bool{}}
+	props, required, err := r.structFields(typ)
+	if err != nil {
+		return nil, err
+	}
+	return &JSONSchema{Type: "object", Properties: props, Required: required}, nil
+}
+
+type schemaReflector struct {
+	seen map[reflect.Type]bool
+}
+
+// structFields follows encoding/json: unexported and "-" fields are skipped
+// and untagged embedded structs are flattened into the parent.
+func (r schemaReflector) structFields(typ reflect.Type) (map[string]interface{}, []string, error) {
+	if r.seen[typ] {
+		return nil, nil, nil
+	}
+	r.seen[typ] = true
+	defer delete(r.seen, typ)
+
+	props := map[string]interface{}{}
+	var required []string
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(jsonTag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				innerProps, innerRequired, err := r.structFields(embedded)
+				if err != nil {
+					return nil, nil, err
+				}
+				for k, v := range innerProps {
+					if _, exists := props[k]; !exists {
+						props[k] = v
+					}
+				}
+				required = append(required, innerRequired...)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		prop, err := r.typeSchema(field.Type)
+		if err != nil {
+			return nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+		isRequired := !hasTagOption(opts, "omitempty") && !hasTagOption(opts, "omitzero")
+		if tag, ok := field.Tag.Lookup("jsonschema"); ok {
+			if isRequired, err = applySchemaTag(prop, field.Type, tag, isRequired); err != nil {
+				return nil, nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+		props[name] = prop
+		if isRequired {
+			required = append(required, name)
+		}
+	}
+	return props, required, nil
+}
+
+func (r schemaReflector) typeSchema(typ reflect.Type) (map[string]interface{}, error) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	switch {
+	case typ == timeType:
+		return map[string]interface{}{"type": "string", "format": "date-time"}, nil
+	case typ == durationType:
+		return map[string]interface{}{"type": "integer", "description": "duration in nanoseconds"}, nil
+	case typ == rawMessageType:
+		return map[string]interface{}{}, nil
+	case typ.Implements(jsonMarshalerType) || reflect.PointerTo(typ).Implements(jsonMarshalerType):
+		return map[string]interface{}{}, nil
+	case typ.Implements(textMarshalerType) || reflect.PointerTo(typ).Implements(textMarshalerType):
+		return map[string]interface{}{"type": "string"}, nil
+	}
+	switch typ.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]interface{}{}, nil
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 && typ.Kind() == reflect.Slice {
+			return map[string]interface{}{"type": "string", "description": "base64-encoded bytes"}, nil
+		}
+		items, err := r.typeSchema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if typ.Key().Kind() != reflect.String && !typ.Key().Implements(textMarshalerType) {
+			switch typ.Key().Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+				reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			default:
+				return nil, fmt.Errorf("unsupported map key type %v", typ.Key())
+			}
+		}
+		values, err := r.typeSchema(typ.Elem())
+		if err != nil {
+			return nil, err
+		}
+		out := map[string]interface{}{"type": "object"}
+		if len(values) > 0 {
+			out["additionalProperties"] = values
+		}
+		return out, nil
+	case reflect.Struct:
+		if r.seen[typ] {
+			// Recursive types stop at a plain object.
+			return map[string]interface{}{"type": "object"}, nil
+		}
+		props, required, err := r.structFields(typ)
+		if err != nil {
+			return nil, err
+		}
+		out := map[string]interface{}{"type": "object", "properties": props}
+		if len(required) > 0 {
+			out["required"] = required
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("unsupported type %v", typ)
+	}
+}
+
+// applySchemaTag merges a jsonschema struct tag into prop and returns the
+// updated required flag.
+func applySchemaTag(prop map[string]interface{}, typ reflect.Type, tag string, required bool) (bool, error) {
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	target := prop
+	if items, ok := prop["items"].(map[string]interface{}); ok && prop["type"] == "array" {
+		// Value constraints on a slice describe its elements.
+		target = items
+		typ = typ.Elem()
+		for typ.Kind() == reflect.Pointer {
+			typ = typ.Elem()
+		}
+	}
+	for _, entry := range splitSchemaTag(tag) {
+		key, value, hasValue := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		switch key {
+		case "":
+		case "required":
+			required = true
+		case "optional":
+			required = false
+		case "description":
+			prop["description"] = value
+		case "pattern":
+			target["pattern"] = value
+		case "minimum", "maximum":
+			num, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+			if err != nil || !hasValue {
+				return required, fmt.Errorf("invalid %s %q", key, value)
+			}
+			target[key] = num
+		case "enum":
+			enumValue, err := parseEnumValue(typ, value)
+			if err != nil {
+				return required, err
+			}
+			values, _ := target["enum"].([]interface{})
+			target["enum"] = append(values, enumValue)
+		default:
+			return required, fmt.Errorf("unknown jsonschema tag option %q", key)
+		}
+	}
+	return required, nil
+}
+
+func parseEnumValue(typ reflect.Type, value string) (interface{}, error) {
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		num, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid numeric enum %q", value)
+		}
+		return num, nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid boolean enum %q", value)
+		}
+		return b, nil
+	default:
+		return value, nil
+	}
+}
+
+// splitSchemaTag splits on commas that are not escaped with a backslash.
+func splitSchemaTag(tag string) []string {
+	var (
+		parts []string
+		cur   strings.Builder
+	)
+	for i := 0; i < len(tag); i++ {
+		switch {
+		case tag[i] == '\\' && i+1 < len(tag) && tag[i+1] == ',':
+			cur.WriteByte(',')
+			i++
+		case tag[i] == ',':
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(tag[i])
+		}
+	}
+	return append(parts, cur.String())
+}
+
+func hasTagOption(opts, option string) bool {
+	for opts != "" {
+		var name string
+		name, opts, _ = strings.Cut(opts, ",")
+		if name == option {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,132 @@
+package tool
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type searchFilters struct {
+	Extensions []string `json:"extensions,omitempty" jsonschema:"enum=go,enum=md"`
+	MaxSize    *int     `json:"max_size,omitempty"`
+}
+
+type searchInput struct {
+	Query   string        `json:"query" jsonschema:"description=Text to find\\, case-sensitive"`
+	Limit   int           `json:"limit,omitempty" jsonschema:"minimum=1,maximum=50"`
+	Mode    string        `json:"mode,omitempty" jsonschema:"enum=fast,enum=exact,required"`
+	Filters searchFilters `json:"filters,omitempty"`
+	Note    *string       `json:"note" jsonschema:"optional"`
+	Ignored string        `json:"-"`
+}
+
+type searchOutput struct {
+	Matches []string `json:"matches"`
+	Total   int      `json:"total"`
+}
+
+func TestNewTypedSchema(t *testing.T) {
+	typed := NewTyped("search", "search files", func(context.Context, searchInput) (searchOutput, error) {
+		return searchOutput{}, nil
+	})
+	schema := typed.Schema()
+	if schema.Type != "object" || !reflect.DeepEqual(schema.Required, []string{"query", "mode"}) {
+		t.Fatalf("unexpected schema %+v", schema)
+	}
+	if _, ok := schema.Properties["Ignored"]; ok || len(schema.Properties) != 5 {
+		t.Fatalf("unexpected properties %v", schema.Properties)
+	}
+	query := schema.Properties["query"].(map[string]interface{})
+	if query["type"] != "string" || query["description"] != "Text to find, case-sensitive" {
+		t.Fatalf("query schema %v", query)
+	}
+	limit := schema.Properties["limit"].(map[string]interface{})
+	if limit["type"] != "integer" || limit["minimum"] != 1.0 || limit["maximum"] != 50.0 {
+		t.Fatalf("limit schema %v", limit)
+	}
+	filters := schema.Properties["filters"].(map[string]interface{})
+	exts := filters["properties"].(map[string]interface{})["extensions"].(map[string]interface{})
+	if exts["type"] != "array" || !reflect.DeepEqual(exts["items"], map[string]interface{}{"type": "string", "enum": []interface{}{"go", "md"}}) {
+		t.Fatalf("extensions schema %v", exts)
+	}
+
+	// The reflected schema drives the default validator.
+	v := DefaultValidator{}
+	if err := v.Validate(map[string]interface{}{"query": "x", "mode": "fast", "limit": 80.0}, schema); err == nil || !strings.Contains(err.Error(), "maximum") {
+		t.Fatalf("expected maximum violation, got %v", err)
+	}
+	if err := v.Validate(map[string]interface{}{"query": "x", "mode": "fast", "filters": map[string]interface{}{"extensions": []interface{}{"rs"}}}, schema); err == nil {
+		t.Fatal("expected enum violation on nested array items")
+	}
+}
+
+func TestNewTypedExecute(t *testing.T) {
+	typed := NewTyped("search", "search files", func(_ context.Context, in searchInput) (searchOutput, error) {
+		if in.Query == "" {
+			return searchOutput{}, errors.New("empty query")
+		}
+		return searchOutput{Matches: []string{in.Query + ".go"}, Total: in.Limit}, nil
+	}).WithMetadata(Metadata{IsReadOnly: true})
+	if !MetadataOf(typed).IsReadOnly {
+		t.Fatal("metadata not applied")
+	}
+
+	res, err := typed.Execute(context.Background(), map[string]interface{}{"query": "main", "limit": float64(3), "mode": "fast"})
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if out, ok := res.Data.(searchOutput); !ok || out.Total != 3 || !strings.Contains(res.Output, `"main.go"`) || !res.Success {
+		t.Fatalf("unexpected result %+v", res)
+	}
+	if _, err := typed.Execute(context.Background(), map[string]interface{}{"query": 1}); err == nil || !strings.Contains(err.Error(), "decode params") {
+		t.Fatalf("expected decode error, got %v", err)
+	}
+	if _, err := typed.Execute(context.Background(), nil); err == nil || err.Error() != "empty query" {
+		t.Fatalf("expected function error, got %v", err)
+	}
+
+	echo := NewTyped("echo", "echo", func(_ context.Context, in *struct {
+		Text string `json:"text"`
+	}) (string, error) {
+		return in.Text, nil
+	})
+	if res, err := echo.Execute(context.Background(), map[string]interface{}{"text": "hi"}); err != nil || res.Output != "hi" || res.Data != nil {
+		t.Fatalf("string output should be verbatim: %+v %v", res, err)
+	}
+}
+
+func TestSchemaForRejectsUnsupportedTypes(t *testing.T) {
+	if _, err := SchemaFor(reflect.TypeFor[string]()); err == nil {
+		t.Fatal("expected error for non-struct input")
+	}
+	if _, err := SchemaFor(reflect.TypeFor[struct{ C chan int }]()); err == nil {
+		t.Fatal("expected error for channel field")
+	}
+	if _, err := SchemaFor(reflect.TypeFor[struct {
+		N int `jsonschema:"minimum=low"`
+	}]()); err == nil {
+		t.Fatal("expected error for invalid minimum")
+	}
+
+	type node struct {
+		Name     string  `json:"name"`
+		Children []*node `json:"children,omitempty"`
+	}
+	schema, err := SchemaFor(reflect.TypeFor[node]())
+	if err != nil {
+		t.Fatalf("recursive type: %v", err)
+	}
+	children := schema.Properties["children"].(map[string]interface{})
+	if children["items"].(map[string]interface{})["type"] != "object" {
+		t.Fatalf("recursive items should degrade to object: %v", children)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("NewTyped should panic on unsupported input")
+		}
+	}()
+	NewTyped("bad", "bad", func(context.Context, int) (string, error) { return "", nil })
+}
@@ -42,9 +42,15 @@ func (c Call) cloneParams() map[string]any {
 
 // CallResult holds the outcome of executing a Call.
 type CallResult struct {
-	Call        Call
-	Result      *ToolResult
-	Err         error
+	Call   Call
+	Result *ToolResult
+	Err    error
+	// Cached reports that Result was served from the executor's ResultCache
+	// instead of running the tool.
+	Cached bool
+	// Attempts is the number of times the tool ran, including retries under
+	// its Policy. It is zero for cached results.
+	Attempts    int
 	StartedAt   time.Time
 	CompletedAt time.Time
 }
@@ -2,10 +2,19 @@ package tool
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
+	"net"
+	"net/mail"
+	"net/url"
 	"reflect"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
 )
 
 // Validator validates tool parameters before execution.
@@ -13,11 +22,34 @@ type Validator interface {
 	Validate(params map[string]interface{}, schema *JSONSchema) error
 }
 
-// DefaultValidator implements a small subset of JSON Schema validation for tool
-// parameters (required fields, primitive types, nested objects/arrays, enum,
-// pattern, minimum/maximum).
+// DefaultValidator validates tool parameters against JSON Schema: types
+// (including type lists and nullable), enum/const, string length, pattern and
+// format, numeric bounds, object required/properties/additionalProperties,
+// array items/minItems/maxItems/uniqueItems, allOf/anyOf/oneOf and local
+// $ref/$defs. Failures are reported as *ValidationError.
 type DefaultValidator struct{}
 
+// ValidationError describes the first schema violation found. Pointer is the
+// RFC 6901 JSON pointer of the offending value within the params ("" for the
+// params object itself) so a model can locate and fix the argument.
+type ValidationError struct {
+	Pointer string
+	Err     error
+}
+
+func (e *ValidationError) Error() string {
+	if e.Pointer == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s (at %s)", e.Err.Error(), e.Pointer)
+}
+
+func (e *ValidationError) Unwrap() error { return e.Err }
+
+// maxRefHops bounds chains of $ref that do not descend into the value, which
+// would otherwise loop forever on schemas like {"$ref": "#"}.
+const maxRefHops = 32
+
 // Validate ensures that params satisfy the provided schema.
 func (v DefaultValidator) Validate(params map[string]interface{}, schema *JSONSchema) error {
 	if schema == nil {
@@ -35,103 +67,399 @@ func (v DefaultValidator) validateValue(value any, schema *JSONSchema, path stri
 	if schema == nil {
 		return nil
 	}
+	loc := location{path: path}
+	if path != "" {
+		loc.pointer = "/" + path
+	}
+	run := &schemaValidation{root: schema}
+	return run.validate(value, schema, loc, 0)
+}
 
-	expectedType := schema.Type
-	if expectedType == "" {
-		switch {
-		case schema.Items != nil:
-			expectedType = "array"
-		case len(schema.Properties) > 0 || len(schema.Required) > 0:
-			expectedType = "object"
+// location tracks where a value sits in the params, both as the dotted path
+// used in messages and as a JSON pointer.
+type location struct {
+	path    string
+	pointer string
+}
+
+func (l location) key(name string) location {
+	return location{path: joinPath(l.path, name), pointer: l.pointer + "/" + escapePointerToken(name)}
+}
+
+func (l location) index(idx int) location {
+	return location{path: indexPath(l.path, idx), pointer: l.pointer + "/" + strconv.Itoa(idx)}
+}
+
+func (l location) fail(err error) error {
+	return &ValidationError{Pointer: l.pointer, Err: wrapFieldError(l.path, err)}
+}
+
+func escapePointerToken(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+// schemaValidation holds per-call state: the root schema that $ref pointers
+// resolve against, decoded lazily on the first reference.
+type schemaValidation struct {
+	root    *JSONSchema
+	rootDoc interface{}
+	decoded bool
+}
+
+func (s *schemaValidation) validateDefinition(value any, definition interface{}, loc location, hops int) error {
+	switch def := definition.(type) {
+	case bool:
+		if !def {
+			return loc.fail(errors.New("no value is allowed here"))
 		}
+		return nil
+	case nil:
+		return nil
 	}
+	schema, ok := schemaFromDefinition(definition)
+	if !ok {
+		return nil
+	}
+	return s.validate(value, schema, loc, hops)
+}
 
-	if expectedType != "" {
-		if err := validateType(value, expectedType); err != nil {
-			return wrapFieldError(path, err)
+func (s *schemaValidation) validate(value any, schema *JSONSchema, loc location, hops int) error {
+	if schema == nil {
+		return nil
+	}
+
+	if schema.Ref != "" {
+		if hops >= maxRefHops {
+			return loc.fail(fmt.Errorf("$ref %q nests too deeply", schema.Ref))
+		}
+		target, err := s.resolveRef(schema.Ref)
+		if err != nil {
+			return loc.fail(err)
 		}
+		if err := s.validateDefinition(value, target, loc, hops+1); err != nil {
+			return err
+		}
+	}
+
+	if value == nil && schema.Nullable {
+		return nil
+	}
+
+	if err := checkTypes(value, schema); err != nil {
+		return loc.fail(err)
 	}
 
 	if len(schema.Enum) > 0 && !valueInEnum(value, schema.Enum) {
-		return wrapFieldError(path, fmt.Errorf("expected one of %v but got %v", schema.Enum, value))
+		return loc.fail(fmt.Errorf("expected one of %v but got %v", schema.Enum, value))
+	}
+	if schema.Const != nil && !jsonEqual(value, schema.Const) {
+		return loc.fail(fmt.Errorf("expected constant %v but got %v", schema.Const, value))
 	}
 
 	if schema.Pattern != "" {
 		str, ok := value.(string)
 		if !ok {
-			return wrapFieldError(path, fmt.Errorf("expected string but got %T", value))
+			return loc.fail(fmt.Errorf("expected string but got %T", value))
 		}
 		re, err := regexp.Compile(schema.Pattern)
 		if err != nil {
-			return wrapFieldError(path, fmt.Errorf("invalid pattern %q: %w", schema.Pattern, err))
+			return loc.fail(fmt.Errorf("invalid pattern %q: %w", schema.Pattern, err))
 		}
 		if !re.MatchString(str) {
-			return wrapFieldError(path, fmt.Errorf("string %q does not match pattern %q", str, schema.Pattern))
+			return loc.fail(fmt.Errorf("string %q does not match pattern %q", str, schema.Pattern))
+		}
+	}
+	if str, ok := value.(string); ok {
+		if err := checkString(str, schema); err != nil {
+			return loc.fail(err)
 		}
 	}
 
 	if schema.Minimum != nil || schema.Maximum != nil {
 		num, ok := toFloat64(value)
 		if !ok {
-			return wrapFieldError(path, fmt.Errorf("expected number but got %T", value))
+			return loc.fail(fmt.Errorf("expected number but got %T", value))
 		}
 		if schema.Minimum != nil && num < *schema.Minimum {
-			return wrapFieldError(path, fmt.Errorf("value %v is less than minimum %v", num, *schema.Minimum))
+			return loc.fail(fmt.Errorf("value %v is less than minimum %v", num, *schema.Minimum))
 		}
 		if schema.Maximum != nil && num > *schema.Maximum {
-			return wrapFieldError(path, fmt.Errorf("value %v exceeds maximum %v", num, *schema.Maximum))
+			return loc.fail(fmt.Errorf("value %v exceeds maximum %v", num, *schema.Maximum))
 		}
 	}
 
-	switch expectedType {
-	case "object":
-		obj, ok := value.(map[string]interface{})
-		if !ok {
-			return wrapFieldError(path, fmt.Errorf("expected object but got %T", value))
+	switch val := value.(type) {
+	case map[string]interface{}:
+		if err := s.validateObject(val, schema, loc, hops); err != nil {
+			return err
 		}
-		for _, field := range schema.Required {
-			if _, exists := obj[field]; !exists {
-				return fmt.Errorf("missing required field: %s", joinPath(path, field))
-			}
+	case []interface{}:
+		if err := s.validateArray(val, schema, loc); err != nil {
+			return err
 		}
-		for key, child := range obj {
-			propDef, ok := schema.Properties[key]
-			if !ok {
-				continue
+	}
+
+	return s.validateCombinators(value, schema, loc, hops)
+}
+
+// checkTypes applies "type", including type lists. Schemas without a type
+// that describe properties or items are treated as objects or arrays.
+func checkTypes(value any, schema *JSONSchema) error {
+	types := schema.Types
+	if len(types) == 0 && schema.Type != "" {
+		types = []string{schema.Type}
+	}
+	if len(types) == 0 {
+		switch {
+		case schema.Items != nil:
+			types = []string{"array"}
+		case len(schema.Properties) > 0 || len(schema.Required) > 0:
+			types = []string{"object"}
+		}
+	}
+	switch len(types) {
+	case 0:
+		return nil
+	case 1:
+		return validateType(value, types[0])
+	}
+	for _, typ := range types {
+		err := validateType(value, typ)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, errUnsupportedSchemaType) {
+			return err
+		}
+	}
+	return fmt.Errorf("expected one of types %v but got %T", types, value)
+}
+
+func checkString(str string, schema *JSONSchema) error {
+	length := utf8.RuneCountInString(str)
+	if schema.MinLength != nil && length < *schema.MinLength {
+		return fmt.Errorf("string length %d is less than minLength %d", length, *schema.MinLength)
+	}
+	if schema.MaxLength != nil && length > *schema.MaxLength {
+		return fmt.Errorf("string length %d exceeds maxLength %d", length, *schema.MaxLength)
+	}
+	if schema.Format != "" && !matchesFormat(str, schema.Format) {
+		return fmt.Errorf("string %q is not a valid %s", str, schema.Format)
+	}
+	return nil
+}
+
+func (s *schemaValidation) validateObject(obj map[string]interface{}, schema *JSONSchema, loc location, hops int) error {
+	for _, field := range schema.Required {
+		if _, exists := obj[field]; !exists {
+			return &ValidationError{Pointer: loc.key(field).pointer, Err: fmt.Errorf("missing required field: %s", joinPath(loc.path, field))}
+		}
+	}
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		child := obj[key]
+		if propDef, ok := schema.Properties[key]; ok {
+			if err := s.validateDefinition(child, propDef, loc.key(key), 0); err != nil {
+				return err
 			}
-			propSchema, ok := schemaFromDefinition(propDef)
-			if !ok {
-				continue
+			continue
+		}
+		switch extra := schema.AdditionalProperties.(type) {
+		case nil:
+		case bool:
+			if !extra {
+				return loc.key(key).fail(fmt.Errorf("unexpected property %q", key))
 			}
-			if err := v.validateValue(child, propSchema, joinPath(path, key)); err != nil {
+		default:
+			if err := s.validateDefinition(child, extra, loc.key(key), 0); err != nil {
 				return err
 			}
 		}
-	case "array":
-		items := schema.Items
-		if items == nil {
-			return nil
+	}
+	return nil
+}
+
+func (s *schemaValidation) validateArray(arr []interface{}, schema *JSONSchema, loc location) error {
+	if schema.MinItems != nil && len(arr) < *schema.MinItems {
+		return loc.fail(fmt.Errorf("array has %d items, fewer than minItems %d", len(arr), *schema.MinItems))
+	}
+	if schema.MaxItems != nil && len(arr) > *schema.MaxItems {
+		return loc.fail(fmt.Errorf("array has %d items, more than maxItems %d", len(arr), *schema.MaxItems))
+	}
+	if schema.UniqueItems {
+		for i := 1; i < len(arr); i++ {
+			for j := 0; j < i; j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					return loc.index(i).fail(fmt.Errorf("duplicates item %d but items must be unique", j))
+				}
+			}
 		}
-		arr, ok := value.([]interface{})
-		if !ok {
-			return wrapFieldError(path, fmt.Errorf("expected array but got %T", value))
+	}
+	if schema.Items == nil {
+		return nil
+	}
+	for idx, item := range arr {
+		if err := s.validate(item, schema.Items, loc.index(idx), 0); err != nil {
+			return err
 		}
-		for idx, item := range arr {
-			if err := v.validateValue(item, items, indexPath(path, idx)); err != nil {
-				return err
+	}
+	return nil
+}
+
+func (s *schemaValidation) validateCombinators(value any, schema *JSONSchema, loc location, hops int) error {
+	for _, def := range schema.AllOf {
+		if err := s.validateDefinition(value, def, loc, hops); err != nil {
+			return err
+		}
+	}
+	if len(schema.AnyOf) > 0 {
+		var failures []string
+		for i, def := range schema.AnyOf {
+			err := s.validateDefinition(value, def, loc, hops)
+			if err == nil {
+				failures = nil
+				break
+			}
+			failures = append(failures, fmt.Sprintf("[%d] %v", i, err))
+		}
+		if failures != nil {
+			return loc.fail(fmt.Errorf("does not match any schema in anyOf: %s", strings.Join(failures, "; ")))
+		}
+	}
+	if len(schema.OneOf) > 0 {
+		var (
+			matched  []int
+			failures []string
+		)
+		for i, def := range schema.OneOf {
+			if err := s.validateDefinition(value, def, loc, hops); err != nil {
+				failures = append(failures, fmt.Sprintf("[%d] %v", i, err))
+				continue
 			}
+			matched = append(matched, i)
+		}
+		switch {
+		case len(matched) == 0:
+			return loc.fail(fmt.Errorf("does not match any schema in oneOf: %s", strings.Join(failures, "; ")))
+		case len(matched) > 1:
+			return loc.fail(fmt.Errorf("matches schemas %v in oneOf but must match exactly one", matched))
 		}
 	}
-
 	return nil
 }
 
+// resolveRef looks up a same-document reference such as "#/$defs/Item".
+func (s *schemaValidation) resolveRef(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are resolved", ref)
+	}
+	if !s.decoded {
+		s.decoded = true
+		data, err := json.Marshal(s.root)
+		if err != nil {
+			return nil, fmt.Errorf("resolve $ref %q: %w", ref, err)
+		}
+		if err := json.Unmarshal(data, &s.rootDoc); err != nil {
+			return nil, fmt.Errorf("resolve $ref %q: %w", ref, err)
+		}
+	}
+	return resolveJSONPointer(s.rootDoc, ref)
+}
+
+// resolveJSONPointer looks up a local "#/..." reference inside doc.
+func resolveJSONPointer(doc interface{}, ref string) (interface{}, error) {
+	fragment, ok := strings.CutPrefix(ref, "#")
+	if !ok {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are resolved", ref)
+	}
+	if unescaped, err := url.PathUnescape(fragment); err == nil {
+		fragment = unescaped
+	}
+	node := doc
+	if fragment == "" {
+		return node, nil
+	}
+	if !strings.HasPrefix(fragment, "/") {
+		return nil, fmt.Errorf("unsupported $ref %q", ref)
+	}
+	for _, token := range strings.Split(fragment[1:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch cur := node.(type) {
+		case map[string]interface{}:
+			next, ok := cur[token]
+			if !ok {
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+			node = next
+		case []interface{}:
+			idx, err := strconv.Atoi(token)
+			if err != nil || idx < 0 || idx >= len(cur) {
+				return nil, fmt.Errorf("$ref %q not found", ref)
+			}
+			node = cur[idx]
+		default:
+			return nil, fmt.Errorf("$ref %q not found", ref)
+		}
+	}
+	return node, nil
+}
+
+var (
+	uuidPattern     = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+	hostnamePattern = regexp.MustCompile(`^(?i)[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?(\.[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?)*$`)
+)
+
+// matchesFormat checks the common string formats. Unknown formats are
+// annotations only and always match.
+func matchesFormat(str, format string) bool {
+	switch format {
+	case "date-time":
+		_, err := time.Parse(time.RFC3339, str)
+		return err == nil
+	case "date":
+		_, err := time.Parse(time.DateOnly, str)
+		return err == nil
+	case "time":
+		_, err := time.Parse("15:04:05Z07:00", str)
+		return err == nil
+	case "email":
+		addr, err := mail.ParseAddress(str)
+		return err == nil && addr.Address == str
+	case "uri":
+		u, err := url.Parse(str)
+		return err == nil && u.IsAbs()
+	case "uri-reference":
+		_, err := url.Parse(str)
+		return err == nil
+	case "uuid":
+		return uuidPattern.MatchString(str)
+	case "ipv4":
+		ip := net.ParseIP(str)
+		return ip != nil && ip.To4() != nil && !strings.Contains(str, ":")
+	case "ipv6":
+		return net.ParseIP(str) != nil && strings.Contains(str, ":")
+	case "hostname":
+		return len(str) <= 253 && hostnamePattern.MatchString(str)
+	case "regex":
+		_, err := regexp.Compile(str)
+		return err == nil
+	default:
+		return true
+	}
+}
+
 func schemaFromDefinition(definition interface{}) (*JSONSchema, bool) {
 	switch def := definition.(type) {
 	case map[string]interface{}:
 		return schemaFromMap(def), true
 	case *JSONSchema:
 		return def, true
+	case JSONSchema:
+		return &def, true
 	default:
 		return nil, false
 	}
@@ -139,9 +467,7 @@ func schemaFromDefinition(definition interface{}) (*JSONSchema, bool) {
 
 func schemaFromMap(def map[string]interface{}) *JSONSchema {
 	schema := &JSONSchema{}
-	if value, ok := def["type"].(string); ok {
-		schema.Type = value
-	}
+	schema.setTypes(def["type"])
 	if props, ok := def["properties"].(map[string]interface{}); ok {
 		schema.Properties = props
 	}
@@ -161,6 +487,30 @@ func schemaFromMap(def map[string]interface{}) *JSONSchema {
 			schema.Items = itemsSchema
 		}
 	}
+	if nullable, ok := def["nullable"].(bool); ok && nullable {
+		schema.Nullable = true
+	}
+	schema.Const = def["const"]
+	schema.Default = def["default"]
+	if format, ok := def["format"].(string); ok {
+		schema.Format = format
+	}
+	schema.MinLength = extractIntPointer(def["minLength"])
+	schema.MaxLength = extractIntPointer(def["maxLength"])
+	schema.MinItems = extractIntPointer(def["minItems"])
+	schema.MaxItems = extractIntPointer(def["maxItems"])
+	if unique, ok := def["uniqueItems"].(bool); ok {
+		schema.UniqueItems = unique
+	}
+	schema.AdditionalProperties = def["additionalProperties"]
+	schema.AllOf, _ = def["allOf"].([]interface{})
+	schema.AnyOf, _ = def["anyOf"].([]interface{})
+	schema.OneOf, _ = def["oneOf"].([]interface{})
+	if ref, ok := def["$ref"].(string); ok {
+		schema.Ref = ref
+	}
+	schema.Defs, _ = def["$defs"].(map[string]interface{})
+	schema.Definitions, _ = def["definitions"].(map[string]interface{})
 	return schema
 }
 
@@ -211,6 +561,15 @@ func extractFloatPointer(raw any) (*float64, bool) {
 	return &value, true
 }
 
+func extractIntPointer(raw any) *int {
+	value, ok := toFloat64(raw)
+	if !ok {
+		return nil
+	}
+	n := int(value)
+	return &n
+}
+
 func toFloat64(value any) (float64, bool) {
 	switch v := value.(type) {
 	case float32:
@@ -255,11 +614,42 @@ func valueInEnum(value any, values []interface{}) bool {
 }
 
 func enumEqual(a, b any) bool {
+	return jsonEqual(a, b)
+}
+
+// jsonEqual compares values by JSON semantics: numbers of any Go type are
+// equal when numerically equal, and objects and arrays compare element-wise.
+func jsonEqual(a, b any) bool {
 	if aNum, ok := toFloat64(a); ok {
 		if bNum, ok := toFloat64(b); ok {
 			return aNum == bNum
 		}
 	}
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for k, v := range av {
+			other, ok := bv[k]
+			if !ok || !jsonEqual(v, other) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok || len(av) != len(bv) {
+			return false
+		}
+		for i := range av {
+			if !jsonEqual(av[i], bv[i]) {
+				return false
+			}
+		}
+		return true
+	}
 	return reflect.DeepEqual(a, b)
 }
 
@@ -284,6 +674,8 @@ func wrapFieldError(path string, err error) error {
 	return fmt.Errorf("field %s: %w", path, err)
 }
 
+var errUnsupportedSchemaType = errors.New("unsupported schema type")
+
 func validateType(value interface{}, expected string) error {
 	switch expected {
 	case "string":
@@ -318,7 +710,7 @@ func validateType(value interface{}, expected string) error {
 			return nil
 		}
 	default:
-		return fmt.Errorf("unsupported schema type %q", expected)
+		return fmt.Errorf("%w %q", errUnsupportedSchemaType, expected)
 	}
 	return fmt.Errorf("expected %s but got %T", expected, value)
 }
@@ -0,0 +1,174 @@
+package tool
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// mcpStyleSchema is a richer schema of the kind MCP servers publish.
+const mcpStyleSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"required": ["id", "tags", "target"],
+	"$defs": {
+		"address": {
+			"type": "object",
+			"required": ["city"],
+			"properties": {"city": {"type": "string", "minLength": 2}, "zip": {"type": ["string", "null"], "pattern": "^[0-9]{5}$"}}
+		}
+	},
+	"properties": {
+		"id": {"type": "string", "format": "uuid"},
+		"kind": {"const": "order"},
+		"tags": {"type": "array", "minItems": 1, "maxItems": 3, "uniqueItems": true, "items": {"type": "string", "maxLength": 5}},
+		"ship_to": {"$ref": "#/$defs/address"},
+		"target": {"oneOf": [
+			{"type": "object", "required": ["email"], "properties": {"email": {"type": "string", "format": "email"}}},
+			{"type": "object", "required": ["url"], "properties": {"url": {"type": "string", "format": "uri"}}}
+		]},
+		"amount": {"anyOf": [{"type": "integer", "minimum": 0}, {"type": "string", "pattern": "^[0-9]+$"}]},
+		"meta": {"type": "object", "additionalProperties": {"type": "number"}},
+		"note": {"allOf": [{"type": "string"}, {"maxLength": 4}]}
+	}
+}`
+
+func TestDefaultValidatorFullSchema(t *testing.T) {
+	schema, err := convertMCPSchema(json.RawMessage(mcpStyleSchema))
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	valid := func() map[string]interface{} {
+		return map[string]interface{}{
+			"id":      "6f1c2a3e-8a5b-4c1d-9e2f-0a1b2c3d4e5f",
+			"kind":    "order",
+			"tags":    []interface{}{"a", "b"},
+			"ship_to": map[string]interface{}{"city": "Oslo", "zip": nil},
+			"target":  map[string]interface{}{"email": "ops@example.com"},
+			"amount":  "42",
+			"meta":    map[string]interface{}{"weight": 1.5},
+			"note":    "ok",
+		}
+	}
+	v := DefaultValidator{}
+	if err := v.Validate(valid(), schema); err != nil {
+		t.Fatalf("valid params rejected: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		mutate  func(map[string]interface{})
+		pointer string
+		message string
+	}{
+		{"additional property", func(p map[string]interface{}) { p["extra"] = 1 }, "/extra", "unexpected property"},
+		{"const", func(p map[string]interface{}) { p["kind"] = "refund" }, "/kind", "expected constant"},
+		{"format", func(p map[string]interface{}) { p["id"] = "not-a-uuid" }, "/id", "not a valid uuid"},
+		{"minItems", func(p map[string]interface{}) { p["tags"] = []interface{}{} }, "/tags", "fewer than minItems"},
+		{"uniqueItems", func(p map[string]interface{}) { p["tags"] = []interface{}{"a", "a"} }, "/tags/1", "must be unique"},
+		{"item maxLength", func(p map[string]interface{}) { p["tags"] = []interface{}{"toolong"} }, "/tags/0", "exceeds maxLength"},
+		{"ref", func(p map[string]interface{}) { p["ship_to"] = map[string]interface{}{"city": "X"} }, "/ship_to/city", "less than minLength"},
+		{"ref required", func(p map[string]interface{}) { p["ship_to"] = map[string]interface{}{} }, "/ship_to/city", "missing required field: ship_to.city"},
+		{"nullable pattern", func(p map[string]interface{}) { p["ship_to"] = map[string]interface{}{"city": "Oslo", "zip": "12"} }, "/ship_to/zip", "does not match pattern"},
+		{"oneOf none", func(p map[string]interface{}) { p["target"] = map[string]interface{}{"email": "nope"} }, "/target", "does not match any schema in oneOf"},
+		{"oneOf both", func(p map[string]interface{}) {
+			p["target"] = map[string]interface{}{"email": "a@b.co", "url": "https://b.co"}
+		}, "/target", "must match exactly one"},
+		{"anyOf", func(p map[string]interface{}) { p["amount"] = -1.0 }, "/amount", "does not match any schema in anyOf"},
+		{"additionalProperties schema", func(p map[string]interface{}) {
+			p["meta"] = map[string]interface{}{"weight": "heavy"}
+		}, "/meta/weight", "expected number"},
+		{"allOf", func(p map[string]interface{}) { p["note"] = "too long" }, "/note", "exceeds maxLength"},
+		{"missing required", func(p map[string]interface{}) { delete(p, "target") }, "/target", "missing required field: target"},
+	}
+	for _, tt := range tests {
+		params := valid()
+		tt.mutate(params)
+		err := v.Validate(params, schema)
+		var verr *ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("%s: expected ValidationError, got %v", tt.name, err)
+		}
+		if verr.Pointer != tt.pointer || !strings.Contains(err.Error(), tt.message) || !strings.Contains(err.Error(), "(at "+tt.pointer+")") {
+			t.Fatalf("%s: got pointer %q, error %q", tt.name, verr.Pointer, err)
+		}
+	}
+}
+
+func TestDefaultValidatorRefEdgeCases(t *testing.T) {
+	v := DefaultValidator{}
+	// Recursive schema through "#".
+	tree := &JSONSchema{Type: "object", Properties: map[string]interface{}{
+		"name":     map[string]interface{}{"type": "string"},
+		"children": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#"}},
+	}}
+	params := map[string]interface{}{"name": "root", "children": []interface{}{
+		map[string]interface{}{"name": "leaf", "children": []interface{}{map[string]interface{}{"name": 3}}},
+	}}
+	err := v.Validate(params, tree)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Pointer != "/children/0/children/0/name" {
+		t.Fatalf("expected nested pointer, got %v", err)
+	}
+
+	loop := &JSONSchema{Type: "object", Properties: map[string]interface{}{"x": map[string]interface{}{"$ref": "#/$defs/a"}},
+		Defs: map[string]interface{}{"a": map[string]interface{}{"$ref": "#/$defs/a"}}}
+	if err := v.Validate(map[string]interface{}{"x": 1}, loop); err == nil || !strings.Contains(err.Error(), "nests too deeply") {
+		t.Fatalf("expected ref loop error, got %v", err)
+	}
+	remote := &JSONSchema{Type: "object", Properties: map[string]interface{}{"x": map[string]interface{}{"$ref": "https://example.com/s.json"}}}
+	if err := v.Validate(map[string]interface{}{"x": 1}, remote); err == nil || !strings.Contains(err.Error(), "only local references") {
+		t.Fatalf("expected remote ref error, got %v", err)
+	}
+	escaped := &JSONSchema{Type: "object", Properties: map[string]interface{}{"a/b": map[string]interface{}{"type": "string"}}}
+	if err := v.Validate(map[string]interface{}{"a/b": 1}, escaped); !errors.As(err, &verr) || verr.Pointer != "/a~1b" {
+		t.Fatalf("pointer tokens should be escaped, got %v", err)
+	}
+}
+
+func TestJSONSchemaTypeListRoundTrip(t *testing.T) {
+	var schema JSONSchema
+	if err := json.Unmarshal([]byte(`{"type":["integer","string","null"],"minLength":1}`), &schema); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if schema.Type != "integer" || len(schema.Types) != 2 || !schema.Nullable || *schema.MinLength != 1 {
+		t.Fatalf("unexpected schema %+v", schema)
+	}
+	v := DefaultValidator{}
+	for _, ok := range []interface{}{float64(3), "x", nil} {
+		if err := v.validateValue(ok, &schema, "v"); err != nil {
+			t.Fatalf("%v should be accepted: %v", ok, err)
+		}
+	}
+	if err := v.validateValue(true, &schema, "v"); err == nil || !strings.Contains(err.Error(), "expected one of types") {
+		t.Fatalf("expected type list error, got %v", err)
+	}
+	data, err := json.Marshal(schema)
+	if err != nil || !strings.Contains(string(data), `"type":["integer","string","null"]`) || strings.Contains(string(data), "nullable") {
+		t.Fatalf("unexpected marshal %s %v", data, err)
+	}
+	data, _ = json.Marshal(JSONSchema{Type: "string"})
+	if !strings.Contains(string(data), `"type":"string"`) {
+		t.Fatalf("plain type should stay a string: %s", data)
+	}
+}
+
+func TestJSONSchemaMap(t *testing.T) {
+	var schema JSONSchema
+	if err := json.Unmarshal([]byte(mcpStyleSchema), &schema); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	m := schema.Map()
+	for _, key := range []string{"type", "properties", "required", "$defs"} {
+		if m[key] == nil {
+			t.Fatalf("map is missing %s: %v", key, m)
+		}
+	}
+	if m["additionalProperties"] != false {
+		t.Fatalf("additionalProperties should be kept: %v", m["additionalProperties"])
+	}
+	if (*JSONSchema)(nil).Map() != nil {
+		t.Fatal("nil schema should map to nil")
+	}
+}
@@ -0,0 +1,202 @@
+package tool
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stellarlinkco/agentsdk-go/pkg/gitignore"
+)
+
+// ErrTreeUnwatched reports a directory the TreeWatcher could not watch, for
+// example because the inotify watch limit was reached. Changes below it go
+// unnoticed.
+var ErrTreeUnwatched = errors.New("directory could not be watched")
+
+// TreeEvent is a change below a TreeWatcher's root. Err is set instead of
+// Path when changes may have been missed: fsnotify.ErrEventOverflow after
+// dropped events, or ErrTreeUnwatched for a directory that cannot be
+// watched.
+type TreeEvent struct {
+	Path string
+	Op   fsnotify.Op
+	Err  error
+}
+
+// TreeWatcher watches a directory tree with one fsnotify watcher shared by
+// several subscribers. Gitignored directories (including .git), vendor and
+// node_modules are not watched; edits there made outside the agent's tools
+// are not reported.
+type TreeWatcher struct {
+	root    string
+	matcher *gitignore.Matcher
+	watcher *fsnotify.Watcher
+	done    chan struct{}
+
+	mu     sync.Mutex
+	subs   map[int]func(TreeEvent)
+	nextID int
+	// unwatched is the first directory that could not be watched.
+	unwatched error
+	closed    bool
+}
+
+// NewTreeWatcher starts watching every directory below root. Only a failure
+// to watch root itself is returned; failures below it are reported by Err.
+func NewTreeWatcher(root string) (*TreeWatcher, error) {
+	root = filepath.Clean(root)
+	if abs, err := filepath.Abs(root); err == nil {
+		root = abs
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	matcher, _ := gitignore.NewMatcher(root) //nolint:errcheck // best-effort gitignore
+	w := &TreeWatcher{root: root, matcher: matcher, watcher: watcher, done: make(chan struct{}), subs: map[int]func(TreeEvent){}}
+	if err := watcher.Add(root); err != nil {
+		_ = watcher.Close()
+		return nil, err
+	}
+	w.addTree(root)
+	go w.run()
+	return w, nil
+}
+
+// Root reports the watched directory.
+func (w *TreeWatcher) Root() string {
+	if w == nil {
+		return ""
+	}
+	return w.root
+}
+
+// Err reports the first directory that could not be watched, if any.
+func (w *TreeWatcher) Err() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.unwatched
+}
+
+// Subscribe registers fn for every later event and returns a function that
+// removes it. fn runs on the watcher goroutine and must not block.
+func (w *TreeWatcher) Subscribe(fn func(TreeEvent)) (cancel func()) {
+	if w == nil || fn == nil {
+		return func() {}
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	id := w.nextID
+	w.nextID++
+	w.subs[id] = fn
+	return func() {
+		w.mu.Lock()
+		delete(w.subs, id)
+		w.mu.Unlock()
+	}
+}
+
+// Close stops watching.
+func (w *TreeWatcher) Close() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	err := w.watcher.Close()
+	<-w.done
+	return err
+}
+
+func (w *TreeWatcher) skipped(path string) bool {
+	if path == w.root {
+		return false
+	}
+	switch filepath.Base(path) {
+	case "vendor", "node_modules":
+		return true
+	}
+	rel, err := filepath.Rel(w.root, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return true
+	}
+	return w.matcher != nil && w.matcher.Match(rel, true)
+}
+
+// addTree watches dir and the directories below it.
+func (w *TreeWatcher) addTree(dir string) {
+	_ = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if w.skipped(path) {
+			return filepath.SkipDir
+		}
+		if path == w.root {
+			return nil // added by NewTreeWatcher
+		}
+		if err := w.watcher.Add(path); err != nil {
+			w.fail(fmt.Errorf("%w: %s: %v", ErrTreeUnwatched, path, err))
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
+
+func (w *TreeWatcher) fail(err error) {
+	w.mu.Lock()
+	if w.unwatched == nil {
+		w.unwatched = err
+	}
+	w.mu.Unlock()
+	w.publish(TreeEvent{Err: err})
+}
+
+func (w *TreeWatcher) publish(event TreeEvent) {
+	w.mu.Lock()
+	subs := make([]func(TreeEvent), 0, len(w.subs))
+	for _, fn := range w.subs {
+		subs = append(subs, fn)
+	}
+	w.mu.Unlock()
+	for _, fn := range subs {
+		fn(event)
+	}
+}
+
+func (w *TreeWatcher) run() {
+	defer close(w.done)
+	for {
+		select {
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			path := filepath.Clean(event.Name)
+			if event.Has(fsnotify.Create) {
+				if info, err := os.Lstat(path); err == nil && info.IsDir() {
+					w.addTree(path)
+				}
+			}
+			w.publish(TreeEvent{Path: path, Op: event.Op})
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.publish(TreeEvent{Err: err})
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package tool
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestTreeWatcherSkipsIgnoredDirectories(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"src", "build", "vendor", "node_modules/pkg"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("build/\n"), 0o600); err != nil {
+		t.Fatalf("write .gitignore: %v", err)
+	}
+	tree, err := NewTreeWatcher(dir)
+	if err != nil {
+		t.Fatalf("tree watcher: %v", err)
+	}
+	t.Cleanup(func() { _ = tree.Close() })
+
+	watched := map[string]bool{}
+	for _, path := range tree.watcher.WatchList() {
+		rel, _ := filepath.Rel(tree.Root(), path)
+		watched[rel] = true
+	}
+	if !watched["."] || !watched["src"] {
+		t.Fatalf("expected root and src to be watched, got %v", watched)
+	}
+	for _, skipped := range []string{"build", "vendor", "node_modules", "node_modules/pkg"} {
+		if watched[skipped] {
+			t.Fatalf("%s should not be watched", skipped)
+		}
+	}
+}
+
+func TestTreeWatcherFansOutEvents(t *testing.T) {
+	dir := t.TempDir()
+	tree, err := NewTreeWatcher(dir)
+	if err != nil {
+		t.Fatalf("tree watcher: %v", err)
+	}
+	t.Cleanup(func() { _ = tree.Close() })
+
+	var (
+		mu   sync.Mutex
+		seen [2][]string
+	)
+	for i := range seen {
+		i := i
+		tree.Subscribe(func(event TreeEvent) {
+			mu.Lock()
+			seen[i] = append(seen[i], event.Path)
+			mu.Unlock()
+		})
+	}
+	if err := os.Mkdir(filepath.Join(dir, "new"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	// Directories created later are watched too.
+	target := filepath.Join(tree.Root(), "new", "f.txt")
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		_ = os.WriteFile(target, []byte("x"), 0o600)
+		mu.Lock()
+		done := contains(seen[0], target) && contains(seen[1], target)
+		mu.Unlock()
+		if done {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("events not delivered to every subscriber: %v", seen)
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+}
+
+func contains(items []string, want string) bool {
+	for _, item := range items {
+		if item == want {
+			return true
+		}
+	}
+	return false
+}